@@ -0,0 +1,167 @@
+// Команда importer переносит ссылки из экспорта Bitly, YOURLS или Kutt в хранилище сервиса
+// с сохранением коротких имен и времени создания. Хранилище задается теми же флагами и переменными
+// окружения, что и у сервиса (-f, -d, -c). Результат по каждой ссылке выводится в stdout в формате NDJSON,
+// итоги - в stderr.
+//
+// Пример:
+//
+//	importer -source bitly -input links.csv -d postgres://localhost/shortener
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/blocklist"
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/importer"
+	"github.com/IvanKondrashkov/go-shortener/internal/logger"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/IvanKondrashkov/go-shortener/internal/storage/db"
+	"github.com/IvanKondrashkov/go-shortener/internal/storage/file"
+	"github.com/IvanKondrashkov/go-shortener/internal/storage/mem"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Параметры импорта
+var (
+	source string // Сервис экспорта
+	input  string // Путь к файлу экспорта
+	format string // Формат экспорта, по умолчанию определяется по расширению файла
+	user   string // UUID пользователя, которому принадлежат импортированные ссылки
+)
+
+func main() {
+	flag.StringVar(&source, "source", "", "Export source: bitly, yourls or kutt")
+	flag.StringVar(&input, "input", "", "Export file path")
+	flag.StringVar(&format, "format", "", "Export format: csv or json (default by file extension)")
+	flag.StringVar(&user, "user", "", "Owner user UUID")
+
+	err := config.ParseConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	if source == "" || input == "" {
+		return errors.New("source and input are required")
+	}
+	if config.FileStoragePath == "" && config.DatabaseDSN == "" {
+		return errors.New("file storage path or database dsn is required")
+	}
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(input)), ".")
+	}
+
+	ctx := context.Background()
+	if user != "" {
+		userID, err := uuid.Parse(user)
+		if err != nil {
+			return fmt.Errorf("user is invalidate: %w", err)
+		}
+		ctx = customContext.SetContextUserID(ctx, userID)
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	links, err := importer.Parse(bufio.NewReader(f), source, format)
+	if err != nil {
+		return err
+	}
+
+	zl, err := logger.NewZapLogger(config.LogLevel)
+	if err != nil {
+		return err
+	}
+	defer zl.Sync()
+
+	loadCtx, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	var newRepository service.Repository
+	var newRunner service.Runner
+
+	newRepository = mem.NewRepository(zl)
+	newRunner = newRepository
+	if config.FileStoragePath != "" {
+		newRepository, err = file.NewRepository(zl, newRepository, config.FileStoragePath)
+		newRunner = newRepository
+		if err != nil {
+			return err
+		}
+
+		err = newRepository.Load(loadCtx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.DatabaseDSN != "" {
+		newRepository, err = db.NewRepository(loadCtx, zl, config.DatabaseDSN)
+		newRunner = newRepository
+		if err != nil {
+			return err
+		}
+		defer newRepository.Close()
+	}
+
+	var newBlocklist *blocklist.List
+	if config.BlocklistPath != "" {
+		newBlocklist, err = blocklist.Load(config.BlocklistPath)
+		if err != nil {
+			return err
+		}
+		zl.Log.Info("Blocklist loaded", zap.Int("records", newBlocklist.Len()))
+	}
+
+	newService := service.NewService(zl, newRunner, newRepository, newBlocklist, nil, nil)
+	results, err := newService.ImportLinks(ctx, links)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(os.Stdout)
+	defer writer.Flush()
+	encoder := json.NewEncoder(writer)
+
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Status]++
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "imported %d links: %d created, %d existing, %d collision, %d invalid, %d blocked, %d deleted, %d failed\n",
+		len(results),
+		counts[models.BatchStatusCreated],
+		counts[models.BatchStatusExisting],
+		counts[models.BatchStatusCollision],
+		counts[models.BatchStatusInvalid],
+		counts[models.BatchStatusBlocked],
+		counts[models.BatchStatusDeleted],
+		counts[models.BatchStatusFailed],
+	)
+	return nil
+}
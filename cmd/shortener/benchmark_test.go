@@ -22,8 +22,8 @@ import (
 
 func setupApp() (*handlers.App, *service.Service) {
 	repo := mem.NewRepository(nil)
-	svc := service.NewService(nil, repo, repo)
-	app := handlers.NewApp(svc, nil)
+	svc := service.NewService(nil, repo, repo, nil, nil, nil)
+	app := handlers.NewApp(svc, nil, nil)
 	return app, svc
 }
 
@@ -10,14 +10,22 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/IvanKondrashkov/go-shortener/internal/blocklist"
 	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/geoip"
 	"github.com/IvanKondrashkov/go-shortener/internal/handlers"
+	"github.com/IvanKondrashkov/go-shortener/internal/linkcheck"
 	"github.com/IvanKondrashkov/go-shortener/internal/logger"
+	"github.com/IvanKondrashkov/go-shortener/internal/opengraph"
+	"github.com/IvanKondrashkov/go-shortener/internal/outbox"
 	"github.com/IvanKondrashkov/go-shortener/internal/service"
 	"github.com/IvanKondrashkov/go-shortener/internal/service/worker"
+	"github.com/IvanKondrashkov/go-shortener/internal/storage/cache"
 	"github.com/IvanKondrashkov/go-shortener/internal/storage/db"
 	"github.com/IvanKondrashkov/go-shortener/internal/storage/file"
 	"github.com/IvanKondrashkov/go-shortener/internal/storage/mem"
+	"github.com/IvanKondrashkov/go-shortener/internal/stream"
+	"github.com/IvanKondrashkov/go-shortener/internal/webhook"
 
 	"go.uber.org/zap"
 )
@@ -91,16 +99,92 @@ func run() error {
 		defer newRepository.Close()
 	}
 
+	if config.CacheSize > 0 {
+		newCache := cache.NewRepository(zl, newRepository, config.CacheSize, config.CacheTTL)
+		newRepository = newCache
+		newRunner = newRepository
+		defer newCache.LogStats()
+
+		if config.DatabaseDSN != "" {
+			listenCtx, stopListen := context.WithCancel(context.Background())
+			newListener := db.NewListener(zl, config.DatabaseDSN, newCache)
+			go newListener.Listen(listenCtx)
+			defer func() {
+				stopListen()
+				<-newListener.Done()
+			}()
+		}
+	}
+
+	var newGeoIP *geoip.DB
+	if config.GeoIPPath != "" {
+		newGeoIP, err = geoip.Load(config.GeoIPPath)
+		if err != nil {
+			return err
+		}
+		zl.Log.Info("GeoIP database loaded", zap.Int("ranges", newGeoIP.Len()))
+	}
+
+	var newBlocklist *blocklist.List
+	if config.BlocklistPath != "" {
+		newBlocklist, err = blocklist.Load(config.BlocklistPath)
+		if err != nil {
+			return err
+		}
+		zl.Log.Info("Blocklist loaded", zap.Int("records", newBlocklist.Len()))
+
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		defer stopWatch()
+		go newBlocklist.Watch(watchCtx, config.BlocklistReload, func(err error) {
+			if err != nil {
+				zl.Log.Warn("Blocklist reload failed, previous list is kept", zap.Error(err))
+				return
+			}
+			zl.Log.Info("Blocklist reloaded", zap.Int("records", newBlocklist.Len()))
+		})
+	}
+
+	allow, err := linkcheck.ParseAllowlist(config.LinkCheckAllowlist)
+	if err != nil {
+		return err
+	}
+
+	var newFetcher *opengraph.Fetcher
+	if config.PageFetchWorkers > 0 {
+		newFetcher = opengraph.NewFetcher(config.PageFetchTimeout, int64(config.PageFetchMaxSize), allow)
+	}
+
+	var newDispatcher *webhook.Dispatcher
+	if config.WebhookWorkers > 0 {
+		thresholds, err := webhook.ParseThresholds(config.WebhookClickThresholds)
+		if err != nil {
+			return err
+		}
+		newDispatcher = webhook.NewDispatcher(newRepository, allow, thresholds)
+	}
+
+	var newRelay *outbox.Relay
+	if config.OutboxSinks != "" {
+		sinks, err := outbox.NewSinks(config.OutboxSinks)
+		if err != nil {
+			return err
+		}
+		newRelay = outbox.NewRelay(newRepository, sinks, config.OutboxBatch)
+		defer newRelay.Close()
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		newService := service.NewService(zl, newRunner, newRepository)
-		newWorker := worker.NewWorker(ctx, config.WorkerCount, zl, newService)
-		newApp := handlers.NewApp(newService, newWorker)
+		newHub := stream.NewHub(config.StreamBuffer, config.StreamQueue)
+		newService := service.NewService(zl, newRunner, newRepository, newBlocklist, newDispatcher, newHub)
+		newWorker := worker.NewWorker(ctx, config.WorkerCount, zl, newService, newFetcher, newRelay)
+		newApp := handlers.NewApp(newService, newWorker, newGeoIP)
 		newHandler := handlers.NewHandler(zl, newApp)
 		newRouter := handlers.NewRouter(newHandler)
 		newServer := handlers.NewServer(newRouter)
+		newServer.RegisterOnShutdown(newHub.Close)
 
 		defer newWorker.Close()
 
@@ -0,0 +1,286 @@
+package blocklist
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// confusables буквы кириллицы и греческого алфавита, совпадающие по написанию с латинскими
+const confusables = "аеорсухіјѕһԁԛԝӏαεικνορτυχ"
+
+// Load загружает список из файла
+// Принимает:
+// - path: путь к файлу списка
+// Возвращает:
+// - список с поддержкой перезагрузки (Reload, Watch)
+// - ошибку, если файл не удалось прочитать или строка не соответствует формату
+func Load(path string) (*List, error) {
+	l := &List{path: path}
+	if _, err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Parse читает список из r
+// Пустые строки и комментарии (#) пропускаются
+func Parse(r io.Reader) (*List, error) {
+	l := &List{}
+	domains, prefixes, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	l.domains, l.prefixes = domains, prefixes
+	return l, nil
+}
+
+// Reload перечитывает файл списка, если он изменился с прошлой загрузки
+// Возвращает true, если список был заменен; при ошибке сохраняется прежний список
+func (l *List) Reload() (bool, error) {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false, fmt.Errorf("stat blocklist error: %w", err)
+	}
+
+	l.mux.RLock()
+	unchanged := info.ModTime().Equal(l.modTime) && l.domains != nil
+	l.mux.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		return false, fmt.Errorf("open blocklist error: %w", err)
+	}
+	defer file.Close()
+
+	domains, prefixes, err := parse(file)
+	if err != nil {
+		return false, err
+	}
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.domains, l.prefixes, l.modTime = domains, prefixes, info.ModTime()
+	return true, nil
+}
+
+// Watch периодически перечитывает файл списка до отмены контекста
+// Принимает:
+// - ctx: контекст для остановки
+// - interval: период проверки файла
+// - onReload: вызывается после каждой перезагрузки с ее результатом (может быть nil)
+func (l *List) Watch(ctx context.Context, interval time.Duration, onReload func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reloaded, err := l.Reload()
+			if (reloaded || err != nil) && onReload != nil {
+				onReload(err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Len возвращает количество записей списка
+func (l *List) Len() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+	return len(l.domains) + len(l.prefixes)
+}
+
+// Check проверяет адрес назначения по списку и эвристикам
+// Эвристики применяются и для nil списка
+// Возвращает:
+// - причину блокировки или пустую строку, если адрес разрешен
+func (l *List) Check(u *url.URL) string {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return ""
+	}
+
+	if isIPLiteral(host) {
+		return ReasonIPLiteral
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		ascii = host
+	}
+
+	if l.blocked(ascii, u) {
+		return ReasonBlocklisted
+	}
+
+	labels := strings.Split(ascii, ".")
+	if len(labels) > maxLabels {
+		return ReasonSubdomains
+	}
+
+	for _, label := range labels {
+		if isLookalike(label) {
+			return ReasonLookalike
+		}
+	}
+	return ""
+}
+
+// blocked проверяет домен и его родительские домены, а также префиксы URL
+func (l *List) blocked(host string, u *url.URL) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
+	for d := host; d != ""; {
+		if _, ok := l.domains[d]; ok {
+			return true
+		}
+		_, d, _ = strings.Cut(d, ".")
+	}
+
+	if len(l.prefixes) == 0 {
+		return false
+	}
+
+	raw := normalizeURL(u)
+	for _, prefix := range l.prefixes {
+		if strings.HasPrefix(raw, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parse читает записи списка
+func parse(r io.Reader) (map[string]struct{}, []string, error) {
+	domains := make(map[string]struct{})
+	var prefixes []string
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) == 1 && strings.Contains(fields[0], "://"):
+			u, err := url.Parse(fields[0])
+			if err != nil || u.Host == "" {
+				return nil, nil, fmt.Errorf("line %d: %w", n, ErrRecordNotValid)
+			}
+			prefixes = append(prefixes, normalizeURL(u))
+		case len(fields) == 1:
+			domains[normalizeDomain(fields[0])] = struct{}{}
+		default:
+			if _, err := netip.ParseAddr(fields[0]); err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", n, ErrRecordNotValid)
+			}
+			for _, field := range fields[1:] {
+				if _, err := netip.ParseAddr(field); err == nil {
+					continue
+				}
+				domains[normalizeDomain(field)] = struct{}{}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read blocklist error: %w", err)
+	}
+	return domains, prefixes, nil
+}
+
+// normalizeDomain приводит домен записи к нижнему регистру и punycode
+// Префиксы "*." и "." допускаются и означают домен вместе с поддоменами
+func normalizeDomain(domain string) string {
+	domain = strings.TrimPrefix(domain, "*")
+	domain = strings.Trim(strings.ToLower(domain), ".")
+	if ascii, err := idna.Lookup.ToASCII(domain); err == nil {
+		return ascii
+	}
+	return domain
+}
+
+// normalizeURL возвращает URL без схемы с хостом в нижнем регистре для сравнения префиксов
+func normalizeURL(u *url.URL) string {
+	res := strings.ToLower(u.Host) + u.EscapedPath()
+	if u.RawQuery != "" {
+		res += "?" + u.RawQuery
+	}
+	return res
+}
+
+// isIPLiteral проверяет, что хост является IP адресом, в том числе в десятичной или шестнадцатеричной записи
+func isIPLiteral(host string) bool {
+	if _, err := netip.ParseAddr(host); err == nil {
+		return true
+	}
+
+	digits, hex := strings.CutPrefix(host, "0x")
+	if digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if hex && !unicode.Is(unicode.ASCII_Hex_Digit, r) || !hex && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLookalike проверяет, что punycode метка смешивает алфавиты или целиком состоит из букв,
+// совпадающих по написанию с латинскими
+func isLookalike(label string) bool {
+	if !strings.HasPrefix(label, "xn--") {
+		return false
+	}
+
+	decoded, err := idna.Punycode.ToUnicode(label)
+	if err != nil {
+		return true
+	}
+
+	var latin, other, confusable, letters int
+	for _, r := range decoded {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		default:
+			other++
+			if strings.ContainsRune(confusables, r) {
+				confusable++
+			}
+		}
+	}
+	return latin > 0 && other > 0 || letters > 0 && confusable == letters
+}
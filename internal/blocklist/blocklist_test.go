@@ -0,0 +1,98 @@
+package blocklist
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testList = `# hosts style
+0.0.0.0 evil.com www.evil.com
+127.0.0.1 tracker.example.org # trailing comment
+
+*.phish.net
+Пример.испытание
+https://docs.example.com/shared/
+`
+
+func TestCheck(t *testing.T) {
+	l, err := Parse(strings.NewReader(testList))
+	require.NoError(t, err)
+	assert.Equal(t, 6, l.Len())
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "allowed", url: "https://go.dev/doc/"},
+		{name: "hosts entry", url: "http://evil.com/login", want: ReasonBlocklisted},
+		{name: "subdomain of entry", url: "https://login.tracker.example.org/", want: ReasonBlocklisted},
+		{name: "parent of entry", url: "https://example.org/"},
+		{name: "wildcard entry", url: "https://a.phish.net/", want: ReasonBlocklisted},
+		{name: "unicode entry", url: "https://xn--e1afmkfd.xn--80akhbyknj4f/", want: ReasonBlocklisted},
+		{name: "url prefix", url: "https://DOCS.example.com/shared/file?id=1", want: ReasonBlocklisted},
+		{name: "url prefix other path", url: "https://docs.example.com/public/"},
+		{name: "ipv4", url: "http://192.168.0.1/admin", want: ReasonIPLiteral},
+		{name: "ipv6", url: "http://[::1]:8080/", want: ReasonIPLiteral},
+		{name: "decimal ip", url: "http://3232235521/", want: ReasonIPLiteral},
+		{name: "hex ip", url: "http://0xC0A80001/", want: ReasonIPLiteral},
+		{name: "excessive subdomains", url: "https://a.b.c.d.e.example.com/", want: ReasonSubdomains},
+		{name: "cyrillic lookalike", url: "https://аррӏе.com/", want: ReasonLookalike},
+		{name: "mixed scripts", url: "https://xn--pypal-4ve.com/", want: ReasonLookalike},
+		{name: "cyrillic domain", url: "https://пример.рф/"},
+		{name: "no host", url: "mailto:user@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, l.Check(u))
+		})
+	}
+}
+
+func TestCheckNil(t *testing.T) {
+	var l *List
+	assert.Equal(t, 0, l.Len())
+	assert.Equal(t, "", l.Check(&url.URL{Scheme: "https", Host: "evil.com"}))
+	assert.Equal(t, ReasonIPLiteral, l.Check(&url.URL{Scheme: "http", Host: "10.0.0.1"}))
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("evil.com\nnot an ip evil.org\n"))
+	assert.ErrorIs(t, err, ErrRecordNotValid)
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("evil.com\n"), 0o600))
+
+	l, err := Load(path)
+	require.NoError(t, err)
+
+	reloaded, err := l.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	require.NoError(t, os.WriteFile(path, []byte("evil.org\nbroken line here\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	_, err = l.Reload()
+	assert.ErrorIs(t, err, ErrRecordNotValid)
+	assert.Equal(t, ReasonBlocklisted, l.Check(&url.URL{Host: "evil.com"}))
+
+	require.NoError(t, os.WriteFile(path, []byte("evil.org\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+	reloaded, err = l.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "", l.Check(&url.URL{Host: "evil.com"}))
+	assert.Equal(t, ReasonBlocklisted, l.Check(&url.URL{Host: "evil.org"}))
+}
@@ -0,0 +1,34 @@
+// Package blocklist содержит список запрещенных адресов назначения и эвристики защиты от фишинга
+package blocklist
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Причины блокировки адреса назначения
+const (
+	ReasonBlocklisted = "blocklisted"          // Домен или URL есть в списке запрещенных
+	ReasonIPLiteral   = "ip_literal"           // Вместо доменного имени указан IP адрес
+	ReasonSubdomains  = "excessive_subdomains" // Слишком много уровней поддоменов
+	ReasonLookalike   = "punycode_lookalike"   // Punycode домен, имитирующий латинское написание
+)
+
+// Ограничения эвристик
+const (
+	maxLabels = 6 // Максимальное количество меток доменного имени
+)
+
+// ErrRecordNotValid возвращается когда строка списка не соответствует формату
+var ErrRecordNotValid = errors.New("blocklist record is invalidate")
+
+// List список запрещенных доменов и URL
+// Поддерживает формат hosts (IP адрес и домены) и простой список доменов или URL, по одному в строке
+type List struct {
+	mux      sync.RWMutex
+	path     string              // Путь к файлу списка, пустой для списка без файла
+	modTime  time.Time           // Время изменения загруженного файла
+	domains  map[string]struct{} // Запрещенные домены (в punycode), включая их поддомены
+	prefixes []string            // Запрещенные префиксы URL
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -25,6 +26,65 @@ type Config struct {
 	TerminationTimeout int  `env:"TERMINATION_TIMEOUT" json:"termination_timeout"` // Таймаут завершения работы (в секундах)
 	WorkerCount        int  `env:"WORKER_COUNT" json:"worker_count"`               // Количество воркеров
 	EnableHTTPS        bool `env:"ENABLE_HTTPS" json:"enable_https"`               // Включение защищенного протокола
+	CacheSize          int  `env:"CACHE_SIZE" json:"cache_size"`                   // Размер LRU кэша URL (0 - кэш выключен)
+	CacheTTL           int  `env:"CACHE_TTL" json:"cache_ttl"`                     // Время жизни записи кэша (в секундах)
+
+	QRSize       int    `env:"QR_SIZE" json:"qr_size"`             // Размер стороны QR-кода (в пикселях)
+	QRLevel      string `env:"QR_LEVEL" json:"qr_level"`           // Уровень коррекции ошибок QR-кода (L, M, Q, H)
+	QRMargin     int    `env:"QR_MARGIN" json:"qr_margin"`         // Отступ QR-кода (в модулях)
+	QRForeground string `env:"QR_FOREGROUND" json:"qr_foreground"` // Цвет модулей QR-кода (#RRGGBB)
+	QRBackground string `env:"QR_BACKGROUND" json:"qr_background"` // Цвет фона QR-кода (#RRGGBB)
+
+	PasswordMaxAttempts int `env:"PASSWORD_MAX_ATTEMPTS" json:"password_max_attempts"` // Количество неверных паролей до блокировки
+	PasswordLockout     int `env:"PASSWORD_LOCKOUT" json:"password_lockout"`           // Время блокировки ввода пароля (в секундах)
+	LinkAccessTTL       int `env:"LINK_ACCESS_TTL" json:"link_access_ttl"`             // Время жизни доступа к защищенной ссылке (в секундах)
+
+	RedirectCode   int `env:"REDIRECT_CODE" json:"redirect_code"`       // Код перенаправления по умолчанию (301, 302, 303, 307, 308)
+	RedirectMaxAge int `env:"REDIRECT_MAX_AGE" json:"redirect_max_age"` // Время кэширования постоянных перенаправлений (в секундах)
+
+	GeoIPPath  string `env:"GEOIP_PATH" json:"geoip_path"`   // Путь к CSV базе GeoIP для правил по стране (пусто - правила по стране не срабатывают)
+	VariantTTL int    `env:"VARIANT_TTL" json:"variant_ttl"` // Время закрепления варианта A/B теста за посетителем (в секундах)
+
+	ScheduleInterval int `env:"SCHEDULE_INTERVAL" json:"schedule_interval"` // Период проверки окон активности URL (в секундах)
+
+	BlocklistPath   string `env:"BLOCKLIST_PATH" json:"blocklist_path"`     // Путь к списку запрещенных доменов и URL (пусто - только эвристики)
+	BlocklistReload int    `env:"BLOCKLIST_RELOAD" json:"blocklist_reload"` // Период проверки изменений списка запрещенных адресов (в секундах)
+	AdminToken      string `env:"ADMIN_TOKEN" json:"admin_token"`           // Токен администратора (пусто - API администратора отключено)
+
+	LinkCheckInterval  int    `env:"LINK_CHECK_INTERVAL" json:"link_check_interval"`   // Период проверки доступности адресов назначения (в секундах, 0 - проверка выключена)
+	LinkCheckAge       int    `env:"LINK_CHECK_AGE" json:"link_check_age"`             // Время, через которое адрес назначения проверяется повторно (в секундах)
+	LinkCheckTimeout   int    `env:"LINK_CHECK_TIMEOUT" json:"link_check_timeout"`     // Таймаут проверки одного адреса назначения (в секундах)
+	LinkCheckBatch     int    `env:"LINK_CHECK_BATCH" json:"link_check_batch"`         // Количество адресов назначения за одну проверку
+	LinkCheckAllowlist string `env:"LINK_CHECK_ALLOWLIST" json:"link_check_allowlist"` // Разрешенные для проверки и загрузки страниц внутренние сети через запятую (CIDR)
+
+	PageFetchWorkers int `env:"PAGE_FETCH_WORKERS" json:"page_fetch_workers"`   // Количество одновременных загрузок метаданных страниц назначения
+	PageFetchTimeout int `env:"PAGE_FETCH_TIMEOUT" json:"page_fetch_timeout"`   // Таймаут загрузки страницы назначения (в секундах)
+	PageFetchMaxSize int `env:"PAGE_FETCH_MAX_SIZE" json:"page_fetch_max_size"` // Максимальный размер читаемой части страницы назначения (в байтах)
+
+	WebhookWorkers         int    `env:"WEBHOOK_WORKERS" json:"webhook_workers"`                   // Количество одновременных доставок событий webhook
+	WebhookTimeout         int    `env:"WEBHOOK_TIMEOUT" json:"webhook_timeout"`                   // Таймаут одной попытки доставки (в секундах)
+	WebhookMaxAttempts     int    `env:"WEBHOOK_MAX_ATTEMPTS" json:"webhook_max_attempts"`         // Максимальное количество попыток доставки события
+	WebhookBackoff         int    `env:"WEBHOOK_BACKOFF" json:"webhook_backoff"`                   // Задержка перед первым повтором, удваивается с каждой попыткой (в секундах)
+	WebhookSecretGrace     int    `env:"WEBHOOK_SECRET_GRACE" json:"webhook_secret_grace"`         // Время после ротации, в течение которого события подписываются и старым секретом (в секундах)
+	WebhookClickThresholds string `env:"WEBHOOK_CLICK_THRESHOLDS" json:"webhook_click_thresholds"` // Пороги переходов для события link.click_threshold через запятую
+
+	OutboxSinks       string `env:"OUTBOX_SINKS" json:"outbox_sinks"`               // Получатели доменных событий через запятую: stdout, file, webhook, nats
+	OutboxInterval    int    `env:"OUTBOX_INTERVAL" json:"outbox_interval"`         // Период отправки накопленных доменных событий (в секундах)
+	OutboxBatch       int    `env:"OUTBOX_BATCH" json:"outbox_batch"`               // Количество доменных событий за одну отправку
+	OutboxTimeout     int    `env:"OUTBOX_TIMEOUT" json:"outbox_timeout"`           // Таймаут отправки события получателю (в секундах)
+	OutboxFilePath    string `env:"OUTBOX_FILE_PATH" json:"outbox_file_path"`       // Файл получателя file, события дописываются построчно в JSON
+	OutboxWebhookURL  string `env:"OUTBOX_WEBHOOK_URL" json:"outbox_webhook_url"`   // Адрес получателя webhook
+	OutboxNATSURL     string `env:"OUTBOX_NATS_URL" json:"outbox_nats_url"`         // Адрес сервера NATS получателя nats
+	OutboxNATSSubject string `env:"OUTBOX_NATS_SUBJECT" json:"outbox_nats_subject"` // Тема NATS, в которую публикуются события
+
+	StreamBuffer    int `env:"STREAM_BUFFER" json:"stream_buffer"`       // Количество последних событий потока SSE, доступных для продолжения по Last-Event-ID
+	StreamQueue     int `env:"STREAM_QUEUE" json:"stream_queue"`         // Очередь событий соединения SSE, при переполнении соединение закрывается
+	StreamHeartbeat int `env:"STREAM_HEARTBEAT" json:"stream_heartbeat"` // Период отправки комментария-пульса в поток SSE (в секундах)
+
+	IdempotencyTTL      int `env:"IDEMPOTENCY_TTL" json:"idempotency_ttl"`           // Время хранения ответа на запрос с Idempotency-Key (в секундах)
+	IdempotencyInterval int `env:"IDEMPOTENCY_INTERVAL" json:"idempotency_interval"` // Период удаления устаревших ответов Idempotency-Key (в секундах)
+
+	ImportChunk int `env:"IMPORT_CHUNK" json:"import_chunk"` // Количество строк импорта, сохраняемых одним пакетом
 }
 
 // Глобальные переменные конфигурации со значениями по умолчанию
@@ -37,10 +97,56 @@ var (
 	DatabaseDSN     = ""
 	AuthKey         = []byte("6368616e676520746869732070617373776f726420746f206120736563726574")
 
-	TerminationTimeout = time.Second * 30
-	WorkerCount        = 10
-	EnableHTTPS        = false
-	FileConfigPath     = "internal/config/config.json"
+	TerminationTimeout     = time.Second * 30
+	WorkerCount            = 10
+	EnableHTTPS            = false
+	CacheSize              = 0
+	CacheTTL               = time.Minute * 5
+	QRSize                 = 256
+	QRLevel                = "M"
+	QRMargin               = 4
+	QRForeground           = "#000000"
+	QRBackground           = "#FFFFFF"
+	PasswordMaxAttempts    = 5
+	PasswordLockout        = time.Minute * 15
+	LinkAccessTTL          = time.Minute * 10
+	RedirectCode           = http.StatusTemporaryRedirect
+	RedirectMaxAge         = time.Hour * 24
+	GeoIPPath              = ""
+	VariantTTL             = time.Hour * 24 * 30
+	ScheduleInterval       = time.Minute
+	BlocklistPath          = ""
+	BlocklistReload        = time.Second * 30
+	AdminToken             = ""
+	LinkCheckInterval      = time.Duration(0)
+	LinkCheckAge           = time.Hour * 24
+	LinkCheckTimeout       = time.Second * 10
+	LinkCheckBatch         = 100
+	LinkCheckAllowlist     = ""
+	PageFetchWorkers       = 4
+	PageFetchTimeout       = time.Second * 5
+	PageFetchMaxSize       = 512 * 1024
+	WebhookWorkers         = 2
+	WebhookTimeout         = time.Second * 10
+	WebhookMaxAttempts     = 5
+	WebhookBackoff         = time.Second
+	WebhookSecretGrace     = time.Hour * 24
+	WebhookClickThresholds = "100,1000,10000"
+	OutboxSinks            = ""
+	OutboxInterval         = time.Second
+	OutboxBatch            = 100
+	OutboxTimeout          = time.Second * 5
+	OutboxFilePath         = ""
+	OutboxWebhookURL       = ""
+	OutboxNATSURL          = "nats://127.0.0.1:4222"
+	OutboxNATSSubject      = "shortener.links"
+	StreamBuffer           = 1000
+	StreamQueue            = 64
+	StreamHeartbeat        = time.Second * 15
+	IdempotencyTTL         = time.Hour * 24
+	IdempotencyInterval    = time.Hour
+	ImportChunk            = 1000
+	FileConfigPath         = "internal/config/config.json"
 )
 
 // ParseConfig загружает конфигурацию приложения из:
@@ -111,6 +217,197 @@ func ParseConfig() error {
 		EnableHTTPS = true
 	}
 
+	if envCacheSize := envCfg.CacheSize; envCacheSize != 0 {
+		CacheSize = envCacheSize
+	}
+
+	if envCacheTTL := envCfg.CacheTTL; envCacheTTL != 0 {
+		CacheTTL = time.Duration(envCacheTTL) * time.Second
+	}
+
+	if envQRSize := envCfg.QRSize; envQRSize != 0 {
+		QRSize = envQRSize
+	}
+
+	if envQRLevel := envCfg.QRLevel; envQRLevel != "" {
+		QRLevel = envQRLevel
+	}
+
+	if envQRMargin := envCfg.QRMargin; envQRMargin != 0 {
+		QRMargin = envQRMargin
+	}
+
+	if envQRForeground := envCfg.QRForeground; envQRForeground != "" {
+		QRForeground = envQRForeground
+	}
+
+	if envQRBackground := envCfg.QRBackground; envQRBackground != "" {
+		QRBackground = envQRBackground
+	}
+
+	if envPasswordMaxAttempts := envCfg.PasswordMaxAttempts; envPasswordMaxAttempts != 0 {
+		PasswordMaxAttempts = envPasswordMaxAttempts
+	}
+
+	if envPasswordLockout := envCfg.PasswordLockout; envPasswordLockout != 0 {
+		PasswordLockout = time.Duration(envPasswordLockout) * time.Second
+	}
+
+	if envLinkAccessTTL := envCfg.LinkAccessTTL; envLinkAccessTTL != 0 {
+		LinkAccessTTL = time.Duration(envLinkAccessTTL) * time.Second
+	}
+
+	if envRedirectCode := envCfg.RedirectCode; envRedirectCode != 0 {
+		RedirectCode = envRedirectCode
+	}
+
+	if envRedirectMaxAge := envCfg.RedirectMaxAge; envRedirectMaxAge != 0 {
+		RedirectMaxAge = time.Duration(envRedirectMaxAge) * time.Second
+	}
+
+	if envGeoIPPath := envCfg.GeoIPPath; envGeoIPPath != "" {
+		GeoIPPath = envGeoIPPath
+	}
+
+	if envVariantTTL := envCfg.VariantTTL; envVariantTTL != 0 {
+		VariantTTL = time.Duration(envVariantTTL) * time.Second
+	}
+
+	if envScheduleInterval := envCfg.ScheduleInterval; envScheduleInterval != 0 {
+		ScheduleInterval = time.Duration(envScheduleInterval) * time.Second
+	}
+
+	if envBlocklistPath := envCfg.BlocklistPath; envBlocklistPath != "" {
+		BlocklistPath = envBlocklistPath
+	}
+
+	if envBlocklistReload := envCfg.BlocklistReload; envBlocklistReload != 0 {
+		BlocklistReload = time.Duration(envBlocklistReload) * time.Second
+	}
+
+	if envAdminToken := envCfg.AdminToken; envAdminToken != "" {
+		AdminToken = envAdminToken
+	}
+
+	if envLinkCheckInterval := envCfg.LinkCheckInterval; envLinkCheckInterval != 0 {
+		LinkCheckInterval = time.Duration(envLinkCheckInterval) * time.Second
+	}
+
+	if envLinkCheckAge := envCfg.LinkCheckAge; envLinkCheckAge != 0 {
+		LinkCheckAge = time.Duration(envLinkCheckAge) * time.Second
+	}
+
+	if envLinkCheckTimeout := envCfg.LinkCheckTimeout; envLinkCheckTimeout != 0 {
+		LinkCheckTimeout = time.Duration(envLinkCheckTimeout) * time.Second
+	}
+
+	if envLinkCheckBatch := envCfg.LinkCheckBatch; envLinkCheckBatch != 0 {
+		LinkCheckBatch = envLinkCheckBatch
+	}
+
+	if envLinkCheckAllowlist := envCfg.LinkCheckAllowlist; envLinkCheckAllowlist != "" {
+		LinkCheckAllowlist = envLinkCheckAllowlist
+	}
+
+	if envPageFetchWorkers := envCfg.PageFetchWorkers; envPageFetchWorkers != 0 {
+		PageFetchWorkers = envPageFetchWorkers
+	}
+
+	if envPageFetchTimeout := envCfg.PageFetchTimeout; envPageFetchTimeout != 0 {
+		PageFetchTimeout = time.Duration(envPageFetchTimeout) * time.Second
+	}
+
+	if envPageFetchMaxSize := envCfg.PageFetchMaxSize; envPageFetchMaxSize != 0 {
+		PageFetchMaxSize = envPageFetchMaxSize
+	}
+
+	if envWebhookWorkers := envCfg.WebhookWorkers; envWebhookWorkers != 0 {
+		WebhookWorkers = envWebhookWorkers
+	}
+
+	if envWebhookTimeout := envCfg.WebhookTimeout; envWebhookTimeout != 0 {
+		WebhookTimeout = time.Duration(envWebhookTimeout) * time.Second
+	}
+
+	if envWebhookMaxAttempts := envCfg.WebhookMaxAttempts; envWebhookMaxAttempts != 0 {
+		WebhookMaxAttempts = envWebhookMaxAttempts
+	}
+
+	if envWebhookBackoff := envCfg.WebhookBackoff; envWebhookBackoff != 0 {
+		WebhookBackoff = time.Duration(envWebhookBackoff) * time.Second
+	}
+
+	if envWebhookSecretGrace := envCfg.WebhookSecretGrace; envWebhookSecretGrace != 0 {
+		WebhookSecretGrace = time.Duration(envWebhookSecretGrace) * time.Second
+	}
+
+	if envWebhookClickThresholds := envCfg.WebhookClickThresholds; envWebhookClickThresholds != "" {
+		WebhookClickThresholds = envWebhookClickThresholds
+	}
+
+	if envOutboxSinks := envCfg.OutboxSinks; envOutboxSinks != "" {
+		OutboxSinks = envOutboxSinks
+	}
+
+	if envOutboxInterval := envCfg.OutboxInterval; envOutboxInterval != 0 {
+		OutboxInterval = time.Duration(envOutboxInterval) * time.Second
+	}
+
+	if envOutboxBatch := envCfg.OutboxBatch; envOutboxBatch != 0 {
+		OutboxBatch = envOutboxBatch
+	}
+
+	if envOutboxTimeout := envCfg.OutboxTimeout; envOutboxTimeout != 0 {
+		OutboxTimeout = time.Duration(envOutboxTimeout) * time.Second
+	}
+
+	if envOutboxFilePath := envCfg.OutboxFilePath; envOutboxFilePath != "" {
+		OutboxFilePath = envOutboxFilePath
+	}
+
+	if envOutboxWebhookURL := envCfg.OutboxWebhookURL; envOutboxWebhookURL != "" {
+		OutboxWebhookURL = envOutboxWebhookURL
+	}
+
+	if envOutboxNATSURL := envCfg.OutboxNATSURL; envOutboxNATSURL != "" {
+		OutboxNATSURL = envOutboxNATSURL
+	}
+
+	if envOutboxNATSSubject := envCfg.OutboxNATSSubject; envOutboxNATSSubject != "" {
+		OutboxNATSSubject = envOutboxNATSSubject
+	}
+
+	if envStreamBuffer := envCfg.StreamBuffer; envStreamBuffer != 0 {
+		StreamBuffer = envStreamBuffer
+	}
+
+	if envStreamQueue := envCfg.StreamQueue; envStreamQueue != 0 {
+		StreamQueue = envStreamQueue
+	}
+
+	if envStreamHeartbeat := envCfg.StreamHeartbeat; envStreamHeartbeat != 0 {
+		StreamHeartbeat = time.Duration(envStreamHeartbeat) * time.Second
+	}
+
+	if envIdempotencyTTL := envCfg.IdempotencyTTL; envIdempotencyTTL != 0 {
+		IdempotencyTTL = time.Duration(envIdempotencyTTL) * time.Second
+	}
+
+	if envIdempotencyInterval := envCfg.IdempotencyInterval; envIdempotencyInterval != 0 {
+		IdempotencyInterval = time.Duration(envIdempotencyInterval) * time.Second
+	}
+
+	if envImportChunk := envCfg.ImportChunk; envImportChunk != 0 {
+		ImportChunk = envImportChunk
+	}
+
+	switch RedirectCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return fmt.Errorf("config parse error: redirect code %d is invalidate", RedirectCode)
+	}
+
 	if EnableHTTPS {
 		URL = SecureURL
 	}
@@ -153,4 +450,50 @@ func applyJSONConfig(envCfg Config, jsonCfg *Config) {
 	applyDurationIfEmpty(&TerminationTimeout, envCfg.TerminationTimeout, jsonCfg.TerminationTimeout)
 	applyIntIfEmpty(&WorkerCount, envCfg.WorkerCount, jsonCfg.WorkerCount)
 	applyBollIfEmpty(&EnableHTTPS, envCfg.EnableHTTPS, jsonCfg.EnableHTTPS)
+	applyIntIfEmpty(&CacheSize, envCfg.CacheSize, jsonCfg.CacheSize)
+	applyDurationIfEmpty(&CacheTTL, envCfg.CacheTTL, jsonCfg.CacheTTL)
+	applyIntIfEmpty(&QRSize, envCfg.QRSize, jsonCfg.QRSize)
+	applyStrIfEmpty(&QRLevel, envCfg.QRLevel, jsonCfg.QRLevel)
+	applyIntIfEmpty(&QRMargin, envCfg.QRMargin, jsonCfg.QRMargin)
+	applyStrIfEmpty(&QRForeground, envCfg.QRForeground, jsonCfg.QRForeground)
+	applyStrIfEmpty(&QRBackground, envCfg.QRBackground, jsonCfg.QRBackground)
+	applyIntIfEmpty(&PasswordMaxAttempts, envCfg.PasswordMaxAttempts, jsonCfg.PasswordMaxAttempts)
+	applyDurationIfEmpty(&PasswordLockout, envCfg.PasswordLockout, jsonCfg.PasswordLockout)
+	applyDurationIfEmpty(&LinkAccessTTL, envCfg.LinkAccessTTL, jsonCfg.LinkAccessTTL)
+	applyIntIfEmpty(&RedirectCode, envCfg.RedirectCode, jsonCfg.RedirectCode)
+	applyDurationIfEmpty(&RedirectMaxAge, envCfg.RedirectMaxAge, jsonCfg.RedirectMaxAge)
+	applyStrIfEmpty(&GeoIPPath, envCfg.GeoIPPath, jsonCfg.GeoIPPath)
+	applyDurationIfEmpty(&VariantTTL, envCfg.VariantTTL, jsonCfg.VariantTTL)
+	applyDurationIfEmpty(&ScheduleInterval, envCfg.ScheduleInterval, jsonCfg.ScheduleInterval)
+	applyStrIfEmpty(&BlocklistPath, envCfg.BlocklistPath, jsonCfg.BlocklistPath)
+	applyDurationIfEmpty(&BlocklistReload, envCfg.BlocklistReload, jsonCfg.BlocklistReload)
+	applyStrIfEmpty(&AdminToken, envCfg.AdminToken, jsonCfg.AdminToken)
+	applyDurationIfEmpty(&LinkCheckInterval, envCfg.LinkCheckInterval, jsonCfg.LinkCheckInterval)
+	applyDurationIfEmpty(&LinkCheckAge, envCfg.LinkCheckAge, jsonCfg.LinkCheckAge)
+	applyDurationIfEmpty(&LinkCheckTimeout, envCfg.LinkCheckTimeout, jsonCfg.LinkCheckTimeout)
+	applyIntIfEmpty(&LinkCheckBatch, envCfg.LinkCheckBatch, jsonCfg.LinkCheckBatch)
+	applyStrIfEmpty(&LinkCheckAllowlist, envCfg.LinkCheckAllowlist, jsonCfg.LinkCheckAllowlist)
+	applyIntIfEmpty(&PageFetchWorkers, envCfg.PageFetchWorkers, jsonCfg.PageFetchWorkers)
+	applyDurationIfEmpty(&PageFetchTimeout, envCfg.PageFetchTimeout, jsonCfg.PageFetchTimeout)
+	applyIntIfEmpty(&PageFetchMaxSize, envCfg.PageFetchMaxSize, jsonCfg.PageFetchMaxSize)
+	applyIntIfEmpty(&WebhookWorkers, envCfg.WebhookWorkers, jsonCfg.WebhookWorkers)
+	applyDurationIfEmpty(&WebhookTimeout, envCfg.WebhookTimeout, jsonCfg.WebhookTimeout)
+	applyIntIfEmpty(&WebhookMaxAttempts, envCfg.WebhookMaxAttempts, jsonCfg.WebhookMaxAttempts)
+	applyDurationIfEmpty(&WebhookBackoff, envCfg.WebhookBackoff, jsonCfg.WebhookBackoff)
+	applyDurationIfEmpty(&WebhookSecretGrace, envCfg.WebhookSecretGrace, jsonCfg.WebhookSecretGrace)
+	applyStrIfEmpty(&WebhookClickThresholds, envCfg.WebhookClickThresholds, jsonCfg.WebhookClickThresholds)
+	applyStrIfEmpty(&OutboxSinks, envCfg.OutboxSinks, jsonCfg.OutboxSinks)
+	applyDurationIfEmpty(&OutboxInterval, envCfg.OutboxInterval, jsonCfg.OutboxInterval)
+	applyIntIfEmpty(&OutboxBatch, envCfg.OutboxBatch, jsonCfg.OutboxBatch)
+	applyDurationIfEmpty(&OutboxTimeout, envCfg.OutboxTimeout, jsonCfg.OutboxTimeout)
+	applyStrIfEmpty(&OutboxFilePath, envCfg.OutboxFilePath, jsonCfg.OutboxFilePath)
+	applyStrIfEmpty(&OutboxWebhookURL, envCfg.OutboxWebhookURL, jsonCfg.OutboxWebhookURL)
+	applyStrIfEmpty(&OutboxNATSURL, envCfg.OutboxNATSURL, jsonCfg.OutboxNATSURL)
+	applyStrIfEmpty(&OutboxNATSSubject, envCfg.OutboxNATSSubject, jsonCfg.OutboxNATSSubject)
+	applyIntIfEmpty(&StreamBuffer, envCfg.StreamBuffer, jsonCfg.StreamBuffer)
+	applyIntIfEmpty(&StreamQueue, envCfg.StreamQueue, jsonCfg.StreamQueue)
+	applyDurationIfEmpty(&StreamHeartbeat, envCfg.StreamHeartbeat, jsonCfg.StreamHeartbeat)
+	applyDurationIfEmpty(&IdempotencyTTL, envCfg.IdempotencyTTL, jsonCfg.IdempotencyTTL)
+	applyDurationIfEmpty(&IdempotencyInterval, envCfg.IdempotencyInterval, jsonCfg.IdempotencyInterval)
+	applyIntIfEmpty(&ImportChunk, envCfg.ImportChunk, jsonCfg.ImportChunk)
 }
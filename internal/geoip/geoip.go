@@ -0,0 +1,116 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Load загружает базу GeoIP из CSV файла
+// Каждая запись содержит первый адрес, последний адрес и код страны: 1.0.0.0,1.0.0.255,AU
+// Поддерживаются адреса IPv4 и IPv6, строки начинающиеся с # пропускаются
+func Load(path string) (*DB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open geoip file error: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse читает базу GeoIP в формате CSV
+// Возвращает ErrRecordNotValid с номером строки, если запись невалидна
+func Parse(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	db := &DB{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read geoip record error: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rng, err := parseRange(record)
+		if err != nil {
+			return nil, fmt.Errorf("parse geoip record on line %d error: %w", line, err)
+		}
+		db.ranges = append(db.ranges, rng)
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	return db, nil
+}
+
+// Country возвращает код страны IP адреса или пустую строку, если адрес не найден
+func (db *DB) Country(addr netip.Addr) string {
+	if db == nil || !addr.IsValid() {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// Первый диапазон, начинающийся после адреса; искомый диапазон предшествует ему
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return addr.Less(db.ranges[i].start)
+	})
+	if i == 0 {
+		return ""
+	}
+
+	rng := db.ranges[i-1]
+	if rng.end.Less(addr) || rng.start.Is4() != addr.Is4() {
+		return ""
+	}
+	return rng.country
+}
+
+// Len возвращает количество диапазонов в базе
+func (db *DB) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.ranges)
+}
+
+// parseRange разбирает запись CSV в диапазон IP адресов
+func parseRange(record []string) (ipRange, error) {
+	if len(record) < 3 {
+		return ipRange{}, ErrRecordNotValid
+	}
+
+	start, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+	if err != nil {
+		return ipRange{}, fmt.Errorf("%w: %w", ErrRecordNotValid, err)
+	}
+
+	end, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+	if err != nil {
+		return ipRange{}, fmt.Errorf("%w: %w", ErrRecordNotValid, err)
+	}
+
+	start, end = start.Unmap(), end.Unmap()
+	country := strings.ToUpper(strings.TrimSpace(record[2]))
+	if start.Is4() != end.Is4() || end.Less(start) || len(country) != 2 {
+		return ipRange{}, ErrRecordNotValid
+	}
+
+	return ipRange{
+		start:   start,
+		end:     end,
+		country: country,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package geoip
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDB = `# start,end,country
+1.0.0.0,1.0.0.255,au
+5.3.0.0,5.3.255.255,RU
+"2a02:6b8::","2a02:6b8:ffff:ffff:ffff:ffff:ffff:ffff","RU"
+8.8.8.0,8.8.8.255,US
+`
+
+func TestCountry(t *testing.T) {
+	db, err := Parse(strings.NewReader(testDB))
+	require.NoError(t, err)
+	assert.Equal(t, 4, db.Len())
+
+	tests := []struct {
+		name string
+		addr string
+		want string
+	}{
+		{name: "first range", addr: "1.0.0.1", want: "AU"},
+		{name: "range end", addr: "5.3.255.255", want: "RU"},
+		{name: "between ranges", addr: "7.0.0.1"},
+		{name: "before first range", addr: "0.0.0.1"},
+		{name: "after last range", addr: "9.9.9.9"},
+		{name: "ipv6", addr: "2a02:6b8::1", want: "RU"},
+		{name: "ipv4 mapped ipv6", addr: "::ffff:8.8.8.8", want: "US"},
+		{name: "ipv6 not found", addr: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, db.Country(netip.MustParseAddr(tt.addr)))
+		})
+	}
+
+	var empty *DB
+	assert.Equal(t, "", empty.Country(netip.MustParseAddr("1.0.0.1")))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "too few fields", data: "1.0.0.0,1.0.0.255\n"},
+		{name: "address is invalidate", data: "1.0.0,1.0.0.255,AU\n"},
+		{name: "end before start", data: "1.0.0.255,1.0.0.0,AU\n"},
+		{name: "mixed families", data: "1.0.0.0,::1,AU\n"},
+		{name: "country is invalidate", data: "1.0.0.0,1.0.0.255,AUS\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data))
+			assert.ErrorIs(t, err, ErrRecordNotValid)
+		})
+	}
+}
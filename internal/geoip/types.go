@@ -0,0 +1,25 @@
+// Package geoip содержит определение страны по IP адресу на основе локальной базы диапазонов в формате CSV
+package geoip
+
+import (
+	"errors"
+	"net/netip"
+)
+
+// Ошибки загрузки базы GeoIP
+var (
+	ErrRecordNotValid = errors.New("record is invalidate")
+)
+
+// DB база диапазонов IP адресов, отсортированных по началу диапазона.
+// База неизменяема после загрузки и безопасна для конкурентного использования.
+type DB struct {
+	ranges []ipRange // Диапазоны IP адресов
+}
+
+// ipRange диапазон IP адресов страны
+type ipRange struct {
+	start   netip.Addr // Первый адрес диапазона
+	end     netip.Addr // Последний адрес диапазона
+	country string     // Код страны ISO 3166-1 alpha-2 в верхнем регистре
+}
@@ -0,0 +1,190 @@
+// Package handlers содержит HTTP-хендлеры для API
+package handlers
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Параметры отключения URL администратором
+const (
+	defaultDisableReason   = "abuse" // Причина отключения по умолчанию
+	maxDisableReasonLength = 64      // Максимальная длина причины отключения
+)
+
+// DisableURLByID отключает URL администратором
+// @Summary Отключить URL
+// @Description Отключает сокращенный URL, например при жалобе на злоупотребление. Переход по отключенному URL
+// @Description возвращает 451 с причиной в заголовке X-Block-Reason. Требуется токен администратора (config.AdminToken).
+// @Tags Администратор
+// @Accept json
+// @Param Authorization header string true "Bearer <токен администратора>"
+// @Param id path string true "ID сокращенного URL"
+// @Param input body models.RequestDisableURL false "Причина отключения"
+// @Success 204 "URL отключен"
+// @Failure 400 {string} string "Неверный ID или формат запроса"
+// @Failure 403 {string} string "Неверный токен администратора"
+// @Failure 404 {string} string "URL не найден"
+// @Router /api/admin/urls/{id}/disable [post]
+func (app *App) DisableURLByID(res http.ResponseWriter, req *http.Request) {
+	id, ok := app.adminLinkID(res, req)
+	if !ok {
+		return
+	}
+
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(req.Body)
+	defer readerPool.Put(reader)
+
+	var reqDto models.RequestDisableURL
+	if err := json.NewDecoder(reader).Decode(&reqDto); err != nil && !errors.Is(err, io.EOF) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Body is invalidate!"))
+		return
+	}
+
+	reason := strings.TrimSpace(reqDto.Reason)
+	if reason == "" {
+		reason = defaultDisableReason
+	}
+	if len(reason) > maxDisableReasonLength {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Reason is invalidate!"))
+		return
+	}
+
+	app.disableByID(res, req, id, reason)
+}
+
+// EnableURLByID снимает отключение URL администратором
+// @Summary Включить URL
+// @Description Снимает отключение сокращенного URL. Требуется токен администратора (config.AdminToken).
+// @Tags Администратор
+// @Param Authorization header string true "Bearer <токен администратора>"
+// @Param id path string true "ID сокращенного URL"
+// @Success 204 "URL включен"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 403 {string} string "Неверный токен администратора"
+// @Failure 404 {string} string "URL не найден"
+// @Router /api/admin/urls/{id}/enable [post]
+func (app *App) EnableURLByID(res http.ResponseWriter, req *http.Request) {
+	id, ok := app.adminLinkID(res, req)
+	if !ok {
+		return
+	}
+	app.disableByID(res, req, id, "")
+}
+
+// GetCacheStats возвращает счетчики кэша записей URL
+// @Summary Статистика кэша
+// @Description Возвращает количество попаданий и промахов кэша записей URL с момента запуска сервиса
+// @Description и текущее количество записей. Требуется токен администратора (config.AdminToken).
+// @Tags Администратор
+// @Produce json
+// @Param Authorization header string true "Bearer <токен администратора>"
+// @Success 200 {object} models.CacheStats
+// @Failure 403 {string} string "Неверный токен администратора"
+// @Failure 404 {string} string "Кэш не настроен"
+// @Router /api/admin/cache/stats [get]
+func (app *App) GetCacheStats(res http.ResponseWriter, req *http.Request) {
+	if !adminAuthorized(res, req) {
+		return
+	}
+
+	stats, err := app.service.CacheStats()
+	if err != nil {
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Cache is disabled!"))
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("Cache-Control", "no-store")
+	res.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(res).Encode(stats); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Response is invalidate!"))
+		return
+	}
+}
+
+// adminAuthorized проверяет токен администратора и записывает 403, если он неверный
+// Возвращает false, если обработку запроса нужно прекратить
+func adminAuthorized(res http.ResponseWriter, req *http.Request) bool {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if config.AdminToken == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(config.AdminToken)) != 1 {
+		res.WriteHeader(http.StatusForbidden)
+		_, _ = res.Write([]byte("Admin token is invalidate!"))
+		return false
+	}
+	return true
+}
+
+// adminLinkID проверяет токен администратора и разбирает ID сокращенного URL
+// Возвращает false, если обработку запроса нужно прекратить
+func (app *App) adminLinkID(res http.ResponseWriter, req *http.Request) (uuid.UUID, bool) {
+	if !adminAuthorized(res, req) {
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// disableByID сохраняет причину отключения URL и записывает ответ
+func (app *App) disableByID(res http.ResponseWriter, req *http.Request, id uuid.UUID, reason string) {
+	err := app.service.DisableByID(req.Context(), id, reason)
+	if err != nil && errors.Is(err, customError.ErrNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Url by id not found!"))
+		return
+	}
+
+	if err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Disable url error!"))
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// writeBlocked записывает ответ для запрещенного адреса назначения
+// Причина блокировки передается в заголовке X-Block-Reason
+// Возвращает true, если err содержит ошибку блокировки и ответ записан
+func writeBlocked(res http.ResponseWriter, err error) bool {
+	var blocked *service.BlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+
+	res.Header().Set("Cache-Control", "no-store")
+	res.Header().Set("X-Block-Reason", blocked.Reason)
+	res.WriteHeader(http.StatusForbidden)
+	_, _ = res.Write([]byte("URL is blocked!"))
+	return true
+}
+
+// writeDisabled записывает ответ для URL, отключенного администратором
+func writeDisabled(res http.ResponseWriter, link *models.Link) {
+	res.Header().Set("Cache-Control", "no-store")
+	res.Header().Set("X-Block-Reason", link.Disabled)
+	res.WriteHeader(http.StatusUnavailableForLegalReasons)
+	_, _ = res.Write([]byte("URL is disabled!"))
+}
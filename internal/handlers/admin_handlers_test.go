@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/blocklist"
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/storage/cache"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminRequest(tc *Suite, id uuid.UUID, action, token, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/admin/urls/"+id.String()+"/"+action, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestShortenBlocked(t *testing.T) {
+	tc := NewSuite(t)
+	list, err := blocklist.Parse(strings.NewReader("evil.com\n"))
+	require.NoError(t, err)
+	tc.app.service.Blocklist = list
+
+	tests := []struct {
+		name   string
+		body   string
+		reason string
+	}{
+		{
+			name:   "blocklisted domain",
+			body:   "https://login.evil.com/",
+			reason: blocklist.ReasonBlocklisted,
+		},
+		{
+			name:   "ip literal",
+			body:   "http://192.168.0.1/admin",
+			reason: blocklist.ReasonIPLiteral,
+		},
+		{
+			name:   "excessive subdomains",
+			body:   "https://a.b.c.d.e.example.com/",
+			reason: blocklist.ReasonSubdomains,
+		},
+		{
+			name: "allowed",
+			body: "https://go.dev/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			tc.app.ShortenURL(w, req)
+
+			assert.Equal(t, tt.reason, w.Header().Get("X-Block-Reason"))
+			if tt.reason == "" {
+				assert.Equal(t, http.StatusCreated, w.Code)
+				return
+			}
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Equal(t, "URL is blocked!", w.Body.String())
+		})
+	}
+
+	t.Run("variant url", func(t *testing.T) {
+		payload := `{"url":"https://go.dev/a","variants":[{"url":"https://evil.com/","weight":1}]}`
+		req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten", strings.NewReader(payload))
+		w := httptest.NewRecorder()
+
+		tc.app.ShortenAPI(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, blocklist.ReasonBlocklisted, w.Header().Get("X-Block-Reason"))
+	})
+
+	t.Run("redirect recheck", func(t *testing.T) {
+		u, _ := url.Parse("https://phish.example.org/")
+		id, err := tc.app.service.Save(context.Background(), uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String())), u)
+		require.NoError(t, err)
+
+		list, err := blocklist.Parse(strings.NewReader("example.org\n"))
+		require.NoError(t, err)
+		tc.app.service.Blocklist = list
+		w := httptest.NewRecorder()
+
+		tc.app.GetURLByID(w, newGetRequest(tc, id))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, blocklist.ReasonBlocklisted, w.Header().Get("X-Block-Reason"))
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+}
+
+func TestDisableURLByID(t *testing.T) {
+	tc := NewSuite(t)
+	config.AdminToken = "secret"
+	u, _ := url.Parse("https://go.dev/abuse")
+	id, err := tc.app.service.Save(context.Background(), uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String())), u)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		id     uuid.UUID
+		action string
+		token  string
+		body   string
+		status int
+		get    int
+		reason string
+	}{
+		{
+			name:   "token is missing",
+			id:     id,
+			action: "disable",
+			status: http.StatusForbidden,
+			get:    http.StatusTemporaryRedirect,
+		},
+		{
+			name:   "token is invalidate",
+			id:     id,
+			action: "disable",
+			token:  "wrong",
+			status: http.StatusForbidden,
+			get:    http.StatusTemporaryRedirect,
+		},
+		{
+			name:   "url not found",
+			id:     uuid.New(),
+			action: "disable",
+			token:  "secret",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "reason is too long",
+			id:     id,
+			action: "disable",
+			token:  "secret",
+			body:   `{"reason":"` + strings.Repeat("a", maxDisableReasonLength+1) + `"}`,
+			status: http.StatusBadRequest,
+			get:    http.StatusTemporaryRedirect,
+		},
+		{
+			name:   "disable with default reason",
+			id:     id,
+			action: "disable",
+			token:  "secret",
+			status: http.StatusNoContent,
+			get:    http.StatusUnavailableForLegalReasons,
+			reason: defaultDisableReason,
+		},
+		{
+			name:   "disable with reason",
+			id:     id,
+			action: "disable",
+			token:  "secret",
+			body:   `{"reason":" phishing "}`,
+			status: http.StatusNoContent,
+			get:    http.StatusUnavailableForLegalReasons,
+			reason: "phishing",
+		},
+		{
+			name:   "enable",
+			id:     id,
+			action: "enable",
+			token:  "secret",
+			status: http.StatusNoContent,
+			get:    http.StatusTemporaryRedirect,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newAdminRequest(tc, tt.id, tt.action, tt.token, tt.body)
+			w := httptest.NewRecorder()
+
+			if tt.action == "enable" {
+				tc.app.EnableURLByID(w, req)
+			} else {
+				tc.app.DisableURLByID(w, req)
+			}
+			assert.Equal(t, tt.status, w.Code)
+
+			if tt.get == 0 {
+				return
+			}
+			w = httptest.NewRecorder()
+
+			tc.app.GetURLByID(w, newGetRequest(tc, tt.id))
+
+			assert.Equal(t, tt.get, w.Code)
+			assert.Equal(t, tt.reason, w.Header().Get("X-Block-Reason"))
+		})
+	}
+}
+
+func TestGetCacheStats(t *testing.T) {
+	tc := NewSuite(t)
+	config.AdminToken = "secret"
+
+	getStats := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/admin/cache/stats", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		tc.app.GetCacheStats(w, req)
+		return w
+	}
+
+	w := getStats("secret")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Cache is disabled!", w.Body.String())
+
+	tc.app.service.Repository = cache.NewRepository(nil, tc.app.service.Repository, 10, time.Minute)
+	id := uuid.New()
+	for i := 0; i < 3; i++ {
+		_, _ = tc.app.service.GetLinkByID(context.Background(), id)
+	}
+
+	w = getStats("secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.CacheStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, models.CacheStats{Hits: 2, Misses: 1, Size: 1}, got)
+
+	w = getStats("wrong")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -28,9 +28,9 @@ func setUpApp() *App {
 	newRepository = mem.NewRepository(zl)
 	newRunner = newRepository
 	// В реальном коде используйте NewSuite для инициализации
-	newService := service.NewService(zl, newRunner, newRepository)
-	newWorker := worker.NewWorker(context.Background(), config.WorkerCount, zl, newService)
-	return NewApp(newService, newWorker)
+	newService := service.NewService(zl, newRunner, newRepository, nil, nil, nil)
+	newWorker := worker.NewWorker(context.Background(), config.WorkerCount, zl, newService, nil, nil)
+	return NewApp(newService, newWorker, nil)
 }
 
 // Пример использования ShortenURL (текстовый формат)
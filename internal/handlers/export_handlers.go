@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+)
+
+// exportDeadlineEvery количество записанных URL, после которого продлевается срок записи ответа экспорта
+const exportDeadlineEvery = 1000
+
+// exportCSVHeader столбцы экспорта в формате CSV
+var exportCSVHeader = []string{
+	"short_url", "original_url", "created_at", "is_deleted", "clicks", "protected", "title", "tags", "note",
+	"folder_id", "redirect_code", "schedule_state", "disabled_reason", "health_status", "page_title",
+}
+
+// exportWriter записывает URL экспорта в одном из форматов
+type exportWriter interface {
+	// begin записывает начало ответа
+	begin() error
+	// write записывает URL
+	write(u *models.ResponseShortenAPIUser) error
+	// end записывает окончание ответа
+	end() error
+}
+
+// ExportURLByUserID выгружает все URL пользователя
+// @Summary Экспорт URL пользователя
+// @Description Выгружает все URL текущего пользователя с атрибутами и количеством переходов одним ответом без пагинации.
+// @Description URL читаются из хранилища и записываются в ответ по одному, не накапливаясь в памяти.
+// @Description Поддерживает те же фильтры и сортировку, что и /api/user/urls; limit и cursor не учитываются.
+// @Description В формате csv теги разделяются символом ";", а из health и page выгружаются только статус и заголовок.
+// @Description Если чтение прервалось после начала ответа, ответ обрывается без завершающих данных формата.
+// @Tags Пользователь
+// @Security ApiKeyAuth
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Param format query string false "Формат: json (по умолчанию), ndjson или csv"
+// @Param order query string false "Сортировка по времени создания: asc или desc (по умолчанию)"
+// @Param domain query string false "Домен оригинального URL"
+// @Param created_from query string false "Нижняя граница времени создания (RFC3339)"
+// @Param created_to query string false "Верхняя граница времени создания (RFC3339)"
+// @Param deleted query string false "Удаленные URL: include или exclude (по умолчанию)"
+// @Param q query string false "Подстрока оригинального URL"
+// @Param tag query string false "Тег URL"
+// @Param folder_id query string false "ID папки"
+// @Success 200 {array} models.ResponseShortenAPIUser
+// @Failure 400 {string} string "Неверные параметры запроса"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 500 {string} string "Ошибка экспорта"
+// @Router /api/user/urls/export [get]
+func (app *App) ExportURLByUserID(res http.ResponseWriter, req *http.Request) {
+	filter, err := parseFilterURLs(req.URL.Query())
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Query is invalidate!"))
+		return
+	}
+
+	writer := writerPool.Get().(*bufio.Writer)
+	writer.Reset(res)
+	defer writerPool.Put(writer)
+
+	var export exportWriter
+	var contentType, ext string
+	switch req.URL.Query().Get("format") {
+	case "", "json":
+		export, contentType, ext = &jsonExportWriter{writer: writer, encoder: json.NewEncoder(writer)}, "application/json", "json"
+	case "ndjson":
+		export, contentType, ext = &ndjsonExportWriter{encoder: json.NewEncoder(writer)}, "application/x-ndjson", "ndjson"
+	case "csv":
+		export, contentType, ext = &csvExportWriter{writer: csv.NewWriter(writer)}, "text/csv", "csv"
+	default:
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Format is invalidate!"))
+		return
+	}
+
+	// Статус ответа отправляется только вместе с первым URL, чтобы ошибку до начала выгрузки можно было вернуть кодом
+	rc := http.NewResponseController(res)
+	started := false
+	start := func() error {
+		started = true
+		res.Header().Set("Content-Type", contentType)
+		res.Header().Set("Content-Disposition", "attachment; filename=\"urls."+ext+"\"")
+		res.WriteHeader(http.StatusOK)
+		return export.begin()
+	}
+
+	n := 0
+	err = app.service.ExportByUserID(req.Context(), filter, func(u *models.ResponseShortenAPIUser) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		// WriteTimeout сервера ограничивает весь ответ, поэтому большой экспорт продлевает срок записи
+		if n%exportDeadlineEvery == 0 {
+			_ = rc.SetWriteDeadline(time.Now().Add(config.TerminationTimeout))
+		}
+		n++
+		return export.write(u)
+	})
+	switch {
+	case err != nil && started:
+		// Заголовок уже отправлен, поэтому клиент узнает об ошибке по оборванному ответу
+		return
+	case errors.Is(err, service.ErrUserUnauthorized):
+		res.WriteHeader(http.StatusUnauthorized)
+		_, _ = res.Write([]byte("User unauthorized!"))
+		return
+	case err != nil:
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Export error!"))
+		return
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			return
+		}
+	}
+	if err := export.end(); err != nil {
+		return
+	}
+	_ = writer.Flush()
+}
+
+// jsonExportWriter записывает URL JSON массивом
+type jsonExportWriter struct {
+	writer  *bufio.Writer // Буфер ответа
+	encoder *json.Encoder // Кодировщик элементов массива
+	n       int           // Количество записанных элементов
+}
+
+// begin записывает открывающую скобку массива
+func (w *jsonExportWriter) begin() error {
+	return w.writer.WriteByte('[')
+}
+
+// write записывает элемент массива, отделяя его запятой от предыдущего
+func (w *jsonExportWriter) write(u *models.ResponseShortenAPIUser) error {
+	if w.n > 0 {
+		if err := w.writer.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	w.n++
+	return w.encoder.Encode(u)
+}
+
+// end записывает закрывающую скобку массива
+func (w *jsonExportWriter) end() error {
+	_, err := w.writer.WriteString("]\n")
+	return err
+}
+
+// ndjsonExportWriter записывает URL по одному JSON объекту на строку
+type ndjsonExportWriter struct {
+	encoder *json.Encoder // Кодировщик строк
+}
+
+// begin ничего не записывает: у NDJSON нет начала
+func (w *ndjsonExportWriter) begin() error {
+	return nil
+}
+
+// write записывает строку с URL
+func (w *ndjsonExportWriter) write(u *models.ResponseShortenAPIUser) error {
+	return w.encoder.Encode(u)
+}
+
+// end ничего не записывает: у NDJSON нет окончания
+func (w *ndjsonExportWriter) end() error {
+	return nil
+}
+
+// csvExportWriter записывает URL строками CSV со столбцами exportCSVHeader
+type csvExportWriter struct {
+	writer *csv.Writer // Кодировщик CSV поверх буфера ответа
+}
+
+// begin записывает строку заголовка
+func (w *csvExportWriter) begin() error {
+	return w.writer.Write(exportCSVHeader)
+}
+
+// write записывает строку с URL
+func (w *csvExportWriter) write(u *models.ResponseShortenAPIUser) error {
+	var folderID, redirectCode, healthStatus, pageTitle string
+	if u.FolderID != nil {
+		folderID = u.FolderID.String()
+	}
+	if u.RedirectCode != 0 {
+		redirectCode = strconv.Itoa(u.RedirectCode)
+	}
+	if u.Health != nil {
+		healthStatus = u.Health.Status
+	}
+	if u.Page != nil {
+		pageTitle = u.Page.Title
+	}
+
+	return w.writer.Write([]string{
+		u.ShortURL,
+		u.OriginalURL,
+		u.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatBool(u.IsDeleted),
+		strconv.FormatInt(u.Clicks, 10),
+		strconv.FormatBool(u.Protected),
+		u.Title,
+		strings.Join(u.Tags, ";"),
+		u.Note,
+		folderID,
+		redirectCode,
+		u.ScheduleState,
+		u.Disabled,
+		healthStatus,
+		pageTitle,
+	})
+}
+
+// end сбрасывает строки CSV в буфер ответа
+func (w *csvExportWriter) end() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
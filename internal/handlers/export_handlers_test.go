@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportURLByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), userID)
+
+	createdAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i, raw := range []string{"https://ya.ru/", "https://go.dev/doc", "https://example.com/"} {
+		_, err := tc.app.service.Repository.SaveLink(ctx, nil, &models.Link{
+			ID:          uuid.NewSHA1(uuid.NameSpaceURL, []byte(raw)),
+			UserID:      &userID,
+			OriginalURL: raw,
+			CreatedAt:   createdAt.Add(time.Duration(i) * time.Hour),
+			LinkMeta:    models.LinkMeta{Title: "link, " + raw, Tags: []string{"a", "b"}},
+		})
+		require.NoError(t, err)
+	}
+	goDevID := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://go.dev/doc"))
+	require.NoError(t, tc.app.service.AddClick(ctx, goDevID, ""))
+	_, err := tc.app.service.Repository.DeleteBatchByUserID(ctx, nil, userID, []uuid.UUID{uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://example.com/"))})
+	require.NoError(t, err)
+
+	export := func(query string, userID uuid.UUID) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/urls/export"+query, nil)
+		if userID != uuid.Nil {
+			req = req.WithContext(customContext.SetContextUserID(req.Context(), userID))
+		}
+		w := httptest.NewRecorder()
+		tc.app.ExportURLByUserID(w, req)
+		return w
+	}
+
+	t.Run("json", func(t *testing.T) {
+		w := export("?order=asc", userID)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=\"urls.json\"", w.Header().Get("Content-Disposition"))
+
+		var got []models.ResponseShortenAPIUser
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		require.Len(t, got, 2)
+		assert.Equal(t, "https://ya.ru/", got[0].OriginalURL)
+		assert.Equal(t, "https://go.dev/doc", got[1].OriginalURL)
+		assert.Equal(t, int64(1), got[1].Clicks)
+		assert.Equal(t, []string{"a", "b"}, got[1].Tags)
+	})
+
+	t.Run("ndjson with deleted", func(t *testing.T) {
+		w := export("?format=ndjson&deleted=include", userID)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 3)
+		var last models.ResponseShortenAPIUser
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &last))
+		assert.Equal(t, "https://example.com/", last.OriginalURL)
+		assert.True(t, last.IsDeleted)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		w := export("?format=csv&order=asc", userID)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, exportCSVHeader, records[0])
+		assert.Equal(t, []string{
+			tc.app.URL + goDevID.String(), "https://go.dev/doc", "2024-01-01T01:00:00Z", "false", "1", "false",
+			"link, https://go.dev/doc", "a;b", "", "", "", "", "", "", "",
+		}, records[2])
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		w := export("", uuid.New())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "[]\n", w.Body.String())
+
+		w = export("?format=csv", uuid.New())
+		assert.Equal(t, strings.Join(exportCSVHeader, ",")+"\n", w.Body.String())
+	})
+
+	t.Run("format is invalidate", func(t *testing.T) {
+		w := export("?format=xml", userID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "Format is invalidate!", w.Body.String())
+	})
+
+	t.Run("user unauthorized", func(t *testing.T) {
+		w := export("", uuid.Nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "User unauthorized!", w.Body.String())
+	})
+
+	t.Run("deleted during export", func(t *testing.T) {
+		var got []string
+		err := tc.app.service.Repository.ExportByUserID(ctx, userID, &models.FilterURLs{}, func(u *models.ResponseShortenAPIUser) error {
+			got = append(got, u.OriginalURL)
+			_, err := tc.app.service.Repository.DeleteBatchByUserID(ctx, nil, userID, []uuid.UUID{goDevID})
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://ya.ru/"}, got)
+	})
+}
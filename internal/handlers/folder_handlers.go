@@ -0,0 +1,192 @@
+// Package handlers содержит HTTP-хендлеры для API
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Ограничения названия папки
+const (
+	maxFolderNameLength = 255 // Максимальная длина названия папки
+)
+
+// SaveFolder создает папку пользователя
+// @Summary Создать папку
+// @Description Создает папку для группировки сокращенных URL текущего пользователя
+// @Tags Папки
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param input body models.RequestFolder true "Название папки"
+// @Success 201 {object} models.Folder
+// @Failure 400 {string} string "Неверный формат запроса"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 409 {string} string "Папка с таким названием уже существует"
+// @Router /api/user/folders [post]
+func (app *App) SaveFolder(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	name, ok := decodeFolderName(res, req)
+	if !ok {
+		return
+	}
+
+	respDto, err := app.service.SaveFolder(req.Context(), name)
+	if !writeFolderError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusCreated, respDto)
+}
+
+// GetFoldersByUserID возвращает папки пользователя
+// @Summary Получить папки
+// @Description Возвращает папки текущего пользователя в порядке создания
+// @Tags Папки
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} models.Folder
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Router /api/user/folders [get]
+func (app *App) GetFoldersByUserID(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	respDto, err := app.service.GetFoldersByUserID(req.Context())
+	if !writeFolderError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusOK, respDto)
+}
+
+// UpdateFolderByUserID переименовывает папку пользователя
+// @Summary Переименовать папку
+// @Description Изменяет название папки текущего пользователя
+// @Tags Папки
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID папки"
+// @Param input body models.RequestFolder true "Новое название папки"
+// @Success 200 {object} models.Folder
+// @Failure 400 {string} string "Неверный формат запроса"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 404 {string} string "Папка не найдена"
+// @Failure 409 {string} string "Папка с таким названием уже существует"
+// @Router /api/user/folders/{id} [patch]
+func (app *App) UpdateFolderByUserID(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	name, ok := decodeFolderName(res, req)
+	if !ok {
+		return
+	}
+
+	respDto, err := app.service.UpdateFolderByUserID(req.Context(), id, name)
+	if !writeFolderError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusOK, respDto)
+}
+
+// DeleteFolderByUserID удаляет папку пользователя
+// @Summary Удалить папку
+// @Description Удаляет папку текущего пользователя, сокращенные URL папки остаются без папки
+// @Tags Папки
+// @Security ApiKeyAuth
+// @Param id path string true "ID папки"
+// @Success 204 "Папка удалена"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 404 {string} string "Папка не найдена"
+// @Router /api/user/folders/{id} [delete]
+func (app *App) DeleteFolderByUserID(res http.ResponseWriter, req *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	err = app.service.DeleteFolderByUserID(req.Context(), id)
+	if !writeFolderError(res, err) {
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// decodeFolderName читает и проверяет название папки из тела запроса
+func decodeFolderName(res http.ResponseWriter, req *http.Request) (string, bool) {
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(req.Body)
+	defer readerPool.Put(reader)
+
+	var reqDto models.RequestFolder
+	if err := json.NewDecoder(reader).Decode(&reqDto); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Body is invalidate!"))
+		return "", false
+	}
+
+	name := strings.TrimSpace(reqDto.Name)
+	if name == "" || len(name) > maxFolderNameLength {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Folder name is invalidate!"))
+		return "", false
+	}
+	return name, true
+}
+
+// writeFolderError записывает ответ с ошибкой операции над папкой
+// Возвращает true, если ошибки нет и обработку запроса нужно продолжить
+func writeFolderError(res http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrUserUnauthorized):
+		res.WriteHeader(http.StatusUnauthorized)
+		_, _ = res.Write([]byte("User unauthorized!"))
+	case errors.Is(err, customError.ErrNotFound):
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Folder by id not found!"))
+	case errors.Is(err, customError.ErrConflict):
+		res.WriteHeader(http.StatusConflict)
+		_, _ = res.Write([]byte("Folder name already exists!"))
+	default:
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Folder operation error!"))
+	}
+	return false
+}
+
+// writeJSON записывает JSON ответ с объектом или списком объектов
+func writeJSON(res http.ResponseWriter, status int, respDto any) {
+	writer := writerPool.Get().(*bufio.Writer)
+	writer.Reset(res)
+	defer func() {
+		writer.Flush()
+		writerPool.Put(writer)
+	}()
+
+	res.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(respDto); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Response is invalidate!"))
+		return
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveFolder(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	tests := []struct {
+		name    string
+		payload []byte
+		status  int
+	}{
+		{
+			name:    "body is invalidate",
+			payload: []byte("invalid json"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "name is empty",
+			payload: []byte("{\"name\":\"  \"}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "ok",
+			payload: []byte("{\"name\":\"work\"}"),
+			status:  http.StatusCreated,
+		},
+		{
+			name:    "name already exists",
+			payload: []byte("{\"name\":\"work\"}"),
+			status:  http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/user/folders", bytes.NewBuffer(tt.payload))
+			req = req.WithContext(customContext.SetContextUserID(req.Context(), userID))
+			w := httptest.NewRecorder()
+
+			tc.app.SaveFolder(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusCreated {
+				var got models.Folder
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				assert.Equal(t, "work", got.Name)
+				assert.NotEqual(t, uuid.Nil, got.ID)
+			}
+		})
+	}
+}
+
+func TestUpdateFolderByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	folder, _ := tc.app.service.SaveFolder(ctx, "work")
+	_, _ = tc.app.service.SaveFolder(ctx, "home")
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		id      string
+		payload []byte
+		status  int
+	}{
+		{
+			name:    "id is invalidate",
+			userID:  ownerID,
+			id:      "not-uuid",
+			payload: []byte("{\"name\":\"job\"}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "user is not owner",
+			userID:  uuid.New(),
+			id:      folder.ID.String(),
+			payload: []byte("{\"name\":\"job\"}"),
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "name already exists",
+			userID:  ownerID,
+			id:      folder.ID.String(),
+			payload: []byte("{\"name\":\"home\"}"),
+			status:  http.StatusConflict,
+		},
+		{
+			name:    "ok",
+			userID:  ownerID,
+			id:      folder.ID.String(),
+			payload: []byte("{\"name\":\"job\"}"),
+			status:  http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, tc.app.URL+"api/user/folders/"+tt.id, bytes.NewBuffer(tt.payload))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.UpdateFolderByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var got models.Folder
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				assert.Equal(t, "job", got.Name)
+				assert.Equal(t, folder.ID, got.ID)
+			}
+		})
+	}
+}
+
+func TestDeleteFolderByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	folder, _ := tc.app.service.SaveFolder(ctx, "work")
+	u, _ := url.Parse("https://ya.ru/")
+	_, _ = tc.app.service.SaveLink(ctx, &models.Link{
+		ID:          uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String())),
+		OriginalURL: u.String(),
+		LinkMeta:    models.LinkMeta{FolderID: &folder.ID},
+	})
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		id     string
+		status int
+	}{
+		{
+			name:   "user is not owner",
+			userID: uuid.New(),
+			id:     folder.ID.String(),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ok",
+			userID: ownerID,
+			id:     folder.ID.String(),
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "folder already deleted",
+			userID: ownerID,
+			id:     folder.ID.String(),
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tc.app.URL+"api/user/folders/"+tt.id, nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.DeleteFolderByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusNoContent {
+				urls, _, _ := tc.app.service.GetAllByUserID(ctx, &models.FilterURLs{Limit: defaultPageLimit})
+				assert.Len(t, urls, 1)
+				assert.Nil(t, urls[0].FolderID)
+			}
+		})
+	}
+}
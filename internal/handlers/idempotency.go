@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+)
+
+// Заголовки идемпотентных запросов
+const (
+	headerIdempotencyKey     = "Idempotency-Key"     // Ключ, по которому повтор запроса получает первый ответ
+	headerIdempotentReplayed = "Idempotent-Replayed" // Ответ повторен из сохраненного для Idempotency-Key
+)
+
+// idempotencyWriter передает ответ клиенту и копирует код и тело ответа для сохранения
+type idempotencyWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader запоминает первый код ответа
+func (w *idempotencyWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write копирует тело ответа
+func (w *idempotencyWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap возвращает исходный http.ResponseWriter для http.ResponseController
+func (w *idempotencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// idempotent выполняет обработчик next с учетом заголовка Idempotency-Key
+// Первый ответ на ключ пользователя сохраняется и повторяется на запросы с тем же ключом и телом.
+// Одновременный запрос с ключом, который еще выполняется, получает 409, а запрос с другим телом - 422.
+// Без заголовка next выполняется как обычно
+func (app *App) idempotent(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	key := req.Header.Get(headerIdempotencyKey)
+	if key == "" {
+		next(res, req)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Body is invalidate!"))
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, req.Method+" "+req.URL.Path+"\n")
+	_, _ = hash.Write(body)
+
+	record, err := app.service.BeginIdempotent(req.Context(), key, hex.EncodeToString(hash.Sum(nil)))
+	switch {
+	case errors.Is(err, service.ErrUserUnauthorized):
+		next(res, req)
+		return
+	case errors.Is(err, service.ErrIdempotencyKeyNotValid):
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Idempotency-Key is invalidate!"))
+		return
+	case errors.Is(err, service.ErrIdempotencyInProgress):
+		res.WriteHeader(http.StatusConflict)
+		_, _ = res.Write([]byte("Request is in progress!"))
+		return
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		res.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = res.Write([]byte("Idempotency-Key is reused!"))
+		return
+	case err != nil:
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Idempotency-Key error!"))
+		return
+	case record != nil:
+		res.Header().Set("Content-Type", record.ContentType)
+		res.Header().Set(headerIdempotentReplayed, "true")
+		res.WriteHeader(record.Status)
+		_, _ = res.Write(record.Body)
+		return
+	}
+
+	// Ответ сохраняется и после отключения клиента, иначе повтор запроса получил бы 409
+	ctx := context.WithoutCancel(req.Context())
+	w := &idempotencyWriter{ResponseWriter: res}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = app.service.CompleteIdempotent(ctx, key, http.StatusInternalServerError, "", nil)
+			panic(p)
+		}
+	}()
+
+	next(w, req)
+	_ = app.service.CompleteIdempotent(ctx, key, max(w.status, http.StatusOK), res.Header().Get("Content-Type"), w.body.Bytes())
+}
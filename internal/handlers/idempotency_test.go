@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortenAPIBatchIdempotency(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), userID)
+	payload := []byte("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"original_url\":\"https://ya.ru/\"}]")
+	want := []byte("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"short_url\":\"" + tc.app.URL + "eefbcef4-3940-5a38-b2f0-877152a6d470\",\"status\":\"created\"}]\n")
+
+	// Ключ, запрос с которым еще выполняется
+	_, err := tc.app.service.BeginIdempotent(ctx, "in-progress", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		key      string
+		payload  []byte
+		status   int
+		want     []byte
+		replayed bool
+	}{
+		{
+			name:    "first request",
+			key:     "batch-1",
+			payload: payload,
+			status:  http.StatusCreated,
+			want:    want,
+		},
+		{
+			name:     "retry is replayed",
+			key:      "batch-1",
+			payload:  payload,
+			status:   http.StatusCreated,
+			want:     want,
+			replayed: true,
+		},
+		{
+			name:    "key is reused with other body",
+			key:     "batch-1",
+			payload: []byte("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"original_url\":\"https://go.dev/\"}]"),
+			status:  http.StatusUnprocessableEntity,
+			want:    []byte("Idempotency-Key is reused!"),
+		},
+		{
+			name:    "request is in progress",
+			key:     "in-progress",
+			payload: payload,
+			status:  http.StatusConflict,
+			want:    []byte("Request is in progress!"),
+		},
+		{
+			name:    "key is invalidate",
+			key:     "key\x01",
+			payload: payload,
+			status:  http.StatusBadRequest,
+			want:    []byte("Idempotency-Key is invalidate!"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/batch", bytes.NewBuffer(tt.payload))
+			req = req.WithContext(ctx)
+			req.Header.Set("Idempotency-Key", tt.key)
+			w := httptest.NewRecorder()
+
+			tc.app.ShortenAPIBatch(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.want, w.Body.Bytes())
+			if tt.replayed {
+				assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	// Ключи разных пользователей не пересекаются
+	req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/batch", bytes.NewBuffer(payload))
+	req = req.WithContext(customContext.SetContextUserID(context.Background(), uuid.New()))
+	req.Header.Set("Idempotency-Key", "batch-1")
+	w := httptest.NewRecorder()
+	tc.app.ShortenAPIBatch(w, req)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+}
+
+func TestDeleteExpiredIdempotency(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	expiresAt := map[string]time.Time{
+		"expired": time.Now().Add(-time.Minute),
+		"active":  time.Now().Add(time.Hour),
+	}
+	for key, at := range expiresAt {
+		_, err := tc.app.service.Repository.ReserveIdempotency(context.Background(), &models.IdempotencyRecord{
+			UserID:    userID,
+			Key:       key,
+			ExpiresAt: at,
+		})
+		require.NoError(t, err)
+	}
+
+	n, err := tc.app.service.DeleteExpiredIdempotency(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// Просроченный ключ можно зарезервировать заново
+	ctx := customContext.SetContextUserID(context.Background(), userID)
+	record, err := tc.app.service.BeginIdempotent(ctx, "expired", "")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
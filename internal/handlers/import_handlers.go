@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// importLineMax максимальная длина строки NDJSON, более длинная строка прерывает импорт
+const importLineMax = 1024 * 1024
+
+// Причины, по которым строка импорта не передается в сервис
+const (
+	importReasonLine          = "line is invalidate"
+	importReasonCorrelationID = "correlation_id is invalidate"
+	importReasonSave          = "save error"
+	importReasonBody          = "body is invalidate"
+)
+
+// importDecoder читает следующую непустую строку тела запроса импорта
+// Возвращает номер строки и элемент пакета либо причину, по которой строка невалидна.
+// В конце тела возвращает io.EOF, при ошибке чтения - саму ошибку
+type importDecoder func() (line int, item *models.RequestShortenAPIBatch, reason string, err error)
+
+// importLine строка импорта в обрабатываемой части тела запроса
+type importLine struct {
+	line int                             // Номер строки тела запроса
+	item *models.RequestShortenAPIBatch  // Элемент пакета, nil для невалидной строки
+	res  *models.ResponseShortenAPIBatch // Результат обработки строки
+}
+
+// ShortenAPIImport сокращает URL из потокового тела запроса
+// @Summary Потоковый импорт URL
+// @Description Читает тело запроса построчно и сохраняет URL частями по IMPORT_CHUNK строк,
+// @Description поэтому объем памяти не зависит от размера тела. Тело может быть сжато gzip.
+// @Description Форматы тела определяются по Content-Type:
+// @Description application/x-ndjson - по одному объекту как в /api/shorten/batch на строку, поле qr не учитывается;
+// @Description text/csv - столбцы original_url и correlation_id, первая строка может быть заголовком с этими названиями.
+// @Description Результаты отправляются в формате NDJSON после сохранения каждой части, порядок строк сохраняется.
+// @Description Статус ответа всегда 200: результат каждой строки в поле status (created, existing, invalid, blocked).
+// @Description При ошибке хранилища или чтения тела строки текущей части получают статус failed и импорт прекращается,
+// @Description предыдущие части остаются сохраненными.
+// @Tags URL
+// @Accept application/x-ndjson
+// @Accept text/csv
+// @Produce application/x-ndjson
+// @Param request body string true "Строки NDJSON или CSV"
+// @Success 200 {object} models.ResponseShortenAPIImport "Строка ответа"
+// @Failure 415 {string} string "Неподдерживаемый Content-Type"
+// @Router /api/shorten/import [post]
+func (app *App) ShortenAPIImport(res http.ResponseWriter, req *http.Request) {
+	var next importDecoder
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-ndjson", "application/jsonl":
+		next = newNDJSONDecoder(req.Body)
+	case "text/csv":
+		next = newCSVDecoder(req.Body)
+	default:
+		res.WriteHeader(http.StatusUnsupportedMediaType)
+		_, _ = res.Write([]byte("Content-Type is invalidate!"))
+		return
+	}
+
+	// Результаты отправляются до окончания чтения тела, а HTTP/1.1 сервер без этого закрывает тело после первой записи
+	rc := http.NewResponseController(res)
+	_ = rc.EnableFullDuplex()
+
+	writer := writerPool.Get().(*bufio.Writer)
+	writer.Reset(res)
+	defer writerPool.Put(writer)
+	encoder := json.NewEncoder(writer)
+
+	res.Header().Set("Content-Type", "application/x-ndjson")
+	res.WriteHeader(http.StatusOK)
+
+	lines := make([]importLine, 0, config.ImportChunk)
+	batch := make([]*models.RequestShortenAPIBatch, 0, config.ImportChunk)
+	for done := false; !done; {
+		// ReadTimeout и WriteTimeout сервера ограничивают весь запрос, поэтому сроки продлеваются на каждую часть
+		_ = rc.SetReadDeadline(time.Now().Add(config.TerminationTimeout))
+		_ = rc.SetWriteDeadline(time.Now().Add(config.TerminationTimeout))
+
+		lines, batch = lines[:0], batch[:0]
+		var readErr error
+		lastLine := 0
+		for len(lines) < config.ImportChunk {
+			line, item, reason, err := next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr = err
+					lastLine = line
+				}
+				done = true
+				break
+			}
+
+			l := importLine{line: line, item: item, res: &models.ResponseShortenAPIBatch{}}
+			if item == nil {
+				l.res.Status, l.res.Error = models.BatchStatusInvalid, reason
+			} else {
+				item.QR = false
+				batch = append(batch, item)
+			}
+			lines = append(lines, l)
+		}
+
+		if len(batch) > 0 {
+			results, err := app.service.SaveBatch(req.Context(), batch)
+			if err != nil {
+				for _, l := range lines {
+					if l.item != nil {
+						l.res.CorrelationID = l.item.CorrelationID
+						l.res.Status, l.res.Error = models.BatchStatusFailed, importReasonSave
+					}
+				}
+				done = true
+			} else {
+				i := 0
+				for _, l := range lines {
+					if l.item == nil {
+						continue
+					}
+					*l.res = *results[i]
+					if l.res.Status == models.BatchStatusCreated {
+						app.fetchPage(l.item.ID, l.item.OriginalURL)
+					}
+					i++
+				}
+			}
+		}
+
+		for _, l := range lines {
+			_ = encoder.Encode(models.ResponseShortenAPIImport{Line: l.line, ResponseShortenAPIBatch: *l.res})
+		}
+		if readErr != nil {
+			_ = encoder.Encode(models.ResponseShortenAPIImport{
+				Line:                    lastLine,
+				ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{Status: models.BatchStatusFailed, Error: importReasonBody},
+			})
+		}
+
+		if err := writer.Flush(); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// newLineScanner создает построчный сканер тела импорта
+// Буфер сканера не растет больше importLineMax, более длинная строка прерывает чтение
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufReaderSize), importLineMax)
+	return scanner
+}
+
+// newNDJSONDecoder создает importDecoder для тела в формате NDJSON, пустые строки пропускаются
+func newNDJSONDecoder(r io.Reader) importDecoder {
+	scanner := newLineScanner(r)
+	line := 0
+
+	return func() (int, *models.RequestShortenAPIBatch, string, error) {
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			item := &models.RequestShortenAPIBatch{}
+			if err := json.Unmarshal(data, item); err != nil {
+				return line, nil, importReasonLine, nil
+			}
+			if item.OriginalURL == "" {
+				return line, nil, customError.ErrURLNotValid.Error(), nil
+			}
+			return line, item, "", nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return line + 1, nil, "", err
+		}
+		return line, nil, "", io.EOF
+	}
+}
+
+// newCSVDecoder создает importDecoder для тела в формате CSV
+// Если первая строка содержит столбец original_url, она считается заголовком и задает порядок столбцов,
+// иначе первый столбец - original_url, второй (необязательный) - correlation_id.
+// Каждая запись занимает одну строку: csv.Reader разбирает строки сканера, поэтому незакрытая кавычка
+// делает невалидной только свою строку, а не накапливает в памяти остаток тела
+func newCSVDecoder(r io.Reader) importDecoder {
+	scanner := newLineScanner(r)
+	src := bytes.NewReader(nil)
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	urlColumn, idColumn := 0, 1
+	header := true
+	line := 0
+
+	return func() (int, *models.RequestShortenAPIBatch, string, error) {
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			src.Reset(data)
+			record, err := reader.Read()
+			if err != nil {
+				header = false
+				return line, nil, importReasonLine, nil
+			}
+
+			if header {
+				header = false
+				if column, ok := csvColumn(record, "original_url"); ok {
+					urlColumn = column
+					idColumn, _ = csvColumn(record, "correlation_id")
+					continue
+				}
+			}
+
+			item := &models.RequestShortenAPIBatch{}
+			if urlColumn < len(record) {
+				item.OriginalURL = strings.TrimSpace(record[urlColumn])
+			}
+			if item.OriginalURL == "" {
+				return line, nil, customError.ErrURLNotValid.Error(), nil
+			}
+			if idColumn >= 0 && idColumn < len(record) && strings.TrimSpace(record[idColumn]) != "" {
+				id, err := uuid.Parse(strings.TrimSpace(record[idColumn]))
+				if err != nil {
+					return line, nil, importReasonCorrelationID, nil
+				}
+				item.CorrelationID = id
+			}
+			return line, item, "", nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return line + 1, nil, "", err
+		}
+		return line, nil, "", io.EOF
+	}
+}
+
+// csvColumn возвращает индекс столбца заголовка CSV с названием name или -1 и false, если его нет
+func csvColumn(record []string, name string) (int, bool) {
+	for i, field := range record {
+		if strings.EqualFold(strings.TrimSpace(field), name) {
+			return i, true
+		}
+	}
+	return -1, false
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service/middleware/compress"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readImportLines читает строки NDJSON ответа импорта
+func readImportLines(t *testing.T, body io.Reader) []models.ResponseShortenAPIImport {
+	t.Helper()
+
+	var lines []models.ResponseShortenAPIImport
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var line models.ResponseShortenAPIImport
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestShortenAPIImport(t *testing.T) {
+	tc := NewSuite(t)
+	correlationID := uuid.MustParse("eefbcef4-3940-5a38-b2f0-877152a6d470")
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []models.ResponseShortenAPIImport
+	}{
+		{
+			name:        "ndjson",
+			contentType: "application/x-ndjson",
+			body: `{"correlation_id":"eefbcef4-3940-5a38-b2f0-877152a6d470","original_url":"https://ya.ru/"}
+
+{"original_url":"https://ya.ru/"}
+{"original_url":
+{"original_url":"https://go.dev/","rules":[{"url":"https://go.dev/"}]}`,
+			want: []models.ResponseShortenAPIImport{
+				{Line: 1, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{CorrelationID: correlationID, ShortURL: tc.app.URL + correlationID.String(), Status: models.BatchStatusCreated}},
+				{Line: 3, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{ShortURL: tc.app.URL + correlationID.String(), Status: models.BatchStatusExisting}},
+				{Line: 4, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{Status: models.BatchStatusInvalid, Error: "line is invalidate"}},
+				{Line: 5, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{Status: models.BatchStatusInvalid, Error: "rules is invalidate"}},
+			},
+		},
+		{
+			name:        "csv with header",
+			contentType: "text/csv; charset=utf-8",
+			body: `correlation_id,original_url
+eefbcef4-3940-5a38-b2f0-877152a6d470,https://ya.ru/
+,"https://go.dev/
+abc,https://go.dev/blog
+,https://go.dev/doc`,
+			want: []models.ResponseShortenAPIImport{
+				{Line: 2, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{CorrelationID: correlationID, ShortURL: tc.app.URL + correlationID.String(), Status: models.BatchStatusExisting}},
+				{Line: 3, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{Status: models.BatchStatusInvalid, Error: "line is invalidate"}},
+				{Line: 4, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{Status: models.BatchStatusInvalid, Error: "correlation_id is invalidate"}},
+				{Line: 5, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{ShortURL: tc.app.URL + uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://go.dev/doc")).String(), Status: models.BatchStatusCreated}},
+			},
+		},
+		{
+			name:        "csv without header",
+			contentType: "text/csv",
+			body:        "https://go.dev/doc\n",
+			want: []models.ResponseShortenAPIImport{
+				{Line: 1, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{ShortURL: tc.app.URL + uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://go.dev/doc")).String(), Status: models.BatchStatusExisting}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/import", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+
+			tc.app.ShortenAPIImport(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.want, readImportLines(t, w.Body))
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/import", strings.NewReader("[]"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	tc.app.ShortenAPIImport(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestShortenAPIImportChunks(t *testing.T) {
+	tc := NewSuite(t)
+
+	// Тело сжато gzip и содержит больше строк, чем помещается в одну часть
+	n := config.ImportChunk*2 + 1
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	for i := 0; i < n; i++ {
+		_, _ = fmt.Fprintf(zw, "{\"original_url\":\"https://go.dev/%d\"}\n", i%(n-1))
+	}
+	require.NoError(t, zw.Close())
+
+	srv := httptest.NewServer(compress.Gzip(http.HandlerFunc(tc.app.ShortenAPIImport)))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	lines := readImportLines(t, resp.Body)
+	require.Len(t, lines, n)
+	for i, line := range lines[:n-1] {
+		assert.Equal(t, i+1, line.Line)
+		assert.Equal(t, models.BatchStatusCreated, line.Status)
+	}
+	assert.Equal(t, models.BatchStatusExisting, lines[n-1].Status)
+	assert.Equal(t, lines[0].ShortURL, lines[n-1].ShortURL)
+
+	// Ошибка чтения тела завершает импорт строкой failed с номером следующей строки
+	req = httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/import", io.MultiReader(strings.NewReader("{\"original_url\":\"https://go.dev/\"}\n"), &failingReader{}))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	tc.app.ShortenAPIImport(w, req)
+
+	lines = readImportLines(t, w.Body)
+	require.Len(t, lines, 2)
+	assert.Equal(t, models.BatchStatusCreated, lines[0].Status)
+	assert.Equal(t, models.ResponseShortenAPIImport{Line: 2, ResponseShortenAPIBatch: models.ResponseShortenAPIBatch{Status: models.BatchStatusFailed, Error: "body is invalidate"}}, lines[1])
+}
+
+// failingReader возвращает ошибку чтения, как оборванное соединение
+type failingReader struct{}
+
+// Read всегда возвращает io.ErrUnexpectedEOF
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
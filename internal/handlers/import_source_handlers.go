@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/importer"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// importSourceMax максимальный размер экспорта другого сервиса, экспорт разбирается целиком в памяти
+const importSourceMax = 32 * 1024 * 1024
+
+// ShortenAPIImportSource переносит ссылки из экспорта другого сервиса сокращения URL
+// @Summary Импорт экспорта Bitly, YOURLS или Kutt
+// @Description Переносит ссылки из CSV или JSON экспорта сервиса source с сохранением коротких имен
+// @Description и времени создания. Импортированная ссылка открывается и по UUID, и по короткому имени
+// @Description (/{slug}, /{slug}+, /{slug}/qr). Каждая ссылка проверяется так же, как в /api/shorten.
+// @Description Формат тела определяется по Content-Type: text/csv со строкой заголовка или application/json.
+// @Description Результат каждой ссылки в поле status: created - ссылка или короткое имя сохранены,
+// @Description existing - уже были импортированы, collision - короткое имя занято другой ссылкой,
+// @Description invalid - URL, короткое имя или атрибуты невалидны, blocked - адрес назначения запрещен,
+// @Description deleted - ссылка на URL удалена пользователем и не восстанавливается, failed - ошибка хранилища.
+// @Description Если сохранены все ссылки, возвращается 201, иначе 207.
+// @Tags URL
+// @Accept text/csv
+// @Accept json
+// @Produce json
+// @Param source path string true "Сервис экспорта: bitly, yourls или kutt"
+// @Param request body string true "Экспорт ссылок"
+// @Success 201 {object} []models.ResponseImportLink
+// @Success 207 {object} []models.ResponseImportLink
+// @Failure 400 {string} string "Неверный сервис, тело запроса или пустой экспорт"
+// @Failure 415 {string} string "Неподдерживаемый Content-Type"
+// @Failure 500 {string} string "Ошибка импорта"
+// @Router /api/shorten/import/{source} [post]
+func (app *App) ShortenAPIImportSource(res http.ResponseWriter, req *http.Request) {
+	var format string
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		format = importer.FormatCSV
+	case "application/json":
+		format = importer.FormatJSON
+	default:
+		res.WriteHeader(http.StatusUnsupportedMediaType)
+		_, _ = res.Write([]byte("Content-Type is invalidate!"))
+		return
+	}
+
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(http.MaxBytesReader(res, req.Body, importSourceMax))
+	defer readerPool.Put(reader)
+
+	links, err := importer.Parse(reader, chi.URLParam(req, "source"), format)
+	if err != nil && errors.Is(err, importer.ErrSourceNotValid) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Source is invalidate!"))
+		return
+	}
+
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Body is invalidate!"))
+		return
+	}
+
+	respDto, err := app.service.ImportLinks(req.Context(), links)
+	if err != nil && errors.Is(err, customError.ErrBatchIsEmpty) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Batch is empty!"))
+		return
+	}
+
+	if err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Import error!"))
+		return
+	}
+
+	status := http.StatusCreated
+	for _, r := range respDto {
+		if r.Status != models.BatchStatusCreated {
+			status = http.StatusMultiStatus
+			continue
+		}
+		app.fetchPage(r.ID, r.OriginalURL)
+	}
+
+	writer := writerPool.Get().(*bufio.Writer)
+	writer.Reset(res)
+	defer func() {
+		writer.Flush()
+		writerPool.Put(writer)
+	}()
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(respDto); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Response is invalidate!"))
+		return
+	}
+}
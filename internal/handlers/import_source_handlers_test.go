@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortenAPIImportSource(t *testing.T) {
+	tc := NewSuite(t)
+
+	importSource := func(source, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/import/"+source, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("source", source)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		tc.app.ShortenAPIImportSource(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) []models.ResponseImportLink {
+		var got []models.ResponseImportLink
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		return got
+	}
+
+	t.Run("bitly csv", func(t *testing.T) {
+		w := importSource("bitly", "text/csv", `Long URL,Link,Title,Created At,Tags
+https://ya.ru/,https://bit.ly/ya,Yandex,2021-03-04T05:06:07+0000,a;b
+`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, []models.ResponseImportLink{
+			{Slug: "ya", OriginalURL: "https://ya.ru/", ShortURL: tc.app.URL + "ya", Status: models.BatchStatusCreated},
+		}, decode(w))
+
+		link, err := tc.app.service.GetLinkByID(context.Background(), uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/")))
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC), link.CreatedAt.UTC())
+		assert.Equal(t, "Yandex", link.Title)
+		assert.Equal(t, []string{"a", "b"}, link.Tags)
+	})
+
+	t.Run("redirect by slug", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, tc.app.URL+"ya", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "ya")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		tc.app.GetURLByID(w, req)
+
+		assert.Equal(t, http.StatusTemporaryRedirect, w.Code)
+		assert.Equal(t, "https://ya.ru/", w.Header().Get("Location"))
+	})
+
+	t.Run("kutt json with collision", func(t *testing.T) {
+		w := importSource("kutt", "application/json", `{"data":[
+{"address":"ya","target":"https://go.dev/","created_at":"2022-01-01T00:00:00Z"},
+{"address":"go","target":"https://go.dev/"},
+{"address":"bad slug","target":"https://go.dev/doc"},
+{"address":"ya","target":"https://ya.ru/"}
+]}`)
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		assert.Equal(t, []models.ResponseImportLink{
+			{Slug: "ya", OriginalURL: "https://go.dev/", Status: models.BatchStatusCollision, Error: "slug is already used"},
+			{Slug: "go", OriginalURL: "https://go.dev/", ShortURL: tc.app.URL + "go", Status: models.BatchStatusCreated},
+			{Slug: "bad slug", OriginalURL: "https://go.dev/doc", Status: models.BatchStatusInvalid, Error: "slug is invalidate"},
+			{Slug: "ya", OriginalURL: "https://ya.ru/", ShortURL: tc.app.URL + "ya", Status: models.BatchStatusExisting},
+		}, decode(w))
+	})
+
+	t.Run("duplicates across chunks", func(t *testing.T) {
+		chunk := config.ImportChunk
+		config.ImportChunk = 2
+		defer func() { config.ImportChunk = chunk }()
+
+		w := importSource("kutt", "application/json", `{"data":[
+{"address":"rust","target":"https://rust-lang.org/"},
+{"address":"rust","target":"https://rust-lang.org/"},
+{"address":"rust","target":"https://crates.io/"},
+{"address":"crates","target":"https://crates.io/"}
+]}`)
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		assert.Equal(t, []models.ResponseImportLink{
+			{Slug: "rust", OriginalURL: "https://rust-lang.org/", ShortURL: tc.app.URL + "rust", Status: models.BatchStatusCreated},
+			{Slug: "rust", OriginalURL: "https://rust-lang.org/", ShortURL: tc.app.URL + "rust", Status: models.BatchStatusExisting},
+			{Slug: "rust", OriginalURL: "https://crates.io/", Status: models.BatchStatusCollision, Error: "slug is already used"},
+			{Slug: "crates", OriginalURL: "https://crates.io/", ShortURL: tc.app.URL + "crates", Status: models.BatchStatusCreated},
+		}, decode(w))
+
+		id, err := tc.app.service.ResolveID(context.Background(), "crates")
+		require.NoError(t, err)
+		link, err := tc.app.service.GetLinkByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, "https://crates.io/", link.OriginalURL)
+	})
+
+	t.Run("deleted link", func(t *testing.T) {
+		userID := uuid.New()
+		ctx := customContext.SetContextUserID(context.Background(), userID)
+		id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://example.com/"))
+		_, err := tc.app.service.Repository.SaveLink(ctx, nil, &models.Link{ID: id, UserID: &userID, OriginalURL: "https://example.com/"})
+		require.NoError(t, err)
+		_, err = tc.app.service.DeleteBatchByUserID(ctx, []uuid.UUID{id})
+		require.NoError(t, err)
+
+		w := importSource("bitly", "text/csv", `Long URL,Link,Title,Created At,Tags
+https://example.com/,https://bit.ly/ex,,,
+`)
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		assert.Equal(t, []models.ResponseImportLink{
+			{Slug: "ex", OriginalURL: "https://example.com/", Status: models.BatchStatusDeleted, Error: "url is deleted"},
+		}, decode(w))
+
+		_, err = tc.app.service.GetLinkByID(context.Background(), id)
+		assert.ErrorIs(t, err, customError.ErrDeleteAccepted)
+		_, err = tc.app.service.ResolveID(context.Background(), "ex")
+		assert.ErrorIs(t, err, customError.ErrNotFound)
+	})
+
+	t.Run("source is invalidate", func(t *testing.T) {
+		w := importSource("tinyurl", "text/csv", "url\nhttps://ya.ru/\n")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "Source is invalidate!", w.Body.String())
+	})
+
+	t.Run("body is invalidate", func(t *testing.T) {
+		w := importSource("yourls", "application/json", `{"links":`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "Body is invalidate!", w.Body.String())
+	})
+
+	t.Run("content type is invalidate", func(t *testing.T) {
+		w := importSource("yourls", "application/xml", "<links/>")
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/service/utils.go
+// Source: internal/service/types.go
 
 // Package mock is a generated GoMock package.
 package mock
@@ -8,6 +8,7 @@ import (
 	context "context"
 	url "net/url"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/IvanKondrashkov/go-shortener/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -15,6 +16,43 @@ import (
 	pgx "github.com/jackc/pgx/v5"
 )
 
+// MockStatsRepository is a mock of StatsRepository interface.
+type MockStatsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsRepositoryMockRecorder
+}
+
+// MockStatsRepositoryMockRecorder is the mock recorder for MockStatsRepository.
+type MockStatsRepositoryMockRecorder struct {
+	mock *MockStatsRepository
+}
+
+// NewMockStatsRepository creates a new mock instance.
+func NewMockStatsRepository(ctrl *gomock.Controller) *MockStatsRepository {
+	mock := &MockStatsRepository{ctrl: ctrl}
+	mock.recorder = &MockStatsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsRepository) EXPECT() *MockStatsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Stats mocks base method.
+func (m *MockStatsRepository) Stats() models.CacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(models.CacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStatsRepositoryMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStatsRepository)(nil).Stats))
+}
+
 // MockRunner is a mock of Runner interface.
 type MockRunner struct {
 	ctrl     *gomock.Controller
@@ -77,46 +115,121 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 }
 
 // DeleteBatchByUserID mocks base method.
-func (m *MockUserRepository) DeleteBatchByUserID(ctx context.Context, userID uuid.UUID, batch []uuid.UUID) error {
+func (m *MockUserRepository) DeleteBatchByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBatchByUserID", ctx, tx, userID, batch)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBatchByUserID indicates an expected call of DeleteBatchByUserID.
+func (mr *MockUserRepositoryMockRecorder) DeleteBatchByUserID(ctx, tx, userID, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatchByUserID", reflect.TypeOf((*MockUserRepository)(nil).DeleteBatchByUserID), ctx, tx, userID, batch)
+}
+
+// ExportByUserID mocks base method.
+func (m *MockUserRepository) ExportByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBatchByUserID", ctx, userID, batch)
+	ret := m.ctrl.Call(m, "ExportByUserID", ctx, userID, filter, fn)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteBatchByUserID indicates an expected call of DeleteBatchByUserID.
-func (mr *MockUserRepositoryMockRecorder) DeleteBatchByUserID(ctx, userID, batch interface{}) *gomock.Call {
+// ExportByUserID indicates an expected call of ExportByUserID.
+func (mr *MockUserRepositoryMockRecorder) ExportByUserID(ctx, userID, filter, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatchByUserID", reflect.TypeOf((*MockUserRepository)(nil).DeleteBatchByUserID), ctx, userID, batch)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportByUserID", reflect.TypeOf((*MockUserRepository)(nil).ExportByUserID), ctx, userID, filter, fn)
 }
 
 // GetAllByUserID mocks base method.
-func (m *MockUserRepository) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.ResponseShortenAPIUser, error) {
+func (m *MockUserRepository) GetAllByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs) ([]*models.ResponseShortenAPIUser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByUserID", ctx, userID)
+	ret := m.ctrl.Call(m, "GetAllByUserID", ctx, userID, filter)
 	ret0, _ := ret[0].([]*models.ResponseShortenAPIUser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllByUserID indicates an expected call of GetAllByUserID.
-func (mr *MockUserRepositoryMockRecorder) GetAllByUserID(ctx, userID interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) GetAllByUserID(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockUserRepository)(nil).GetAllByUserID), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockUserRepository)(nil).GetAllByUserID), ctx, userID, filter)
 }
 
-// SaveBatchUser mocks base method.
-func (m *MockUserRepository) SaveBatchUser(ctx context.Context, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) error {
+// GetHistoryByUserID mocks base method.
+func (m *MockUserRepository) GetHistoryByUserID(ctx context.Context, userID, id uuid.UUID) ([]*models.URLHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoryByUserID", ctx, userID, id)
+	ret0, _ := ret[0].([]*models.URLHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistoryByUserID indicates an expected call of GetHistoryByUserID.
+func (mr *MockUserRepositoryMockRecorder) GetHistoryByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoryByUserID", reflect.TypeOf((*MockUserRepository)(nil).GetHistoryByUserID), ctx, userID, id)
+}
+
+// GetIDBySlug mocks base method.
+func (m *MockUserRepository) GetIDBySlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIDBySlug", ctx, slug)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIDBySlug indicates an expected call of GetIDBySlug.
+func (mr *MockUserRepositoryMockRecorder) GetIDBySlug(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDBySlug", reflect.TypeOf((*MockUserRepository)(nil).GetIDBySlug), ctx, slug)
+}
+
+// GetIDsBySlugs mocks base method.
+func (m *MockUserRepository) GetIDsBySlugs(ctx context.Context, slugs []string) (map[string]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIDsBySlugs", ctx, slugs)
+	ret0, _ := ret[0].(map[string]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIDsBySlugs indicates an expected call of GetIDsBySlugs.
+func (mr *MockUserRepositoryMockRecorder) GetIDsBySlugs(ctx, slugs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDsBySlugs", reflect.TypeOf((*MockUserRepository)(nil).GetIDsBySlugs), ctx, slugs)
+}
+
+// SaveAlias mocks base method.
+func (m *MockUserRepository) SaveAlias(ctx context.Context, tx pgx.Tx, alias *models.Alias) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveBatchUser", ctx, userID, batch)
+	ret := m.ctrl.Call(m, "SaveAlias", ctx, tx, alias)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// SaveAlias indicates an expected call of SaveAlias.
+func (mr *MockUserRepositoryMockRecorder) SaveAlias(ctx, tx, alias interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAlias", reflect.TypeOf((*MockUserRepository)(nil).SaveAlias), ctx, tx, alias)
+}
+
+// SaveBatchUser mocks base method.
+func (m *MockUserRepository) SaveBatchUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatchUser", ctx, tx, userID, batch)
+	ret0, _ := ret[0].([]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // SaveBatchUser indicates an expected call of SaveBatchUser.
-func (mr *MockUserRepositoryMockRecorder) SaveBatchUser(ctx, userID, batch interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) SaveBatchUser(ctx, tx, userID, batch interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatchUser", reflect.TypeOf((*MockUserRepository)(nil).SaveBatchUser), ctx, userID, batch)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatchUser", reflect.TypeOf((*MockUserRepository)(nil).SaveBatchUser), ctx, tx, userID, batch)
 }
 
 // SaveUser mocks base method.
@@ -134,182 +247,1165 @@ func (mr *MockUserRepositoryMockRecorder) SaveUser(ctx, tx, userID, id, url inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockUserRepository)(nil).SaveUser), ctx, tx, userID, id, url)
 }
 
-// MockRepository is a mock of Repository interface.
-type MockRepository struct {
+// UpdateByUserID mocks base method.
+func (m *MockUserRepository) UpdateByUserID(ctx context.Context, tx pgx.Tx, change *models.URLHistory) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByUserID", ctx, tx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateByUserID indicates an expected call of UpdateByUserID.
+func (mr *MockUserRepositoryMockRecorder) UpdateByUserID(ctx, tx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByUserID", reflect.TypeOf((*MockUserRepository)(nil).UpdateByUserID), ctx, tx, change)
+}
+
+// MockFolderRepository is a mock of FolderRepository interface.
+type MockFolderRepository struct {
 	ctrl     *gomock.Controller
-	recorder *MockRepositoryMockRecorder
+	recorder *MockFolderRepositoryMockRecorder
 }
 
-// MockRepositoryMockRecorder is the mock recorder for MockRepository.
-type MockRepositoryMockRecorder struct {
-	mock *MockRepository
+// MockFolderRepositoryMockRecorder is the mock recorder for MockFolderRepository.
+type MockFolderRepositoryMockRecorder struct {
+	mock *MockFolderRepository
 }
 
-// NewMockRepository creates a new mock instance.
-func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
-	mock := &MockRepository{ctrl: ctrl}
-	mock.recorder = &MockRepositoryMockRecorder{mock}
+// NewMockFolderRepository creates a new mock instance.
+func NewMockFolderRepository(ctrl *gomock.Controller) *MockFolderRepository {
+	mock := &MockFolderRepository{ctrl: ctrl}
+	mock.recorder = &MockFolderRepositoryMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+func (m *MockFolderRepository) EXPECT() *MockFolderRepositoryMockRecorder {
 	return m.recorder
 }
 
-// BeginTx mocks base method.
-func (m *MockRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
+// DeleteFolderByUserID mocks base method.
+func (m *MockFolderRepository) DeleteFolderByUserID(ctx context.Context, userID, id uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BeginTx", ctx)
-	ret0, _ := ret[0].(pgx.Tx)
+	ret := m.ctrl.Call(m, "DeleteFolderByUserID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFolderByUserID indicates an expected call of DeleteFolderByUserID.
+func (mr *MockFolderRepositoryMockRecorder) DeleteFolderByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFolderByUserID", reflect.TypeOf((*MockFolderRepository)(nil).DeleteFolderByUserID), ctx, userID, id)
+}
+
+// GetFolderByUserID mocks base method.
+func (m *MockFolderRepository) GetFolderByUserID(ctx context.Context, userID, id uuid.UUID) (*models.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFolderByUserID", ctx, userID, id)
+	ret0, _ := ret[0].(*models.Folder)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BeginTx indicates an expected call of BeginTx.
-func (mr *MockRepositoryMockRecorder) BeginTx(ctx interface{}) *gomock.Call {
+// GetFolderByUserID indicates an expected call of GetFolderByUserID.
+func (mr *MockFolderRepositoryMockRecorder) GetFolderByUserID(ctx, userID, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockRepository)(nil).BeginTx), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFolderByUserID", reflect.TypeOf((*MockFolderRepository)(nil).GetFolderByUserID), ctx, userID, id)
 }
 
-// Close mocks base method.
-func (m *MockRepository) Close() {
+// GetFoldersByUserID mocks base method.
+func (m *MockFolderRepository) GetFoldersByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Folder, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Close")
+	ret := m.ctrl.Call(m, "GetFoldersByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Close indicates an expected call of Close.
-func (mr *MockRepositoryMockRecorder) Close() *gomock.Call {
+// GetFoldersByUserID indicates an expected call of GetFoldersByUserID.
+func (mr *MockFolderRepositoryMockRecorder) GetFoldersByUserID(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRepository)(nil).Close))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoldersByUserID", reflect.TypeOf((*MockFolderRepository)(nil).GetFoldersByUserID), ctx, userID)
 }
 
-// DeleteBatchByUserID mocks base method.
-func (m *MockRepository) DeleteBatchByUserID(ctx context.Context, userID uuid.UUID, batch []uuid.UUID) error {
+// SaveFolder mocks base method.
+func (m *MockFolderRepository) SaveFolder(ctx context.Context, folder *models.Folder) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBatchByUserID", ctx, userID, batch)
+	ret := m.ctrl.Call(m, "SaveFolder", ctx, folder)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteBatchByUserID indicates an expected call of DeleteBatchByUserID.
-func (mr *MockRepositoryMockRecorder) DeleteBatchByUserID(ctx, userID, batch interface{}) *gomock.Call {
+// SaveFolder indicates an expected call of SaveFolder.
+func (mr *MockFolderRepositoryMockRecorder) SaveFolder(ctx, folder interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatchByUserID", reflect.TypeOf((*MockRepository)(nil).DeleteBatchByUserID), ctx, userID, batch)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFolder", reflect.TypeOf((*MockFolderRepository)(nil).SaveFolder), ctx, folder)
 }
 
-// GetAllByUserID mocks base method.
-func (m *MockRepository) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.ResponseShortenAPIUser, error) {
+// UpdateFolderByUserID mocks base method.
+func (m *MockFolderRepository) UpdateFolderByUserID(ctx context.Context, folder *models.Folder) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByUserID", ctx, userID)
-	ret0, _ := ret[0].([]*models.ResponseShortenAPIUser)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "UpdateFolderByUserID", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetAllByUserID indicates an expected call of GetAllByUserID.
-func (mr *MockRepositoryMockRecorder) GetAllByUserID(ctx, userID interface{}) *gomock.Call {
+// UpdateFolderByUserID indicates an expected call of UpdateFolderByUserID.
+func (mr *MockFolderRepositoryMockRecorder) UpdateFolderByUserID(ctx, folder interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockRepository)(nil).GetAllByUserID), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFolderByUserID", reflect.TypeOf((*MockFolderRepository)(nil).UpdateFolderByUserID), ctx, folder)
 }
 
-// GetByID mocks base method.
-func (m *MockRepository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error) {
+// MockTemplateRepository is a mock of TemplateRepository interface.
+type MockTemplateRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateRepositoryMockRecorder
+}
+
+// MockTemplateRepositoryMockRecorder is the mock recorder for MockTemplateRepository.
+type MockTemplateRepositoryMockRecorder struct {
+	mock *MockTemplateRepository
+}
+
+// NewMockTemplateRepository creates a new mock instance.
+func NewMockTemplateRepository(ctrl *gomock.Controller) *MockTemplateRepository {
+	mock := &MockTemplateRepository{ctrl: ctrl}
+	mock.recorder = &MockTemplateRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTemplateRepository) EXPECT() *MockTemplateRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteTemplateByUserID mocks base method.
+func (m *MockTemplateRepository) DeleteTemplateByUserID(ctx context.Context, userID, id uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", ctx, id)
-	ret0, _ := ret[0].(*url.URL)
+	ret := m.ctrl.Call(m, "DeleteTemplateByUserID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplateByUserID indicates an expected call of DeleteTemplateByUserID.
+func (mr *MockTemplateRepositoryMockRecorder) DeleteTemplateByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplateByUserID", reflect.TypeOf((*MockTemplateRepository)(nil).DeleteTemplateByUserID), ctx, userID, id)
+}
+
+// GetTemplatesByUserID mocks base method.
+func (m *MockTemplateRepository) GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UTMTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatesByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.UTMTemplate)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByID indicates an expected call of GetByID.
-func (mr *MockRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+// GetTemplatesByUserID indicates an expected call of GetTemplatesByUserID.
+func (mr *MockTemplateRepositoryMockRecorder) GetTemplatesByUserID(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatesByUserID", reflect.TypeOf((*MockTemplateRepository)(nil).GetTemplatesByUserID), ctx, userID)
 }
 
-// Load mocks base method.
-func (m *MockRepository) Load(ctx context.Context) error {
+// SaveTemplate mocks base method.
+func (m *MockTemplateRepository) SaveTemplate(ctx context.Context, template *models.UTMTemplate) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Load", ctx)
+	ret := m.ctrl.Call(m, "SaveTemplate", ctx, template)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Load indicates an expected call of Load.
-func (mr *MockRepositoryMockRecorder) Load(ctx interface{}) *gomock.Call {
+// SaveTemplate indicates an expected call of SaveTemplate.
+func (mr *MockTemplateRepositoryMockRecorder) SaveTemplate(ctx, template interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockRepository)(nil).Load), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTemplate", reflect.TypeOf((*MockTemplateRepository)(nil).SaveTemplate), ctx, template)
 }
 
-// Ping mocks base method.
-func (m *MockRepository) Ping(ctx context.Context) error {
+// UpdateTemplateByUserID mocks base method.
+func (m *MockTemplateRepository) UpdateTemplateByUserID(ctx context.Context, template *models.UTMTemplate) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret := m.ctrl.Call(m, "UpdateTemplateByUserID", ctx, template)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Ping indicates an expected call of Ping.
-func (mr *MockRepositoryMockRecorder) Ping(ctx interface{}) *gomock.Call {
+// UpdateTemplateByUserID indicates an expected call of UpdateTemplateByUserID.
+func (mr *MockTemplateRepositoryMockRecorder) UpdateTemplateByUserID(ctx, template interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateByUserID", reflect.TypeOf((*MockTemplateRepository)(nil).UpdateTemplateByUserID), ctx, template)
 }
 
-// Save mocks base method.
-func (m *MockRepository) Save(ctx context.Context, tx pgx.Tx, id uuid.UUID, url *url.URL) (uuid.UUID, error) {
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteWebhookByUserID mocks base method.
+func (m *MockWebhookRepository) DeleteWebhookByUserID(ctx context.Context, userID, id uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, tx, id, url)
-	ret0, _ := ret[0].(uuid.UUID)
+	ret := m.ctrl.Call(m, "DeleteWebhookByUserID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookByUserID indicates an expected call of DeleteWebhookByUserID.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteWebhookByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookByUserID", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteWebhookByUserID), ctx, userID, id)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) GetWebhookDeliveries(ctx context.Context, userID, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, userID, id, limit)
+	ret0, _ := ret[0].([]*models.WebhookDelivery)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Save indicates an expected call of Save.
-func (mr *MockRepositoryMockRecorder) Save(ctx, tx, id, url interface{}) *gomock.Call {
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookDeliveries(ctx, userID, id, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepository)(nil).Save), ctx, tx, id, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookDeliveries), ctx, userID, id, limit)
 }
 
-// SaveBatch mocks base method.
-func (m *MockRepository) SaveBatch(ctx context.Context, batch []*models.RequestShortenAPIBatch) error {
+// GetWebhooksByUserID mocks base method.
+func (m *MockWebhookRepository) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooksByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooksByUserID indicates an expected call of GetWebhooksByUserID.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhooksByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooksByUserID", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhooksByUserID), ctx, userID)
+}
+
+// SaveWebhook mocks base method.
+func (m *MockWebhookRepository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveBatch", ctx, batch)
+	ret := m.ctrl.Call(m, "SaveWebhook", ctx, webhook)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SaveBatch indicates an expected call of SaveBatch.
-func (mr *MockRepositoryMockRecorder) SaveBatch(ctx, batch interface{}) *gomock.Call {
+// SaveWebhook indicates an expected call of SaveWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhook(ctx, webhook interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockRepository)(nil).SaveBatch), ctx, batch)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhook), ctx, webhook)
 }
 
-// SaveBatchUser mocks base method.
-func (m *MockRepository) SaveBatchUser(ctx context.Context, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) error {
+// SaveWebhookDelivery mocks base method.
+func (m *MockWebhookRepository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveBatchUser", ctx, userID, batch)
+	ret := m.ctrl.Call(m, "SaveWebhookDelivery", ctx, delivery)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SaveBatchUser indicates an expected call of SaveBatchUser.
-func (mr *MockRepositoryMockRecorder) SaveBatchUser(ctx, userID, batch interface{}) *gomock.Call {
+// SaveWebhookDelivery indicates an expected call of SaveWebhookDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhookDelivery(ctx, delivery interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatchUser", reflect.TypeOf((*MockRepository)(nil).SaveBatchUser), ctx, userID, batch)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhookDelivery), ctx, delivery)
 }
 
-// SaveUser mocks base method.
-func (m *MockRepository) SaveUser(ctx context.Context, tx pgx.Tx, userID, id uuid.UUID, url *url.URL) (uuid.UUID, error) {
+// UpdateWebhookByUserID mocks base method.
+func (m *MockWebhookRepository) UpdateWebhookByUserID(ctx context.Context, webhook *models.Webhook) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveUser", ctx, tx, userID, id, url)
-	ret0, _ := ret[0].(uuid.UUID)
+	ret := m.ctrl.Call(m, "UpdateWebhookByUserID", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookByUserID indicates an expected call of UpdateWebhookByUserID.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateWebhookByUserID(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookByUserID", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateWebhookByUserID), ctx, webhook)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteOutbox mocks base method.
+func (m *MockOutboxRepository) DeleteOutbox(ctx context.Context, ids []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutbox", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutbox indicates an expected call of DeleteOutbox.
+func (mr *MockOutboxRepositoryMockRecorder) DeleteOutbox(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutbox", reflect.TypeOf((*MockOutboxRepository)(nil).DeleteOutbox), ctx, ids)
+}
+
+// GetOutbox mocks base method.
+func (m *MockOutboxRepository) GetOutbox(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutbox", ctx, limit)
+	ret0, _ := ret[0].([]*models.OutboxEvent)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SaveUser indicates an expected call of SaveUser.
-func (mr *MockRepositoryMockRecorder) SaveUser(ctx, tx, userID, id, url interface{}) *gomock.Call {
+// GetOutbox indicates an expected call of GetOutbox.
+func (mr *MockOutboxRepositoryMockRecorder) GetOutbox(ctx, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockRepository)(nil).SaveUser), ctx, tx, userID, id, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutbox", reflect.TypeOf((*MockOutboxRepository)(nil).GetOutbox), ctx, limit)
+}
+
+// SaveOutbox mocks base method.
+func (m *MockOutboxRepository) SaveOutbox(ctx context.Context, tx pgx.Tx, events []*models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOutbox", ctx, tx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOutbox indicates an expected call of SaveOutbox.
+func (mr *MockOutboxRepositoryMockRecorder) SaveOutbox(ctx, tx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOutbox", reflect.TypeOf((*MockOutboxRepository)(nil).SaveOutbox), ctx, tx, events)
+}
+
+// MockIdempotencyRepository is a mock of IdempotencyRepository interface.
+type MockIdempotencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyRepositoryMockRecorder
+}
+
+// MockIdempotencyRepositoryMockRecorder is the mock recorder for MockIdempotencyRepository.
+type MockIdempotencyRepositoryMockRecorder struct {
+	mock *MockIdempotencyRepository
+}
+
+// NewMockIdempotencyRepository creates a new mock instance.
+func NewMockIdempotencyRepository(ctrl *gomock.Controller) *MockIdempotencyRepository {
+	mock := &MockIdempotencyRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyRepository) EXPECT() *MockIdempotencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CompleteIdempotency mocks base method.
+func (m *MockIdempotencyRepository) CompleteIdempotency(ctx context.Context, record *models.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotency", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotency indicates an expected call of CompleteIdempotency.
+func (mr *MockIdempotencyRepositoryMockRecorder) CompleteIdempotency(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotency", reflect.TypeOf((*MockIdempotencyRepository)(nil).CompleteIdempotency), ctx, record)
+}
+
+// DeleteExpiredIdempotency mocks base method.
+func (m *MockIdempotencyRepository) DeleteExpiredIdempotency(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotency", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotency indicates an expected call of DeleteExpiredIdempotency.
+func (mr *MockIdempotencyRepositoryMockRecorder) DeleteExpiredIdempotency(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotency", reflect.TypeOf((*MockIdempotencyRepository)(nil).DeleteExpiredIdempotency), ctx)
+}
+
+// DeleteIdempotency mocks base method.
+func (m *MockIdempotencyRepository) DeleteIdempotency(ctx context.Context, userID uuid.UUID, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotency", ctx, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotency indicates an expected call of DeleteIdempotency.
+func (mr *MockIdempotencyRepositoryMockRecorder) DeleteIdempotency(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotency", reflect.TypeOf((*MockIdempotencyRepository)(nil).DeleteIdempotency), ctx, userID, key)
+}
+
+// ReserveIdempotency mocks base method.
+func (m *MockIdempotencyRepository) ReserveIdempotency(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIdempotency", ctx, record)
+	ret0, _ := ret[0].(*models.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveIdempotency indicates an expected call of ReserveIdempotency.
+func (mr *MockIdempotencyRepositoryMockRecorder) ReserveIdempotency(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotency", reflect.TypeOf((*MockIdempotencyRepository)(nil).ReserveIdempotency), ctx, record)
+}
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddClick mocks base method.
+func (m *MockRepository) AddClick(ctx context.Context, id uuid.UUID, variant string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddClick", ctx, id, variant)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddClick indicates an expected call of AddClick.
+func (mr *MockRepositoryMockRecorder) AddClick(ctx, id, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClick", reflect.TypeOf((*MockRepository)(nil).AddClick), ctx, id, variant)
+}
+
+// BeginTx mocks base method.
+func (m *MockRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginTx", ctx)
+	ret0, _ := ret[0].(pgx.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginTx indicates an expected call of BeginTx.
+func (mr *MockRepositoryMockRecorder) BeginTx(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockRepository)(nil).BeginTx), ctx)
+}
+
+// Close mocks base method.
+func (m *MockRepository) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockRepositoryMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRepository)(nil).Close))
+}
+
+// CompleteIdempotency mocks base method.
+func (m *MockRepository) CompleteIdempotency(ctx context.Context, record *models.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotency", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotency indicates an expected call of CompleteIdempotency.
+func (mr *MockRepositoryMockRecorder) CompleteIdempotency(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotency", reflect.TypeOf((*MockRepository)(nil).CompleteIdempotency), ctx, record)
+}
+
+// DeleteBatchByUserID mocks base method.
+func (m *MockRepository) DeleteBatchByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBatchByUserID", ctx, tx, userID, batch)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBatchByUserID indicates an expected call of DeleteBatchByUserID.
+func (mr *MockRepositoryMockRecorder) DeleteBatchByUserID(ctx, tx, userID, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatchByUserID", reflect.TypeOf((*MockRepository)(nil).DeleteBatchByUserID), ctx, tx, userID, batch)
+}
+
+// DeleteExpiredIdempotency mocks base method.
+func (m *MockRepository) DeleteExpiredIdempotency(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotency", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotency indicates an expected call of DeleteExpiredIdempotency.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredIdempotency(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotency", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredIdempotency), ctx)
+}
+
+// DeleteFolderByUserID mocks base method.
+func (m *MockRepository) DeleteFolderByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFolderByUserID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFolderByUserID indicates an expected call of DeleteFolderByUserID.
+func (mr *MockRepositoryMockRecorder) DeleteFolderByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFolderByUserID", reflect.TypeOf((*MockRepository)(nil).DeleteFolderByUserID), ctx, userID, id)
+}
+
+// DeleteIdempotency mocks base method.
+func (m *MockRepository) DeleteIdempotency(ctx context.Context, userID uuid.UUID, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotency", ctx, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotency indicates an expected call of DeleteIdempotency.
+func (mr *MockRepositoryMockRecorder) DeleteIdempotency(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotency", reflect.TypeOf((*MockRepository)(nil).DeleteIdempotency), ctx, userID, key)
+}
+
+// DeleteOutbox mocks base method.
+func (m *MockRepository) DeleteOutbox(ctx context.Context, ids []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutbox", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutbox indicates an expected call of DeleteOutbox.
+func (mr *MockRepositoryMockRecorder) DeleteOutbox(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutbox", reflect.TypeOf((*MockRepository)(nil).DeleteOutbox), ctx, ids)
+}
+
+// DeleteTemplateByUserID mocks base method.
+func (m *MockRepository) DeleteTemplateByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplateByUserID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplateByUserID indicates an expected call of DeleteTemplateByUserID.
+func (mr *MockRepositoryMockRecorder) DeleteTemplateByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplateByUserID", reflect.TypeOf((*MockRepository)(nil).DeleteTemplateByUserID), ctx, userID, id)
+}
+
+// DeleteWebhookByUserID mocks base method.
+func (m *MockRepository) DeleteWebhookByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookByUserID", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookByUserID indicates an expected call of DeleteWebhookByUserID.
+func (mr *MockRepositoryMockRecorder) DeleteWebhookByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookByUserID", reflect.TypeOf((*MockRepository)(nil).DeleteWebhookByUserID), ctx, userID, id)
+}
+
+// DisableByID mocks base method.
+func (m *MockRepository) DisableByID(ctx context.Context, id uuid.UUID, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableByID", ctx, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableByID indicates an expected call of DisableByID.
+func (mr *MockRepositoryMockRecorder) DisableByID(ctx, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableByID", reflect.TypeOf((*MockRepository)(nil).DisableByID), ctx, id, reason)
+}
+
+// ExportByUserID mocks base method.
+func (m *MockRepository) ExportByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportByUserID", ctx, userID, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportByUserID indicates an expected call of ExportByUserID.
+func (mr *MockRepositoryMockRecorder) ExportByUserID(ctx, userID, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportByUserID", reflect.TypeOf((*MockRepository)(nil).ExportByUserID), ctx, userID, filter, fn)
+}
+
+// GetAllByUserID mocks base method.
+func (m *MockRepository) GetAllByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs) ([]*models.ResponseShortenAPIUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUserID", ctx, userID, filter)
+	ret0, _ := ret[0].([]*models.ResponseShortenAPIUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUserID indicates an expected call of GetAllByUserID.
+func (mr *MockRepositoryMockRecorder) GetAllByUserID(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockRepository)(nil).GetAllByUserID), ctx, userID, filter)
+}
+
+// GetByID mocks base method.
+func (m *MockRepository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*url.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, id)
+}
+
+// GetFolderByUserID mocks base method.
+func (m *MockRepository) GetFolderByUserID(ctx context.Context, userID, id uuid.UUID) (*models.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFolderByUserID", ctx, userID, id)
+	ret0, _ := ret[0].(*models.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFolderByUserID indicates an expected call of GetFolderByUserID.
+func (mr *MockRepositoryMockRecorder) GetFolderByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFolderByUserID", reflect.TypeOf((*MockRepository)(nil).GetFolderByUserID), ctx, userID, id)
+}
+
+// GetFoldersByUserID mocks base method.
+func (m *MockRepository) GetFoldersByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFoldersByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFoldersByUserID indicates an expected call of GetFoldersByUserID.
+func (mr *MockRepositoryMockRecorder) GetFoldersByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFoldersByUserID", reflect.TypeOf((*MockRepository)(nil).GetFoldersByUserID), ctx, userID)
+}
+
+// GetHistoryByUserID mocks base method.
+func (m *MockRepository) GetHistoryByUserID(ctx context.Context, userID, id uuid.UUID) ([]*models.URLHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoryByUserID", ctx, userID, id)
+	ret0, _ := ret[0].([]*models.URLHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistoryByUserID indicates an expected call of GetHistoryByUserID.
+func (mr *MockRepositoryMockRecorder) GetHistoryByUserID(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoryByUserID", reflect.TypeOf((*MockRepository)(nil).GetHistoryByUserID), ctx, userID, id)
+}
+
+// GetIDBySlug mocks base method.
+func (m *MockRepository) GetIDBySlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIDBySlug", ctx, slug)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIDBySlug indicates an expected call of GetIDBySlug.
+func (mr *MockRepositoryMockRecorder) GetIDBySlug(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDBySlug", reflect.TypeOf((*MockRepository)(nil).GetIDBySlug), ctx, slug)
+}
+
+// GetIDsBySlugs mocks base method.
+func (m *MockRepository) GetIDsBySlugs(ctx context.Context, slugs []string) (map[string]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIDsBySlugs", ctx, slugs)
+	ret0, _ := ret[0].(map[string]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIDsBySlugs indicates an expected call of GetIDsBySlugs.
+func (mr *MockRepositoryMockRecorder) GetIDsBySlugs(ctx, slugs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDsBySlugs", reflect.TypeOf((*MockRepository)(nil).GetIDsBySlugs), ctx, slugs)
+}
+
+// GetLinkByID mocks base method.
+func (m *MockRepository) GetLinkByID(ctx context.Context, id uuid.UUID) (*models.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinkByID", ctx, id)
+	ret0, _ := ret[0].(*models.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinkByID indicates an expected call of GetLinkByID.
+func (mr *MockRepositoryMockRecorder) GetLinkByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkByID", reflect.TypeOf((*MockRepository)(nil).GetLinkByID), ctx, id)
+}
+
+// GetLinksByIDs mocks base method.
+func (m *MockRepository) GetLinksByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinksByIDs", ctx, ids)
+	ret0, _ := ret[0].(map[uuid.UUID]*models.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinksByIDs indicates an expected call of GetLinksByIDs.
+func (mr *MockRepositoryMockRecorder) GetLinksByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinksByIDs", reflect.TypeOf((*MockRepository)(nil).GetLinksByIDs), ctx, ids)
+}
+
+// GetLinksToCheck mocks base method.
+func (m *MockRepository) GetLinksToCheck(ctx context.Context, before time.Time, limit int) ([]*models.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinksToCheck", ctx, before, limit)
+	ret0, _ := ret[0].([]*models.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinksToCheck indicates an expected call of GetLinksToCheck.
+func (mr *MockRepositoryMockRecorder) GetLinksToCheck(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinksToCheck", reflect.TypeOf((*MockRepository)(nil).GetLinksToCheck), ctx, before, limit)
+}
+
+// GetOutbox mocks base method.
+func (m *MockRepository) GetOutbox(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutbox", ctx, limit)
+	ret0, _ := ret[0].([]*models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutbox indicates an expected call of GetOutbox.
+func (mr *MockRepositoryMockRecorder) GetOutbox(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutbox", reflect.TypeOf((*MockRepository)(nil).GetOutbox), ctx, limit)
+}
+
+// GetTemplatesByUserID mocks base method.
+func (m *MockRepository) GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UTMTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatesByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.UTMTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatesByUserID indicates an expected call of GetTemplatesByUserID.
+func (mr *MockRepositoryMockRecorder) GetTemplatesByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatesByUserID", reflect.TypeOf((*MockRepository)(nil).GetTemplatesByUserID), ctx, userID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockRepository) GetWebhookDeliveries(ctx context.Context, userID, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, userID, id, limit)
+	ret0, _ := ret[0].([]*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) GetWebhookDeliveries(ctx, userID, id, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).GetWebhookDeliveries), ctx, userID, id, limit)
+}
+
+// GetWebhooksByUserID mocks base method.
+func (m *MockRepository) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooksByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooksByUserID indicates an expected call of GetWebhooksByUserID.
+func (mr *MockRepositoryMockRecorder) GetWebhooksByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooksByUserID", reflect.TypeOf((*MockRepository)(nil).GetWebhooksByUserID), ctx, userID)
+}
+
+// Load mocks base method.
+func (m *MockRepository) Load(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockRepositoryMockRecorder) Load(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockRepository)(nil).Load), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockRepository) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockRepositoryMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), ctx)
+}
+
+// ReserveIdempotency mocks base method.
+func (m *MockRepository) ReserveIdempotency(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIdempotency", ctx, record)
+	ret0, _ := ret[0].(*models.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveIdempotency indicates an expected call of ReserveIdempotency.
+func (mr *MockRepositoryMockRecorder) ReserveIdempotency(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotency", reflect.TypeOf((*MockRepository)(nil).ReserveIdempotency), ctx, record)
+}
+
+// Save mocks base method.
+func (m *MockRepository) Save(ctx context.Context, tx pgx.Tx, id uuid.UUID, url *url.URL) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, tx, id, url)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockRepositoryMockRecorder) Save(ctx, tx, id, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepository)(nil).Save), ctx, tx, id, url)
+}
+
+// SaveAlias mocks base method.
+func (m *MockRepository) SaveAlias(ctx context.Context, tx pgx.Tx, alias *models.Alias) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAlias", ctx, tx, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAlias indicates an expected call of SaveAlias.
+func (mr *MockRepositoryMockRecorder) SaveAlias(ctx, tx, alias interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAlias", reflect.TypeOf((*MockRepository)(nil).SaveAlias), ctx, tx, alias)
+}
+
+// SaveBatch mocks base method.
+func (m *MockRepository) SaveBatch(ctx context.Context, tx pgx.Tx, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, tx, batch)
+	ret0, _ := ret[0].([]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockRepositoryMockRecorder) SaveBatch(ctx, tx, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockRepository)(nil).SaveBatch), ctx, tx, batch)
+}
+
+// SaveBatchUser mocks base method.
+func (m *MockRepository) SaveBatchUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatchUser", ctx, tx, userID, batch)
+	ret0, _ := ret[0].([]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBatchUser indicates an expected call of SaveBatchUser.
+func (mr *MockRepositoryMockRecorder) SaveBatchUser(ctx, tx, userID, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatchUser", reflect.TypeOf((*MockRepository)(nil).SaveBatchUser), ctx, tx, userID, batch)
+}
+
+// SaveFolder mocks base method.
+func (m *MockRepository) SaveFolder(ctx context.Context, folder *models.Folder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFolder", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFolder indicates an expected call of SaveFolder.
+func (mr *MockRepositoryMockRecorder) SaveFolder(ctx, folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFolder", reflect.TypeOf((*MockRepository)(nil).SaveFolder), ctx, folder)
+}
+
+// SaveLink mocks base method.
+func (m *MockRepository) SaveLink(ctx context.Context, tx pgx.Tx, link *models.Link) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLink", ctx, tx, link)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveLink indicates an expected call of SaveLink.
+func (mr *MockRepositoryMockRecorder) SaveLink(ctx, tx, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLink", reflect.TypeOf((*MockRepository)(nil).SaveLink), ctx, tx, link)
+}
+
+// SaveOutbox mocks base method.
+func (m *MockRepository) SaveOutbox(ctx context.Context, tx pgx.Tx, events []*models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOutbox", ctx, tx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOutbox indicates an expected call of SaveOutbox.
+func (mr *MockRepositoryMockRecorder) SaveOutbox(ctx, tx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOutbox", reflect.TypeOf((*MockRepository)(nil).SaveOutbox), ctx, tx, events)
+}
+
+// SaveTemplate mocks base method.
+func (m *MockRepository) SaveTemplate(ctx context.Context, template *models.UTMTemplate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTemplate", ctx, template)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTemplate indicates an expected call of SaveTemplate.
+func (mr *MockRepositoryMockRecorder) SaveTemplate(ctx, template interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTemplate", reflect.TypeOf((*MockRepository)(nil).SaveTemplate), ctx, template)
+}
+
+// SaveUser mocks base method.
+func (m *MockRepository) SaveUser(ctx context.Context, tx pgx.Tx, userID, id uuid.UUID, url *url.URL) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, tx, userID, id, url)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockRepositoryMockRecorder) SaveUser(ctx, tx, userID, id, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockRepository)(nil).SaveUser), ctx, tx, userID, id, url)
+}
+
+// SaveWebhook mocks base method.
+func (m *MockRepository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhook indicates an expected call of SaveWebhook.
+func (mr *MockRepositoryMockRecorder) SaveWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhook", reflect.TypeOf((*MockRepository)(nil).SaveWebhook), ctx, webhook)
+}
+
+// SaveWebhookDelivery mocks base method.
+func (m *MockRepository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookDelivery indicates an expected call of SaveWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) SaveWebhookDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).SaveWebhookDelivery), ctx, delivery)
+}
+
+// UpdateByUserID mocks base method.
+func (m *MockRepository) UpdateByUserID(ctx context.Context, tx pgx.Tx, change *models.URLHistory) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByUserID", ctx, tx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateByUserID indicates an expected call of UpdateByUserID.
+func (mr *MockRepositoryMockRecorder) UpdateByUserID(ctx, tx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByUserID", reflect.TypeOf((*MockRepository)(nil).UpdateByUserID), ctx, tx, change)
+}
+
+// UpdateFolderByUserID mocks base method.
+func (m *MockRepository) UpdateFolderByUserID(ctx context.Context, folder *models.Folder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFolderByUserID", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFolderByUserID indicates an expected call of UpdateFolderByUserID.
+func (mr *MockRepositoryMockRecorder) UpdateFolderByUserID(ctx, folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFolderByUserID", reflect.TypeOf((*MockRepository)(nil).UpdateFolderByUserID), ctx, folder)
+}
+
+// UpdateHealth mocks base method.
+func (m *MockRepository) UpdateHealth(ctx context.Context, id uuid.UUID, health *models.LinkHealth) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHealth", ctx, id, health)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHealth indicates an expected call of UpdateHealth.
+func (mr *MockRepositoryMockRecorder) UpdateHealth(ctx, id, health interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHealth", reflect.TypeOf((*MockRepository)(nil).UpdateHealth), ctx, id, health)
+}
+
+// UpdatePageMeta mocks base method.
+func (m *MockRepository) UpdatePageMeta(ctx context.Context, id uuid.UUID, page *models.PageMeta) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePageMeta", ctx, id, page)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePageMeta indicates an expected call of UpdatePageMeta.
+func (mr *MockRepositoryMockRecorder) UpdatePageMeta(ctx, id, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePageMeta", reflect.TypeOf((*MockRepository)(nil).UpdatePageMeta), ctx, id, page)
+}
+
+// UpdateScheduleStates mocks base method.
+func (m *MockRepository) UpdateScheduleStates(ctx context.Context, now time.Time) ([]*models.ScheduleEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduleStates", ctx, now)
+	ret0, _ := ret[0].([]*models.ScheduleEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduleStates indicates an expected call of UpdateScheduleStates.
+func (mr *MockRepositoryMockRecorder) UpdateScheduleStates(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduleStates", reflect.TypeOf((*MockRepository)(nil).UpdateScheduleStates), ctx, now)
+}
+
+// UpdateTemplateByUserID mocks base method.
+func (m *MockRepository) UpdateTemplateByUserID(ctx context.Context, template *models.UTMTemplate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateByUserID", ctx, template)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateByUserID indicates an expected call of UpdateTemplateByUserID.
+func (mr *MockRepositoryMockRecorder) UpdateTemplateByUserID(ctx, template interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateByUserID", reflect.TypeOf((*MockRepository)(nil).UpdateTemplateByUserID), ctx, template)
+}
+
+// UpdateWebhookByUserID mocks base method.
+func (m *MockRepository) UpdateWebhookByUserID(ctx context.Context, webhook *models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookByUserID", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookByUserID indicates an expected call of UpdateWebhookByUserID.
+func (mr *MockRepositoryMockRecorder) UpdateWebhookByUserID(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookByUserID", reflect.TypeOf((*MockRepository)(nil).UpdateWebhookByUserID), ctx, webhook)
 }
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/securecookie"
+)
+
+// Ограничения формы ввода пароля
+const (
+	maxPasswordFormSize = 4 * 1024 // Максимальный размер тела формы (в байтах)
+	linkCookiePrefix    = "link_"  // Префикс имени cookie доступа к защищенной ссылке
+)
+
+// passwordTemplate шаблон HTML страницы ввода пароля защищенной ссылки
+var passwordTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>Ссылка защищена паролем</title>
+</head>
+<body>
+<h1>Ссылка защищена паролем</h1>
+<p>Для перехода по ссылке {{.ShortURL}} введите пароль.</p>
+{{- if .Error}}
+<p role="alert">{{.Error}}</p>
+{{- end}}
+<form method="post" action="{{.ShortURL}}">
+<input type="password" name="password" autocomplete="current-password" required autofocus>
+<button type="submit">Перейти</button>
+</form>
+</body>
+</html>
+`))
+
+// passwordPageData содержит данные для страницы ввода пароля
+type passwordPageData struct {
+	ShortURL string
+	Error    string
+}
+
+// PostPasswordByID проверяет пароль защищенной ссылки
+// @Summary Ввод пароля ссылки
+// @Description Проверяет пароль защищенной ссылки. При успехе выдает подписанный cookie доступа к ссылке
+// @Description с ограниченным временем жизни и перенаправляет на короткую ссылку.
+// @Description Неверные попытки ограничиваются по клиенту и по ссылке.
+// @Tags URL
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param id path string true "ID сокращенного URL или короткое имя импортированной ссылки"
+// @Param password formData string true "Пароль ссылки"
+// @Success 303 "Перенаправление на короткую ссылку"
+// @Failure 400 {string} string "Неверный ID или форма"
+// @Failure 403 {string} string "Неверный пароль"
+// @Failure 404 {string} string "URL не найден"
+// @Failure 410 {string} string "URL был удален"
+// @Failure 429 {string} string "Слишком много попыток"
+// @Router /{id} [post]
+// @Router /{id}/{path} [post]
+func (app *App) PostPasswordByID(res http.ResponseWriter, req *http.Request) {
+	id, ok := app.linkID(res, req)
+	if !ok {
+		return
+	}
+
+	link, ok := app.getLink(res, req, id)
+	if !ok {
+		return
+	}
+
+	if link.PasswordHash == "" {
+		res.Header().Set("Location", app.URL+linkPath(req, id))
+		res.WriteHeader(http.StatusSeeOther)
+		return
+	}
+
+	clientKey, linkKey := clientIP(req)+"/"+id.String(), id.String()
+	if wait, blocked := app.passwordBlocked(clientKey, linkKey); blocked {
+		res.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		res.WriteHeader(http.StatusTooManyRequests)
+		_, _ = res.Write([]byte("Too many password attempts!"))
+		return
+	}
+
+	req.Body = http.MaxBytesReader(res, req.Body, maxPasswordFormSize)
+	if err := req.ParseForm(); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Form is invalidate!"))
+		return
+	}
+
+	if !service.CheckPassword(link.PasswordHash, req.PostFormValue("password")) {
+		app.passwordClients.Fail(clientKey)
+		app.passwordLinks.Fail(linkKey)
+		app.writePasswordPage(res, req, http.StatusForbidden, link, "Неверный пароль")
+		return
+	}
+	app.passwordClients.Reset(clientKey)
+
+	if err := app.setLinkAccess(res, id); err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Cookie is invalidate!"))
+		return
+	}
+
+	res.Header().Set("Location", app.URL+linkPath(req, id))
+	res.WriteHeader(http.StatusSeeOther)
+}
+
+// passwordBlocked проверяет, заблокирован ли ввод пароля для клиента или для ссылки целиком
+// Возвращает время до снятия блокировки
+func (app *App) passwordBlocked(clientKey, linkKey string) (time.Duration, bool) {
+	if ok, wait := app.passwordClients.Allow(clientKey); !ok {
+		return wait, true
+	}
+
+	if ok, wait := app.passwordLinks.Allow(linkKey); !ok {
+		return wait, true
+	}
+	return 0, false
+}
+
+// linkCookie возвращает имя cookie и кодировщик доступа к защищенной ссылке
+func linkCookie(id uuid.UUID) (string, *securecookie.SecureCookie) {
+	sc := securecookie.New(config.AuthKey, nil)
+	sc.MaxAge(int(config.LinkAccessTTL.Seconds()))
+	return linkCookiePrefix + id.String(), sc
+}
+
+// hasLinkAccess проверяет подписанный cookie доступа к защищенной ссылке
+func (app *App) hasLinkAccess(req *http.Request, id uuid.UUID) bool {
+	name, sc := linkCookie(id)
+	cookie, err := req.Cookie(name)
+	if err != nil {
+		return false
+	}
+
+	var linkID uuid.UUID
+	if err = sc.Decode(name, cookie.Value, &linkID); err != nil {
+		return false
+	}
+	return linkID == id
+}
+
+// setLinkAccess выдает подписанный cookie доступа к защищенной ссылке
+// Cookie привязан к UUID ссылки именем, а не путем, чтобы действовать и для UUID, и для короткого имени ссылки,
+// и истекает через config.LinkAccessTTL
+func (app *App) setLinkAccess(res http.ResponseWriter, id uuid.UUID) error {
+	name, sc := linkCookie(id)
+	encoded, err := sc.Encode(name, id)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(res, &http.Cookie{
+		Name:     name,
+		Value:    encoded,
+		Path:     "/",
+		MaxAge:   int(config.LinkAccessTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   config.EnableHTTPS,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// writePasswordPage отрисовывает форму ввода пароля защищенной ссылки
+// Адрес назначения на странице не раскрывается
+func (app *App) writePasswordPage(res http.ResponseWriter, req *http.Request, status int, link *models.Link, message string) {
+	data := passwordPageData{
+		ShortURL: app.URL + linkPath(req, link.ID),
+		Error:    message,
+	}
+
+	var buf bytes.Buffer
+	if err := passwordTemplate.Execute(&buf, data); err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Page render error!"))
+		return
+	}
+
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.Header().Set("Cache-Control", "no-store")
+	res.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	res.Header().Set("Referrer-Policy", "no-referrer")
+	res.WriteHeader(status)
+	_, _ = res.Write(buf.Bytes())
+}
+
+// linkPath возвращает путь короткой ссылки с дополнительным путем и параметрами запроса
+func linkPath(req *http.Request, id uuid.UUID) string {
+	p := id.String()
+	if extra := chi.URLParam(req, "*"); extra != "" {
+		p += "/" + (&url.URL{Path: extra}).EscapedPath()
+	}
+
+	if req.URL.RawQuery != "" {
+		p += "?" + req.URL.RawQuery
+	}
+	return p
+}
+
+// clientIP возвращает IP адрес клиента из адреса соединения
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	"github.com/IvanKondrashkov/go-shortener/internal/service/limiter"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPasswordRequest(tc *Suite, id uuid.UUID, password string) *http.Request {
+	form := url.Values{"password": {password}}
+	req := httptest.NewRequest(http.MethodPost, tc.app.URL+id.String(), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func newGetRequest(tc *Suite, id uuid.UUID) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, tc.app.URL+id.String(), nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id.String())
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func saveProtectedLink(t *testing.T, tc *Suite, rawURL, password string) uuid.UUID {
+	hash, err := service.HashPassword(password)
+	require.NoError(t, err)
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(rawURL))
+	_, err = tc.app.service.SaveLink(context.Background(), &models.Link{
+		ID:           id,
+		OriginalURL:  rawURL,
+		PasswordHash: hash,
+	})
+	require.NoError(t, err)
+	return id
+}
+
+func TestPostPasswordByID(t *testing.T) {
+	tc := NewSuite(t)
+	id := saveProtectedLink(t, tc, "https://ya.ru/", "secret")
+
+	w := httptest.NewRecorder()
+	tc.app.GetURLByID(w, newGetRequest(tc, id))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Location"))
+	assert.Contains(t, w.Body.String(), "<form method=\"post\"")
+	assert.NotContains(t, w.Body.String(), "https://ya.ru/")
+
+	w = httptest.NewRecorder()
+	tc.app.PostPasswordByID(w, newPasswordRequest(tc, id, "wrong"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Неверный пароль")
+	assert.Empty(t, w.Result().Cookies())
+
+	w = httptest.NewRecorder()
+	tc.app.PostPasswordByID(w, newPasswordRequest(tc, id, "secret"))
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, tc.app.URL+id.String(), w.Header().Get("Location"))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "link_"+id.String(), cookies[0].Name)
+	assert.Equal(t, "/", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+
+	req := newGetRequest(tc, id)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	tc.app.GetURLByID(w, req)
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	assert.Equal(t, "https://ya.ru/", w.Header().Get("Location"))
+
+	other := saveProtectedLink(t, tc, "https://go.dev/", "secret")
+	req = newGetRequest(tc, other)
+	req.AddCookie(&http.Cookie{Name: "link_" + other.String(), Value: cookies[0].Value})
+	w = httptest.NewRecorder()
+	tc.app.GetURLByID(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	link, _ := tc.app.service.GetLinkByID(context.Background(), id)
+	assert.Equal(t, int64(1), link.Clicks)
+}
+
+func TestGetPreviewByIDPassword(t *testing.T) {
+	tc := NewSuite(t)
+	id := saveProtectedLink(t, tc, "https://ya.ru/", "secret")
+
+	w := httptest.NewRecorder()
+	tc.app.GetPreviewByID(w, newGetRequest(tc, id))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<form method=\"post\"")
+	assert.NotContains(t, w.Body.String(), "https://ya.ru/")
+
+	w = httptest.NewRecorder()
+	tc.app.PostPasswordByID(w, newPasswordRequest(tc, id, "secret"))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	req := newGetRequest(tc, id)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	tc.app.GetPreviewByID(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<code>https://ya.ru/</code>")
+	assert.NotContains(t, w.Body.String(), "<form method=\"post\"")
+}
+
+func TestPostPasswordByIDLimit(t *testing.T) {
+	tc := NewSuite(t)
+	tc.app.passwordClients = limiter.NewLimiter(2, time.Minute)
+	tc.app.passwordLinks = limiter.NewLimiter(3, time.Minute)
+	id := saveProtectedLink(t, tc, "https://ya.ru/", "secret")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		password   string
+		status     int
+	}{
+		{name: "first wrong", remoteAddr: "10.0.0.1:1000", password: "wrong", status: http.StatusForbidden},
+		{name: "second wrong", remoteAddr: "10.0.0.1:1001", password: "wrong", status: http.StatusForbidden},
+		{name: "client blocked", remoteAddr: "10.0.0.1:1002", password: "secret", status: http.StatusTooManyRequests},
+		{name: "other client wrong", remoteAddr: "10.0.0.2:1000", password: "wrong", status: http.StatusForbidden},
+		{name: "link blocked", remoteAddr: "10.0.0.3:1000", password: "secret", status: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		req := newPasswordRequest(tc, id, tt.password)
+		req.RemoteAddr = tt.remoteAddr
+		w := httptest.NewRecorder()
+
+		tc.app.PostPasswordByID(w, req)
+
+		assert.Equal(t, tt.status, w.Code, tt.name)
+		if tt.status == http.StatusTooManyRequests {
+			// Между блокировкой и ответом проходит время, поэтому проверяется диапазон, а не точное значение
+			retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+			require.NoError(t, err, tt.name)
+			assert.Greater(t, retryAfter, 0, tt.name)
+			assert.LessOrEqual(t, retryAfter, 60, tt.name)
+		}
+	}
+}
+
+func TestShortenAPIPassword(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	tests := []struct {
+		name    string
+		payload string
+		status  int
+	}{
+		{
+			name:    "password is invalidate",
+			payload: "{\"url\":\"https://go.dev/\",\"password\":\"" + strings.Repeat("a", 73) + "\"}",
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "ok",
+			payload: "{\"url\":\"https://ya.ru/\",\"password\":\"secret\"}",
+			status:  http.StatusCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten", bytes.NewBufferString(tt.payload))
+			req = req.WithContext(customContext.SetContextUserID(req.Context(), userID))
+			w := httptest.NewRecorder()
+
+			tc.app.ShortenAPI(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/urls", nil)
+	req = req.WithContext(customContext.SetContextUserID(req.Context(), userID))
+	w := httptest.NewRecorder()
+
+	tc.app.GetAllURLByUserID(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "\"protected\":true")
+	assert.NotContains(t, w.Body.String(), "secret")
+}
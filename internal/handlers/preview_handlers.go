@@ -0,0 +1,225 @@
+// Package handlers содержит HTTP-хендлеры для API
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Формат даты создания на HTML страницах
+const pageDateLayout = "02.01.2006 15:04 MST"
+
+// Параметр запроса, которым страница-предупреждение подтверждает переход
+// Параметр не передается в адрес назначения
+const continueParam = "_continue"
+
+// Шаблоны HTML страниц предпросмотра и предупреждения
+var (
+	previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>Предпросмотр ссылки</title>
+</head>
+<body>
+<h1>Предпросмотр ссылки</h1>
+<dl>
+<dt>Короткая ссылка</dt><dd>{{.ShortURL}}</dd>
+<dt>Перенаправляет на</dt><dd><code>{{.OriginalURL}}</code></dd>
+{{- if .Title}}
+<dt>Название</dt><dd>{{.Title}}</dd>
+{{- end}}
+<dt>Создана</dt><dd>{{.CreatedAt}}</dd>
+<dt>Переходов</dt><dd>{{.Clicks}}</dd>
+</dl>
+{{- with .Page}}
+<figure>
+{{- if .Image}}
+<img src="{{.Image}}" alt="" width="320" referrerpolicy="no-referrer">
+{{- end}}
+<figcaption>
+{{- if .Favicon}}<img src="{{.Favicon}}" alt="" width="16" height="16" referrerpolicy="no-referrer"> {{end}}
+{{- if .Title}}<strong>{{.Title}}</strong>{{end}}
+{{- if .Description}}<p>{{.Description}}</p>{{end}}
+</figcaption>
+</figure>
+{{- end}}
+<p><a href="{{.OriginalURL}}" rel="noopener noreferrer nofollow">Перейти по ссылке</a></p>
+</body>
+</html>
+`))
+	interstitialTemplate = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>Вы покидаете сайт</title>
+</head>
+<body>
+<h1>Вы покидаете сайт</h1>
+<p>Ссылка {{.ShortURL}} ведет на внешний ресурс. Убедитесь, что доверяете ему, прежде чем продолжить.</p>
+<p><code>{{.OriginalURL}}</code></p>
+{{- if .Title}}
+<p>{{.Title}}</p>
+{{- end}}
+<p><a href="{{.ContinueURL}}" rel="noopener noreferrer nofollow">Продолжить</a></p>
+</body>
+</html>
+`))
+)
+
+// pageData содержит данные для HTML страниц ссылки
+// html/template экранирует текст и заменяет небезопасные схемы (javascript:, data:) в ссылках
+type pageData struct {
+	ShortURL    string
+	OriginalURL string
+	Title       string
+	CreatedAt   string
+	Clicks      int64
+	Page        *models.PageMeta // Метаданные страницы назначения, nil если еще не загружены
+	ContinueURL string           // Адрес продолжения со страницы-предупреждения
+}
+
+// GetPreviewByID возвращает страницу предпросмотра сокращенного URL вместо перенаправления
+// @Summary Предпросмотр ссылки
+// @Description Показывает адрес назначения, название, дату создания, количество переходов и метаданные страницы назначения.
+// @Description Для ссылок с паролем без действующего доступа показывает форму ввода пароля.
+// @Tags URL
+// @Produce html
+// @Param id path string true "ID сокращенного URL или короткое имя импортированной ссылки"
+// @Success 200 {string} string "HTML страница предпросмотра или форма ввода пароля"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 403 {string} string "Адрес назначения запрещен, причина в заголовке X-Block-Reason"
+// @Failure 404 {string} string "URL не найден"
+// @Failure 410 {string} string "URL был удален"
+// @Failure 451 {string} string "URL отключен администратором, причина в заголовке X-Block-Reason"
+// @Router /{id}+ [get]
+func (app *App) GetPreviewByID(res http.ResponseWriter, req *http.Request) {
+	id, ok := app.linkID(res, req)
+	if !ok {
+		return
+	}
+
+	link, ok := app.getLink(res, req, id)
+	if !ok {
+		return
+	}
+	if link.PasswordHash != "" && !app.hasLinkAccess(req, id) {
+		app.writePasswordPage(res, req, http.StatusOK, link, "")
+		return
+	}
+	app.writePage(res, previewTemplate, app.newPageData(link))
+}
+
+// linkID получает UUID ссылки из пути короткого URL, который содержит UUID или короткое имя импортированной ссылки,
+// и записывает ответ с ошибкой, если идентификатор неверный или короткое имя не найдено
+// Возвращает false, если обработку запроса нужно прекратить
+func (app *App) linkID(res http.ResponseWriter, req *http.Request) (uuid.UUID, bool) {
+	id, err := app.service.ResolveID(req.Context(), chi.URLParam(req, "id"))
+	if err != nil && errors.Is(err, service.ErrSlugNotValid) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return uuid.Nil, false
+	}
+
+	if err != nil && errors.Is(err, customError.ErrNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Url by id not found!"))
+		return uuid.Nil, false
+	}
+
+	if err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Get url error!"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// getLink получает запись URL и записывает ответ с ошибкой, если запись недоступна,
+// отключена администратором или ее адрес назначения запрещен
+// Возвращает false, если обработку запроса нужно прекратить
+func (app *App) getLink(res http.ResponseWriter, req *http.Request, id uuid.UUID) (*models.Link, bool) {
+	link, err := app.service.GetLinkByID(req.Context(), id)
+	if err != nil && errors.Is(err, customError.ErrNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Url by id not found!"))
+		return nil, false
+	}
+
+	if err != nil && errors.Is(err, customError.ErrDeleteAccepted) {
+		res.WriteHeader(http.StatusGone)
+		_, _ = res.Write([]byte("Delete url accepted!"))
+		return nil, false
+	}
+
+	if err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Get url error!"))
+		return nil, false
+	}
+
+	if link.Disabled != "" {
+		writeDisabled(res, link)
+		return nil, false
+	}
+
+	if writeBlocked(res, app.service.CheckURL(link.OriginalURL)) {
+		return nil, false
+	}
+	return link, true
+}
+
+// newPageData возвращает данные HTML страницы ссылки
+func (app *App) newPageData(link *models.Link) pageData {
+	return pageData{
+		ShortURL:    app.URL + link.ID.String(),
+		OriginalURL: link.OriginalURL,
+		Title:       link.Title,
+		CreatedAt:   link.CreatedAt.UTC().Format(pageDateLayout),
+		Clicks:      link.Clicks,
+		Page:        link.Page,
+	}
+}
+
+// writeInterstitial отрисовывает страницу-предупреждение ссылки
+// Кнопка продолжения ведет на тот же короткий URL с параметром continueParam, переход учитывается при продолжении
+func (app *App) writeInterstitial(res http.ResponseWriter, req *http.Request, link *models.Link) {
+	query := req.URL.Query()
+	query.Set(continueParam, "1")
+	next := url.URL{Path: req.URL.Path, RawQuery: query.Encode()}
+
+	data := app.newPageData(link)
+	data.ContinueURL = next.String()
+	app.writePage(res, interstitialTemplate, data)
+}
+
+// writePage отрисовывает HTML страницу ссылки
+// Страница не кэшируется и не индексируется, так как содержит счетчик переходов
+func (app *App) writePage(res http.ResponseWriter, tmpl *template.Template, data pageData) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Page render error!"))
+		return
+	}
+
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.Header().Set("Cache-Control", "no-store")
+	res.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	res.Header().Set("Referrer-Policy", "no-referrer")
+	res.WriteHeader(http.StatusOK)
+	_, _ = res.Write(buf.Bytes())
+}
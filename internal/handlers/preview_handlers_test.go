@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPreviewByID(t *testing.T) {
+	tc := NewSuite(t)
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	_, _ = tc.app.service.SaveLink(context.Background(), &models.Link{
+		ID:          id,
+		OriginalURL: "https://ya.ru/",
+		LinkMeta:    models.LinkMeta{Title: "<b>Yandex</b>"},
+	})
+	_ = tc.app.service.AddClick(context.Background(), id, "")
+	_ = tc.app.service.AddClick(context.Background(), id, "")
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+		want   []string
+	}{
+		{
+			name:   "id is invalidate",
+			id:     "not.uuid",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "ok",
+			id:     id.String(),
+			status: http.StatusOK,
+			want: []string{
+				"<a href=\"https://ya.ru/\"",
+				"&lt;b&gt;Yandex&lt;/b&gt;",
+				"<dt>Переходов</dt><dd>2</dd>",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.app.URL+tt.id+"+", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.GetPreviewByID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			for _, want := range tt.want {
+				assert.Contains(t, w.Body.String(), want)
+			}
+		})
+	}
+}
+
+func TestGetURLByIDInterstitial(t *testing.T) {
+	tc := NewSuite(t)
+	tests := []struct {
+		name     string
+		url      string
+		query    string
+		status   int
+		location string
+		want     string
+		clicks   int64
+	}{
+		{
+			name:   "interstitial",
+			url:    "https://ya.ru/",
+			status: http.StatusOK,
+			want:   "<code>https://ya.ru/</code>",
+		},
+		{
+			name:   "continue link",
+			url:    "https://go.dev/",
+			query:  "?q=1",
+			status: http.StatusOK,
+			want:   "?_continue=1&amp;q=1\"",
+		},
+		{
+			name:   "unsafe scheme",
+			url:    "javascript:alert(1)",
+			status: http.StatusOK,
+			want:   "<code>javascript:alert(1)</code>",
+		},
+		{
+			name:     "continue",
+			url:      "https://practicum.yandex.ru/",
+			query:    "?_continue=1",
+			status:   http.StatusTemporaryRedirect,
+			location: "https://practicum.yandex.ru/",
+			clicks:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(tt.url))
+			_, _ = tc.app.service.SaveLink(context.Background(), &models.Link{
+				ID:          id,
+				OriginalURL: tt.url,
+				LinkMeta:    models.LinkMeta{Interstitial: true},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.app.URL+id.String()+tt.query, nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.GetURLByID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.location, w.Header().Get("Location"))
+			if tt.want != "" {
+				assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), tt.want)
+			}
+
+			link, _ := tc.app.service.GetLinkByID(context.Background(), id)
+			assert.Equal(t, tt.clicks, link.Clicks)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package handlers содержит HTTP-хендлеры для API
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/color"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/qr"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+	"github.com/google/uuid"
+)
+
+// Ограничения параметров QR-кода
+const (
+	minQRSize   = 32                      // Минимальный размер стороны QR-кода (в пикселях)
+	maxQRSize   = 4096                    // Максимальный размер стороны QR-кода (в пикселях)
+	maxQRMargin = 32                      // Максимальный отступ QR-кода (в модулях)
+	qrCacheCtl  = "public, max-age=86400" // Значение заголовка Cache-Control для QR-кода
+)
+
+// qrParams содержит параметры генерации QR-кода
+type qrParams struct {
+	format string
+	level  qr.Level
+	opts   qr.Options
+}
+
+// GetQRByID возвращает QR-код короткой ссылки
+// @Summary Получить QR-код
+// @Description Возвращает QR-код в формате PNG или SVG, кодирующий короткую ссылку
+// @Tags URL
+// @Produce png
+// @Produce image/svg+xml
+// @Param id path string true "ID сокращенного URL или короткое имя импортированной ссылки"
+// @Param format query string false "Формат изображения (png, svg)"
+// @Param size query int false "Размер стороны изображения в пикселях"
+// @Param level query string false "Уровень коррекции ошибок (L, M, Q, H)"
+// @Param margin query int false "Отступ в модулях"
+// @Param fg query string false "Цвет модулей (#RRGGBB)"
+// @Param bg query string false "Цвет фона (#RRGGBB)"
+// @Success 200 {file} file "QR-код"
+// @Success 304 "QR-код не изменился"
+// @Failure 400 {string} string "Неверный ID или параметры"
+// @Failure 404 {string} string "URL не найден"
+// @Failure 410 {string} string "URL был удален"
+// @Router /{id}/qr [get]
+func (app *App) GetQRByID(res http.ResponseWriter, req *http.Request) {
+	id, ok := app.linkID(res, req)
+	if !ok {
+		return
+	}
+
+	_, err := app.service.GetByID(req.Context(), id)
+	if err != nil && errors.Is(err, customError.ErrNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Url by id not found!"))
+		return
+	}
+
+	if err != nil && errors.Is(err, customError.ErrDeleteAccepted) {
+		res.WriteHeader(http.StatusGone)
+		_, _ = res.Write([]byte("Delete url accepted!"))
+		return
+	}
+
+	params, err := parseQRParams(req.URL.Query())
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Query is invalidate!"))
+		return
+	}
+
+	content := app.URL + id.String()
+	etag := params.etag(content)
+	res.Header().Set("Cache-Control", qrCacheCtl)
+	res.Header().Set("ETag", etag)
+	if match := req.Header.Get("If-None-Match"); match != "" && strings.Contains(match, etag) {
+		res.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	b, contentType, err := renderQR(content, params)
+	if err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("QR code error!"))
+		return
+	}
+
+	res.Header().Set("Content-Type", contentType)
+	res.WriteHeader(http.StatusOK)
+	_, _ = res.Write(b)
+}
+
+// qrDataURI возвращает QR-код короткой ссылки с параметрами по умолчанию в виде data URI PNG
+func (app *App) qrDataURI(id uuid.UUID) (string, error) {
+	params, err := parseQRParams(url.Values{})
+	if err != nil {
+		return "", err
+	}
+
+	b, contentType, err := renderQR(app.URL+id.String(), params)
+	if err != nil {
+		return "", err
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// renderQR кодирует содержимое в QR-код и отрисовывает его
+func renderQR(content string, params *qrParams) ([]byte, string, error) {
+	code, err := qr.Encode(content, params.level)
+	if err != nil {
+		return nil, "", fmt.Errorf("qr encode error: %w", err)
+	}
+	return code.Render(params.format, params.opts)
+}
+
+// parseQRParams разбирает параметры QR-кода из query запроса
+// Отсутствующие параметры берутся из конфигурации
+func parseQRParams(query url.Values) (*qrParams, error) {
+	get := func(key, def string) string {
+		if v := query.Get(key); v != "" {
+			return v
+		}
+		return def
+	}
+
+	params := &qrParams{
+		format: strings.ToLower(get("format", qr.FormatPNG)),
+	}
+	if params.format != qr.FormatPNG && params.format != qr.FormatSVG {
+		return nil, fmt.Errorf("%w: %q", qr.ErrFormatNotValid, params.format)
+	}
+
+	level, err := qr.ParseLevel(get("level", config.QRLevel))
+	if err != nil {
+		return nil, err
+	}
+	params.level = level
+
+	size, err := strconv.Atoi(get("size", strconv.Itoa(config.QRSize)))
+	if err != nil || size < minQRSize || size > maxQRSize {
+		return nil, fmt.Errorf("size is invalidate: %w", qr.ErrOptionsNotValid)
+	}
+
+	margin, err := strconv.Atoi(get("margin", strconv.Itoa(config.QRMargin)))
+	if err != nil || margin < 0 || margin > maxQRMargin {
+		return nil, fmt.Errorf("margin is invalidate: %w", qr.ErrOptionsNotValid)
+	}
+
+	var fg, bg color.RGBA
+	if fg, err = qr.ParseColor(get("fg", config.QRForeground)); err != nil {
+		return nil, err
+	}
+	if bg, err = qr.ParseColor(get("bg", config.QRBackground)); err != nil {
+		return nil, err
+	}
+
+	params.opts = qr.Options{Size: size, Margin: margin, Foreground: fg, Background: bg}
+	return params, nil
+}
+
+// etag возвращает ETag QR-кода, зависящий от содержимого и параметров отрисовки
+func (p *qrParams) etag(content string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%d|%d|%v|%v",
+		content, p.format, p.level, p.opts.Size, p.opts.Margin, p.opts.Foreground, p.opts.Background)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetQRByID(t *testing.T) {
+	tc := NewSuite(t)
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	u, _ := url.Parse("https://ya.ru/")
+	_, _ = tc.app.service.Save(context.Background(), id, u)
+
+	tests := []struct {
+		name        string
+		id          string
+		query       string
+		status      int
+		contentType string
+	}{
+		{
+			name:   "id is invalidate",
+			id:     "not.uuid",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "query is invalidate",
+			id:     id.String(),
+			query:  "size=10",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "color is invalidate",
+			id:     id.String(),
+			query:  "fg=red",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:        "png",
+			id:          id.String(),
+			status:      http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:        "svg",
+			id:          id.String(),
+			query:       "format=svg&level=H&margin=0&fg=%23112233",
+			status:      http.StatusOK,
+			contentType: "image/svg+xml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.app.URL+tt.id+"/qr?"+tt.query, nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.GetQRByID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.NotEmpty(t, w.Header().Get("ETag"))
+			assert.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+
+			req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+			w = httptest.NewRecorder()
+			tc.app.GetQRByID(w, req)
+			assert.Equal(t, http.StatusNotModified, w.Code)
+			assert.Empty(t, w.Body.Bytes())
+		})
+	}
+}
+
+func TestShortenAPIWithQR(t *testing.T) {
+	tc := NewSuite(t)
+	req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten", bytes.NewBufferString("{\"url\":\"https://ya.ru/\",\"qr\":true}"))
+	w := httptest.NewRecorder()
+
+	tc.app.ShortenAPI(w, req)
+
+	var got models.ResponseShortenAPI
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.True(t, strings.HasPrefix(got.QR, "data:image/png;base64,"))
+
+	req = httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/batch", bytes.NewBufferString("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"original_url\":\"https://go.dev/\",\"qr\":true}]"))
+	w = httptest.NewRecorder()
+
+	tc.app.ShortenAPIBatch(w, req)
+
+	var batch []*models.ResponseShortenAPIBatch
+	_ = json.Unmarshal(w.Body.Bytes(), &batch)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Len(t, batch, 1)
+	assert.True(t, strings.HasPrefix(batch[0].QR, "data:image/png;base64,"))
+
+	b, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(batch[0].QR, "data:image/png;base64,"))
+	_, err := png.DecodeConfig(bytes.NewReader(b))
+	assert.NoError(t, err)
+}
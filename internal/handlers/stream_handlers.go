@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+)
+
+// streamRetry задержка переподключения клиента, передаваемая в поле retry потока SSE
+const streamRetry = 3 * time.Second
+
+// StreamByUserID открывает поток SSE событий ссылок пользователя
+// @Summary Поток событий ссылок
+// @Description Держит соединение text/event-stream и отправляет события ссылок текущего пользователя:
+// @Description click - переход по ссылке, delete - ссылка удалена.
+// @Description Каждое событие содержит поле id. При переподключении с заголовком Last-Event-ID
+// @Description сначала отправляются пропущенные события из буфера последних STREAM_BUFFER событий.
+// @Description Если часть пропущенных событий уже вытеснена из буфера, первым отправляется событие reset.
+// @Description Каждые STREAM_HEARTBEAT секунд отправляется комментарий ": ping".
+// @Description Если клиент не успевает читать события и очередь соединения переполняется, поток закрывается.
+// @Tags User
+// @Security ApiKeyAuth
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "Номер последнего полученного события"
+// @Success 200 {object} models.StreamEvent "Поле data события"
+// @Failure 400 {string} string "Неверный Last-Event-ID"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 503 {string} string "Поток событий не настроен"
+// @Router /api/user/stream [get]
+func (app *App) StreamByUserID(res http.ResponseWriter, req *http.Request) {
+	var lastID *uint64
+	if raw := req.Header.Get("Last-Event-ID"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			_, _ = res.Write([]byte("Last-Event-ID is invalidate!"))
+			return
+		}
+		lastID = &id
+	}
+
+	sub, missed, reset, err := app.service.SubscribeStream(req.Context(), lastID)
+	switch {
+	case errors.Is(err, service.ErrUserUnauthorized):
+		res.WriteHeader(http.StatusUnauthorized)
+		_, _ = res.Write([]byte("User unauthorized!"))
+		return
+	case err != nil:
+		res.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = res.Write([]byte("Stream is unavailable!"))
+		return
+	}
+	defer app.service.UnsubscribeStream(req.Context(), sub)
+
+	// WriteTimeout сервера ограничивает весь ответ, поэтому срок записи продлевается перед каждой отправкой
+	rc := http.NewResponseController(res)
+	writer := writerPool.Get().(*bufio.Writer)
+	writer.Reset(res)
+	defer writerPool.Put(writer)
+
+	flush := func() bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(2 * config.StreamHeartbeat))
+		if err := writer.Flush(); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	_, _ = fmt.Fprintf(writer, "retry: %d\n\n", streamRetry.Milliseconds())
+	if reset {
+		_, _ = fmt.Fprintf(writer, "event: %s\ndata: {\"type\":%q}\n\n", models.StreamEventReset, models.StreamEventReset)
+	}
+	for _, event := range missed {
+		writeStreamEvent(writer, event)
+	}
+	if !flush() {
+		return
+	}
+
+	ticker := time.NewTicker(config.StreamHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-sub.Events():
+			writeStreamEvent(writer, event)
+		case <-ticker.C:
+			_, _ = writer.WriteString(": ping\n\n")
+		case <-sub.Done():
+			return
+		case <-req.Context().Done():
+			return
+		}
+
+		if !flush() {
+			return
+		}
+	}
+}
+
+// writeStreamEvent записывает событие в формате text/event-stream
+func writeStreamEvent(writer *bufio.Writer, event models.StreamEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
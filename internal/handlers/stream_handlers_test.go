@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/IvanKondrashkov/go-shortener/internal/service/middleware/compress"
+	customLogger "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent событие потока SSE, прочитанное клиентом
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readSSEEvent читает строки потока до пустой строки и пропускает блоки без поля event
+func readSSEEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if event.event != "" {
+				return event
+			}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			event.id = value
+		case "event":
+			event.event = value
+		case "data":
+			event.data = value
+		}
+	}
+}
+
+func TestStreamByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	tests := []struct {
+		name        string
+		userID      uuid.UUID
+		lastEventID string
+		status      int
+	}{
+		{
+			name:   "user unauthorized",
+			userID: uuid.Nil,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:        "last event id is invalidate",
+			userID:      userID,
+			lastEventID: "abc",
+			status:      http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/stream", nil)
+			if tt.userID != uuid.Nil {
+				req = req.WithContext(customContext.SetContextUserID(req.Context(), tt.userID))
+			}
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+
+			tc.app.StreamByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestStreamByUserIDEvents(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), userID)
+	id, err := tc.app.service.Repository.SaveLink(ctx, nil, &models.Link{
+		ID:          uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://go.dev/")),
+		UserID:      &userID,
+		OriginalURL: "https://go.dev/",
+		CreatedAt:   time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	// Поток проходит через те же middleware, что и в NewRouter, чтобы проверить сброс сжатых данных
+	srv := httptest.NewServer(customLogger.RequestLogger(compress.Gzip(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		tc.app.StreamByUserID(res, req.WithContext(customContext.SetContextUserID(req.Context(), userID)))
+	}))))
+	defer srv.Close()
+
+	open := func(lastEventID string) (*bufio.Reader, context.CancelFunc) {
+		reqCtx, cancel := context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", "gzip")
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		resp, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+		zr, err := gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+		reader := bufio.NewReader(zr)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "retry: 3000\n", line)
+		return reader, cancel
+	}
+
+	reader, cancel := open("")
+	require.NoError(t, tc.app.service.AddClick(context.Background(), id, ""))
+	click := readSSEEvent(t, reader)
+	assert.Equal(t, models.StreamEventClick, click.event)
+
+	var got models.StreamEvent
+	require.NoError(t, json.Unmarshal([]byte(click.data), &got))
+	assert.Equal(t, id, got.LinkID)
+	assert.Equal(t, tc.app.URL+id.String(), got.ShortURL)
+	assert.Equal(t, int64(1), got.Clicks)
+	cancel()
+
+	// Событие удаления произошло, пока клиент был отключен, и приходит после переподключения
+	// Для несуществующего UUID пакета событие не отправляется
+	_, err = tc.app.service.DeleteBatchByUserID(ctx, []uuid.UUID{uuid.New(), id})
+	require.NoError(t, err)
+	reader, cancel = open(click.id)
+	defer cancel()
+	deleted := readSSEEvent(t, reader)
+	assert.Equal(t, models.StreamEventDelete, deleted.event)
+	require.NoError(t, json.Unmarshal([]byte(deleted.data), &got))
+	assert.Equal(t, id, got.LinkID)
+
+	clickID, _ := strconv.ParseUint(click.id, 10, 64)
+	deletedID, _ := strconv.ParseUint(deleted.id, 10, 64)
+	assert.Greater(t, deletedID, clickID)
+
+	reader, cancel = open("1")
+	defer cancel()
+	assert.Equal(t, models.StreamEventReset, readSSEEvent(t, reader).event)
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Ограничения шаблона UTM разметки
+const (
+	maxTemplateFieldLength = 255 // Максимальная длина названия шаблона и значений параметров
+)
+
+// SaveTemplate создает шаблон UTM разметки пользователя
+// @Summary Создать шаблон UTM разметки
+// @Description Создает именованный шаблон UTM параметров текущего пользователя.
+// @Description Шаблон применяется при сокращении URL через поле template.
+// @Tags Шаблоны
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param input body models.RequestUTMTemplate true "Название шаблона и UTM параметры"
+// @Success 201 {object} models.UTMTemplate
+// @Failure 400 {string} string "Неверный формат запроса"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 409 {string} string "Шаблон с таким названием уже существует"
+// @Router /api/user/templates [post]
+func (app *App) SaveTemplate(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	reqDto, ok := decodeTemplate(res, req)
+	if !ok {
+		return
+	}
+
+	respDto, err := app.service.SaveTemplate(req.Context(), reqDto)
+	if !writeTemplateError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusCreated, respDto)
+}
+
+// GetTemplatesByUserID возвращает шаблоны UTM разметки пользователя
+// @Summary Получить шаблоны UTM разметки
+// @Description Возвращает шаблоны UTM разметки текущего пользователя в порядке создания
+// @Tags Шаблоны
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} models.UTMTemplate
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Router /api/user/templates [get]
+func (app *App) GetTemplatesByUserID(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	respDto, err := app.service.GetTemplatesByUserID(req.Context())
+	if !writeTemplateError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusOK, respDto)
+}
+
+// UpdateTemplateByUserID изменяет шаблон UTM разметки пользователя
+// @Summary Изменить шаблон UTM разметки
+// @Description Заменяет название и UTM параметры шаблона текущего пользователя.
+// @Description Ранее сокращенные URL не изменяются.
+// @Tags Шаблоны
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID шаблона"
+// @Param input body models.RequestUTMTemplate true "Новое название шаблона и UTM параметры"
+// @Success 200 {object} models.UTMTemplate
+// @Failure 400 {string} string "Неверный формат запроса"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 404 {string} string "Шаблон не найден"
+// @Failure 409 {string} string "Шаблон с таким названием уже существует"
+// @Router /api/user/templates/{id} [patch]
+func (app *App) UpdateTemplateByUserID(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	reqDto, ok := decodeTemplate(res, req)
+	if !ok {
+		return
+	}
+
+	respDto, err := app.service.UpdateTemplateByUserID(req.Context(), id, reqDto)
+	if !writeTemplateError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusOK, respDto)
+}
+
+// DeleteTemplateByUserID удаляет шаблон UTM разметки пользователя
+// @Summary Удалить шаблон UTM разметки
+// @Description Удаляет шаблон UTM разметки текущего пользователя, ранее сокращенные URL не изменяются
+// @Tags Шаблоны
+// @Security ApiKeyAuth
+// @Param id path string true "ID шаблона"
+// @Success 204 "Шаблон удален"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 404 {string} string "Шаблон не найден"
+// @Router /api/user/templates/{id} [delete]
+func (app *App) DeleteTemplateByUserID(res http.ResponseWriter, req *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	err = app.service.DeleteTemplateByUserID(req.Context(), id)
+	if !writeTemplateError(res, err) {
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// decodeTemplate читает и проверяет шаблон UTM разметки из тела запроса
+// Шаблон должен иметь название и хотя бы один параметр
+func decodeTemplate(res http.ResponseWriter, req *http.Request) (*models.RequestUTMTemplate, bool) {
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(req.Body)
+	defer readerPool.Put(reader)
+
+	var reqDto models.RequestUTMTemplate
+	if err := json.NewDecoder(reader).Decode(&reqDto); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Body is invalidate!"))
+		return nil, false
+	}
+
+	reqDto.Name = strings.TrimSpace(reqDto.Name)
+	if reqDto.Name == "" || len(reqDto.Name) > maxTemplateFieldLength {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Template name is invalidate!"))
+		return nil, false
+	}
+
+	valid, empty := true, true
+	for _, p := range []*string{&reqDto.Source, &reqDto.Medium, &reqDto.Campaign, &reqDto.Content} {
+		*p = strings.TrimSpace(*p)
+		valid = valid && len(*p) <= maxTemplateFieldLength
+		empty = empty && *p == ""
+	}
+
+	if !valid || empty {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Template params is invalidate!"))
+		return nil, false
+	}
+	return &reqDto, true
+}
+
+// writeTemplateError записывает ответ с ошибкой операции над шаблоном UTM разметки
+// Возвращает true, если ошибки нет и обработку запроса нужно продолжить
+func writeTemplateError(res http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrUserUnauthorized):
+		res.WriteHeader(http.StatusUnauthorized)
+		_, _ = res.Write([]byte("User unauthorized!"))
+	case errors.Is(err, customError.ErrNotFound):
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Template by id not found!"))
+	case errors.Is(err, customError.ErrConflict):
+		res.WriteHeader(http.StatusConflict)
+		_, _ = res.Write([]byte("Template name already exists!"))
+	default:
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Template operation error!"))
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveTemplate(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	tests := []struct {
+		name    string
+		payload []byte
+		status  int
+	}{
+		{
+			name:    "body is invalidate",
+			payload: []byte("invalid json"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "name is empty",
+			payload: []byte("{\"name\":\" \",\"source\":\"newsletter\"}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "params is empty",
+			payload: []byte("{\"name\":\"mail\"}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "ok",
+			payload: []byte("{\"name\":\"mail\",\"source\":\" newsletter \",\"medium\":\"email\"}"),
+			status:  http.StatusCreated,
+		},
+		{
+			name:    "name already exists",
+			payload: []byte("{\"name\":\"mail\",\"source\":\"other\"}"),
+			status:  http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/user/templates", bytes.NewBuffer(tt.payload))
+			req = req.WithContext(customContext.SetContextUserID(req.Context(), userID))
+			w := httptest.NewRecorder()
+
+			tc.app.SaveTemplate(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusCreated {
+				var got models.UTMTemplate
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				assert.Equal(t, "mail", got.Name)
+				assert.Equal(t, "newsletter", got.Source)
+				assert.NotEqual(t, uuid.Nil, got.ID)
+			}
+		})
+	}
+}
+
+func TestUpdateTemplateByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	template, _ := tc.app.service.SaveTemplate(ctx, &models.RequestUTMTemplate{
+		Name:      "mail",
+		UTMParams: models.UTMParams{Source: "newsletter"},
+	})
+	_, _ = tc.app.service.SaveTemplate(ctx, &models.RequestUTMTemplate{
+		Name:      "social",
+		UTMParams: models.UTMParams{Source: "twitter"},
+	})
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		id      string
+		payload []byte
+		status  int
+	}{
+		{
+			name:    "id is invalidate",
+			userID:  ownerID,
+			id:      "not-uuid",
+			payload: []byte("{\"name\":\"mail\",\"source\":\"digest\"}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "template of another user",
+			userID:  uuid.New(),
+			id:      template.ID.String(),
+			payload: []byte("{\"name\":\"mail\",\"source\":\"digest\"}"),
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "name already exists",
+			userID:  ownerID,
+			id:      template.ID.String(),
+			payload: []byte("{\"name\":\"social\",\"source\":\"digest\"}"),
+			status:  http.StatusConflict,
+		},
+		{
+			name:    "ok",
+			userID:  ownerID,
+			id:      template.ID.String(),
+			payload: []byte("{\"name\":\"mail\",\"source\":\"digest\",\"campaign\":\"spring\"}"),
+			status:  http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, tc.app.URL+"api/user/templates/"+tt.id, bytes.NewBuffer(tt.payload))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.UpdateTemplateByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+
+	templates, _ := tc.app.service.GetTemplatesByUserID(ctx)
+	assert.Len(t, templates, 2)
+	assert.Equal(t, models.UTMParams{Source: "digest", Campaign: "spring"}, templates[0].UTMParams)
+	assert.Equal(t, template.CreatedAt, templates[0].CreatedAt)
+}
+
+func TestDeleteTemplateByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	template, _ := tc.app.service.SaveTemplate(ctx, &models.RequestUTMTemplate{
+		Name:      "mail",
+		UTMParams: models.UTMParams{Source: "newsletter"},
+	})
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		status int
+	}{
+		{
+			name:   "template of another user",
+			userID: uuid.New(),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ok",
+			userID: ownerID,
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "already deleted",
+			userID: ownerID,
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tc.app.URL+"api/user/templates/"+template.ID.String(), nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", template.ID.String())
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.DeleteTemplateByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestShortenAPITemplate(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	_, _ = tc.app.service.SaveTemplate(ctx, &models.RequestUTMTemplate{
+		Name:      "mail",
+		UTMParams: models.UTMParams{Source: "newsletter", Medium: "email", Campaign: "spring"},
+	})
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		payload string
+		status  int
+		want    string
+	}{
+		{
+			name:    "template of another user",
+			userID:  uuid.New(),
+			payload: "{\"url\":\"https://ya.ru/\",\"template\":\"mail\"}",
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "ok",
+			userID:  ownerID,
+			payload: "{\"url\":\"https://ya.ru/?utm_campaign=autumn\",\"template\":\"mail\"}",
+			status:  http.StatusCreated,
+			want:    "https://ya.ru/?utm_campaign=autumn&utm_medium=email&utm_source=newsletter",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten", bytes.NewBufferString(tt.payload))
+			req = req.WithContext(customContext.SetContextUserID(req.Context(), tt.userID))
+			w := httptest.NewRecorder()
+
+			tc.app.ShortenAPI(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusCreated {
+				link, err := tc.app.service.GetLinkByID(ctx, uuid.NewSHA1(uuid.NameSpaceURL, []byte(tt.want)))
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, link.OriginalURL)
+			}
+		})
+	}
+
+	payload := "[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"original_url\":\"https://go.dev/\",\"template\":\"mail\"}]"
+	req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/batch", bytes.NewBufferString(payload))
+	req = req.WithContext(customContext.SetContextUserID(req.Context(), ownerID))
+	w := httptest.NewRecorder()
+
+	tc.app.ShortenAPIBatch(w, req)
+
+	want := "https://go.dev/?utm_campaign=spring&utm_medium=email&utm_source=newsletter"
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), uuid.NewSHA1(uuid.NameSpaceURL, []byte(want)).String())
+}
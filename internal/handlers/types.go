@@ -8,13 +8,16 @@ import (
 	"testing"
 
 	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/geoip"
 	"github.com/IvanKondrashkov/go-shortener/internal/logger"
 	api "github.com/IvanKondrashkov/go-shortener/internal/service"
+	"github.com/IvanKondrashkov/go-shortener/internal/service/limiter"
 	"github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
 	"github.com/IvanKondrashkov/go-shortener/internal/service/middleware/compress"
 	customLogger "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/logger"
 	"github.com/IvanKondrashkov/go-shortener/internal/service/worker"
 	"github.com/IvanKondrashkov/go-shortener/internal/storage/mem"
+	"github.com/IvanKondrashkov/go-shortener/internal/stream"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -25,6 +28,15 @@ const (
 	bufWriterSize = 128 * 1024 // 128KB размер буфера для записи
 )
 
+// Параметры пагинации списка URL пользователя
+const (
+	defaultPageLimit = 100  // Размер страницы по умолчанию
+	maxPageLimit     = 1000 // Максимальный размер страницы
+)
+
+// linkAttemptsFactor определяет, во сколько раз лимит неверных паролей для ссылки больше лимита для клиента
+const linkAttemptsFactor = 10
+
 // Пул буферизированных ридеров и райтеров для повторного использования
 var (
 	readerPool = sync.Pool{
@@ -47,21 +59,74 @@ type Service interface {
 	ShortenAPI(res http.ResponseWriter, req *http.Request)
 	// Пакетное сокращение URL
 	ShortenAPIBatch(res http.ResponseWriter, req *http.Request)
+	// Потоковый импорт URL (NDJSON, CSV)
+	ShortenAPIImport(res http.ResponseWriter, req *http.Request)
+	// Импорт экспорта другого сервиса сокращения URL (Bitly, YOURLS, Kutt)
+	ShortenAPIImportSource(res http.ResponseWriter, req *http.Request)
 	// Получение оригинального URL по ID
 	GetURLByID(res http.ResponseWriter, req *http.Request)
+	// Проверка пароля защищенной ссылки
+	PostPasswordByID(res http.ResponseWriter, req *http.Request)
+	// Получение страницы предпросмотра короткой ссылки
+	GetPreviewByID(res http.ResponseWriter, req *http.Request)
+	// Получение QR-кода короткой ссылки
+	GetQRByID(res http.ResponseWriter, req *http.Request)
 	// Получение всех URL пользователя
 	GetAllURLByUserID(res http.ResponseWriter, req *http.Request)
+	// Экспорт всех URL пользователя (JSON, NDJSON, CSV)
+	ExportURLByUserID(res http.ResponseWriter, req *http.Request)
 	// Пакетное удаление URL пользователя
 	DeleteBatchByUserID(res http.ResponseWriter, req *http.Request)
+	// Изменение оригинального URL пользователя
+	UpdateURLByUserID(res http.ResponseWriter, req *http.Request)
+	// Получение истории изменений URL пользователя
+	GetHistoryByUserID(res http.ResponseWriter, req *http.Request)
+	// Поток SSE событий ссылок пользователя
+	StreamByUserID(res http.ResponseWriter, req *http.Request)
+	// Создание папки пользователя
+	SaveFolder(res http.ResponseWriter, req *http.Request)
+	// Получение папок пользователя
+	GetFoldersByUserID(res http.ResponseWriter, req *http.Request)
+	// Переименование папки пользователя
+	UpdateFolderByUserID(res http.ResponseWriter, req *http.Request)
+	// Удаление папки пользователя
+	DeleteFolderByUserID(res http.ResponseWriter, req *http.Request)
+	// Создание шаблона UTM разметки пользователя
+	SaveTemplate(res http.ResponseWriter, req *http.Request)
+	// Получение шаблонов UTM разметки пользователя
+	GetTemplatesByUserID(res http.ResponseWriter, req *http.Request)
+	// Изменение шаблона UTM разметки пользователя
+	UpdateTemplateByUserID(res http.ResponseWriter, req *http.Request)
+	// Удаление шаблона UTM разметки пользователя
+	DeleteTemplateByUserID(res http.ResponseWriter, req *http.Request)
+	// Создание webhook пользователя
+	SaveWebhook(res http.ResponseWriter, req *http.Request)
+	// Получение webhook пользователя
+	GetWebhooksByUserID(res http.ResponseWriter, req *http.Request)
+	// Ротация секрета webhook пользователя
+	RotateWebhookSecret(res http.ResponseWriter, req *http.Request)
+	// Удаление webhook пользователя
+	DeleteWebhookByUserID(res http.ResponseWriter, req *http.Request)
+	// Получение журнала доставок webhook пользователя
+	GetWebhookDeliveries(res http.ResponseWriter, req *http.Request)
+	// Отключение URL администратором
+	DisableURLByID(res http.ResponseWriter, req *http.Request)
+	// Снятие отключения URL администратором
+	EnableURLByID(res http.ResponseWriter, req *http.Request)
+	// Получение счетчиков кэша администратором
+	GetCacheStats(res http.ResponseWriter, req *http.Request)
 	// Пакетное удаление URL пользователя
 	Ping(res http.ResponseWriter, req *http.Request)
 }
 
 // App представляет основное приложение с сервисом и воркером
 type App struct {
-	URL     string         // Базовый URL сервиса
-	service *api.Service   // Сервис для работы с URL
-	worker  *worker.Worker // Воркер для фоновых задач
+	URL             string           // Базовый URL сервиса
+	service         *api.Service     // Сервис для работы с URL
+	worker          *worker.Worker   // Воркер для фоновых задач
+	passwordClients *limiter.Limiter // Ограничитель неверных паролей по клиенту и ссылке
+	passwordLinks   *limiter.Limiter // Ограничитель неверных паролей по ссылке
+	geoIP           *geoip.DB        // База GeoIP для правил по стране (может быть nil)
 }
 
 // Handler обрабатывает HTTP-запросы
@@ -77,11 +142,15 @@ type Suite struct {
 }
 
 // NewApp создает новый экземпляр App
-func NewApp(s *api.Service, w *worker.Worker) *App {
+// База GeoIP g может быть nil, тогда правила по стране не срабатывают
+func NewApp(s *api.Service, w *worker.Worker, g *geoip.DB) *App {
 	return &App{
-		URL:     config.URL,
-		service: s,
-		worker:  w,
+		URL:             config.URL,
+		service:         s,
+		worker:          w,
+		passwordClients: limiter.NewLimiter(config.PasswordMaxAttempts, config.PasswordLockout),
+		passwordLinks:   limiter.NewLimiter(config.PasswordMaxAttempts*linkAttemptsFactor, config.PasswordLockout),
+		geoIP:           g,
 	}
 }
 
@@ -101,13 +170,42 @@ func NewRouter(h *Handler) *chi.Mux {
 	r.Route(`/`, func(r chi.Router) {
 		r.Post(`/`, h.service.ShortenURL)
 		r.Get(`/{id}`, h.service.GetURLByID)
+		r.Head(`/{id}`, h.service.GetURLByID)
+		r.Post(`/{id}`, h.service.PostPasswordByID)
+		r.Get(`/{id}/*`, h.service.GetURLByID)
+		r.Head(`/{id}/*`, h.service.GetURLByID)
+		r.Post(`/{id}/*`, h.service.PostPasswordByID)
+		r.Get(`/{id}+`, h.service.GetPreviewByID)
+		r.Get(`/{id}/qr`, h.service.GetQRByID)
 		r.Get(`/ping`, h.service.Ping)
 	})
 	r.Route(`/api`, func(r chi.Router) {
 		r.Post(`/shorten`, h.service.ShortenAPI)
 		r.Post(`/shorten/batch`, h.service.ShortenAPIBatch)
+		r.Post(`/shorten/import`, h.service.ShortenAPIImport)
+		r.Post(`/shorten/import/{source}`, h.service.ShortenAPIImportSource)
 		r.Get(`/user/urls`, h.service.GetAllURLByUserID)
+		r.Get(`/user/urls/export`, h.service.ExportURLByUserID)
 		r.Delete(`/user/urls`, h.service.DeleteBatchByUserID)
+		r.Patch(`/user/urls/{id}`, h.service.UpdateURLByUserID)
+		r.Get(`/user/urls/{id}/history`, h.service.GetHistoryByUserID)
+		r.Get(`/user/stream`, h.service.StreamByUserID)
+		r.Post(`/user/folders`, h.service.SaveFolder)
+		r.Get(`/user/folders`, h.service.GetFoldersByUserID)
+		r.Patch(`/user/folders/{id}`, h.service.UpdateFolderByUserID)
+		r.Delete(`/user/folders/{id}`, h.service.DeleteFolderByUserID)
+		r.Post(`/user/templates`, h.service.SaveTemplate)
+		r.Get(`/user/templates`, h.service.GetTemplatesByUserID)
+		r.Patch(`/user/templates/{id}`, h.service.UpdateTemplateByUserID)
+		r.Delete(`/user/templates/{id}`, h.service.DeleteTemplateByUserID)
+		r.Post(`/user/webhooks`, h.service.SaveWebhook)
+		r.Get(`/user/webhooks`, h.service.GetWebhooksByUserID)
+		r.Post(`/user/webhooks/{id}/rotate`, h.service.RotateWebhookSecret)
+		r.Delete(`/user/webhooks/{id}`, h.service.DeleteWebhookByUserID)
+		r.Get(`/user/webhooks/{id}/deliveries`, h.service.GetWebhookDeliveries)
+		r.Post(`/admin/urls/{id}/disable`, h.service.DisableURLByID)
+		r.Post(`/admin/urls/{id}/enable`, h.service.EnableURLByID)
+		r.Get(`/admin/cache/stats`, h.service.GetCacheStats)
 	})
 	return r
 }
@@ -130,13 +228,9 @@ func NewSuite(t *testing.T) *Suite {
 	zl, _ := logger.NewZapLogger(config.LogLevel)
 	newRepository := mem.NewRepository(zl)
 	newRunner := newRepository
-	newService := api.NewService(zl, newRunner, newRepository)
-	newWorker := worker.NewWorker(context.Background(), config.WorkerCount, zl, newService)
-	app := &App{
-		URL:     config.URL,
-		service: newService,
-		worker:  newWorker,
-	}
+	newService := api.NewService(zl, newRunner, newRepository, nil, nil, stream.NewHub(config.StreamBuffer, config.StreamQueue))
+	newWorker := worker.NewWorker(context.Background(), config.WorkerCount, zl, newService, nil, nil)
+	app := NewApp(newService, newWorker, nil)
 
 	return &Suite{
 		T:   t,
@@ -7,9 +7,14 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
 	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -22,11 +27,20 @@ import (
 // @Accept plain
 // @Produce plain
 // @Param url body string true "Оригинальный URL для сокращения"
+// @Param Idempotency-Key header string false "Ключ повтора: ответ на первый запрос с ключом повторяется в течение IDEMPOTENCY_TTL"
 // @Success 201 {string} string "Сокращенный URL"
 // @Success 409 {string} string "URL уже был сокращен ранее"
 // @Failure 400 {string} string "Неверный формат URL"
+// @Failure 403 {string} string "Адрес назначения запрещен, причина в заголовке X-Block-Reason"
+// @Failure 409 {string} string "Запрос с тем же Idempotency-Key еще выполняется"
+// @Failure 422 {string} string "Idempotency-Key уже использован для запроса с другим телом"
 // @Router / [post]
 func (app *App) ShortenURL(res http.ResponseWriter, req *http.Request) {
+	app.idempotent(res, req, app.shortenURL)
+}
+
+// shortenURL сокращает URL из тела запроса text/plain
+func (app *App) shortenURL(res http.ResponseWriter, req *http.Request) {
 	res.Header().Set("Content-Type", "text/plain")
 
 	reader := readerPool.Get().(*bufio.Reader)
@@ -48,28 +62,42 @@ func (app *App) ShortenURL(res http.ResponseWriter, req *http.Request) {
 	}
 
 	id, err := app.service.Save(req.Context(), uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String())), u)
+	if writeBlocked(res, err) {
+		return
+	}
+
 	if err != nil && errors.Is(err, customError.ErrConflict) {
 		res.WriteHeader(http.StatusConflict)
 		_, _ = res.Write([]byte(app.URL + id.String()))
 		return
 	}
 
+	app.fetchPage(id, u.String())
 	res.WriteHeader(http.StatusCreated)
 	_, _ = res.Write([]byte(app.URL + id.String()))
 }
 
 // ShortenAPI обрабатывает JSON запрос на сокращение URL
 // @Summary Сократить URL (JSON)
-// @Description Создает короткую версию переданного URL (JSON формат)
+// @Description Создает короткую версию переданного URL (JSON формат) с необязательными названием, тегами, заметкой и папкой
 // @Tags URL
 // @Accept json
 // @Produce json
-// @Param input body models.RequestShortenAPI true "Запрос на сокращение URL"
+// @Param input body models.RequestShortenAPI true "Запрос на сокращение URL (qr: true добавляет QR-код в ответ, password защищает ссылку паролем, template добавляет UTM параметры шаблона)"
+// @Param Idempotency-Key header string false "Ключ повтора: ответ на первый запрос с ключом повторяется в течение IDEMPOTENCY_TTL"
 // @Success 201 {object} models.ResponseShortenAPI
 // @Success 409 {object} models.ResponseShortenAPI
-// @Failure 400 {string} string "Неверный формат запроса"
+// @Failure 400 {string} string "Неверный формат запроса, папка, пароль, шаблон, код перенаправления, политика передачи, правила, варианты или окно активности"
+// @Failure 403 {string} string "Адрес назначения запрещен, причина в заголовке X-Block-Reason"
+// @Failure 409 {string} string "Запрос с тем же Idempotency-Key еще выполняется"
+// @Failure 422 {string} string "Idempotency-Key уже использован для запроса с другим телом"
 // @Router /api/shorten [post]
 func (app *App) ShortenAPI(res http.ResponseWriter, req *http.Request) {
+	app.idempotent(res, req, app.shortenAPI)
+}
+
+// shortenAPI сокращает URL из JSON запроса с атрибутами ссылки
+func (app *App) shortenAPI(res http.ResponseWriter, req *http.Request) {
 	res.Header().Set("Content-Type", "application/json")
 
 	reader := readerPool.Get().(*bufio.Reader)
@@ -90,11 +118,86 @@ func (app *App) ShortenAPI(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	id, err := app.service.Save(req.Context(), uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String())), u)
+	if reqDto.Template != "" {
+		u, err = app.service.ApplyTemplate(req.Context(), reqDto.Template, u)
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			_, _ = res.Write([]byte("Template is invalidate!"))
+			return
+		}
+	}
+
+	var passwordHash string
+	if reqDto.Password != "" {
+		passwordHash, err = service.HashPassword(reqDto.Password)
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			_, _ = res.Write([]byte("Password is invalidate!"))
+			return
+		}
+	}
+
+	id, err := app.service.SaveLink(req.Context(), &models.Link{
+		ID:           uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String())),
+		OriginalURL:  u.String(),
+		CreatedAt:    time.Now().UTC(),
+		PasswordHash: passwordHash,
+		LinkMeta:     reqDto.LinkMeta,
+	})
+	if err != nil && errors.Is(err, service.ErrFolderNotValid) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Folder is invalidate!"))
+		return
+	}
+
+	if err != nil && errors.Is(err, service.ErrRedirectCodeNotValid) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Redirect code is invalidate!"))
+		return
+	}
+
+	if err != nil && errors.Is(err, service.ErrPassthroughNotValid) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Passthrough is invalidate!"))
+		return
+	}
+
+	if err != nil && errors.Is(err, service.ErrRulesNotValid) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Rules is invalidate!"))
+		return
+	}
+
+	if err != nil && errors.Is(err, service.ErrVariantsNotValid) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Variants is invalidate!"))
+		return
+	}
+
+	if err != nil && errors.Is(err, service.ErrScheduleNotValid) {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Schedule is invalidate!"))
+		return
+	}
+
+	if writeBlocked(res, err) {
+		return
+	}
+
 	respDto := models.ResponseShortenAPI{
 		Result: app.URL + id.String(),
 	}
 
+	if reqDto.QR {
+		dataURI, qrErr := app.qrDataURI(id)
+		if qrErr != nil {
+			res.WriteHeader(http.StatusInternalServerError)
+			_, _ = res.Write([]byte("QR code error!"))
+			return
+		}
+		respDto.QR = dataURI
+	}
+
 	writer := writerPool.Get().(*bufio.Writer)
 	writer.Reset(res)
 	defer func() {
@@ -112,6 +215,7 @@ func (app *App) ShortenAPI(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	app.fetchPage(id, u.String())
 	res.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(writer).Encode(respDto); err != nil {
 		res.WriteHeader(http.StatusBadRequest)
@@ -122,15 +226,28 @@ func (app *App) ShortenAPI(res http.ResponseWriter, req *http.Request) {
 
 // ShortenAPIBatch обрабатывает пакетный запрос на сокращение URL
 // @Summary Пакетное сокращение URL
-// @Description Создает короткие версии для списка URL
+// @Description Создает короткие версии для списка URL и возвращает результат по каждому элементу в поле status:
+// @Description created - URL сохранен, existing - URL уже был сокращен ранее, invalid - URL или атрибуты невалидны,
+// @Description blocked - адрес назначения запрещен. Для invalid и blocked причина возвращается в поле error.
+// @Description Если сохранены все элементы, возвращается 201, иначе 207.
 // @Tags URL
 // @Accept json
 // @Produce json
-// @Param input body []models.RequestShortenAPIBatch true "Список URL для сокращения"
+// @Param input body []models.RequestShortenAPIBatch true "Список URL для сокращения (qr: true добавляет QR-код в ответ, template добавляет UTM параметры шаблона)"
+// @Param Idempotency-Key header string false "Ключ повтора: ответ на первый запрос с ключом повторяется в течение IDEMPOTENCY_TTL"
 // @Success 201 {object} []models.ResponseShortenAPIBatch
-// @Failure 400 {string} string "Неверный формат запроса"
+// @Success 207 {object} []models.ResponseShortenAPIBatch
+// @Failure 400 {string} string "Неверный формат запроса или пустой пакет"
+// @Failure 409 {string} string "Запрос с тем же Idempotency-Key еще выполняется"
+// @Failure 422 {string} string "Idempotency-Key уже использован для запроса с другим телом"
+// @Failure 500 {string} string "Ошибка сохранения пакета"
 // @Router /api/shorten/batch [post]
 func (app *App) ShortenAPIBatch(res http.ResponseWriter, req *http.Request) {
+	app.idempotent(res, req, app.shortenAPIBatch)
+}
+
+// shortenAPIBatch сокращает список URL из JSON запроса
+func (app *App) shortenAPIBatch(res http.ResponseWriter, req *http.Request) {
 	res.Header().Set("Content-Type", "application/json")
 
 	reader := readerPool.Get().(*bufio.Reader)
@@ -144,20 +261,43 @@ func (app *App) ShortenAPIBatch(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	err := app.service.SaveBatch(req.Context(), reqDto)
-	if err != nil {
+	respDto, err := app.service.SaveBatch(req.Context(), reqDto)
+	if err != nil && errors.Is(err, customError.ErrBatchIsEmpty) {
 		res.WriteHeader(http.StatusBadRequest)
-		_, _ = res.Write([]byte("Save batch error!"))
+		_, _ = res.Write([]byte("Batch is empty!"))
 		return
 	}
 
-	respDto, err := models.RequestBatchToResponseBatch(reqDto)
 	if err != nil {
-		res.WriteHeader(http.StatusBadRequest)
-		_, _ = res.Write([]byte("Entity mapping is incorrect!"))
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Save batch error!"))
 		return
 	}
 
+	status := http.StatusCreated
+	for i, r := range respDto {
+		if r.Status != models.BatchStatusCreated {
+			status = http.StatusMultiStatus
+		}
+		if r.ShortURL == "" {
+			continue
+		}
+
+		id := reqDto[i].ID
+		if r.Status == models.BatchStatusCreated {
+			app.fetchPage(id, reqDto[i].OriginalURL)
+		}
+		if !reqDto[i].QR {
+			continue
+		}
+		r.QR, err = app.qrDataURI(id)
+		if err != nil {
+			res.WriteHeader(http.StatusInternalServerError)
+			_, _ = res.Write([]byte("QR code error!"))
+			return
+		}
+	}
+
 	writer := writerPool.Get().(*bufio.Writer)
 	writer.Reset(res)
 	defer func() {
@@ -165,7 +305,7 @@ func (app *App) ShortenAPIBatch(res http.ResponseWriter, req *http.Request) {
 		writerPool.Put(writer)
 	}()
 
-	res.WriteHeader(http.StatusCreated)
+	res.WriteHeader(status)
 	if err := json.NewEncoder(writer).Encode(respDto); err != nil {
 		res.WriteHeader(http.StatusBadRequest)
 		_, _ = res.Write([]byte("Response is invalidate!"))
@@ -175,32 +315,103 @@ func (app *App) ShortenAPIBatch(res http.ResponseWriter, req *http.Request) {
 
 // GetURLByID возвращает оригинальный URL по его ID
 // @Summary Получить оригинальный URL
-// @Description Перенаправляет на оригинальный URL по его сокращенному ID
+// @Description Перенаправляет на оригинальный URL по его сокращенному ID и учитывает переход.
+// @Description Для ссылок с включенным режимом interstitial показывает страницу-предупреждение с кнопкой продолжения.
+// @Description Переход по такой ссылке учитывается при продолжении (параметр _continue), а не при показе страницы.
+// @Description Для ссылок с паролем без действующего доступа показывает форму ввода пароля.
+// @Description Код перенаправления задается ссылкой или глобально (config.RedirectCode). Постоянные перенаправления
+// @Description (301, 308) кэшируются клиентами, временные (302, 303, 307) и перенаправления ссылок с паролем
+// @Description или правилами запрещено кэшировать.
+// @Description HEAD запрос возвращает те же заголовки без учета перехода.
+// @Description Адрес назначения выбирается первым сработавшим правилом ссылки по платформе (User-Agent),
+// @Description языку (Accept-Language) или стране клиента (база GeoIP). Если правила не сработали и у ссылки есть
+// @Description варианты, адрес выбирается случайно пропорционально весу варианта и закрепляется за посетителем cookie,
+// @Description иначе используется оригинальный URL. Перенаправления на варианты не кэшируются.
+// @Description Вне окна активности ссылки (active_from, active_until) выполняется перенаправление на резервный адрес,
+// @Description а без него возвращается 404 до начала окна и 410 после его окончания.
+// @Description Дополнительный путь и параметры запроса передаются в адрес назначения согласно политике ссылки
+// @Description (append, override или ignore).
+// @Description Адрес назначения повторно проверяется по списку блокировки и эвристикам перед перенаправлением.
 // @Tags URL
-// @Param id path string true "ID сокращенного URL"
-// @Success 307 "Перенаправление на оригинальный URL"
-// @Failure 404 {string} string "URL не найден"
-// @Failure 410 {string} string "URL был удален"
+// @Param id path string true "ID сокращенного URL или короткое имя импортированной ссылки"
+// @Param path path string false "Дополнительный путь"
+// @Success 200 {string} string "HTML страница-предупреждение или форма ввода пароля"
+// @Success 301 "Постоянное перенаправление на оригинальный URL"
+// @Success 302 "Временное перенаправление на оригинальный URL"
+// @Success 303 "Перенаправление на оригинальный URL"
+// @Success 307 "Временное перенаправление на оригинальный URL"
+// @Success 308 "Постоянное перенаправление на оригинальный URL"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 403 {string} string "Адрес назначения запрещен, причина в заголовке X-Block-Reason"
+// @Failure 404 {string} string "URL не найден или еще не активен"
+// @Failure 410 {string} string "URL был удален или окно активности закончилось"
+// @Failure 451 {string} string "URL отключен администратором, причина в заголовке X-Block-Reason"
 // @Router /{id} [get]
+// @Router /{id} [head]
+// @Router /{id}/{path} [get]
 func (app *App) GetURLByID(res http.ResponseWriter, req *http.Request) {
-	id := chi.URLParam(req, "id")
+	id, ok := app.linkID(res, req)
+	if !ok {
+		return
+	}
 
-	u, err := app.service.GetByID(req.Context(), uuid.MustParse(id))
-	if err != nil && errors.Is(err, customError.ErrNotFound) {
-		res.WriteHeader(http.StatusNotFound)
-		_, _ = res.Write([]byte("Url by id not found!"))
+	link, ok := app.getLink(res, req, id)
+	if !ok {
+		return
+	}
+	if state := link.ScheduleStateAt(time.Now()); state == models.ScheduleStatePending || state == models.ScheduleStateExpired {
+		app.writeInactive(res, link, state)
+		return
+	}
+	if link.PasswordHash != "" && !app.hasLinkAccess(req, id) {
+		app.writePasswordPage(res, req, http.StatusOK, link, "")
 		return
 	}
 
-	if err != nil && errors.Is(err, customError.ErrDeleteAccepted) {
-		res.WriteHeader(http.StatusGone)
-		_, _ = res.Write([]byte("Delete url accepted!"))
+	query := req.URL.Query()
+	_, confirmed := query[continueParam]
+	query.Del(continueParam)
+
+	var variant string
+	target, matched := service.TargetURL(link, app.client(req))
+	if !matched && len(link.Variants) > 0 {
+		if i := app.pickVariant(res, req, link); i >= 0 {
+			target, variant = link.Variants[i].URL, link.Variants[i].URL
+		}
+	}
+	link.OriginalURL = service.PassthroughURL(link, target, chi.URLParam(req, "*"), query)
+	if writeBlocked(res, app.service.CheckURL(link.OriginalURL)) {
 		return
 	}
+	interstitial := link.Interstitial && !confirmed
+	if req.Method != http.MethodHead && !interstitial {
+		_ = app.service.AddClick(req.Context(), id, variant)
+	}
+	if len(link.Rules) > 0 {
+		res.Header().Set("Vary", "User-Agent, Accept-Language")
+	}
+
+	if interstitial {
+		app.writeInterstitial(res, req, link)
+		return
+	}
+
+	code := link.RedirectCode
+	if code == 0 {
+		code = config.RedirectCode
+	}
 
+	// Перенаправление ссылки с паролем или правилами зависит от посетителя (cookie доступа, страна по IP),
+	// поэтому его нельзя хранить в общих кэшах
+	if models.IsPermanentRedirect(code) && len(link.Variants) == 0 && link.ActiveUntil == nil &&
+		link.PasswordHash == "" && len(link.Rules) == 0 {
+		res.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(config.RedirectMaxAge.Seconds())))
+	} else {
+		res.Header().Set("Cache-Control", "no-store")
+	}
 	res.Header().Set("Content-Type", "text/plain")
-	res.Header().Set("Location", u.String())
-	res.WriteHeader(http.StatusTemporaryRedirect)
+	res.Header().Set("Location", link.OriginalURL)
+	res.WriteHeader(code)
 }
 
 // Ping проверяет доступность базы данных
@@ -220,3 +431,79 @@ func (app *App) Ping(res http.ResponseWriter, req *http.Request) {
 
 	res.WriteHeader(http.StatusOK)
 }
+
+// client возвращает параметры клиента для выбора адреса назначения по правилам ссылки
+// Страна определяется по IP адресу клиента, если база GeoIP загружена
+func (app *App) client(req *http.Request) *service.Client {
+	var country string
+	if addr, err := netip.ParseAddr(clientIP(req)); err == nil {
+		country = app.geoIP.Country(addr)
+	}
+
+	return &service.Client{
+		UserAgent:      req.UserAgent(),
+		AcceptLanguage: req.Header.Get("Accept-Language"),
+		Country:        country,
+	}
+}
+
+// pickVariant выбирает вариант адреса назначения ссылки и закрепляет его за посетителем cookie
+// Cookie привязан к UUID ссылки именем, а не путем, чтобы действовать и для UUID, и для короткого имени ссылки
+// Возвращает индекс выбранного варианта или -1, если ни один вариант не может быть выдан
+func (app *App) pickVariant(res http.ResponseWriter, req *http.Request, link *models.Link) int {
+	name := "variant_" + link.ID.String()
+	sticky := -1
+	if cookie, err := req.Cookie(name); err == nil {
+		if i, err := strconv.Atoi(cookie.Value); err == nil {
+			sticky = i
+		}
+	}
+
+	i := service.PickVariant(link.Variants, sticky)
+	if i >= 0 && i != sticky {
+		http.SetCookie(res, &http.Cookie{
+			Name:     name,
+			Value:    strconv.Itoa(i),
+			Path:     "/",
+			MaxAge:   int(config.VariantTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   config.EnableHTTPS,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return i
+}
+
+// writeInactive записывает ответ для ссылки вне окна активности
+// При заданном резервном адресе выполняется временное перенаправление на него без учета перехода
+func (app *App) writeInactive(res http.ResponseWriter, link *models.Link, state string) {
+	res.Header().Set("Cache-Control", "no-store")
+	if link.FallbackURL != "" {
+		if writeBlocked(res, app.service.CheckURL(link.FallbackURL)) {
+			return
+		}
+		res.Header().Set("Content-Type", "text/plain")
+		res.Header().Set("Location", link.FallbackURL)
+		res.WriteHeader(http.StatusTemporaryRedirect)
+		return
+	}
+
+	if state == models.ScheduleStatePending {
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("URL is not active yet!"))
+		return
+	}
+	res.WriteHeader(http.StatusGone)
+	_, _ = res.Write([]byte("URL is expired!"))
+}
+
+// fetchPage ставит загрузку метаданных страницы назначения в очередь воркера
+// Принимает:
+// - id: UUID сокращенного URL
+// - originalURL: адрес назначения
+func (app *App) fetchPage(id uuid.UUID, originalURL string) {
+	if app.worker == nil {
+		return
+	}
+	app.worker.SendFetchPageRequest(models.FetchEvent{ID: id, URL: originalURL})
+}
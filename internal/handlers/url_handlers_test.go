@@ -3,18 +3,29 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/geoip"
 	"github.com/IvanKondrashkov/go-shortener/internal/handlers/mock"
+	"github.com/IvanKondrashkov/go-shortener/internal/logger"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestShortenURL(t *testing.T) {
@@ -67,12 +78,30 @@ func TestShortenAPI(t *testing.T) {
 			status:  http.StatusBadRequest,
 			want:    []byte("Url is invalidate!"),
 		},
+		{
+			name:    "folder is invalidate",
+			payload: []byte("{\"url\":\"https://ya.ru/\",\"folder_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\"}"),
+			status:  http.StatusBadRequest,
+			want:    []byte("Folder is invalidate!"),
+		},
+		{
+			name:    "redirect code is invalidate",
+			payload: []byte("{\"url\":\"https://ya.ru/\",\"redirect_code\":200}"),
+			status:  http.StatusBadRequest,
+			want:    []byte("Redirect code is invalidate!"),
+		},
 		{
 			name:    "ok",
 			payload: []byte("{\"url\":\"https://ya.ru/\"}"),
 			status:  http.StatusCreated,
 			want:    []byte("{\"result\":\"" + (tc.app.URL + uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/")).String()) + "\"}\n"),
 		},
+		{
+			name:    "ok with meta",
+			payload: []byte("{\"url\":\"https://go.dev/\",\"title\":\"Go\",\"tags\":[\" Lang \",\"lang\"],\"note\":\"docs\"}"),
+			status:  http.StatusCreated,
+			want:    []byte("{\"result\":\"" + (tc.app.URL + uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://go.dev/")).String()) + "\"}\n"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -99,14 +128,20 @@ func TestShortenAPIBatch(t *testing.T) {
 		{
 			name:    "is invalidate url",
 			payload: []byte("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"original_url\":\"://ya.ru/\"}]"),
+			status:  http.StatusMultiStatus,
+			want:    []byte("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"status\":\"invalid\",\"error\":\"url is invalidate\"}]\n"),
+		},
+		{
+			name:    "batch is empty",
+			payload: []byte("[]"),
 			status:  http.StatusBadRequest,
-			want:    []byte("Save batch error!"),
+			want:    []byte("Batch is empty!"),
 		},
 		{
 			name:    "ok",
 			payload: []byte("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"original_url\":\"https://ya.ru/\"}]"),
 			status:  http.StatusCreated,
-			want:    []byte("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"short_url\":\"" + tc.app.URL + "eefbcef4-3940-5a38-b2f0-877152a6d470\"}]\n"),
+			want:    []byte("[{\"correlation_id\":\"eefbcef4-3940-5a38-b2f0-877152a6d470\",\"short_url\":\"" + tc.app.URL + "eefbcef4-3940-5a38-b2f0-877152a6d470\",\"status\":\"created\"}]\n"),
 		},
 	}
 	for _, tt := range tests {
@@ -123,6 +158,55 @@ func TestShortenAPIBatch(t *testing.T) {
 	}
 }
 
+func TestShortenAPIBatchPartial(t *testing.T) {
+	tc := NewSuite(t)
+	ctx := customContext.SetContextUserID(context.Background(), uuid.New())
+	_, err := tc.app.service.Save(ctx, uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/")), &url.URL{Scheme: "https", Host: "ya.ru", Path: "/"})
+	require.NoError(t, err)
+
+	payload := []byte(`[
+		{"correlation_id":"00000000-0000-0000-0000-000000000001","original_url":"https://go.dev/"},
+		{"correlation_id":"00000000-0000-0000-0000-000000000002","original_url":"https://ya.ru/"},
+		{"correlation_id":"00000000-0000-0000-0000-000000000003","original_url":"https://go.dev/doc","rules":[{"url":"https://go.dev/"}]},
+		{"correlation_id":"00000000-0000-0000-0000-000000000004","original_url":"http://127.0.0.1/admin"},
+		{"correlation_id":"00000000-0000-0000-0000-000000000005","original_url":"https://go.dev/"},
+		{"correlation_id":"00000000-0000-0000-0000-000000000006","original_url":"https://go.dev/blog","template":"unknown"}
+	]`)
+	req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten/batch", bytes.NewBuffer(payload))
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	tc.app.ShortenAPIBatch(w, req)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	var got []models.ResponseShortenAPIBatch
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Len(t, got, 6)
+
+	want := []struct {
+		status string
+		err    string
+	}{
+		{status: models.BatchStatusCreated},
+		{status: models.BatchStatusExisting},
+		{status: models.BatchStatusInvalid, err: "rules is invalidate"},
+		{status: models.BatchStatusBlocked},
+		{status: models.BatchStatusExisting},
+		{status: models.BatchStatusInvalid, err: "template is invalidate"},
+	}
+	for i, item := range got {
+		assert.Equal(t, uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1)), item.CorrelationID)
+		assert.Equal(t, want[i].status, item.Status, "item %d", i+1)
+		if want[i].err != "" {
+			assert.Equal(t, want[i].err, item.Error, "item %d", i+1)
+		}
+	}
+	assert.Equal(t, tc.app.URL+uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/")).String(), got[1].ShortURL)
+	assert.Equal(t, got[0].ShortURL, got[4].ShortURL)
+	assert.NotEmpty(t, got[3].Error)
+	assert.Empty(t, got[3].ShortURL)
+}
+
 func TestGetURLByID(t *testing.T) {
 	tc := NewSuite(t)
 	tests := []struct {
@@ -133,7 +217,7 @@ func TestGetURLByID(t *testing.T) {
 	}{
 		{
 			name:   "id not found",
-			status: http.StatusGone,
+			status: http.StatusNotFound,
 			id:     uuid.New(),
 			want:   "Url by id not found!",
 		},
@@ -169,6 +253,420 @@ func TestGetURLByID(t *testing.T) {
 	}
 }
 
+func TestGetURLByIDRedirectCode(t *testing.T) {
+	tc := NewSuite(t)
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		code     int
+		status   int
+		cache    string
+		clicks   int64
+		rules    []models.TargetRule
+		password string
+	}{
+		{
+			name:   "default",
+			method: http.MethodGet,
+			url:    "https://ya.ru/",
+			status: http.StatusTemporaryRedirect,
+			cache:  "no-store",
+			clicks: 1,
+		},
+		{
+			name:   "found",
+			method: http.MethodGet,
+			url:    "https://go.dev/",
+			code:   http.StatusFound,
+			status: http.StatusFound,
+			cache:  "no-store",
+			clicks: 1,
+		},
+		{
+			name:   "moved permanently",
+			method: http.MethodGet,
+			url:    "https://pkg.go.dev/",
+			code:   http.StatusMovedPermanently,
+			status: http.StatusMovedPermanently,
+			cache:  "public, max-age=86400",
+			clicks: 1,
+		},
+		{
+			name:   "head",
+			method: http.MethodHead,
+			url:    "https://go.dev/blog/",
+			code:   http.StatusPermanentRedirect,
+			status: http.StatusPermanentRedirect,
+			cache:  "public, max-age=86400",
+		},
+		{
+			name:   "moved permanently with rules",
+			method: http.MethodGet,
+			url:    "https://go.dev/doc/",
+			code:   http.StatusMovedPermanently,
+			status: http.StatusMovedPermanently,
+			cache:  "no-store",
+			clicks: 1,
+			rules:  []models.TargetRule{{Country: "ru", URL: "https://go.dev/ru/"}},
+		},
+		{
+			name:     "moved permanently with password",
+			method:   http.MethodGet,
+			url:      "https://go.dev/play/",
+			code:     http.StatusMovedPermanently,
+			status:   http.StatusMovedPermanently,
+			cache:    "no-store",
+			clicks:   1,
+			password: "secret",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(tt.url))
+			link := &models.Link{
+				ID:          id,
+				OriginalURL: tt.url,
+				LinkMeta:    models.LinkMeta{RedirectCode: tt.code, Rules: tt.rules},
+			}
+			if tt.password != "" {
+				link.PasswordHash, _ = service.HashPassword(tt.password)
+			}
+			_, err := tc.app.service.SaveLink(context.Background(), link)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(tt.method, tc.app.URL+id.String(), nil)
+			if tt.password != "" {
+				access := httptest.NewRecorder()
+				require.NoError(t, tc.app.setLinkAccess(access, id))
+				req.AddCookie(access.Result().Cookies()[0])
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.GetURLByID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.url, w.Header().Get("Location"))
+			assert.Equal(t, tt.cache, w.Header().Get("Cache-Control"))
+
+			link, _ = tc.app.service.GetLinkByID(context.Background(), id)
+			assert.Equal(t, tt.clicks, link.Clicks)
+		})
+	}
+}
+
+func TestGetURLByIDPassthrough(t *testing.T) {
+	tc := NewSuite(t)
+	tests := []struct {
+		name   string
+		url    string
+		policy string
+		path   string
+		want   string
+	}{
+		{
+			name: "default ignore",
+			url:  "https://ya.ru/search?lr=213",
+			path: "extra/path?utm_source=x",
+			want: "https://ya.ru/search?lr=213",
+		},
+		{
+			name:   "append",
+			url:    "https://go.dev/doc/?utm_source=site",
+			policy: models.PassthroughAppend,
+			path:   "effective_go?utm_source=x&utm_medium=email",
+			want:   "https://go.dev/doc/effective_go?utm_medium=email&utm_source=site&utm_source=x",
+		},
+		{
+			name:   "override",
+			url:    "https://pkg.go.dev/?utm_source=site#top",
+			policy: models.PassthroughOverride,
+			path:   "std/?utm_source=x",
+			want:   "https://pkg.go.dev/std/?utm_source=x#top",
+		},
+		{
+			name:   "path does not escape destination",
+			url:    "https://go.dev/blog/",
+			policy: models.PassthroughAppend,
+			path:   "../../admin",
+			want:   "https://go.dev/blog/admin",
+		},
+	}
+
+	zl, _ := logger.NewZapLogger(config.LogLevel)
+	router := NewRouter(NewHandler(zl, tc.app))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(tt.url))
+			_, _ = tc.app.service.SaveLink(context.Background(), &models.Link{
+				ID:          id,
+				OriginalURL: tt.url,
+				LinkMeta:    models.LinkMeta{Passthrough: tt.policy},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/"+id.String()+"/"+tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusTemporaryRedirect, w.Code)
+			assert.Equal(t, tt.want, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestGetURLByIDTargeting(t *testing.T) {
+	tc := NewSuite(t)
+	tc.app.geoIP, _ = geoip.Parse(strings.NewReader("5.3.0.0,5.3.255.255,RU\n"))
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://example.com/"))
+	_, err := tc.app.service.SaveLink(context.Background(), &models.Link{
+		ID:          id,
+		OriginalURL: "https://example.com/",
+		LinkMeta: models.LinkMeta{Rules: []models.TargetRule{
+			{Platform: "IOS", URL: "https://apps.apple.com/app"},
+			{Platform: models.PlatformAndroid, Language: "ru", URL: "https://play.google.com/app?hl=ru"},
+			{Platform: models.PlatformAndroid, URL: "https://play.google.com/app"},
+			{Country: "ru", URL: "https://example.ru/"},
+		}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		userAgent  string
+		language   string
+		remoteAddr string
+		want       string
+	}{
+		{
+			name:      "ios",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)",
+			want:      "https://apps.apple.com/app",
+		},
+		{
+			name:      "android with language",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8)",
+			language:  "en;q=0.5, ru-RU",
+			want:      "https://play.google.com/app?hl=ru",
+		},
+		{
+			name:      "android",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8)",
+			language:  "en-US,en;q=0.9",
+			want:      "https://play.google.com/app",
+		},
+		{
+			name:       "country",
+			userAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			remoteAddr: "5.3.1.1:4000",
+			want:       "https://example.ru/",
+		},
+		{
+			name:       "fallback",
+			userAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			remoteAddr: "8.8.8.8:4000",
+			want:       "https://example.com/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newGetRequest(tc, id)
+			req.Header.Set("User-Agent", tt.userAgent)
+			req.Header.Set("Accept-Language", tt.language)
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			w := httptest.NewRecorder()
+
+			tc.app.GetURLByID(w, req)
+
+			assert.Equal(t, http.StatusTemporaryRedirect, w.Code)
+			assert.Equal(t, tt.want, w.Header().Get("Location"))
+			assert.Equal(t, "User-Agent, Accept-Language", w.Header().Get("Vary"))
+		})
+	}
+
+	payload := "{\"url\":\"https://go.dev/\",\"rules\":[{\"platform\":\"symbian\",\"url\":\"https://go.dev/m\"}]}"
+	req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten", strings.NewReader(payload))
+	w := httptest.NewRecorder()
+
+	tc.app.ShortenAPI(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Rules is invalidate!", w.Body.String())
+}
+
+func TestGetURLByIDVariants(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+
+	shorten := func(payload string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten", strings.NewReader(payload))
+		req = req.WithContext(customContext.SetContextUserID(req.Context(), userID))
+		w := httptest.NewRecorder()
+		tc.app.ShortenAPI(w, req)
+		return w
+	}
+
+	w := shorten("{\"url\":\"https://go.dev/\",\"variants\":[{\"url\":\"https://go.dev/a\",\"weight\":0}]}")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Variants is invalidate!", w.Body.String())
+
+	w = shorten("{\"url\":\"https://ya.ru/\",\"redirect_code\":308,\"variants\":[" +
+		"{\"url\":\"https://ya.ru/a\",\"weight\":1},{\"url\":\"https://ya.ru/b\",\"weight\":1}]}")
+	require.Equal(t, http.StatusCreated, w.Code)
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+
+	w = httptest.NewRecorder()
+	tc.app.GetURLByID(w, newGetRequest(tc, id))
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "variant_"+id.String(), cookies[0].Name)
+	assert.Equal(t, "/", cookies[0].Path)
+
+	first := w.Header().Get("Location")
+	assert.Contains(t, []string{"https://ya.ru/a", "https://ya.ru/b"}, first)
+	for i := 0; i < 10; i++ {
+		req := newGetRequest(tc, id)
+		req.AddCookie(cookies[0])
+		w = httptest.NewRecorder()
+
+		tc.app.GetURLByID(w, req)
+
+		assert.Equal(t, first, w.Header().Get("Location"))
+		assert.Empty(t, w.Result().Cookies())
+	}
+
+	req := newGetRequest(tc, id)
+	req.AddCookie(&http.Cookie{Name: cookies[0].Name, Value: "7"})
+	w = httptest.NewRecorder()
+	tc.app.GetURLByID(w, req)
+	assert.Len(t, w.Result().Cookies(), 1)
+
+	link, err := tc.app.service.GetLinkByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), link.Clicks)
+	assert.Equal(t, int64(12), link.VariantClicks["https://ya.ru/a"]+link.VariantClicks["https://ya.ru/b"])
+	assert.GreaterOrEqual(t, link.VariantClicks[first], int64(11))
+
+	req = httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/urls", nil)
+	req = req.WithContext(customContext.SetContextUserID(req.Context(), userID))
+	w = httptest.NewRecorder()
+	tc.app.GetAllURLByUserID(w, req)
+	assert.Contains(t, w.Body.String(), "\"variant_clicks\":{")
+}
+
+func TestGetURLByIDSchedule(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	tests := []struct {
+		name     string
+		url      string
+		schedule string
+		status   int
+		location string
+		state    string
+	}{
+		{
+			name:     "window is empty",
+			url:      "https://go.dev/",
+			schedule: "\"active_from\":\"2030-01-01T03:00:00+03:00\",\"active_until\":\"2030-01-01T00:00:00Z\"",
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:     "fallback without window",
+			url:      "https://go.dev/",
+			schedule: "\"fallback_url\":\"https://go.dev/soon\"",
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:     "pending",
+			url:      "https://ya.ru/pending",
+			schedule: "\"active_from\":\"2099-01-01T00:00:00+03:00\"",
+			status:   http.StatusNotFound,
+			state:    models.ScheduleStatePending,
+		},
+		{
+			name:     "pending with fallback",
+			url:      "https://ya.ru/soon",
+			schedule: "\"active_from\":\"2099-01-01T00:00:00+03:00\",\"fallback_url\":\"https://ya.ru/teaser\"",
+			status:   http.StatusTemporaryRedirect,
+			location: "https://ya.ru/teaser",
+			state:    models.ScheduleStatePending,
+		},
+		{
+			name:     "active",
+			url:      "https://ya.ru/active",
+			schedule: "\"active_from\":\"2000-01-01T00:00:00-05:00\",\"active_until\":\"2099-01-01T00:00:00Z\"",
+			status:   http.StatusTemporaryRedirect,
+			location: "https://ya.ru/active",
+			state:    models.ScheduleStateActive,
+		},
+		{
+			name:     "expired",
+			url:      "https://ya.ru/expired",
+			schedule: "\"active_until\":\"2000-01-01T00:00:00+03:00\"",
+			status:   http.StatusGone,
+			state:    models.ScheduleStateExpired,
+		},
+	}
+
+	want := make(map[uuid.UUID]string)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := "{\"url\":\"" + tt.url + "\"," + tt.schedule + "}"
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/shorten", strings.NewReader(payload))
+			req = req.WithContext(customContext.SetContextUserID(req.Context(), userID))
+			w := httptest.NewRecorder()
+
+			tc.app.ShortenAPI(w, req)
+
+			if tt.status == http.StatusBadRequest {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Equal(t, "Schedule is invalidate!", w.Body.String())
+				return
+			}
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(tt.url))
+			want[id] = tt.state
+			w = httptest.NewRecorder()
+
+			tc.app.GetURLByID(w, newGetRequest(tc, id))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.location, w.Header().Get("Location"))
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		})
+	}
+
+	events, err := tc.app.service.UpdateScheduleStates(context.Background())
+	require.NoError(t, err)
+	got := make(map[uuid.UUID]string)
+	for _, e := range events {
+		got[e.ID] = e.State
+		assert.Equal(t, userID, *e.UserID)
+	}
+	assert.Equal(t, want, got)
+
+	events, err = tc.app.service.UpdateScheduleStates(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	link, err := tc.app.service.GetLinkByID(context.Background(), uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/soon")))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2098, 12, 31, 21, 0, 0, 0, time.UTC), *link.ActiveFrom)
+	assert.Equal(t, models.ScheduleStatePending, link.ScheduleState)
+}
+
 func TestPing(t *testing.T) {
 	tc := NewSuite(t)
 	tests := []struct {
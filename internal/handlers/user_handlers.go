@@ -6,28 +6,55 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
 	"github.com/IvanKondrashkov/go-shortener/internal/service"
 	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
-// GetAllURLByUserID возвращает все URL пользователя
+// GetAllURLByUserID возвращает страницу URL пользователя
 // @Summary Получить URL пользователя
-// @Description Возвращает все сокращенные URL, созданные текущим пользователем
+// @Description Возвращает сокращенные URL, созданные текущим пользователем, с курсорной пагинацией.
+// @Description Ссылка на следующую страницу передается в заголовке Link (rel="next").
+// @Description Поле health содержит результат последней фоновой проверки доступности адреса назначения.
 // @Tags Пользователь
 // @Security ApiKeyAuth
 // @Produce json
+// @Param limit query int false "Размер страницы (по умолчанию 100, не более 1000)"
+// @Param cursor query string false "Курсор следующей страницы из заголовка Link"
+// @Param order query string false "Сортировка по времени создания: asc или desc (по умолчанию)"
+// @Param domain query string false "Домен оригинального URL"
+// @Param created_from query string false "Нижняя граница времени создания (RFC3339)"
+// @Param created_to query string false "Верхняя граница времени создания (RFC3339)"
+// @Param deleted query string false "Удаленные URL: include или exclude (по умолчанию)"
+// @Param q query string false "Подстрока оригинального URL"
+// @Param tag query string false "Тег URL"
+// @Param folder_id query string false "ID папки"
 // @Success 200 {array} models.ResponseShortenAPIUser
 // @Success 204 "Нет сохраненных URL"
+// @Failure 400 {string} string "Неверные параметры запроса"
 // @Failure 401 {string} string "Пользователь не авторизован"
 // @Router /api/user/urls [get]
 func (app *App) GetAllURLByUserID(res http.ResponseWriter, req *http.Request) {
 	res.Header().Set("Content-Type", "application/json")
 
-	respDto, err := app.service.GetAllByUserID(req.Context())
+	filter, err := parseFilterURLs(req.URL.Query())
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Query is invalidate!"))
+		return
+	}
+
+	respDto, next, err := app.service.GetAllByUserID(req.Context(), filter)
 	if err != nil && errors.Is(err, service.ErrUserUnauthorized) {
 		res.WriteHeader(http.StatusUnauthorized)
 		_, _ = res.Write([]byte("User unauthorized!"))
@@ -40,6 +67,12 @@ func (app *App) GetAllURLByUserID(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if next != "" {
+		query := req.URL.Query()
+		query.Set("cursor", next)
+		res.Header().Set("Link", "<"+app.URL+"api/user/urls?"+query.Encode()+">; rel=\"next\"")
+	}
+
 	writer := writerPool.Get().(*bufio.Writer)
 	writer.Reset(res)
 	defer func() {
@@ -87,3 +120,202 @@ func (app *App) DeleteBatchByUserID(res http.ResponseWriter, req *http.Request)
 	go app.worker.SendDeleteBatchRequest(context.Background(), event)
 	res.WriteHeader(http.StatusAccepted)
 }
+
+// UpdateURLByUserID изменяет оригинальный URL пользователя
+// @Summary Изменить оригинальный URL
+// @Description Перенаправляет существующий сокращенный URL владельца на новый адрес и записывает изменение в историю
+// @Tags Пользователь
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сокращенного URL"
+// @Param input body models.RequestUpdateURL true "Новый оригинальный URL"
+// @Success 200 {object} models.URLHistory
+// @Failure 400 {string} string "Неверный формат запроса"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 403 {string} string "Адрес назначения запрещен, причина в заголовке X-Block-Reason"
+// @Failure 404 {string} string "URL не найден"
+// @Failure 410 {string} string "URL был удален"
+// @Router /api/user/urls/{id} [patch]
+func (app *App) UpdateURLByUserID(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(req.Body)
+	defer readerPool.Put(reader)
+
+	var reqDto models.RequestUpdateURL
+	if err := json.NewDecoder(reader).Decode(&reqDto); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Body is invalidate!"))
+		return
+	}
+
+	u, err := url.Parse(reqDto.URL)
+	if err != nil || reqDto.URL == "" {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Url is invalidate!"))
+		return
+	}
+
+	respDto, err := app.service.UpdateByUserID(req.Context(), id, u)
+	switch {
+	case errors.Is(err, service.ErrUserUnauthorized):
+		res.WriteHeader(http.StatusUnauthorized)
+		_, _ = res.Write([]byte("User unauthorized!"))
+		return
+	case errors.Is(err, customError.ErrNotFound):
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Url by id not found!"))
+		return
+	case errors.Is(err, customError.ErrDeleteAccepted):
+		res.WriteHeader(http.StatusGone)
+		_, _ = res.Write([]byte("Delete url accepted!"))
+		return
+	case writeBlocked(res, err):
+		return
+	case err != nil:
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Update url error!"))
+		return
+	}
+	app.fetchPage(id, respDto.NewURL)
+
+	writer := writerPool.Get().(*bufio.Writer)
+	writer.Reset(res)
+	defer func() {
+		writer.Flush()
+		writerPool.Put(writer)
+	}()
+
+	res.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(writer).Encode(respDto); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Response is invalidate!"))
+		return
+	}
+}
+
+// GetHistoryByUserID возвращает историю изменений URL пользователя
+// @Summary Получить историю изменений URL
+// @Description Возвращает изменения оригинального URL (кто, когда, старое и новое значение) в порядке их применения
+// @Tags Пользователь
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID сокращенного URL"
+// @Success 200 {array} models.URLHistory
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 404 {string} string "URL не найден"
+// @Router /api/user/urls/{id}/history [get]
+func (app *App) GetHistoryByUserID(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	respDto, err := app.service.GetHistoryByUserID(req.Context(), id)
+	switch {
+	case errors.Is(err, service.ErrUserUnauthorized):
+		res.WriteHeader(http.StatusUnauthorized)
+		_, _ = res.Write([]byte("User unauthorized!"))
+		return
+	case err != nil:
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Url by id not found!"))
+		return
+	}
+
+	writer := writerPool.Get().(*bufio.Writer)
+	writer.Reset(res)
+	defer func() {
+		writer.Flush()
+		writerPool.Put(writer)
+	}()
+
+	res.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(writer).Encode(respDto); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Response is invalidate!"))
+		return
+	}
+}
+
+// parseFilterURLs разбирает параметры пагинации и фильтрации списка URL пользователя
+func parseFilterURLs(query url.Values) (*models.FilterURLs, error) {
+	filter := &models.FilterURLs{
+		Limit:  defaultPageLimit,
+		Desc:   true,
+		Domain: query.Get("domain"),
+		Search: query.Get("q"),
+		Tag:    strings.ToLower(strings.TrimSpace(query.Get("tag"))),
+	}
+
+	if v := query.Get("folder_id"); v != "" {
+		folderID, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("folder_id is invalidate: %w", err)
+		}
+		filter.FolderID = &folderID
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return nil, fmt.Errorf("limit is invalidate: %s", v)
+		}
+		filter.Limit = limit
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := models.ParseCursor(v)
+		if err != nil {
+			return nil, err
+		}
+		filter.Cursor = cursor
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Desc = false
+	default:
+		return nil, fmt.Errorf("order is invalidate: %s", query.Get("order"))
+	}
+
+	switch query.Get("deleted") {
+	case "", "exclude":
+	case "include":
+		filter.WithDeleted = true
+	default:
+		return nil, fmt.Errorf("deleted is invalidate: %s", query.Get("deleted"))
+	}
+
+	for key, target := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		v := query.Get(key)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s is invalidate: %w", key, err)
+		}
+		t = t.UTC()
+		*target = &t
+	}
+	return filter, nil
+}
@@ -3,17 +3,22 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/IvanKondrashkov/go-shortener/internal/handlers/mock"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
 	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetAllURLByUserID(t *testing.T) {
@@ -40,7 +45,7 @@ func TestGetAllURLByUserID(t *testing.T) {
 			name:   "ok",
 			status: http.StatusOK,
 			userID: uuid.New(),
-			want:   []byte("[{\"short_url\":\"http://localhost:8080/eefbcef4-3940-5a38-b2f0-877152a6d470\",\"original_url\":\"https://ya.ru/\"}]\n"),
+			want:   []byte("[{\"short_url\":\"http://localhost:8080/eefbcef4-3940-5a38-b2f0-877152a6d470\",\"original_url\":\"https://ya.ru/\"}]"),
 		},
 	}
 	for _, tt := range tests {
@@ -68,9 +73,136 @@ func TestGetAllURLByUserID(t *testing.T) {
 			tc.app.GetAllURLByUserID(w, req)
 
 			assert.Equal(t, tt.status, w.Code)
-			assert.Equal(t, tt.want, w.Body.Bytes())
+			if tt.status == http.StatusOK {
+				var got, want []*models.ResponseShortenAPIUser
+				_ = json.Unmarshal(tt.want, &want)
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				for _, u := range got {
+					u.CreatedAt = time.Time{}
+				}
+				assert.Equal(t, want, got)
+			} else {
+				assert.Equal(t, tt.want, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestGetAllURLByUserIDPagination(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), userID)
+
+	folder, _ := tc.app.service.SaveFolder(ctx, "docs")
+	metas := []models.LinkMeta{
+		{Tags: []string{"search"}},
+		{Tags: []string{"go", "docs"}, FolderID: &folder.ID},
+		{Tags: []string{"search"}},
+		{},
+	}
+
+	createdAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i, raw := range []string{"https://ya.ru/", "https://go.dev/doc", "https://ya.ru/search", "https://example.com/"} {
+		_, _ = tc.app.service.Repository.SaveLink(ctx, nil, &models.Link{
+			ID:          uuid.NewSHA1(uuid.NameSpaceURL, []byte(raw)),
+			UserID:      &userID,
+			OriginalURL: raw,
+			CreatedAt:   createdAt.Add(time.Duration(i) * time.Hour),
+			LinkMeta:    metas[i],
+		})
+	}
+	_, _ = tc.app.service.Repository.DeleteBatchByUserID(ctx, nil, userID, []uuid.UUID{uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://example.com/"))})
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   []string
+		next   bool
+	}{
+		{
+			name:   "query is invalidate",
+			query:  "limit=0",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "first page",
+			query:  "limit=2",
+			status: http.StatusOK,
+			want:   []string{"https://ya.ru/search", "https://go.dev/doc"},
+			next:   true,
+		},
+		{
+			name:   "ascending with deleted",
+			query:  "order=asc&deleted=include&created_from=2024-01-01T01:00:00Z",
+			status: http.StatusOK,
+			want:   []string{"https://go.dev/doc", "https://ya.ru/search", "https://example.com/"},
+		},
+		{
+			name:   "domain and search",
+			query:  "domain=YA.RU&q=SEARCH",
+			status: http.StatusOK,
+			want:   []string{"https://ya.ru/search"},
+		},
+		{
+			name:   "tag",
+			query:  "tag=Search",
+			status: http.StatusOK,
+			want:   []string{"https://ya.ru/search", "https://ya.ru/"},
+		},
+		{
+			name:   "folder",
+			query:  "folder_id=" + folder.ID.String(),
+			status: http.StatusOK,
+			want:   []string{"https://go.dev/doc"},
+		},
+		{
+			name:   "folder is invalidate",
+			query:  "folder_id=docs",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/urls?"+tt.query, nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			tc.app.GetAllURLByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var got []*models.ResponseShortenAPIUser
+			_ = json.Unmarshal(w.Body.Bytes(), &got)
+			urls := make([]string, 0, len(got))
+			for _, u := range got {
+				urls = append(urls, u.OriginalURL)
+			}
+			assert.Equal(t, tt.want, urls)
+			assert.Equal(t, tt.next, w.Header().Get("Link") != "")
 		})
 	}
+
+	t.Run("next page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/urls?limit=2", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		tc.app.GetAllURLByUserID(w, req)
+
+		link := w.Header().Get("Link")
+		next := link[strings.Index(link, "<")+1 : strings.Index(link, ">")]
+		req = httptest.NewRequest(http.MethodGet, next, nil).WithContext(ctx)
+		w = httptest.NewRecorder()
+		tc.app.GetAllURLByUserID(w, req)
+
+		var got []*models.ResponseShortenAPIUser
+		_ = json.Unmarshal(w.Body.Bytes(), &got)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, got, 1)
+		assert.Equal(t, "https://ya.ru/", got[0].OriginalURL)
+		assert.Empty(t, w.Header().Get("Link"))
+	})
 }
 
 func TestDeleteBatchByUserID(t *testing.T) {
@@ -118,3 +250,210 @@ func TestDeleteBatchByUserID(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateURLByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	u, _ := url.Parse("https://ya.ru/")
+	_, _ = tc.app.service.Repository.SaveUser(context.Background(), nil, ownerID, id, u)
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		id      string
+		payload []byte
+		status  int
+	}{
+		{
+			name:    "id is invalidate",
+			userID:  ownerID,
+			id:      "not-uuid",
+			payload: []byte("{\"url\":\"https://go.dev/\"}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "body is invalidate",
+			userID:  ownerID,
+			id:      id.String(),
+			payload: []byte("invalid json"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "user is not owner",
+			userID:  uuid.New(),
+			id:      id.String(),
+			payload: []byte("{\"url\":\"https://go.dev/\"}"),
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "ok",
+			userID:  ownerID,
+			id:      id.String(),
+			payload: []byte("{\"url\":\"https://go.dev/\"}"),
+			status:  http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, tc.app.URL+"api/user/urls/"+tt.id, bytes.NewBuffer(tt.payload))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.UpdateURLByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var got models.URLHistory
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				assert.Equal(t, "https://ya.ru/", got.OldURL)
+				assert.Equal(t, "https://go.dev/", got.NewURL)
+
+				location, _ := tc.app.service.GetByID(req.Context(), id)
+				assert.Equal(t, "https://go.dev/", location.String())
+			}
+		})
+	}
+}
+
+func TestUpdateURLByUserIDRevived(t *testing.T) {
+	tc := NewSuite(t)
+	u, _ := url.Parse("https://ya.ru/")
+	oldCtx := customContext.SetContextUserID(context.Background(), uuid.New())
+	newCtx := customContext.SetContextUserID(context.Background(), uuid.New())
+
+	id, err := tc.app.service.Save(oldCtx, uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String())), u)
+	require.NoError(t, err)
+	_, err = tc.app.service.DeleteBatchByUserID(oldCtx, []uuid.UUID{id})
+	require.NoError(t, err)
+
+	// Удаленная ссылка, сохраненная другим пользователем, переходит к нему
+	revived, err := tc.app.service.Save(newCtx, id, u)
+	require.NoError(t, err)
+	require.Equal(t, id, revived)
+
+	patch := func(ctx context.Context) int {
+		req := httptest.NewRequest(http.MethodPatch, tc.app.URL+"api/user/urls/"+id.String(), strings.NewReader("{\"url\":\"https://go.dev/\"}"))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		tc.app.UpdateURLByUserID(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusNotFound, patch(oldCtx))
+
+	deleted, err := tc.app.service.DeleteBatchByUserID(oldCtx, []uuid.UUID{id})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	assert.Equal(t, http.StatusOK, patch(newCtx))
+}
+
+func TestUpdateURLByUserIDReshorten(t *testing.T) {
+	tc := NewSuite(t)
+	ctx := customContext.SetContextUserID(context.Background(), uuid.New())
+	withID := func(req *http.Request, id string) *http.Request {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	}
+	shorten := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, tc.app.URL, strings.NewReader("https://ya.ru/")).WithContext(ctx)
+		w := httptest.NewRecorder()
+		tc.app.ShortenURL(w, req)
+		return w
+	}
+
+	w := shorten()
+	require.Equal(t, http.StatusCreated, w.Code)
+	retargeted := strings.TrimPrefix(w.Body.String(), tc.app.URL)
+
+	req := withID(httptest.NewRequest(http.MethodPatch, tc.app.URL+"api/user/urls/"+retargeted, strings.NewReader("{\"url\":\"https://go.dev/\"}")), retargeted)
+	w = httptest.NewRecorder()
+	tc.app.UpdateURLByUserID(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Ссылка сохранила UUID хэша https://ya.ru/, поэтому повторное сокращение создает новую ссылку
+	w = shorten()
+	require.Equal(t, http.StatusCreated, w.Code)
+	id := strings.TrimPrefix(w.Body.String(), tc.app.URL)
+	assert.NotEqual(t, retargeted, id)
+
+	w = httptest.NewRecorder()
+	tc.app.GetURLByID(w, withID(httptest.NewRequest(http.MethodGet, tc.app.URL+id, nil), id))
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	assert.Equal(t, "https://ya.ru/", w.Header().Get("Location"))
+
+	w = shorten()
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, tc.app.URL+id, w.Body.String())
+
+	res, err := tc.app.service.SaveBatch(ctx, []*models.RequestShortenAPIBatch{{OriginalURL: "https://ya.ru/"}, {OriginalURL: "https://go.dev/"}})
+	require.NoError(t, err)
+	assert.Equal(t, models.BatchStatusExisting, res[0].Status)
+	assert.Equal(t, tc.app.URL+id, res[0].ShortURL)
+	assert.Equal(t, models.BatchStatusCreated, res[1].Status)
+}
+
+func TestGetHistoryByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	u, _ := url.Parse("https://ya.ru/")
+
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	_, _ = tc.app.service.Repository.SaveUser(ctx, nil, ownerID, id, u)
+	for _, raw := range []string{"https://go.dev/", "https://pkg.go.dev/"} {
+		next, _ := url.Parse(raw)
+		_, _ = tc.app.service.UpdateByUserID(ctx, id, next)
+	}
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		status int
+		want   [][2]string
+	}{
+		{
+			name:   "user is not owner",
+			userID: uuid.New(),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ok",
+			userID: ownerID,
+			status: http.StatusOK,
+			want:   [][2]string{{"https://ya.ru/", "https://go.dev/"}, {"https://go.dev/", "https://pkg.go.dev/"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/urls/"+id.String()+"/history", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id.String())
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.GetHistoryByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var got []*models.URLHistory
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				changes := make([][2]string, 0, len(got))
+				for _, h := range got {
+					assert.Equal(t, ownerID, h.UserID)
+					changes = append(changes, [2]string{h.OldURL, h.NewURL})
+				}
+				assert.Equal(t, tt.want, changes)
+			}
+		})
+	}
+}
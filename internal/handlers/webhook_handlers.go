@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// webhookDeliveriesLimit количество последних попыток доставки в ответе журнала доставок
+const webhookDeliveriesLimit = 100
+
+// SaveWebhook создает webhook пользователя
+// @Summary Создать webhook
+// @Description Регистрирует адрес, на который отправляются события жизненного цикла ссылок текущего пользователя:
+// @Description link.created, link.deleted, link.expired и link.click_threshold.
+// @Description Тело события подписывается HMAC-SHA256 секретом webhook: заголовок X-Webhook-Signature
+// @Description содержит sha256=<hex> от строки "<X-Webhook-Timestamp>.<тело>". Секрет возвращается только в этом ответе.
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param input body models.RequestWebhook true "Адрес получателя и события"
+// @Success 201 {object} models.Webhook
+// @Failure 400 {string} string "Неверный адрес или события, превышено количество webhook"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Router /api/user/webhooks [post]
+func (app *App) SaveWebhook(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	reader := readerPool.Get().(*bufio.Reader)
+	reader.Reset(req.Body)
+	defer readerPool.Put(reader)
+
+	var reqDto models.RequestWebhook
+	if err := json.NewDecoder(reader).Decode(&reqDto); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Body is invalidate!"))
+		return
+	}
+	reqDto.URL = strings.TrimSpace(reqDto.URL)
+
+	respDto, err := app.service.SaveWebhook(req.Context(), &reqDto)
+	if !writeWebhookError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusCreated, respDto)
+}
+
+// GetWebhooksByUserID возвращает webhook пользователя
+// @Summary Получить webhook
+// @Description Возвращает webhook текущего пользователя в порядке создания без секретов
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} models.Webhook
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Router /api/user/webhooks [get]
+func (app *App) GetWebhooksByUserID(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	respDto, err := app.service.GetWebhooksByUserID(req.Context())
+	if !writeWebhookError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusOK, respDto)
+}
+
+// RotateWebhookSecret заменяет секрет webhook пользователя
+// @Summary Ротация секрета webhook
+// @Description Создает новый секрет подписи webhook текущего пользователя.
+// @Description В течение WEBHOOK_SECRET_GRACE события подписываются и предыдущим секретом:
+// @Description X-Webhook-Signature содержит обе подписи через запятую.
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID webhook"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 404 {string} string "Webhook не найден"
+// @Router /api/user/webhooks/{id}/rotate [post]
+func (app *App) RotateWebhookSecret(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	respDto, err := app.service.RotateWebhookSecret(req.Context(), id)
+	if !writeWebhookError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusOK, respDto)
+}
+
+// DeleteWebhookByUserID удаляет webhook пользователя
+// @Summary Удалить webhook
+// @Description Удаляет webhook текущего пользователя вместе с журналом доставок
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Param id path string true "ID webhook"
+// @Success 204 "Webhook удален"
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 404 {string} string "Webhook не найден"
+// @Router /api/user/webhooks/{id} [delete]
+func (app *App) DeleteWebhookByUserID(res http.ResponseWriter, req *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	err = app.service.DeleteWebhookByUserID(req.Context(), id)
+	if !writeWebhookError(res, err) {
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries возвращает журнал доставок webhook пользователя
+// @Summary Журнал доставок webhook
+// @Description Возвращает последние 100 попыток доставки событий на webhook текущего пользователя, начиная с новых
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "ID webhook"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Пользователь не авторизован"
+// @Failure 404 {string} string "Webhook не найден"
+// @Router /api/user/webhooks/{id}/deliveries [get]
+func (app *App) GetWebhookDeliveries(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Id is invalidate!"))
+		return
+	}
+
+	respDto, err := app.service.GetWebhookDeliveries(req.Context(), id, webhookDeliveriesLimit)
+	if !writeWebhookError(res, err) {
+		return
+	}
+	writeJSON(res, http.StatusOK, respDto)
+}
+
+// writeWebhookError записывает ответ с ошибкой операции над webhook
+// Возвращает true, если ошибки нет и обработку запроса нужно продолжить
+func writeWebhookError(res http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrUserUnauthorized):
+		res.WriteHeader(http.StatusUnauthorized)
+		_, _ = res.Write([]byte("User unauthorized!"))
+	case errors.Is(err, service.ErrWebhookNotValid):
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Webhook is invalidate!"))
+	case errors.Is(err, service.ErrWebhookLimit):
+		res.WriteHeader(http.StatusBadRequest)
+		_, _ = res.Write([]byte("Webhook limit exceeded!"))
+	case errors.Is(err, customError.ErrNotFound):
+		res.WriteHeader(http.StatusNotFound)
+		_, _ = res.Write([]byte("Webhook by id not found!"))
+	default:
+		res.WriteHeader(http.StatusInternalServerError)
+		_, _ = res.Write([]byte("Webhook operation error!"))
+	}
+	return false
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveWebhook(t *testing.T) {
+	tc := NewSuite(t)
+	userID := uuid.New()
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		payload []byte
+		status  int
+	}{
+		{
+			name:    "user unauthorized",
+			userID:  uuid.Nil,
+			payload: []byte("{\"url\":\"https://example.com/hook\",\"events\":[\"link.created\"]}"),
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "body is invalidate",
+			userID:  userID,
+			payload: []byte("invalid json"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "url is invalidate",
+			userID:  userID,
+			payload: []byte("{\"url\":\"ftp://example.com/hook\",\"events\":[\"link.created\"]}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "event is invalidate",
+			userID:  userID,
+			payload: []byte("{\"url\":\"https://example.com/hook\",\"events\":[\"link.unknown\"]}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "events is empty",
+			userID:  userID,
+			payload: []byte("{\"url\":\"https://example.com/hook\"}"),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "ok",
+			userID:  userID,
+			payload: []byte("{\"url\":\" https://example.com/hook \",\"events\":[\"link.created\",\"link.deleted\",\"link.created\"]}"),
+			status:  http.StatusCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/user/webhooks", bytes.NewBuffer(tt.payload))
+			if tt.userID != uuid.Nil {
+				req = req.WithContext(customContext.SetContextUserID(req.Context(), tt.userID))
+			}
+			w := httptest.NewRecorder()
+
+			tc.app.SaveWebhook(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusCreated {
+				var got models.Webhook
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				assert.NotEqual(t, uuid.Nil, got.ID)
+				assert.Equal(t, "https://example.com/hook", got.URL)
+				assert.Equal(t, []string{models.WebhookEventCreated, models.WebhookEventDeleted}, got.Events)
+				assert.NotEmpty(t, got.Secret)
+			}
+		})
+	}
+}
+
+func TestGetWebhooksByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	webhook, _ := tc.app.service.SaveWebhook(ctx, &models.RequestWebhook{
+		URL:    "https://example.com/hook",
+		Events: []string{models.WebhookEventExpired},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/webhooks", nil)
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	tc.app.GetWebhooksByUserID(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []*models.Webhook
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	assert.Len(t, got, 1)
+	assert.Equal(t, webhook.ID, got[0].ID)
+	assert.Empty(t, got[0].Secret)
+}
+
+func TestRotateWebhookSecret(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	webhook, _ := tc.app.service.SaveWebhook(ctx, &models.RequestWebhook{
+		URL:    "https://example.com/hook",
+		Events: []string{models.WebhookEventCreated},
+	})
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		id     string
+		status int
+	}{
+		{
+			name:   "id is invalidate",
+			userID: ownerID,
+			id:     "not-uuid",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "webhook of another user",
+			userID: uuid.New(),
+			id:     webhook.ID.String(),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ok",
+			userID: ownerID,
+			id:     webhook.ID.String(),
+			status: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.app.URL+"api/user/webhooks/"+tt.id+"/rotate", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.RotateWebhookSecret(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var got models.Webhook
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				assert.NotEmpty(t, got.Secret)
+				assert.NotEqual(t, webhook.Secret, got.Secret)
+				assert.Empty(t, got.PreviousSecret)
+				assert.NotNil(t, got.RotatedAt)
+			}
+		})
+	}
+}
+
+func TestDeleteWebhookByUserID(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	webhook, _ := tc.app.service.SaveWebhook(ctx, &models.RequestWebhook{
+		URL:    "https://example.com/hook",
+		Events: []string{models.WebhookEventCreated},
+	})
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		status int
+	}{
+		{
+			name:   "webhook of another user",
+			userID: uuid.New(),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ok",
+			userID: ownerID,
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "already deleted",
+			userID: ownerID,
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tc.app.URL+"api/user/webhooks/"+webhook.ID.String(), nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", webhook.ID.String())
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.DeleteWebhookByUserID(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	tc := NewSuite(t)
+	ownerID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), ownerID)
+	webhook, _ := tc.app.service.SaveWebhook(ctx, &models.RequestWebhook{
+		URL:    "https://example.com/hook",
+		Events: []string{models.WebhookEventCreated},
+	})
+	_ = tc.app.service.Repository.SaveWebhookDelivery(ctx, &models.WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhook.ID,
+		EventID:   uuid.New(),
+		EventType: models.WebhookEventCreated,
+		Attempt:   1,
+		Status:    models.DeliveryStatusDelivered,
+	})
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		length int
+		status int
+	}{
+		{
+			name:   "webhook of another user",
+			userID: uuid.New(),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "ok",
+			userID: ownerID,
+			length: 1,
+			status: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.app.URL+"api/user/webhooks/"+webhook.ID.String()+"/deliveries", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", webhook.ID.String())
+			ctx := customContext.SetContextUserID(req.Context(), tt.userID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tc.app.GetWebhookDeliveries(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var got []*models.WebhookDelivery
+				_ = json.Unmarshal(w.Body.Bytes(), &got)
+				assert.Len(t, got, tt.length)
+				assert.Equal(t, models.DeliveryStatusDelivered, got[0].Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+)
+
+// Parse разбирает экспорт сервиса source в формате format
+// Для каждого короткого имени ссылки возвращается отдельный элемент с тем же адресом назначения,
+// ссылка без короткого имени возвращается одним элементом с пустым Slug.
+// Элементы с пустым или невалидным адресом возвращаются как есть, их проверяет сервис.
+// CSV должен начинаться со строки заголовка. JSON может быть массивом объектов, объектом с массивом
+// в поле links или data либо объектом YOURLS с объектами ссылок в поле links
+func Parse(r io.Reader, source, format string) ([]*models.ImportLink, error) {
+	f, ok := sources[strings.ToLower(source)]
+	if !ok {
+		return nil, fmt.Errorf("parse export error: %w", ErrSourceNotValid)
+	}
+
+	var records []map[string]any
+	var err error
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		records, err = readCSV(r)
+	case FormatJSON:
+		records, err = readJSON(r)
+	default:
+		return nil, fmt.Errorf("parse export error: %w", ErrFormatNotValid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse export error: %w", err)
+	}
+
+	links := make([]*models.ImportLink, 0, len(records))
+	for _, record := range records {
+		links = append(links, f.links(record)...)
+	}
+	return links, nil
+}
+
+// links преобразует запись экспорта в элементы импорта, по одному на короткое имя
+func (f fields) links(record map[string]any) []*models.ImportLink {
+	base := models.ImportLink{
+		OriginalURL: text(lookup(record, f.url)),
+		CreatedAt:   parseTime(lookup(record, f.created)),
+		Title:       text(lookup(record, f.title)),
+		Tags:        list(lookup(record, f.tags)),
+	}
+
+	var slugs []string
+	seen := make(map[string]struct{})
+	for _, raw := range append([]string{text(lookup(record, f.slug))}, list(lookup(record, f.aliases))...) {
+		slug := slugFromLink(raw)
+		if _, ok := seen[slug]; ok || slug == "" {
+			continue
+		}
+		seen[slug] = struct{}{}
+		slugs = append(slugs, slug)
+	}
+	if len(slugs) == 0 {
+		return []*models.ImportLink{&base}
+	}
+
+	res := make([]*models.ImportLink, 0, len(slugs))
+	for _, slug := range slugs {
+		link := base
+		link.Slug = slug
+		res = append(res, &link)
+	}
+	return res
+}
+
+// readCSV читает записи CSV с названиями полей из строки заголовка
+func readCSV(r io.Reader) ([]map[string]any, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range header {
+		header[i] = normalizeKey(strings.TrimPrefix(name, "\ufeff"))
+	}
+
+	var records []map[string]any
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := make(map[string]any, len(header))
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = value
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+// readJSON читает объекты ссылок из JSON экспорта
+func readJSON(r io.Reader) ([]map[string]any, error) {
+	var root any
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+
+	if obj, ok := root.(map[string]any); ok {
+		root = nil
+		for _, key := range []string{"links", "data"} {
+			if v, ok := obj[key]; ok {
+				root = v
+				break
+			}
+		}
+	}
+
+	var items []any
+	switch v := root.(type) {
+	case []any:
+		items = v
+	case map[string]any:
+		// YOURLS stats: {"links": {"link_1": {...}, "link_2": {...}}}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, errA := strconv.Atoi(strings.TrimPrefix(keys[i], "link_"))
+			b, errB := strconv.Atoi(strings.TrimPrefix(keys[j], "link_"))
+			if errA != nil || errB != nil {
+				return keys[i] < keys[j]
+			}
+			return a < b
+		})
+		for _, key := range keys {
+			items = append(items, v[key])
+		}
+	default:
+		return nil, errors.New("links are not found")
+	}
+
+	records := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, errors.New("link is not an object")
+		}
+
+		record := make(map[string]any, len(obj))
+		for key, value := range obj {
+			record[normalizeKey(key)] = value
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// normalizeKey приводит название поля к нижнему регистру и заменяет пробелы и "-" на "_"
+func normalizeKey(key string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(key)))
+}
+
+// lookup возвращает значение первого найденного поля из names
+func lookup(record map[string]any, names []string) any {
+	for _, name := range names {
+		if v, ok := record[name]; ok && v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// text возвращает строковое значение поля
+func text(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// list возвращает значения поля-списка: массива JSON или строки CSV с разделителями "," ";" "|" или пробелами
+func list(v any) []string {
+	var res []string
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if s := text(item); s != "" {
+				res = append(res, s)
+			}
+		}
+	case string:
+		res = strings.FieldsFunc(v, func(r rune) bool {
+			return r == ',' || r == ';' || r == '|' || r == ' ' || r == '\t'
+		})
+	}
+	return res
+}
+
+// slugFromLink возвращает короткое имя из короткого URL (https://bit.ly/abc, bit.ly/abc) или само значение
+func slugFromLink(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if i := strings.IndexAny(raw, "?#"); i >= 0 {
+		raw = raw[:i]
+	}
+	raw = strings.TrimRight(raw, "/")
+	if i := strings.LastIndex(raw, "/"); i >= 0 {
+		raw = raw[i+1:]
+	}
+	return raw
+}
+
+// parseTime разбирает время создания: строку в одном из timeLayouts или Unix-время в секундах или миллисекундах
+// Возвращает нулевое время, если значение не удалось разобрать
+func parseTime(v any) time.Time {
+	s := text(v)
+	if s == "" {
+		return time.Time{}
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC()
+		}
+		return time.Unix(n, 0).UTC()
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	created := time.Date(2023, time.May, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		source string
+		format string
+		body   string
+		want   []*models.ImportLink
+	}{
+		{
+			name:   "bitly json",
+			source: SourceBitly,
+			format: FormatJSON,
+			body: `{"links":[{"id":"bit.ly/3abc","link":"https://bit.ly/3abc","long_url":"https://go.dev/",
+"title":"Go","created_at":"2023-05-01T12:30:00+0000","tags":["lang","go"],
+"custom_bitlinks":["https://bit.ly/golang","https://bit.ly/3abc"]}]}`,
+			want: []*models.ImportLink{
+				{Slug: "3abc", OriginalURL: "https://go.dev/", CreatedAt: created, Title: "Go", Tags: []string{"lang", "go"}},
+				{Slug: "golang", OriginalURL: "https://go.dev/", CreatedAt: created, Title: "Go", Tags: []string{"lang", "go"}},
+			},
+		},
+		{
+			name:   "bitly csv",
+			source: SourceBitly,
+			format: FormatCSV,
+			body:   "\ufeffLong URL,Bitlink,Date Created\nhttps://go.dev/,bit.ly/3abc,2023-05-01 12:30:00\nhttps://ya.ru/,,\n",
+			want: []*models.ImportLink{
+				{Slug: "3abc", OriginalURL: "https://go.dev/", CreatedAt: created},
+				{OriginalURL: "https://ya.ru/"},
+			},
+		},
+		{
+			name:   "yourls json",
+			source: SourceYOURLS,
+			format: FormatJSON,
+			body: `{"statusCode":200,"links":{
+"link_10":{"shorturl":"https://sho.rt/ten","url":"https://ya.ru/","timestamp":"2023-05-01 12:30:00"},
+"link_2":{"shorturl":"https://sho.rt/two","url":"https://go.dev/","title":"Go"}}}`,
+			want: []*models.ImportLink{
+				{Slug: "two", OriginalURL: "https://go.dev/", Title: "Go"},
+				{Slug: "ten", OriginalURL: "https://ya.ru/", CreatedAt: created},
+			},
+		},
+		{
+			name:   "yourls csv",
+			source: SourceYOURLS,
+			format: FormatCSV,
+			body:   "keyword,url,title,timestamp\ngo,https://go.dev/,\"Go, lang\",1682944200\n",
+			want: []*models.ImportLink{
+				{Slug: "go", OriginalURL: "https://go.dev/", CreatedAt: created, Title: "Go, lang"},
+			},
+		},
+		{
+			name:   "kutt json",
+			source: SourceKutt,
+			format: FormatJSON,
+			body:   `[{"address":"go","link":"https://kutt.it/go","target":"https://go.dev/","description":"Go","created_at":"2023-05-01T12:30:00.000Z"}]`,
+			want: []*models.ImportLink{
+				{Slug: "go", OriginalURL: "https://go.dev/", CreatedAt: created, Title: "Go"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.body), tt.source, tt.format)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	_, err := Parse(strings.NewReader("[]"), "tinyurl", FormatJSON)
+	assert.ErrorIs(t, err, ErrSourceNotValid)
+
+	_, err = Parse(strings.NewReader("[]"), SourceKutt, "xml")
+	assert.ErrorIs(t, err, ErrFormatNotValid)
+
+	_, err = Parse(strings.NewReader(`{"links":"go"}`), SourceYOURLS, FormatJSON)
+	assert.Error(t, err)
+
+	_, err = Parse(strings.NewReader(`[1]`), SourceKutt, FormatJSON)
+	assert.Error(t, err)
+}
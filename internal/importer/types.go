@@ -0,0 +1,75 @@
+// Package importer разбирает экспорт ссылок других сервисов сокращения URL (Bitly, YOURLS, Kutt)
+// в форматах CSV и JSON, сохраняя короткие имена и время создания ссылок, если они есть в экспорте
+package importer
+
+import (
+	"errors"
+	"time"
+)
+
+// Сервисы, экспорт которых поддерживается
+const (
+	SourceBitly  = "bitly"
+	SourceYOURLS = "yourls"
+	SourceKutt   = "kutt"
+)
+
+// Форматы экспорта
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var (
+	// ErrSourceNotValid возвращается для неподдерживаемого сервиса
+	ErrSourceNotValid = errors.New("source is invalidate")
+	// ErrFormatNotValid возвращается для неподдерживаемого формата
+	ErrFormatNotValid = errors.New("format is invalidate")
+)
+
+// fields названия полей экспорта сервиса в порядке приоритета
+// Названия приведены к нижнему регистру, пробелы и "-" заменены на "_"
+type fields struct {
+	url     []string // Адрес назначения
+	slug    []string // Короткое имя или короткий URL, из которого берется последний сегмент пути
+	aliases []string // Дополнительные короткие имена (custom back-halves Bitly)
+	title   []string // Название
+	created []string // Время создания
+	tags    []string // Теги
+}
+
+// sources поля экспорта поддерживаемых сервисов: CSV и JSON выгрузки Bitly (API v4 /bitlinks),
+// YOURLS (плагины экспорта и API stats) и Kutt (API v2 /links)
+var sources = map[string]fields{
+	SourceBitly: {
+		url:     []string{"long_url", "original_url", "destination"},
+		slug:    []string{"link", "bitlink", "id", "short_url"},
+		aliases: []string{"custom_bitlinks", "custom_back_halves", "custom_links"},
+		title:   []string{"title"},
+		created: []string{"created_at", "created", "date_created", "creation_date"},
+		tags:    []string{"tags"},
+	},
+	SourceYOURLS: {
+		url:     []string{"url", "long_url"},
+		slug:    []string{"keyword", "shorturl", "short_url"},
+		title:   []string{"title"},
+		created: []string{"timestamp", "date"},
+	},
+	SourceKutt: {
+		url:     []string{"target"},
+		slug:    []string{"address", "link"},
+		title:   []string{"description", "title"},
+		created: []string{"created_at"},
+		tags:    []string{"tags"},
+	},
+}
+
+// timeLayouts форматы времени создания в экспортах
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
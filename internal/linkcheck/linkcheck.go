@@ -0,0 +1,208 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+)
+
+// NewChecker создает проверку доступности адресов назначения
+// Принимает:
+// - timeout: таймаут проверки одного адреса, включая перенаправления
+// - allow: внутренние сети, запросы к которым разрешены (может быть nil)
+func NewChecker(timeout time.Duration, allow []netip.Prefix) *Checker {
+	return &Checker{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: NewTransport(timeout, allow),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// NewTransport создает HTTP транспорт без прокси, отклоняющий соединения с внутренними, локальными
+// и зарезервированными адресами после разрешения имени
+// Принимает:
+// - timeout: таймаут соединения, TLS рукопожатия и ожидания заголовков ответа
+// - allow: внутренние сети, соединения с которыми разрешены (может быть nil)
+func NewTransport(timeout time.Duration, allow []netip.Prefix) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return control(address, allow)
+		},
+	}
+	return &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		DisableKeepAlives:     true,
+	}
+}
+
+// ParseAllowlist разбирает список разрешенных внутренних сетей
+// Принимает:
+// - raw: CIDR или IP адреса через запятую
+// Возвращает:
+// - сети списка или ErrPrefixNotValid
+func ParseAllowlist(raw string) ([]netip.Prefix, error) {
+	var res []netip.Prefix
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		if addr, err := netip.ParseAddr(field); err == nil {
+			res = append(res, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field, ErrPrefixNotValid)
+		}
+		res = append(res, prefix.Masked())
+	}
+	return res, nil
+}
+
+// Check проверяет адрес назначения
+// Выполняет HEAD запрос, а если сервер его не поддерживает - GET, и следует перенаправлениям
+// Принимает:
+// - ctx: контекст для контроля времени выполнения
+// - raw: адрес назначения
+// Возвращает:
+// - результат проверки со временем проверки в UTC
+func (c *Checker) Check(ctx context.Context, raw string) *models.LinkHealth {
+	start := time.Now()
+	health := &models.LinkHealth{
+		Status:    models.HealthStatusOK,
+		CheckedAt: start.UTC(),
+	}
+	defer func() {
+		health.LatencyMs = time.Since(start).Milliseconds()
+	}()
+
+	visited := make(map[string]struct{}, maxRedirects)
+	current := raw
+	for i := 0; ; i++ {
+		if _, ok := visited[current]; ok || i > maxRedirects {
+			health.Status, health.FinalURL = models.HealthStatusRedirectLoop, current
+			return health
+		}
+		visited[current] = struct{}{}
+
+		code, location, err := c.do(ctx, current)
+		health.StatusCode, health.FinalURL = code, current
+		if err != nil {
+			health.Status, health.Error = models.HealthStatusDead, err.Error()
+			if errors.Is(err, ErrAddressNotAllowed) || errors.Is(err, ErrSchemeNotAllowed) {
+				health.Status = models.HealthStatusRefused
+			}
+			return health
+		}
+
+		if location == "" {
+			if code >= http.StatusBadRequest {
+				health.Status = models.HealthStatusDead
+			}
+			return health
+		}
+		current = location
+	}
+}
+
+// do выполняет запрос к адресу и возвращает код ответа и абсолютный адрес перенаправления
+func (c *Checker) do(ctx context.Context, raw string) (int, string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return 0, "", fmt.Errorf("parse url error: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return 0, "", ErrSchemeNotAllowed
+	}
+
+	res, err := c.request(ctx, http.MethodHead, u)
+	if err == nil && (res.StatusCode == http.StatusMethodNotAllowed || res.StatusCode == http.StatusNotImplemented) {
+		res.Body.Close()
+		res, err = c.request(ctx, http.MethodGet, u)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxBodySize))
+
+	location := res.Header.Get("Location")
+	if res.StatusCode < http.StatusMultipleChoices || res.StatusCode >= http.StatusBadRequest || location == "" {
+		return res.StatusCode, "", nil
+	}
+
+	next, err := u.Parse(location)
+	if err != nil {
+		return res.StatusCode, "", fmt.Errorf("parse location error: %w", err)
+	}
+	return res.StatusCode, next.String(), nil
+}
+
+// request выполняет запрос с заданным методом
+func (c *Checker) request(ctx context.Context, method string, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request error: %w", strings.ToLower(method), err)
+	}
+	return res, nil
+}
+
+// control проверяет адрес соединения после разрешения имени
+func control(address string, allow []netip.Prefix) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, ErrAddressNotAllowed)
+	}
+
+	if addr := addrPort.Addr().Unmap(); !allowed(addr, allow) {
+		return fmt.Errorf("%s: %w", addr, ErrAddressNotAllowed)
+	}
+	return nil
+}
+
+// allowed проверяет, что адрес публичный или входит в список разрешенных сетей
+func allowed(addr netip.Addr, allow []netip.Prefix) bool {
+	for _, prefix := range allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsMulticast() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, prefix := range reserved {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
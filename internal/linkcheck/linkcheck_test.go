@@ -0,0 +1,144 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(res http.ResponseWriter, _ *http.Request) {
+		res.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/get-only", func(res http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodHead {
+			res.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/moved", func(res http.ResponseWriter, req *http.Request) {
+		http.Redirect(res, req, "/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop-a", func(res http.ResponseWriter, req *http.Request) {
+		http.Redirect(res, req, "/loop-b", http.StatusFound)
+	})
+	mux.HandleFunc("/loop-b", func(res http.ResponseWriter, req *http.Request) {
+		http.Redirect(res, req, "/loop-a", http.StatusFound)
+	})
+	mux.HandleFunc("/gone", func(res http.ResponseWriter, _ *http.Request) {
+		res.WriteHeader(http.StatusGone)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheck(t *testing.T) {
+	srv := newTestServer(t)
+	allow, err := ParseAllowlist("127.0.0.1, ::1/128")
+	require.NoError(t, err)
+	c := NewChecker(time.Second, allow)
+
+	tests := []struct {
+		name     string
+		path     string
+		status   string
+		code     int
+		finalURL string
+	}{
+		{name: "ok", path: "/ok", status: models.HealthStatusOK, code: http.StatusOK, finalURL: "/ok"},
+		{name: "head not allowed", path: "/get-only", status: models.HealthStatusOK, code: http.StatusOK, finalURL: "/get-only"},
+		{name: "redirect", path: "/moved", status: models.HealthStatusOK, code: http.StatusOK, finalURL: "/ok"},
+		{name: "redirect loop", path: "/loop-a", status: models.HealthStatusRedirectLoop, code: http.StatusFound, finalURL: "/loop-a"},
+		{name: "dead", path: "/gone", status: models.HealthStatusDead, code: http.StatusGone, finalURL: "/gone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := c.Check(context.Background(), srv.URL+tt.path)
+
+			assert.Equal(t, tt.status, health.Status)
+			assert.Equal(t, tt.code, health.StatusCode)
+			assert.Equal(t, srv.URL+tt.finalURL, health.FinalURL)
+			assert.Empty(t, health.Error)
+			assert.False(t, health.CheckedAt.IsZero())
+		})
+	}
+}
+
+func TestCheckRefused(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewChecker(time.Second, nil)
+
+	health := c.Check(context.Background(), srv.URL+"/ok")
+	assert.Equal(t, models.HealthStatusRefused, health.Status)
+	assert.Contains(t, health.Error, ErrAddressNotAllowed.Error())
+
+	health = c.Check(context.Background(), "ftp://example.com/file")
+	assert.Equal(t, models.HealthStatusRefused, health.Status)
+
+	health = c.Check(context.Background(), "http://127.0.0.1:1/")
+	assert.Equal(t, models.HealthStatusRefused, health.Status)
+}
+
+func TestCheckDead(t *testing.T) {
+	allow, err := ParseAllowlist("127.0.0.0/8")
+	require.NoError(t, err)
+	c := NewChecker(time.Second, allow)
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	health := c.Check(context.Background(), srv.URL)
+	assert.Equal(t, models.HealthStatusDead, health.Status)
+	assert.NotEmpty(t, health.Error)
+}
+
+func TestAllowed(t *testing.T) {
+	allow, err := ParseAllowlist("10.1.0.0/16")
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "10.1.2.3", want: true},
+		{addr: "10.2.0.1"},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "169.254.169.254"},
+		{addr: "192.168.1.1"},
+		{addr: "100.64.0.1"},
+		{addr: "0.0.0.0"},
+		{addr: "fd00::1"},
+		{addr: "fe80::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, allowed(netip.MustParseAddr(tt.addr), allow))
+		})
+	}
+}
+
+func TestParseAllowlist(t *testing.T) {
+	allow, err := ParseAllowlist("")
+	require.NoError(t, err)
+	assert.Empty(t, allow)
+
+	_, err = ParseAllowlist("10.0.0.0/8,localhost")
+	assert.ErrorIs(t, err, ErrPrefixNotValid)
+}
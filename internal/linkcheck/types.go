@@ -0,0 +1,44 @@
+// Package linkcheck содержит проверку доступности адресов назначения с защитой от SSRF
+package linkcheck
+
+import (
+	"errors"
+	"net/http"
+	"net/netip"
+)
+
+// Ограничения проверки
+const (
+	maxRedirects = 10      // Максимальное количество перенаправлений
+	maxBodySize  = 1 << 16 // Максимальный размер тела ответа GET запроса, который дочитывается перед закрытием
+)
+
+// userAgent заголовок User-Agent запросов проверки
+const userAgent = "go-shortener-linkcheck/1.0"
+
+var (
+	// ErrAddressNotAllowed возвращается когда адрес разрешается во внутреннюю сеть, не входящую в список разрешенных
+	ErrAddressNotAllowed = errors.New("address is not allowed")
+	// ErrSchemeNotAllowed возвращается когда схема адреса отличается от http и https
+	ErrSchemeNotAllowed = errors.New("scheme is not allowed")
+	// ErrPrefixNotValid возвращается когда элемент списка разрешенных сетей не является CIDR или IP адресом
+	ErrPrefixNotValid = errors.New("allowlist prefix is invalidate")
+)
+
+// reserved специальные сети, не являющиеся частными по net/netip, но недоступные из интернета
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// Checker проверяет доступность адресов назначения
+// Запросы к внутренним, локальным и зарезервированным адресам отклоняются на этапе соединения,
+// поэтому защита действует и для адресов после перенаправлений, и при подмене DNS
+type Checker struct {
+	client *http.Client // Клиент без следования перенаправлениям и без прокси
+}
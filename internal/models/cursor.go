@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrCursorNotValid возвращается при разборе некорректного курсора пагинации
+var ErrCursorNotValid = errors.New("cursor is invalidate")
+
+// Cursor позиция курсорной пагинации: время создания и UUID последнего URL страницы
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode кодирует курсор в непрозрачную строку для передачи клиенту.
+func (c *Cursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + "_" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Less сообщает, предшествует ли позиция (createdAt, id) курсору в порядке возрастания.
+func (c *Cursor) Less(createdAt time.Time, id uuid.UUID) bool {
+	if !createdAt.Equal(c.CreatedAt) {
+		return createdAt.Before(c.CreatedAt)
+	}
+	return strings.Compare(id.String(), c.ID.String()) < 0
+}
+
+// ParseCursor декодирует курсор, полученный от клиента.
+// Возвращает ErrCursorNotValid если строка не является курсором.
+func ParseCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrCursorNotValid
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return nil, ErrCursorNotValid
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrCursorNotValid
+	}
+
+	u, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrCursorNotValid
+	}
+
+	return &Cursor{
+		CreatedAt: time.Unix(0, n).UTC(),
+		ID:        u,
+	}, nil
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/IvanKondrashkov/go-shortener/internal/config"
 	"github.com/google/uuid"
 )
@@ -8,11 +12,15 @@ import (
 // RequestBatchToEvents маппер для преобразования RequestShortenAPIBatch в Event.
 func RequestBatchToEvents(batch []*RequestShortenAPIBatch) ([]*Event, error) {
 	res := make([]*Event, 0, len(batch))
+	createdAt := time.Now().UTC()
 	for _, b := range batch {
 		event := &Event{
-			ID:          b.CorrelationID,
-			ShortURL:    uuid.NewSHA1(uuid.NameSpaceURL, []byte(b.OriginalURL)).String(),
-			OriginalURL: b.OriginalURL,
+			ID:           b.CorrelationID,
+			ShortURL:     b.LinkID().String(),
+			OriginalURL:  b.OriginalURL,
+			CreatedAt:    createdAt,
+			PasswordHash: b.PasswordHash,
+			LinkMeta:     b.LinkMeta,
 		}
 		res = append(res, event)
 	}
@@ -22,26 +30,154 @@ func RequestBatchToEvents(batch []*RequestShortenAPIBatch) ([]*Event, error) {
 // RequestBatchUserToEvents маппер для преобразования RequestShortenAPIBatch в Event, пользователя.
 func RequestBatchUserToEvents(userID uuid.UUID, batch []*RequestShortenAPIBatch) ([]*Event, error) {
 	res := make([]*Event, 0, len(batch))
+	createdAt := time.Now().UTC()
 	for _, b := range batch {
 		event := &Event{
-			ID:          userID,
-			ShortURL:    uuid.NewSHA1(uuid.NameSpaceURL, []byte(b.OriginalURL)).String(),
-			OriginalURL: b.OriginalURL,
+			ID:           userID,
+			ShortURL:     b.LinkID().String(),
+			OriginalURL:  b.OriginalURL,
+			CreatedAt:    createdAt,
+			PasswordHash: b.PasswordHash,
+			LinkMeta:     b.LinkMeta,
 		}
 		res = append(res, event)
 	}
 	return res, nil
 }
 
-// RequestBatchToResponseBatch маппер для преобразования RequestShortenAPIBatch в ResponseShortenAPIBatch.
-func RequestBatchToResponseBatch(batch []*RequestShortenAPIBatch) ([]*ResponseShortenAPIBatch, error) {
-	res := make([]*ResponseShortenAPIBatch, 0, len(batch))
-	for _, b := range batch {
-		resp := &ResponseShortenAPIBatch{
-			CorrelationID: b.CorrelationID,
-			ShortURL:      config.URL + uuid.NewSHA1(uuid.NameSpaceURL, []byte(b.OriginalURL)).String(),
+// LinkToResponseUser маппер для преобразования Link в ResponseShortenAPIUser.
+func LinkToResponseUser(link *Link) *ResponseShortenAPIUser {
+	return &ResponseShortenAPIUser{
+		ID:          link.ID,
+		ShortURL:    config.URL + link.ID.String(),
+		OriginalURL: link.OriginalURL,
+		CreatedAt:   link.CreatedAt,
+		IsDeleted:   link.IsDeleted,
+		Clicks:      link.Clicks,
+		Protected:   link.PasswordHash != "",
+		LinkMeta:    link.LinkMeta,
+
+		VariantClicks: variantClicks(link),
+		ScheduleState: link.ScheduleState,
+		Disabled:      link.Disabled,
+		Health:        link.Health,
+		Page:          link.Page,
+	}
+}
+
+// variantClicks возвращает количество переходов по текущим вариантам ссылки.
+// Переходы по удаленным из ссылки вариантам не возвращаются.
+func variantClicks(link *Link) map[string]int64 {
+	if len(link.Variants) == 0 {
+		return nil
+	}
+
+	res := make(map[string]int64, len(link.Variants))
+	for _, v := range link.Variants {
+		res[v.URL] = link.VariantClicks[v.URL]
+	}
+	return res
+}
+
+// EventToLink маппер для преобразования Event в Link.
+// Время создания событий, записанных до его появления в формате файла, заменяется текущим.
+func EventToLink(event *Event) (*Link, error) {
+	id, err := uuid.Parse(event.ShortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := event.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+
+	userID := event.ID
+	return &Link{
+		ID:           id,
+		UserID:       &userID,
+		OriginalURL:  event.OriginalURL,
+		CreatedAt:    createdAt.UTC(),
+		PasswordHash: event.PasswordHash,
+		LinkMeta:     event.LinkMeta,
+	}, nil
+}
+
+// EventToHistory маппер для преобразования Event изменения URL в URLHistory.
+func EventToHistory(event *Event) (*URLHistory, error) {
+	id, err := uuid.Parse(event.ShortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &URLHistory{
+		ID:        id,
+		UserID:    event.ID,
+		OldURL:    event.OldURL,
+		NewURL:    event.OriginalURL,
+		ChangedAt: event.CreatedAt.UTC(),
+	}, nil
+}
+
+// EventToFolder маппер для преобразования Event папки в Folder.
+func EventToFolder(event *Event) (*Folder, error) {
+	if event.FolderID == nil {
+		return nil, errors.New("folder id is empty")
+	}
+
+	return &Folder{
+		ID:        *event.FolderID,
+		UserID:    event.ID,
+		Name:      event.Name,
+		CreatedAt: event.CreatedAt.UTC(),
+	}, nil
+}
+
+// EventToTemplate маппер для преобразования Event шаблона в UTMTemplate.
+func EventToTemplate(event *Event) (*UTMTemplate, error) {
+	if event.TemplateID == nil {
+		return nil, errors.New("template id is empty")
+	}
+
+	template := &UTMTemplate{
+		ID:        *event.TemplateID,
+		UserID:    event.ID,
+		Name:      event.Name,
+		CreatedAt: event.CreatedAt.UTC(),
+	}
+	if event.UTM != nil {
+		template.UTMParams = *event.UTM
+	}
+	return template, nil
+}
+
+// EventToWebhook преобразует событие файлового хранилища в webhook пользователя.
+func EventToWebhook(event *Event) (*Webhook, error) {
+	if event.Webhook == nil {
+		return nil, errors.New("webhook is empty")
+	}
+
+	webhook := *event.Webhook
+	webhook.UserID = event.ID
+	webhook.CreatedAt = webhook.CreatedAt.UTC()
+	return &webhook, nil
+}
+
+// NormalizeTags удаляет пустые и повторяющиеся теги, обрезая пробелы и приводя их к нижнему регистру.
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	res := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if _, ok := seen[tag]; ok || tag == "" {
+			continue
 		}
-		res = append(res, resp)
+		seen[tag] = struct{}{}
+		res = append(res, tag)
 	}
-	return res, nil
+	return res
 }
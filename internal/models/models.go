@@ -2,48 +2,480 @@
 package models
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/google/uuid"
 )
 
 // RequestShortenAPI запрос на сокращение URL
 // @Description Запрос на создание сокращенного URL
 type RequestShortenAPI struct {
-	URL string `json:"url"`
+	URL      string `json:"url"`
+	QR       bool   `json:"qr,omitempty"`       // Вернуть QR-код короткой ссылки
+	Password string `json:"password,omitempty"` // Пароль для перехода по ссылке (хранится только bcrypt хэш)
+	Template string `json:"template,omitempty"` // Название шаблона UTM разметки пользователя
+	LinkMeta
 }
 
 // ResponseShortenAPI ответ с сокращенным URL
 // @Description Сокращенный URL
 type ResponseShortenAPI struct {
 	Result string `json:"result"`
+	QR     string `json:"qr,omitempty"` // QR-код короткой ссылки (data URI PNG)
 }
 
 // RequestShortenAPIBatch элемент пакетного запроса на сокращение
 // @Description Элемент пакетного запроса на сокращение URL
 type RequestShortenAPIBatch struct {
+	ID            uuid.UUID `json:"-"` // UUID сокращенного URL, выбирается сервисом перед сохранением
 	CorrelationID uuid.UUID `json:"correlation_id"`
 	OriginalURL   string    `json:"original_url"`
+	QR            bool      `json:"qr,omitempty"`       // Вернуть QR-код короткой ссылки
+	Password      string    `json:"password,omitempty"` // Пароль для перехода по ссылке
+	PasswordHash  string    `json:"-"`                  // bcrypt хэш пароля, вычисляется перед сохранением
+	Template      string    `json:"template,omitempty"` // Название шаблона UTM разметки пользователя
+	LinkMeta
 }
 
 // ResponseShortenAPIBatch элемент пакетного ответа с сокращенным URL
-// @Description Элемент пакетного ответа с сокращенным URL
+// @Description Результат сокращения элемента пакета: статус, короткий URL или причина отказа
 type ResponseShortenAPIBatch struct {
 	CorrelationID uuid.UUID `json:"correlation_id"`
-	ShortURL      string    `json:"short_url"`
+	ShortURL      string    `json:"short_url,omitempty"` // Пустой для элементов invalid и blocked
+	Status        string    `json:"status"`              // Результат сокращения (created, existing, invalid, blocked)
+	Error         string    `json:"error,omitempty"`     // Причина, по которой элемент не сохранен
+	QR            string    `json:"qr,omitempty"`        // QR-код короткой ссылки (data URI PNG)
+}
+
+// ResponseShortenAPIImport строка потокового ответа импорта
+// @Description Результат обработки строки импорта с ее номером во входных данных
+type ResponseShortenAPIImport struct {
+	Line int `json:"line"` // Номер строки тела запроса, начиная с 1
+	ResponseShortenAPIBatch
+}
+
+// ImportLink ссылка из экспорта другого сервиса сокращения URL
+type ImportLink struct {
+	Slug        string    // Короткое имя ссылки в исходном сервисе, пустое если его нет в экспорте
+	OriginalURL string    // Адрес назначения
+	CreatedAt   time.Time // Время создания в исходном сервисе, нулевое если его нет в экспорте
+	Title       string    // Название ссылки
+	Tags        []string  // Теги ссылки
+}
+
+// ResponseImportLink результат импорта ссылки из другого сервиса
+// @Description Результат импорта ссылки: статус и короткий URL с сохраненным коротким именем или UUID
+type ResponseImportLink struct {
+	ID          uuid.UUID `json:"-"`                   // UUID сохраненной ссылки, пустой если ссылка не сохранена
+	Slug        string    `json:"slug,omitempty"`      // Короткое имя ссылки в исходном сервисе
+	OriginalURL string    `json:"original_url"`        // Адрес назначения
+	ShortURL    string    `json:"short_url,omitempty"` // Пустой для элементов invalid, blocked и collision
+	Status      string    `json:"status"`              // Результат импорта (created, existing, invalid, blocked, collision)
+	Error       string    `json:"error,omitempty"`     // Причина, по которой элемент не сохранен
+}
+
+// Alias дополнительное короткое имя ссылки, сохраненное при импорте из другого сервиса
+type Alias struct {
+	Slug      string    // Короткое имя
+	LinkID    uuid.UUID // UUID ссылки
+	CreatedAt time.Time // Время сохранения короткого имени (UTC)
 }
 
 // ResponseShortenAPIUser элемент ответа с URL пользователя
 // @Description Информация о сокращенном URL пользователя
 type ResponseShortenAPIUser struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
+	ID          uuid.UUID `json:"-"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	IsDeleted   bool      `json:"is_deleted,omitempty"`
+	Clicks      int64     `json:"clicks,omitempty"`
+	Protected   bool      `json:"protected,omitempty"`
+
+	VariantClicks map[string]int64 `json:"variant_clicks,omitempty"`  // Количество переходов по вариантам адреса назначения
+	ScheduleState string           `json:"schedule_state,omitempty"`  // Состояние окна активности (pending, active, expired)
+	Disabled      string           `json:"disabled_reason,omitempty"` // Причина отключения ссылки администратором
+	Health        *LinkHealth      `json:"health,omitempty"`          // Результат последней проверки доступности адреса назначения
+	Page          *PageMeta        `json:"page,omitempty"`            // Метаданные страницы назначения (title, OpenGraph, favicon)
+	LinkMeta
+}
+
+// LinkMeta пользовательские атрибуты сокращенного URL
+// @Description Название, теги, заметка и папка сокращенного URL
+type LinkMeta struct {
+	Title    string     `json:"title,omitempty"`
+	Tags     []string   `json:"tags,omitempty"`
+	Note     string     `json:"note,omitempty"`
+	FolderID *uuid.UUID `json:"folder_id,omitempty"`
+
+	Interstitial bool `json:"interstitial,omitempty"`  // Показывать страницу-предупреждение вместо перенаправления
+	RedirectCode int  `json:"redirect_code,omitempty"` // Код перенаправления (301, 302, 303, 307, 308), 0 - код по умолчанию
+
+	Passthrough string `json:"passthrough,omitempty"` // Передача пути и параметров запроса (append, override, ignore)
+
+	Rules []TargetRule `json:"rules,omitempty"` // Правила выбора альтернативного адреса назначения
+
+	Variants []Variant `json:"variants,omitempty"` // Варианты адреса назначения для A/B тестирования
+
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`  // Начало окна активности (хранится в UTC)
+	ActiveUntil *time.Time `json:"active_until,omitempty"` // Конец окна активности, не включая (хранится в UTC)
+	FallbackURL string     `json:"fallback_url,omitempty"` // Адрес назначения вне окна активности
+}
+
+// Variant вариант адреса назначения для A/B тестирования
+// @Description Вариант выбирается случайно пропорционально весу и закрепляется за посетителем
+type Variant struct {
+	URL    string `json:"url"`    // Адрес назначения
+	Weight int    `json:"weight"` // Вес варианта, 0 - вариант не выдается новым посетителям
+}
+
+// TargetRule правило выбора адреса назначения по устройству, языку или стране клиента
+// @Description Правило срабатывает, если совпадают все заданные условия
+type TargetRule struct {
+	Platform string `json:"platform,omitempty"` // Платформа клиента (ios, android, desktop)
+	Language string `json:"language,omitempty"` // Предпочитаемый язык клиента (ru, pt-BR)
+	Country  string `json:"country,omitempty"`  // Страна клиента по базе GeoIP (ISO 3166-1 alpha-2)
+	URL      string `json:"url"`                // Адрес назначения
+}
+
+// Folder папка пользователя для группировки сокращенных URL
+// @Description Папка пользователя
+type Folder struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UTMParams параметры UTM разметки ссылки
+// @Description Значения параметров utm_source, utm_medium, utm_campaign и utm_content
+type UTMParams struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// UTMTemplate именованный шаблон UTM разметки пользователя
+// @Description Шаблон UTM параметров, применяемый при сокращении URL
+type UTMTemplate struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UTMParams
+}
+
+// RequestUTMTemplate запрос на создание или изменение шаблона UTM разметки
+// @Description Название шаблона и значения UTM параметров
+type RequestUTMTemplate struct {
+	Name string `json:"name"`
+	UTMParams
+}
+
+// Webhook адрес пользователя для уведомлений о событиях жизненного цикла ссылок
+// @Description Адрес, подписанные события и секрет подписи HMAC-SHA256. Секрет возвращается только при создании и ротации
+type Webhook struct {
+	ID             uuid.UUID  `json:"id"`
+	UserID         uuid.UUID  `json:"-"`
+	URL            string     `json:"url"`
+	Events         []string   `json:"events"`
+	Secret         string     `json:"secret,omitempty"`
+	PreviousSecret string     `json:"previous_secret,omitempty"` // Секрет до ротации, подписи которым отправляются в течение config.WebhookSecretGrace
+	RotatedAt      *time.Time `json:"rotated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RequestWebhook запрос на создание webhook
+// @Description Адрес получателя и события, на которые он подписан
+type RequestWebhook struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// WebhookEvent событие жизненного цикла ссылки, отправляемое на адреса пользователя
+// @Description Тело уведомления, подписанное HMAC-SHA256 в заголовке X-Webhook-Signature
+type WebhookEvent struct {
+	ID          uuid.UUID `json:"id"`
+	Type        string    `json:"type"`
+	UserID      uuid.UUID `json:"-"`
+	LinkID      uuid.UUID `json:"link_id"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url,omitempty"`
+	Clicks      int64     `json:"clicks,omitempty"` // Количество переходов для события link.click_threshold
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WebhookDelivery попытка доставки события на адрес пользователя
+// @Description Запись журнала доставок webhook
+type WebhookDelivery struct {
+	ID         uuid.UUID `json:"id"`
+	WebhookID  uuid.UUID `json:"-"`
+	EventID    uuid.UUID `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	Status     string    `json:"status"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OutboxEvent доменное событие, записанное в outbox вместе с изменением данных
+// @Description Событие, которое relay публикует получателям outbox
+type OutboxEvent struct {
+	ID          uuid.UUID  `json:"id"`
+	Type        string     `json:"type"`
+	LinkID      uuid.UUID  `json:"link_id"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// StreamEvent событие потока SSE пользователя о переходах и удалениях его ссылок
+// @Description Данные события, номер события передается в поле id потока
+type StreamEvent struct {
+	ID        uint64    `json:"-"`
+	Type      string    `json:"type"`
+	UserID    uuid.UUID `json:"-"`
+	LinkID    uuid.UUID `json:"link_id"`
+	ShortURL  string    `json:"short_url"`
+	Clicks    int64     `json:"clicks,omitempty"`  // Количество переходов после события click
+	Variant   string    `json:"variant,omitempty"` // Адрес выбранного варианта для события click
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RequestFolder запрос на создание или переименование папки
+// @Description Название папки
+type RequestFolder struct {
+	Name string `json:"name"`
+}
+
+// RequestUpdateURL запрос на изменение оригинального URL
+// @Description Новый оригинальный URL для существующего сокращенного URL
+type RequestUpdateURL struct {
+	URL string `json:"url"`
+}
+
+// RequestDisableURL запрос администратора на отключение URL
+// @Description Причина отключения сокращенного URL (по умолчанию abuse)
+type RequestDisableURL struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// URLHistory запись истории изменений оригинального URL
+// @Description Изменение оригинального URL: кто, когда, старое и новое значение
+type URLHistory struct {
+	ID        uuid.UUID `json:"-"`
+	UserID    uuid.UUID `json:"user_id"`
+	OldURL    string    `json:"old_url"`
+	NewURL    string    `json:"new_url"`
+	ChangedAt time.Time `json:"changed_at"`
 }
 
+// Link запись сокращенного URL в хранилище
+// @Description Сокращенный URL с атрибутами хранения
+type Link struct {
+	ID           uuid.UUID  // UUID сокращенного URL
+	UserID       *uuid.UUID // UUID владельца, nil для анонимных URL
+	OriginalURL  string     // Оригинальный URL
+	CreatedAt    time.Time  // Время создания (UTC)
+	IsDeleted    bool       // Признак удаления
+	Clicks       int64      // Количество переходов
+	PasswordHash string     // bcrypt хэш пароля, пустой для ссылок без пароля
+
+	VariantClicks map[string]int64 // Количество переходов по адресам вариантов
+	ScheduleState string           // Состояние окна активности, зафиксированное воркером
+	Disabled      string           // Причина отключения ссылки администратором, пустая для активных ссылок
+	Health        *LinkHealth      // Результат последней проверки доступности, nil для непроверенных ссылок
+	Page          *PageMeta        // Метаданные страницы назначения, nil пока страница не загружена
+	LinkMeta                       // Пользовательские атрибуты
+}
+
+// PageMeta метаданные страницы назначения
+// @Description Заголовок, описание и изображение OpenGraph, title и favicon страницы назначения
+type PageMeta struct {
+	Title       string    `json:"title,omitempty"`       // og:title, а при его отсутствии содержимое <title>
+	Description string    `json:"description,omitempty"` // og:description, а при его отсутствии meta description
+	Image       string    `json:"image,omitempty"`       // Абсолютный адрес og:image
+	Favicon     string    `json:"favicon,omitempty"`     // Абсолютный адрес иконки страницы
+	FetchedAt   time.Time `json:"fetched_at"`            // Время загрузки страницы (UTC)
+}
+
+// LinkHealth результат проверки доступности адреса назначения
+// @Description Код ответа, конечный адрес после перенаправлений, время ответа и время проверки
+type LinkHealth struct {
+	Status     string    `json:"status"`                // Результат проверки (ok, dead, redirect_loop, refused)
+	StatusCode int       `json:"status_code,omitempty"` // Код последнего ответа
+	FinalURL   string    `json:"final_url,omitempty"`   // Адрес назначения после всех перенаправлений
+	LatencyMs  int64     `json:"latency_ms"`            // Время проверки (в миллисекундах)
+	Error      string    `json:"error,omitempty"`       // Ошибка запроса
+	CheckedAt  time.Time `json:"checked_at"`            // Время проверки (UTC)
+}
+
+// CacheStats счетчики кэширующего хранилища
+// @Description Попадания и промахи кэша записей URL с момента запуска сервиса и текущий размер кэша
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`   // Количество попаданий в кэш
+	Misses uint64 `json:"misses"` // Количество промахов кэша
+	Size   int    `json:"size"`   // Текущее количество записей в кэше
+}
+
+// IdempotencyRecord ответ на запрос пользователя с заголовком Idempotency-Key
+// @Description Код, тип содержимого и тело первого ответа, которые повторяются на запросы с тем же ключом
+type IdempotencyRecord struct {
+	UserID      uuid.UUID // UUID пользователя
+	Key         string    // Значение заголовка Idempotency-Key
+	RequestHash string    // SHA-256 метода, пути и тела первого запроса (hex)
+	Status      int       // Код ответа, 0 пока первый запрос выполняется
+	ContentType string    // Заголовок Content-Type ответа
+	Body        []byte    // Тело ответа
+	ExpiresAt   time.Time // Время, после которого ключ можно использовать повторно (UTC)
+}
+
+// FilterURLs параметры выборки URL пользователя
+// @Description Курсорная пагинация, сортировка и фильтры списка URL пользователя
+type FilterURLs struct {
+	Limit       int        // Максимальное количество URL в ответе
+	Cursor      *Cursor    // Позиция, после которой начинается выборка
+	Desc        bool       // Сортировка по убыванию времени создания
+	Domain      string     // Домен оригинального URL
+	CreatedFrom *time.Time // Нижняя граница времени создания (включительно)
+	CreatedTo   *time.Time // Верхняя граница времени создания (не включительно)
+	WithDeleted bool       // Включать удаленные URL
+	Search      string     // Подстрока оригинального URL
+	Tag         string     // Тег URL
+	FolderID    *uuid.UUID // Папка URL
+}
+
+// Типы событий файлового хранилища
+const (
+	EventTypeSave   = ""       // Сохранение URL (события без типа)
+	EventTypeUpdate = "update" // Изменение оригинального URL пользователем
+	EventTypeClick  = "click"  // Переход по сокращенному URL
+	EventTypeDelete = "delete" // Удаление URL пользователем; UUID пользователя в поле uuid, UUID URL в поле short_url
+
+	EventTypeFolderSave   = "folder_save"   // Создание папки
+	EventTypeFolderUpdate = "folder_update" // Переименование папки
+	EventTypeFolderDelete = "folder_delete" // Удаление папки
+
+	EventTypeTemplateSave   = "template_save"   // Создание шаблона UTM разметки
+	EventTypeTemplateUpdate = "template_update" // Изменение шаблона UTM разметки
+	EventTypeTemplateDelete = "template_delete" // Удаление шаблона UTM разметки
+
+	EventTypeWebhookSave   = "webhook_save"   // Создание webhook
+	EventTypeWebhookUpdate = "webhook_update" // Ротация секрета webhook
+	EventTypeWebhookDelete = "webhook_delete" // Удаление webhook
+
+	EventTypeOutbox       = "outbox"        // Запись доменного события в outbox
+	EventTypeOutboxDelete = "outbox_delete" // Доменное событие опубликовано relay, UUID события в поле ID
+
+	EventTypeSchedule = "schedule" // Смена состояния окна активности URL
+	EventTypeDisable  = "disable"  // Отключение или включение URL администратором
+	EventTypeHealth   = "health"   // Результат проверки доступности адреса назначения
+	EventTypePage     = "page"     // Метаданные страницы назначения
+	EventTypeAlias    = "alias"    // Короткое имя ссылки, перенесенное импортом; UUID ссылки в поле short_url, имя в поле name
+)
+
 // Event элемент события для записи в файловое хранилище
 // @Description Информация о сокращенном URL пользователя
 type Event struct {
-	ID          uuid.UUID `json:"uuid"`
-	ShortURL    string    `json:"short_url"`
-	OriginalURL string    `json:"original_url"`
+	Type         string       `json:"type,omitempty"`
+	ID           uuid.UUID    `json:"uuid"`
+	ShortURL     string       `json:"short_url"`
+	OriginalURL  string       `json:"original_url"`
+	OldURL       string       `json:"old_url,omitempty"`
+	Name         string       `json:"name,omitempty"`
+	PasswordHash string       `json:"password_hash,omitempty"`
+	TemplateID   *uuid.UUID   `json:"template_id,omitempty"`
+	UTM          *UTMParams   `json:"utm,omitempty"`
+	Variant      string       `json:"variant,omitempty"` // Адрес варианта, выбранного при переходе
+	State        string       `json:"state,omitempty"`   // Новое состояние окна активности URL
+	Reason       string       `json:"reason,omitempty"`  // Причина отключения URL, пустая при включении
+	Health       *LinkHealth  `json:"health,omitempty"`  // Результат проверки доступности адреса назначения
+	Page         *PageMeta    `json:"page,omitempty"`    // Метаданные страницы назначения
+	Webhook      *Webhook     `json:"webhook,omitempty"` // Webhook пользователя, владелец в поле ID
+	Outbox       *OutboxEvent `json:"outbox,omitempty"`  // Доменное событие outbox
+	CreatedAt    time.Time    `json:"created_at"`
+	LinkMeta
+}
+
+// Платформы клиента для правил выбора адреса назначения
+const (
+	PlatformIOS     = "ios"     // iPhone, iPad, iPod
+	PlatformAndroid = "android" // Устройства Android
+	PlatformDesktop = "desktop" // Остальные клиенты
+)
+
+// Типы событий жизненного цикла ссылки, на которые подписываются webhook
+const (
+	WebhookEventCreated        = "link.created"         // Ссылка создана
+	WebhookEventDeleted        = "link.deleted"         // Ссылка удалена воркером удаления
+	WebhookEventExpired        = "link.expired"         // Окно активности ссылки закончилось
+	WebhookEventClickThreshold = "link.click_threshold" // Количество переходов достигло порога из config.WebhookClickThresholds
+)
+
+// Типы доменных событий outbox
+const (
+	OutboxEventSaved   = "link.saved"   // Ссылка сохранена, в том числе пакетом
+	OutboxEventDeleted = "link.deleted" // Ссылка удалена пользователем
+)
+
+// Типы событий потока SSE
+const (
+	StreamEventClick  = "click"  // Переход по ссылке
+	StreamEventDelete = "delete" // Ссылка удалена
+	StreamEventReset  = "reset"  // События после Last-Event-ID уже вытеснены из буфера, данные нужно перечитать
+)
+
+// Статусы элементов пакетного сокращения и импорта URL
+const (
+	BatchStatusCreated   = "created"   // URL сохранен
+	BatchStatusExisting  = "existing"  // URL уже был сокращен ранее и не изменен
+	BatchStatusInvalid   = "invalid"   // URL, шаблон, пароль или атрибуты невалидны, элемент не сохранен
+	BatchStatusBlocked   = "blocked"   // Адрес назначения запрещен, элемент не сохранен
+	BatchStatusFailed    = "failed"    // Импорт прерван ошибкой хранилища или чтения тела запроса, элемент не сохранен
+	BatchStatusCollision = "collision" // Короткое имя из другого сервиса уже занято другой ссылкой, элемент не сохранен
+	BatchStatusDeleted   = "deleted"   // Ссылка на этот URL удалена пользователем и не восстанавливается импортом
+)
+
+// Результаты попытки доставки webhook
+const (
+	DeliveryStatusDelivered = "delivered" // Получатель ответил кодом 2xx
+	DeliveryStatusRetry     = "retry"     // Попытка не удалась и будет повторена
+	DeliveryStatusFailed    = "failed"    // Попытка не удалась, повторов больше не будет
+)
+
+// Состояния окна активности ссылки
+const (
+	ScheduleStatePending = "pending" // Окно активности еще не началось
+	ScheduleStateActive  = "active"  // Ссылка активна
+	ScheduleStateExpired = "expired" // Окно активности закончилось
+)
+
+// Результаты проверки доступности адреса назначения
+const (
+	HealthStatusOK           = "ok"            // Адрес назначения доступен
+	HealthStatusDead         = "dead"          // Адрес недоступен или отвечает ошибкой
+	HealthStatusRedirectLoop = "redirect_loop" // Перенаправления зациклены или их слишком много
+	HealthStatusRefused      = "refused"       // Адрес разрешается во внутреннюю сеть и не проверяется
+)
+
+// Политики передачи дополнительного пути и параметров запроса короткой ссылки
+const (
+	PassthroughIgnore   = "ignore"   // Путь и параметры запроса отбрасываются (по умолчанию)
+	PassthroughAppend   = "append"   // Путь добавляется, параметры добавляются к параметрам назначения
+	PassthroughOverride = "override" // Путь добавляется, параметры заменяют одноименные параметры назначения
+)
+
+// ScheduleEvent событие смены состояния окна активности URL
+type ScheduleEvent struct {
+	ID        uuid.UUID  // UUID сокращенного URL
+	UserID    *uuid.UUID // UUID владельца, nil для анонимных URL
+	State     string     // Новое состояние окна активности
+	ChangedAt time.Time  // Время смены состояния (UTC)
 }
 
 // DeleteEvent элемент события для удаления батча URL пользователя
@@ -52,3 +484,54 @@ type DeleteEvent struct {
 	UserID *uuid.UUID
 	Batch  []uuid.UUID
 }
+
+// FetchEvent задача загрузки метаданных страницы назначения URL
+type FetchEvent struct {
+	ID  uuid.UUID // UUID сокращенного URL
+	URL string    // Адрес назначения
+}
+
+// LinkID возвращает UUID сокращенного URL элемента пакета: выбранный сервисом
+// или SHA-1 хэш оригинального URL, если элемент сохраняется в хранилище напрямую.
+func (b *RequestShortenAPIBatch) LinkID() uuid.UUID {
+	if b.ID != uuid.Nil {
+		return b.ID
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(b.OriginalURL))
+}
+
+// IsEmpty сообщает, что ни один пользовательский атрибут не задан.
+func (m *LinkMeta) IsEmpty() bool {
+	return m.Title == "" && len(m.Tags) == 0 && m.Note == "" && m.FolderID == nil && !m.Interstitial &&
+		m.RedirectCode == 0 && m.Passthrough == "" && len(m.Rules) == 0 && len(m.Variants) == 0 &&
+		m.ActiveFrom == nil && m.ActiveUntil == nil && m.FallbackURL == ""
+}
+
+// ScheduleStateAt возвращает состояние окна активности в момент now или пустую строку, если окно не задано.
+func (m *LinkMeta) ScheduleStateAt(now time.Time) string {
+	switch {
+	case m.ActiveFrom == nil && m.ActiveUntil == nil:
+		return ""
+	case m.ActiveFrom != nil && now.Before(*m.ActiveFrom):
+		return ScheduleStatePending
+	case m.ActiveUntil != nil && !now.Before(*m.ActiveUntil):
+		return ScheduleStateExpired
+	default:
+		return ScheduleStateActive
+	}
+}
+
+// IsRedirectCode сообщает, что код является поддерживаемым кодом перенаправления.
+func IsRedirectCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// IsPermanentRedirect сообщает, что код перенаправления постоянный и может кэшироваться клиентами.
+func IsPermanentRedirect(code int) bool {
+	return code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+}
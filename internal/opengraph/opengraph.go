@@ -0,0 +1,247 @@
+package opengraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/linkcheck"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// NewFetcher создает загрузчик страниц назначения
+// Принимает:
+// - timeout: таймаут загрузки страницы, включая перенаправления
+// - maxSize: максимальный размер читаемой части страницы (в байтах)
+// - allow: внутренние сети, запросы к которым разрешены (может быть nil)
+func NewFetcher(timeout time.Duration, maxSize int64, allow []netip.Prefix) *Fetcher {
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: linkcheck.NewTransport(timeout, allow),
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return http.ErrUseLastResponse
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return ErrSchemeNotAllowed
+				}
+				return nil
+			},
+		},
+		maxSize: maxSize,
+	}
+}
+
+// Fetch загружает страницу назначения и разбирает ее метаданные
+// Принимает:
+// - ctx: контекст для контроля времени выполнения
+// - raw: адрес назначения
+// Возвращает:
+// - метаданные страницы со временем загрузки в UTC
+// - ErrOptOut, если заголовок X-Robots-Tag или meta robots запрещают использование метаданных,
+// ErrNotHTML, ErrStatusNotOK или ошибку запроса
+func (f *Fetcher) Fetch(ctx context.Context, raw string) (*models.PageMeta, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse url error: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, ErrSchemeNotAllowed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9")
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get request error: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("status %d: %w", res.StatusCode, ErrStatusNotOK)
+	}
+
+	if optOut(res.Header.Values("X-Robots-Tag")...) {
+		return nil, ErrOptOut
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	body, err := charset.NewReader(io.LimitReader(res.Body, f.maxSize), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("decode page error: %w", err)
+	}
+	return Parse(body, res.Request.URL)
+}
+
+// Parse разбирает title, OpenGraph разметку, описание и иконку из заголовка HTML документа
+// Относительные адреса изображения и иконки разрешаются относительно base,
+// при отсутствии иконки в разметке используется /favicon.ico
+// Возвращает ErrOptOut, если meta robots запрещает использование метаданных
+func Parse(r io.Reader, base *url.URL) (*models.PageMeta, error) {
+	var (
+		title, ogTitle, description, ogDescription, image, favicon string
+		inTitle                                                    bool
+	)
+
+	z := html.NewTokenizer(r)
+loop:
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("parse page error: %w", err)
+			}
+			break loop
+		case html.TextToken:
+			if inTitle {
+				title += string(z.Text())
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "title" {
+				inTitle = false
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "body":
+				break loop
+			case "title":
+				inTitle = tt == html.StartTagToken && title == ""
+			case "meta":
+				key := strings.ToLower(attr(tok, "property"))
+				if key == "" {
+					key = strings.ToLower(attr(tok, "name"))
+				}
+				content := attr(tok, "content")
+
+				switch key {
+				case "robots", botName:
+					if optOut(content) {
+						return nil, ErrOptOut
+					}
+				case "og:title":
+					ogTitle = firstNonEmpty(ogTitle, content)
+				case "og:description":
+					ogDescription = firstNonEmpty(ogDescription, content)
+				case "description":
+					description = firstNonEmpty(description, content)
+				case "og:image", "og:image:url", "og:image:secure_url":
+					image = firstNonEmpty(image, resolve(base, content))
+				}
+			case "link":
+				if favicon == "" && hasToken(attr(tok, "rel"), "icon") {
+					favicon = resolve(base, attr(tok, "href"))
+				}
+			}
+		}
+	}
+
+	if favicon == "" {
+		favicon = resolve(base, "/favicon.ico")
+	}
+
+	return &models.PageMeta{
+		Title:       clean(firstNonEmpty(ogTitle, title), maxTitleLength),
+		Description: clean(firstNonEmpty(ogDescription, description), maxDescriptionLength),
+		Image:       image,
+		Favicon:     favicon,
+		FetchedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// optOut проверяет директивы X-Robots-Tag или meta robots
+// Директивы с префиксом другого робота ("googlebot: noindex") не учитываются
+func optOut(values ...string) bool {
+	for _, value := range values {
+		if name, rest, ok := strings.Cut(value, ":"); ok {
+			if !strings.EqualFold(strings.TrimSpace(name), botName) {
+				continue
+			}
+			value = rest
+		}
+
+		for _, directive := range strings.Split(value, ",") {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "noindex", "nosnippet", "none":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// attr возвращает значение атрибута тега
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasToken проверяет, что список через пробел содержит значение без учета регистра
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(list) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve возвращает абсолютный http(s) адрес ref относительно base или пустую строку
+func resolve(base *url.URL, ref string) string {
+	u, err := base.Parse(strings.TrimSpace(ref))
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return ""
+	}
+
+	res := u.String()
+	if len(res) > maxURLLength {
+		return ""
+	}
+	return res
+}
+
+// clean схлопывает пробельные символы и обрезает строку до limit символов
+func clean(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:limit]))
+}
+
+// firstNonEmpty возвращает первое непустое значение после удаления пробелов
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
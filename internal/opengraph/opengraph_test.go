@@ -0,0 +1,156 @@
+package opengraph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/linkcheck"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>  Go &amp;
+ Docs </title>
+<meta name="description" content="Plain description">
+<meta property="og:description" content=" OpenGraph description ">
+<meta property="og:image" content="/img/cover.png">
+<link rel="apple-touch-icon" href="/touch.png">
+<link rel="shortcut icon" href="https://cdn.example.com/favicon.png">
+</head>
+<body>
+<meta property="og:title" content="Ignored in body">
+</body>
+</html>`
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://go.dev/doc/")
+
+	tests := []struct {
+		name        string
+		page        string
+		title       string
+		description string
+		image       string
+		favicon     string
+	}{
+		{
+			name:        "title and opengraph",
+			page:        testPage,
+			title:       "Go & Docs",
+			description: "OpenGraph description",
+			image:       "https://go.dev/img/cover.png",
+			favicon:     "https://cdn.example.com/favicon.png",
+		},
+		{
+			name:    "opengraph title wins",
+			page:    `<head><title>Page</title><meta property="og:title" content="Open Graph"></head>`,
+			title:   "Open Graph",
+			favicon: "https://go.dev/favicon.ico",
+		},
+		{
+			name:    "unsafe image scheme",
+			page:    `<meta property="og:image" content="javascript:alert(1)"><link rel=icon href="data:image/png;base64,AA">`,
+			favicon: "https://go.dev/favicon.ico",
+		},
+		{
+			name:    "long title",
+			page:    "<title>" + strings.Repeat("я", maxTitleLength+10) + "</title>",
+			title:   strings.Repeat("я", maxTitleLength),
+			favicon: "https://go.dev/favicon.ico",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := Parse(strings.NewReader(tt.page), base)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.title, page.Title)
+			assert.Equal(t, tt.description, page.Description)
+			assert.Equal(t, tt.image, page.Image)
+			assert.Equal(t, tt.favicon, page.Favicon)
+			assert.False(t, page.FetchedAt.IsZero())
+		})
+	}
+}
+
+func TestParseOptOut(t *testing.T) {
+	base, _ := url.Parse("https://go.dev/")
+
+	_, err := Parse(strings.NewReader(`<meta name="robots" content="index, nosnippet"><title>Secret</title>`), base)
+	assert.ErrorIs(t, err, ErrOptOut)
+
+	_, err = Parse(strings.NewReader(`<meta name="go-shortener-preview" content="none">`), base)
+	assert.ErrorIs(t, err, ErrOptOut)
+
+	_, err = Parse(strings.NewReader(`<meta name="googlebot" content="noindex"><title>Public</title>`), base)
+	assert.NoError(t, err)
+}
+
+func TestFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = res.Write([]byte(testPage))
+	})
+	mux.HandleFunc("/moved", func(res http.ResponseWriter, req *http.Request) {
+		http.Redirect(res, req, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/cp1251", func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html; charset=windows-1251")
+		_, _ = res.Write([]byte("<title>\xcf\xf0\xe8\xe2\xe5\xf2</title>"))
+	})
+	mux.HandleFunc("/private", func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		res.Header().Add("X-Robots-Tag", "googlebot: index")
+		res.Header().Add("X-Robots-Tag", "go-shortener-preview: noindex")
+		_, _ = res.Write([]byte("<title>Private</title>"))
+	})
+	mux.HandleFunc("/image", func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "image/png")
+	})
+	mux.HandleFunc("/huge", func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		_, _ = res.Write([]byte("<!--" + strings.Repeat("x", 1<<20) + "--><title>Late</title>"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	allow, err := linkcheck.ParseAllowlist("127.0.0.0/8,::1")
+	require.NoError(t, err)
+	f := NewFetcher(time.Second, 64*1024, allow)
+
+	page, err := f.Fetch(context.Background(), srv.URL+"/moved")
+	require.NoError(t, err)
+	assert.Equal(t, "Go & Docs", page.Title)
+	assert.Equal(t, srv.URL+"/img/cover.png", page.Image)
+
+	page, err = f.Fetch(context.Background(), srv.URL+"/cp1251")
+	require.NoError(t, err)
+	assert.Equal(t, "Привет", page.Title)
+
+	page, err = f.Fetch(context.Background(), srv.URL+"/huge")
+	require.NoError(t, err)
+	assert.Empty(t, page.Title)
+
+	_, err = f.Fetch(context.Background(), srv.URL+"/private")
+	assert.ErrorIs(t, err, ErrOptOut)
+
+	_, err = f.Fetch(context.Background(), srv.URL+"/image")
+	assert.ErrorIs(t, err, ErrNotHTML)
+
+	_, err = f.Fetch(context.Background(), srv.URL+"/missing")
+	assert.ErrorIs(t, err, ErrStatusNotOK)
+
+	_, err = NewFetcher(time.Second, 64*1024, nil).Fetch(context.Background(), srv.URL+"/page")
+	assert.ErrorIs(t, err, linkcheck.ErrAddressNotAllowed)
+}
@@ -0,0 +1,39 @@
+// Package opengraph содержит загрузку страниц назначения и разбор их title, OpenGraph разметки и favicon
+package opengraph
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Ограничения метаданных страницы
+const (
+	maxTitleLength       = 300  // Максимальная длина заголовка (в символах)
+	maxDescriptionLength = 1000 // Максимальная длина описания (в символах)
+	maxURLLength         = 2048 // Максимальная длина адреса изображения и иконки
+	maxRedirects         = 5    // Максимальное количество перенаправлений при загрузке страницы
+)
+
+// Имя робота загрузки страниц, учитываемое в X-Robots-Tag и meta robots, и заголовок User-Agent его запросов
+const (
+	botName   = "go-shortener-preview"
+	userAgent = botName + "/1.0"
+)
+
+var (
+	// ErrOptOut возвращается когда страница запрещает использование своих метаданных (noindex, nosnippet, none)
+	ErrOptOut = errors.New("page opted out")
+	// ErrNotHTML возвращается когда страница назначения не является HTML документом
+	ErrNotHTML = errors.New("page is not html")
+	// ErrStatusNotOK возвращается когда страница назначения отвечает кодом, отличным от 2xx
+	ErrStatusNotOK = errors.New("page status is not ok")
+	// ErrSchemeNotAllowed возвращается когда схема адреса отличается от http и https
+	ErrSchemeNotAllowed = errors.New("scheme is not allowed")
+)
+
+// Fetcher загружает страницы назначения с ограничением времени и размера
+// Соединения с внутренними адресами отклоняются так же, как при проверке доступности (linkcheck)
+type Fetcher struct {
+	client  *http.Client // Клиент с защитой от SSRF
+	maxSize int64        // Максимальный размер читаемой части страницы (в байтах)
+}
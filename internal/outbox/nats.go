@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+)
+
+// connectOptions параметры команды CONNECT протокола NATS
+const connectOptions = `{"verbose":false,"pedantic":false,"name":"go-shortener","lang":"go","version":"1.0.0","protocol":0}`
+
+// NewNATSSink создает получателя, публикующего события в тему сервера NATS
+// Используется текстовый протокол NATS без внешнего клиента: CONNECT, PUB и PING для подтверждения
+// Принимает:
+// - rawURL: адрес сервера nats://host:port
+// - subject: тема публикации
+// - timeout: таймаут соединения и подтверждения публикации
+// Возвращает:
+// - получателя или ErrSinkNotValid, если адрес или тема невалидны
+func NewNATSSink(rawURL, subject string, timeout time.Duration) (*NATSSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != natsScheme || u.Host == "" {
+		return nil, fmt.Errorf("%w: nats url %q", ErrSinkNotValid, rawURL)
+	}
+
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, fmt.Errorf("%w: nats subject %q", ErrSinkNotValid, subject)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	return &NATSSink{
+		addr:    addr,
+		subject: subject,
+		timeout: timeout,
+	}, nil
+}
+
+// Publish публикует событие JSON телом и ждет PONG на следующий за ним PING,
+// что подтверждает обработку публикации сервером
+// При ошибке соединение закрывается и устанавливается заново при следующей публикации
+func (s *NATSSink) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.conn == nil {
+		err = s.connect(ctx)
+		if err != nil {
+			return fmt.Errorf("nats connect error: %w", err)
+		}
+	}
+
+	err = s.publish(body)
+	if err != nil {
+		s.close()
+		return fmt.Errorf("nats publish error: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с сервером
+func (s *NATSSink) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.close()
+}
+
+// connect устанавливает соединение: читает INFO сервера, отправляет CONNECT и ждет PONG
+func (s *NATSSink) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(s.timeout))
+
+	line, err := s.readLine()
+	if err != nil {
+		s.close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		s.close()
+		return fmt.Errorf("%w: unexpected %q", ErrNATSProtocol, line)
+	}
+
+	_, err = fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connectOptions)
+	if err == nil {
+		err = s.waitPong()
+	}
+	if err != nil {
+		s.close()
+		return err
+	}
+	return nil
+}
+
+// publish отправляет PUB с телом и PING, затем ждет PONG
+func (s *NATSSink) publish(body []byte) error {
+	_ = s.conn.SetDeadline(time.Now().Add(s.timeout))
+
+	msg := make([]byte, 0, len(s.subject)+len(body)+32)
+	msg = fmt.Appendf(msg, "PUB %s %d\r\n", s.subject, len(body))
+	msg = append(msg, body...)
+	msg = append(msg, "\r\nPING\r\n"...)
+
+	_, err := s.conn.Write(msg)
+	if err != nil {
+		return err
+	}
+	return s.waitPong()
+}
+
+// waitPong читает команды сервера до PONG, отвечая на PING сервера и пропуская +OK и INFO
+func (s *NATSSink) waitPong() error {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			_, err = s.conn.Write([]byte("PONG\r\n"))
+			if err != nil {
+				return err
+			}
+		case line == "+OK", strings.HasPrefix(line, "INFO "):
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("%w: %s", ErrNATSProtocol, strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		default:
+			return fmt.Errorf("%w: unexpected %q", ErrNATSProtocol, line)
+		}
+	}
+}
+
+// readLine читает одну команду сервера без завершающего \r\n
+func (s *NATSSink) readLine() (string, error) {
+	line, err := s.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// close закрывает соединение, если оно установлено
+func (s *NATSSink) close() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	s.reader = nil
+	return err
+}
@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// NewRelay создает relay outbox
+// Принимает:
+// - s: хранилище outbox
+// - sinks: получатели событий
+// - batch: количество событий за одну отправку
+func NewRelay(s Store, sinks []Sink, batch int) *Relay {
+	return &Relay{
+		store: s,
+		sinks: sinks,
+		batch: max(batch, 1),
+	}
+}
+
+// NewSinks создает получателей событий по настройкам из конфигурации
+// Принимает:
+// - raw: названия получателей через запятую (stdout, file, webhook, nats)
+// Возвращает:
+// - получателей или ErrSinkNotValid, если получатель неизвестен или не настроен
+func NewSinks(raw string) ([]Sink, error) {
+	sinks := make([]Sink, 0)
+	for _, name := range strings.Split(raw, ",") {
+		var sink Sink
+		var err error
+		switch strings.TrimSpace(name) {
+		case "":
+			continue
+		case SinkStdout:
+			sink = NewStdoutSink()
+		case SinkFile:
+			sink, err = NewFileSink(config.OutboxFilePath)
+		case SinkWebhook:
+			sink, err = NewWebhookSink(config.OutboxWebhookURL, config.OutboxTimeout)
+		case SinkNATS:
+			sink, err = NewNATSSink(config.OutboxNATSURL, config.OutboxNATSSubject, config.OutboxTimeout)
+		default:
+			err = fmt.Errorf("%w: %s", ErrSinkNotValid, name)
+		}
+
+		if err != nil {
+			for _, s := range sinks {
+				_ = s.Close()
+			}
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// Flush публикует до batch событий из outbox в порядке записи
+// События публикуются по одному всем получателям, при первой ошибке публикация останавливается,
+// чтобы следующая отправка начала с того же события и порядок сохранился
+// Принимает:
+// - ctx: контекст
+// Возвращает:
+// - количество опубликованных и удаленных из outbox событий
+// - ошибку получателя или хранилища
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	events, err := r.store.GetOutbox(ctx, r.batch)
+	if err != nil {
+		return 0, fmt.Errorf("get outbox error: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(events))
+	var publishErr error
+	for _, event := range events {
+		for _, sink := range r.sinks {
+			publishErr = sink.Publish(ctx, event)
+			if publishErr != nil {
+				break
+			}
+		}
+		if publishErr != nil {
+			publishErr = fmt.Errorf("publish event %s error: %w", event.ID, publishErr)
+			break
+		}
+		ids = append(ids, event.ID)
+	}
+
+	if len(ids) > 0 {
+		err = r.store.DeleteOutbox(ctx, ids)
+		if err != nil {
+			return 0, errors.Join(publishErr, fmt.Errorf("delete outbox error: %w", err))
+		}
+	}
+	return len(ids), publishErr
+}
+
+// Batch возвращает количество событий за одну отправку
+func (r *Relay) Batch() int {
+	return r.batch
+}
+
+// Close закрывает всех получателей
+func (r *Relay) Close() error {
+	errs := make([]error, 0, len(r.sinks))
+	for _, sink := range r.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,295 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStore struct {
+	mux    sync.Mutex
+	events []*models.OutboxEvent
+}
+
+func (s *testStore) GetOutbox(_ context.Context, limit int) ([]*models.OutboxEvent, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return slices.Clone(s.events[:min(limit, len(s.events))]), nil
+}
+
+func (s *testStore) DeleteOutbox(_ context.Context, ids []uuid.UUID) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.events = slices.DeleteFunc(s.events, func(event *models.OutboxEvent) bool {
+		return slices.Contains(ids, event.ID)
+	})
+	return nil
+}
+
+type failingSink struct {
+	fail map[uuid.UUID]bool
+}
+
+func (s *failingSink) Publish(_ context.Context, event *models.OutboxEvent) error {
+	if s.fail[event.ID] {
+		return errors.New("sink is down")
+	}
+	return nil
+}
+
+func (s *failingSink) Close() error {
+	return nil
+}
+
+func newEvents(n int) []*models.OutboxEvent {
+	events := make([]*models.OutboxEvent, 0, n)
+	for i := 0; i < n; i++ {
+		id := uuid.New()
+		events = append(events, &models.OutboxEvent{
+			ID:        uuid.New(),
+			Type:      models.OutboxEventSaved,
+			LinkID:    id,
+			ShortURL:  "http://localhost:8080/" + id.String(),
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+	return events
+}
+
+// natsServer минимальный сервер NATS: отправляет INFO, отвечает PONG на PING
+// и передает тело каждой команды PUB в канал
+type natsServer struct {
+	listener net.Listener
+	messages chan string
+	reject   bool
+}
+
+func newNATSServer(t *testing.T, reject bool) *natsServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &natsServer{
+		listener: listener,
+		messages: make(chan string, 10),
+		reject:   reject,
+	}
+	go s.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return s
+}
+
+func (s *natsServer) URL() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+func (s *natsServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *natsServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	_, _ = fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "PING":
+			_, _ = fmt.Fprint(conn, "PONG\r\n")
+		case "PUB":
+			n, _ := strconv.Atoi(fields[len(fields)-1])
+			body := make([]byte, n+2)
+			_, err = io.ReadFull(reader, body)
+			if err != nil {
+				return
+			}
+
+			if s.reject {
+				_, _ = fmt.Fprint(conn, "-ERR 'Permissions Violation for Publish'\r\n")
+				continue
+			}
+			s.messages <- fields[1] + " " + string(body[:n])
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	events := newEvents(5)
+	store := &testStore{events: slices.Clone(events)}
+	var buf bytes.Buffer
+	sink := &failingSink{fail: map[uuid.UUID]bool{events[3].ID: true}}
+	r := NewRelay(store, []Sink{NewWriterSink(&buf), sink}, 10)
+
+	n, err := r.Flush(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 3, n)
+	assert.Len(t, store.events, 2)
+	assert.Equal(t, events[3].ID, store.events[0].ID)
+
+	delete(sink.fail, events[3].ID)
+	n, err = r.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Empty(t, store.events)
+
+	decoder := json.NewDecoder(&buf)
+	got := make([]uuid.UUID, 0)
+	for decoder.More() {
+		var event models.OutboxEvent
+		require.NoError(t, decoder.Decode(&event))
+		got = append(got, event.ID)
+	}
+	// Событие, на котором упал второй получатель, первый получатель получил дважды
+	want := []uuid.UUID{events[0].ID, events[1].ID, events[2].ID, events[3].ID, events[3].ID, events[4].ID}
+	assert.Equal(t, want, got)
+}
+
+func TestFlushBatch(t *testing.T) {
+	store := &testStore{events: newEvents(3)}
+	r := NewRelay(store, []Sink{&failingSink{}}, 2)
+
+	n, err := r.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, r.Batch())
+	assert.Len(t, store.events, 1)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	event := newEvents(1)[0]
+
+	for range 2 {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Publish(context.Background(), event))
+		require.NoError(t, sink.Close())
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[1], event.ID.String())
+
+	_, err = NewFileSink("")
+	assert.ErrorIs(t, err, ErrSinkNotValid)
+}
+
+func TestWebhookSink(t *testing.T) {
+	event := newEvents(1)[0]
+	var status int
+	srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var got models.OutboxEvent
+		_ = json.NewDecoder(req.Body).Decode(&got)
+		assert.Equal(t, event.ID, got.ID)
+		assert.Equal(t, event.ID.String(), req.Header.Get(HeaderEventID))
+		res.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink, err := NewWebhookSink(srv.URL, time.Second)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	status = http.StatusAccepted
+	assert.NoError(t, sink.Publish(context.Background(), event))
+
+	status = http.StatusServiceUnavailable
+	assert.ErrorIs(t, sink.Publish(context.Background(), event), ErrStatusNotOK)
+
+	_, err = NewWebhookSink("ftp://example.com", time.Second)
+	assert.ErrorIs(t, err, ErrSinkNotValid)
+}
+
+func TestNATSSink(t *testing.T) {
+	srv := newNATSServer(t, false)
+	events := newEvents(2)
+
+	sink, err := NewNATSSink(srv.URL(), "shortener.links", time.Second)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	for _, event := range events {
+		require.NoError(t, sink.Publish(context.Background(), event))
+	}
+
+	for _, event := range events {
+		select {
+		case msg := <-srv.messages:
+			subject, body, _ := strings.Cut(msg, " ")
+			assert.Equal(t, "shortener.links", subject)
+
+			var got models.OutboxEvent
+			require.NoError(t, json.Unmarshal([]byte(body), &got))
+			assert.Equal(t, event.ID, got.ID)
+		case <-time.After(time.Second):
+			t.Fatal("nats message not received")
+		}
+	}
+}
+
+func TestNATSSinkError(t *testing.T) {
+	srv := newNATSServer(t, true)
+
+	sink, err := NewNATSSink(srv.URL(), "shortener.links", time.Second)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	err = sink.Publish(context.Background(), newEvents(1)[0])
+	assert.ErrorIs(t, err, ErrNATSProtocol)
+
+	_, err = NewNATSSink("http://127.0.0.1:4222", "shortener.links", time.Second)
+	assert.ErrorIs(t, err, ErrSinkNotValid)
+	_, err = NewNATSSink("nats://127.0.0.1:4222", "shortener links", time.Second)
+	assert.ErrorIs(t, err, ErrSinkNotValid)
+}
+
+func TestNewSinks(t *testing.T) {
+	sinks, err := NewSinks(" stdout ,")
+	require.NoError(t, err)
+	assert.Len(t, sinks, 1)
+
+	_, err = NewSinks("stdout,kafka")
+	assert.ErrorIs(t, err, ErrSinkNotValid)
+}
@@ -0,0 +1,120 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+)
+
+// NewStdoutSink создает получателя, записывающего события в стандартный вывод
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+// NewFileSink создает получателя, дописывающего события в файл
+// Принимает:
+// - path: путь к файлу, создается при отсутствии
+// Возвращает:
+// - получателя или ErrSinkNotValid, если путь пуст, или ошибку открытия файла
+func NewFileSink(path string) (*WriterSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: file path is empty", ErrSinkNotValid)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open outbox file error: %w", err)
+	}
+
+	sink := NewWriterSink(file)
+	sink.closer = file
+	return sink, nil
+}
+
+// NewWriterSink создает получателя, записывающего события в w построчно в JSON
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Publish записывает событие одной строкой JSON
+func (s *WriterSink) Publish(_ context.Context, event *models.OutboxEvent) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	err := s.encoder.Encode(event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает файл получателя, стандартный вывод не закрывается
+func (s *WriterSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// NewWebhookSink создает получателя, отправляющего события POST запросом
+// Принимает:
+// - rawURL: адрес получателя http или https
+// - timeout: таймаут одного запроса
+// Возвращает:
+// - получателя или ErrSinkNotValid, если адрес невалиден
+func NewWebhookSink(rawURL string, timeout time.Duration) (*WebhookSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%w: webhook url %q", ErrSinkNotValid, rawURL)
+	}
+
+	return &WebhookSink{
+		url:    u.String(),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Publish отправляет событие JSON телом, UUID события передается в заголовке X-Outbox-Event-ID
+// Возвращает ErrStatusNotOK, если получатель ответил кодом, отличным от 2xx
+func (s *WebhookSink) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEventID, event.ID.String())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request error: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", ErrStatusNotOK, resp.StatusCode)
+	}
+	return nil
+}
+
+// Close закрывает простаивающие соединения клиента
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
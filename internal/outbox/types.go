@@ -0,0 +1,93 @@
+// Package outbox содержит relay, публикующий доменные события из outbox хранилища получателям:
+// stdout, файлу, webhook и серверу NATS
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Получатели доменных событий, перечисляемые в config.OutboxSinks
+const (
+	SinkStdout  = "stdout"  // Построчный JSON в стандартный вывод
+	SinkFile    = "file"    // Построчный JSON в файл config.OutboxFilePath
+	SinkWebhook = "webhook" // POST запрос с JSON телом на config.OutboxWebhookURL
+	SinkNATS    = "nats"    // Публикация в тему config.OutboxNATSSubject сервера config.OutboxNATSURL
+)
+
+// HeaderEventID заголовок запроса получателя webhook с UUID события для отбрасывания повторов
+const HeaderEventID = "X-Outbox-Event-ID"
+
+const (
+	userAgent  = "go-shortener-outbox/1.0" // Заголовок User-Agent запросов получателя webhook
+	natsScheme = "nats"                    // Схема адреса сервера NATS
+)
+
+var (
+	// ErrSinkNotValid возвращается когда получатель неизвестен или его настройки невалидны
+	ErrSinkNotValid = errors.New("outbox sink is invalidate")
+	// ErrStatusNotOK возвращается когда получатель webhook отвечает кодом, отличным от 2xx
+	ErrStatusNotOK = errors.New("outbox webhook status is not ok")
+	// ErrNATSProtocol возвращается когда сервер NATS отвечает ошибкой или неожиданной командой
+	ErrNATSProtocol = errors.New("nats protocol error")
+)
+
+// Store хранилище outbox, из которого relay забирает неопубликованные события
+type Store interface {
+	// GetOutbox получает до limit неопубликованных событий в порядке записи
+	GetOutbox(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	// DeleteOutbox удаляет опубликованные события
+	DeleteOutbox(ctx context.Context, ids []uuid.UUID) error
+}
+
+// Sink получатель доменных событий
+type Sink interface {
+	// Publish публикует событие, ошибка означает, что событие будет отправлено повторно
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+	// Close освобождает ресурсы получателя
+	Close() error
+}
+
+// Relay публикует события из outbox всем получателям и удаляет опубликованные
+// Доставка выполняется хотя бы один раз: при ошибке одного из получателей событие отправляется
+// повторно всем получателям, поэтому они должны отбрасывать повторы по UUID события
+type Relay struct {
+	store Store  // Хранилище outbox
+	sinks []Sink // Получатели событий
+	batch int    // Количество событий за одну отправку
+}
+
+// WriterSink получатель, записывающий события построчно в JSON
+type WriterSink struct {
+	mux     sync.Mutex    // Мьютекс для записи событий целыми строками
+	encoder *json.Encoder // JSON энкодер для сериализации
+	closer  io.Closer     // Закрываемый файл или nil для стандартного вывода
+}
+
+// WebhookSink получатель, отправляющий события POST запросом
+type WebhookSink struct {
+	url    string       // Адрес получателя
+	client *http.Client // Клиент с таймаутом отправки
+}
+
+// NATSSink получатель, публикующий события в тему сервера NATS
+// Соединение устанавливается при первой публикации и переустанавливается после ошибки
+type NATSSink struct {
+	mux     sync.Mutex    // Мьютекс для последовательного использования соединения
+	addr    string        // Адрес сервера host:port
+	subject string        // Тема публикации
+	timeout time.Duration // Таймаут соединения и подтверждения публикации
+	conn    net.Conn      // Соединение с сервером или nil
+	reader  *bufio.Reader // Чтение команд сервера
+}
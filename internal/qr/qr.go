@@ -0,0 +1,505 @@
+package qr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseLevel возвращает уровень коррекции ошибок по его обозначению (L, M, Q, H)
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "L":
+		return L, nil
+	case "M":
+		return M, nil
+	case "Q":
+		return Q, nil
+	case "H":
+		return H, nil
+	default:
+		return M, fmt.Errorf("%w: %q", ErrLevelNotValid, s)
+	}
+}
+
+// String возвращает обозначение уровня коррекции ошибок
+func (l Level) String() string {
+	return [...]string{"L", "M", "Q", "H"}[l]
+}
+
+// Encode кодирует данные в QR-код в байтовом режиме
+// Выбирает минимальную версию, вмещающую данные, и маску с наименьшим штрафом
+// Возвращает ошибку, если данные не помещаются в версию 40
+func Encode(data string, level Level) (*Code, error) {
+	if level < L || level > H {
+		return nil, ErrLevelNotValid
+	}
+
+	version := 0
+	for v := minVersion; v <= maxVersion; v++ {
+		if dataBits(len(data), v) <= numDataCodewords(v, level)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("encode error: %w", ErrDataTooLong)
+	}
+
+	c := newCode(version, level)
+	c.drawFunctionPatterns()
+	c.drawCodewords(c.addErrorCorrection(c.dataCodewords([]byte(data))))
+
+	minPenalty := -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		penalty := c.penalty()
+		if minPenalty < 0 || penalty < minPenalty {
+			c.Mask = mask
+			minPenalty = penalty
+		}
+		c.applyMask(mask)
+	}
+
+	c.applyMask(c.Mask)
+	c.drawFormatBits(c.Mask)
+	return c, nil
+}
+
+// Black сообщает, является ли модуль с координатами (x, y) темным
+// Для координат за пределами матрицы возвращает false
+func (c *Code) Black(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// newCode создает пустую матрицу модулей для версии
+func newCode(version int, level Level) *Code {
+	size := version*4 + 17
+	c := &Code{
+		Version:  version,
+		Level:    level,
+		Size:     size,
+		modules:  make([][]bool, size),
+		function: make([][]bool, size),
+	}
+	for i := range c.modules {
+		c.modules[i] = make([]bool, size)
+		c.function[i] = make([]bool, size)
+	}
+	return c
+}
+
+// dataBits возвращает количество бит сегмента байтового режима
+func dataBits(n, version int) int {
+	return 4 + charCountBits(version) + n*8
+}
+
+// charCountBits возвращает длину поля количества символов байтового режима
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// numRawDataModules возвращает количество модулей, доступных для данных и коррекции
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// numDataCodewords возвращает количество кодовых слов данных для версии и уровня
+func numDataCodewords(version int, level Level) int {
+	return numRawDataModules(version)/8 - eccCodewordsPerBlock[level][version]*numErrorCorrectionBlocks[level][version]
+}
+
+// alignmentPatternPositions возвращает координаты центров выравнивающих узоров
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	numAlign := version/7 + 2
+	step := (version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, version*4+17-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// setFunction устанавливает служебный модуль
+func (c *Code) setFunction(x, y int, black bool) {
+	c.modules[y][x] = black
+	c.function[y][x] = true
+}
+
+// drawFunctionPatterns рисует поисковые, синхронизирующие и выравнивающие узоры,
+// а также резервирует области информации о формате и версии
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.Size-4, 3)
+	c.drawFinderPattern(3, c.Size-4)
+
+	positions := alignmentPatternPositions(c.Version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignmentPattern(x, y)
+		}
+	}
+
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// drawFinderPattern рисует поисковый узор с разделителем вокруг центра (x, y)
+func (c *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignmentPattern рисует выравнивающий узор вокруг центра (x, y)
+func (c *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits рисует обе копии информации о формате для маски
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatInfo(c.Level, mask)
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+	c.setFunction(8, c.Size-8, true)
+}
+
+// drawVersion рисует обе копии информации о версии (для версий 7 и выше)
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+
+	bits := versionInfo(c.Version)
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// formatInfo возвращает 15 бит информации о формате: уровень коррекции и маску,
+// защищенные кодом БЧХ (15,5) и наложенные на маску 0x5412
+func formatInfo(level Level, mask int) int {
+	data := formatBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionInfo возвращает 18 бит информации о версии, защищенные кодом Голея (18,6)
+func versionInfo(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// dataCodewords формирует кодовые слова данных: режим, длину, данные,
+// терминатор и байты заполнения
+func (c *Code) dataCodewords(data []byte) []byte {
+	capacity := numDataCodewords(c.Version, c.Level) * 8
+	bb := &bitBuffer{}
+	bb.append(0x4, 4)
+	bb.append(len(data), charCountBits(c.Version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	bb.append(0, min(4, capacity-bb.len))
+	bb.append(0, (8-bb.len%8)%8)
+	for pad := 0xEC; bb.len < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	return bb.data
+}
+
+// addErrorCorrection делит данные на блоки, добавляет к каждому кодовые слова
+// Рида-Соломона и перемежает блоки
+func (c *Code) addErrorCorrection(data []byte) []byte {
+	numBlocks := numErrorCorrectionBlocks[c.Level][c.Version]
+	blockEccLen := eccCodewordsPerBlock[c.Level][c.Version]
+	rawCodewords := numRawDataModules(c.Version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(blockEccLen)
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		datLen := shortBlockLen - blockEccLen
+		if i >= numShortBlocks {
+			datLen++
+		}
+
+		block := make([]byte, 0, shortBlockLen+1)
+		block = append(block, data[k:k+datLen]...)
+		k += datLen
+		if i < numShortBlocks {
+			block = append(block, 0)
+		}
+		blocks[i] = append(block, reedSolomonRemainder(data[k-datLen:k], divisor)...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := 0; i <= shortBlockLen; i++ {
+		for j, block := range blocks {
+			if i != shortBlockLen-blockEccLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// drawCodewords размещает кодовые слова в матрице зигзагом по парам столбцов
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !c.function[y][x] && i < len(data)*8 {
+					c.modules[y][x] = bit(int(data[i>>3]), 7-(i&7))
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask инвертирует модули данных по шаблону маски
+// Повторное применение той же маски отменяет ее
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.function[y][x] && maskBit(mask, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// maskBit сообщает, инвертируется ли модуль (x, y) маской
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// penalty вычисляет штраф матрицы по правилам выбора маски
+func (c *Code) penalty() int {
+	result := 0
+	dark := 0
+	for i := 0; i < c.Size; i++ {
+		result += c.linePenalty(func(j int) bool { return c.modules[i][j] })
+		result += c.linePenalty(func(j int) bool { return c.modules[j][i] })
+	}
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			black := c.modules[y][x]
+			if black {
+				dark++
+			}
+			if x < c.Size-1 && y < c.Size-1 &&
+				black == c.modules[y][x+1] && black == c.modules[y+1][x] && black == c.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return result + k*10
+}
+
+// finderLike содержит шаблон, похожий на поисковый узор (1:1:3:1:1)
+var finderLike = []bool{true, false, true, true, true, false, true}
+
+// linePenalty вычисляет штраф строки или столбца за серии одного цвета
+// и последовательности, похожие на поисковый узор
+func (c *Code) linePenalty(at func(int) bool) int {
+	result := 0
+	run := 1
+	for j := 1; j <= c.Size; j++ {
+		if j < c.Size && at(j) == at(j-1) {
+			run++
+			continue
+		}
+		if run >= 5 {
+			result += 3 + run - 5
+		}
+		run = 1
+	}
+
+	light := func(from, to int) bool {
+		for j := from; j < to; j++ {
+			if j >= 0 && j < c.Size && at(j) {
+				return false
+			}
+		}
+		return true
+	}
+	for j := 0; j+len(finderLike) <= c.Size; j++ {
+		match := true
+		for k, black := range finderLike {
+			if at(j+k) != black {
+				match = false
+				break
+			}
+		}
+		if match && (light(j-4, j) || light(j+len(finderLike), j+len(finderLike)+4)) {
+			result += 40
+		}
+	}
+	return result
+}
+
+// bitBuffer накапливает последовательность бит в байтах
+type bitBuffer struct {
+	data []byte
+	len  int
+}
+
+// append добавляет n младших бит значения v, начиная со старшего
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if b.len%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+		if bit(v, i) {
+			b.data[b.len/8] |= 1 << (7 - b.len%8)
+		}
+		b.len++
+	}
+}
+
+// reedSolomonDivisor возвращает порождающий многочлен Рида-Соломона степени degree
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder возвращает кодовые слова коррекции для данных
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= gfMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply умножает элементы поля Галуа GF(2^8) по модулю 0x11D
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// bit возвращает i-й бит значения
+func bit(v, i int) bool {
+	return (v>>i)&1 != 0
+}
+
+// abs возвращает модуль числа
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
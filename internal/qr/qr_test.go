@@ -0,0 +1,200 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	assert.Equal(t, want, reedSolomonRemainder(data, reedSolomonDivisor(len(want))))
+}
+
+func TestFunctionInfo(t *testing.T) {
+	assert.Equal(t, 0x77C4, formatInfo(L, 0))
+	assert.Equal(t, 0x5412, formatInfo(M, 0))
+	assert.Equal(t, 0x07C94, versionInfo(7))
+	assert.Equal(t, 0x28C69, versionInfo(40))
+	assert.Equal(t, []int{6, 22, 38}, alignmentPatternPositions(7))
+	assert.Equal(t, []int{6, 34, 60, 86, 112, 138}, alignmentPatternPositions(32))
+	assert.Equal(t, []int{6, 30, 58, 86, 114, 142, 170}, alignmentPatternPositions(40))
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		level   Level
+		version int
+	}{
+		{
+			name:    "short url",
+			data:    "http://localhost:8080/eefbcef4-3940-5a38-b2f0-877152a6d470",
+			level:   M,
+			version: 4,
+		},
+		{
+			name:    "high level",
+			data:    "http://localhost:8080/eefbcef4-3940-5a38-b2f0-877152a6d470",
+			level:   H,
+			version: 6,
+		},
+		{
+			name:    "version info",
+			data:    strings.Repeat("https://ya.ru/", 20),
+			level:   Q,
+			version: 15,
+		},
+		{
+			name:    "max version",
+			data:    strings.Repeat("x", 2953),
+			level:   L,
+			version: 40,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode(tt.data, tt.level)
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, c.Version)
+			assert.Equal(t, tt.data, decode(t, c))
+		})
+	}
+
+	_, err := Encode(strings.Repeat("x", 2954), L)
+	assert.ErrorIs(t, err, ErrDataTooLong)
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode("https://ya.ru/", M)
+	require.NoError(t, err)
+
+	fg, err := ParseColor("#123")
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff}, fg)
+	_, err = ParseColor("red")
+	assert.ErrorIs(t, err, ErrColorNotValid)
+
+	opts := Options{Size: 256, Margin: 4, Foreground: fg, Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}}
+	b, contentType, err := c.Render(FormatPNG, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	img, err := png.Decode(bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, 232, img.Bounds().Dx())
+	assert.Equal(t, color.RGBAModel.Convert(img.At(4*8, 4*8)), fg)
+
+	b, contentType, err = c.Render(FormatSVG, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "image/svg+xml", contentType)
+	assert.Contains(t, string(b), `viewBox="0 0 29 29"`)
+	assert.Contains(t, string(b), `fill="#112233"`)
+
+	_, _, err = c.Render("gif", opts)
+	assert.ErrorIs(t, err, ErrFormatNotValid)
+}
+
+// decode читает QR-код обратно: проверяет информацию о формате, снимает маску,
+// собирает блоки, сверяет коды коррекции и возвращает данные байтового режима
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+
+	format := 0
+	for i := 0; i < 8; i++ {
+		if c.Black(c.Size-1-i, 8) {
+			format |= 1 << i
+		}
+	}
+	for i := 8; i < 15; i++ {
+		if c.Black(8, c.Size-15+i) {
+			format |= 1 << i
+		}
+	}
+	require.Equal(t, formatInfo(c.Level, c.Mask), format)
+
+	ref := newCode(c.Version, c.Level)
+	ref.drawFunctionPatterns()
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if ref.function[y][x] && y != 8 && x != 8 {
+				require.Equal(t, ref.modules[y][x], c.modules[y][x], "function module (%d, %d)", x, y)
+			}
+		}
+	}
+
+	rawCodewords := numRawDataModules(c.Version) / 8
+	codewords := make([]byte, rawCodewords)
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if ref.function[y][x] || i >= rawCodewords*8 {
+					continue
+				}
+				if c.modules[y][x] != maskBit(c.Mask, x, y) {
+					codewords[i>>3] |= 1 << (7 - i&7)
+				}
+				i++
+			}
+		}
+	}
+
+	numBlocks := numErrorCorrectionBlocks[c.Level][c.Version]
+	eccLen := eccCodewordsPerBlock[c.Level][c.Version]
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+	blocks := make([][]byte, numBlocks)
+	for j := range blocks {
+		blocks[j] = make([]byte, shortBlockLen+1)
+	}
+	k := 0
+	for i := 0; i <= shortBlockLen; i++ {
+		for j := range blocks {
+			if i != shortBlockLen-eccLen || j >= numShortBlocks {
+				blocks[j][i] = codewords[k]
+				k++
+			}
+		}
+	}
+
+	var data []byte
+	divisor := reedSolomonDivisor(eccLen)
+	for j, block := range blocks {
+		datLen := shortBlockLen - eccLen
+		if j >= numShortBlocks {
+			datLen++
+		}
+		require.Equal(t, block[len(block)-eccLen:], reedSolomonRemainder(block[:datLen], divisor), "block %d", j)
+		data = append(data, block[:datLen]...)
+	}
+
+	read := func(pos, n int) int {
+		v := 0
+		for i := pos; i < pos+n; i++ {
+			v = v<<1 | int(data[i>>3]>>(7-i&7)&1)
+		}
+		return v
+	}
+	require.Equal(t, 0x4, read(0, 4))
+	n := read(4, charCountBits(c.Version))
+	result := make([]byte, n)
+	for i := range result {
+		result[i] = byte(read(4+charCountBits(c.Version)+i*8, 8))
+	}
+	return string(result)
+}
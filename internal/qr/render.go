@@ -0,0 +1,120 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// ParseColor разбирает цвет в формате #RRGGBB или #RGB
+func ParseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("%w: %q", ErrColorNotValid, s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("%w: %q", ErrColorNotValid, s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// Render отрисовывает QR-код в указанном формате
+// Возвращает изображение и его MIME тип
+func (c *Code) Render(format string, opts Options) ([]byte, string, error) {
+	switch format {
+	case FormatPNG:
+		b, err := c.PNG(opts)
+		return b, "image/png", err
+	case FormatSVG:
+		b, err := c.SVG(opts)
+		return b, "image/svg+xml", err
+	default:
+		return nil, "", fmt.Errorf("%w: %q", ErrFormatNotValid, format)
+	}
+}
+
+// PNG отрисовывает QR-код в PNG
+// Размер модуля подбирается целым числом пикселей, не превышающим opts.Size
+func (c *Code) PNG(opts Options) ([]byte, error) {
+	scale, err := c.scale(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	side := (c.Size + 2*opts.Margin) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{opts.Background, opts.Foreground})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			top, left := (y+opts.Margin)*scale, (x+opts.Margin)*scale
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[(top+dy)*img.Stride+left : (top+dy)*img.Stride+left+scale]
+				for i := range row {
+					row[i] = 1
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("png encode error: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG отрисовывает QR-код в SVG
+// Темные модули строки объединяются в горизонтальные отрезки одного пути
+func (c *Code) SVG(opts Options) ([]byte, error) {
+	if _, err := c.scale(opts); err != nil {
+		return nil, err
+	}
+
+	side := c.Size + 2*opts.Margin
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		opts.Size, opts.Size, side, side)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(opts.Background))
+	fmt.Fprintf(&buf, `<path fill="%s" d="`, hexColor(opts.Foreground))
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; {
+			if !c.modules[y][x] {
+				x++
+				continue
+			}
+			start := x
+			for x < c.Size && c.modules[y][x] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d,%dh%dv1h-%dz", start+opts.Margin, y+opts.Margin, x-start, x-start)
+		}
+	}
+	buf.WriteString("\"/>\n</svg>\n")
+	return buf.Bytes(), nil
+}
+
+// scale проверяет параметры отрисовки и возвращает размер модуля в пикселях
+func (c *Code) scale(opts Options) (int, error) {
+	if opts.Size <= 0 || opts.Margin < 0 {
+		return 0, ErrOptionsNotValid
+	}
+	return max(1, opts.Size/(c.Size+2*opts.Margin)), nil
+}
+
+// hexColor возвращает цвет в формате #RRGGBB
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,79 @@
+// Package qr содержит кодировщик QR-кодов (ISO/IEC 18004) на чистом Go
+// и отрисовку кода в форматах PNG и SVG
+package qr
+
+import (
+	"errors"
+	"image/color"
+)
+
+// Level определяет уровень коррекции ошибок QR-кода
+type Level int
+
+// Уровни коррекции ошибок
+const (
+	L Level = iota // Восстанавливается ~7% кода
+	M              // Восстанавливается ~15% кода
+	Q              // Восстанавливается ~25% кода
+	H              // Восстанавливается ~30% кода
+)
+
+// Границы версий QR-кода
+const (
+	minVersion = 1  // Минимальная версия (21x21 модулей)
+	maxVersion = 40 // Максимальная версия (177x177 модулей)
+)
+
+// Форматы изображения QR-кода
+const (
+	FormatPNG = "png" // Растровое изображение PNG
+	FormatSVG = "svg" // Векторное изображение SVG
+)
+
+// Ошибки кодирования и отрисовки QR-кода
+var (
+	ErrDataTooLong     = errors.New("data too long")
+	ErrLevelNotValid   = errors.New("level is invalidate")
+	ErrColorNotValid   = errors.New("color is invalidate")
+	ErrFormatNotValid  = errors.New("format is invalidate")
+	ErrOptionsNotValid = errors.New("options is invalidate")
+)
+
+// Code представляет закодированный QR-код в виде матрицы модулей
+type Code struct {
+	Version  int      // Версия QR-кода
+	Level    Level    // Уровень коррекции ошибок
+	Mask     int      // Номер примененной маски
+	Size     int      // Размер стороны матрицы в модулях
+	modules  [][]bool // Матрица модулей (true - темный модуль)
+	function [][]bool // Признак служебного модуля
+}
+
+// Options содержит параметры отрисовки QR-кода
+type Options struct {
+	Size       int        // Желаемый размер стороны изображения в пикселях
+	Margin     int        // Размер отступа в модулях
+	Foreground color.RGBA // Цвет темных модулей
+	Background color.RGBA // Цвет фона
+}
+
+// eccCodewordsPerBlock содержит количество кодовых слов коррекции в блоке
+// для каждого уровня коррекции и версии (индекс 0 не используется)
+var eccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// numErrorCorrectionBlocks содержит количество блоков коррекции
+// для каждого уровня коррекции и версии (индекс 0 не используется)
+var numErrorCorrectionBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// formatBits содержит биты уровня коррекции для информации о формате
+var formatBits = [4]int{1, 0, 3, 2}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// slugMaxLen максимальная длина короткого имени
+const slugMaxLen = 64
+
+// reservedSlugs короткие имена, совпадающие со статическими маршрутами сервиса
+var reservedSlugs = map[string]struct{}{
+	"api":  {},
+	"ping": {},
+}
+
+// checkSlug проверяет короткое имя ссылки
+// Принимает:
+// - slug: короткое имя из латинских букв, цифр, "-" и "_"
+// Возвращает:
+// - ErrSlugNotValid, если имя пустое, длиннее slugMaxLen, содержит другие символы,
+// разбирается как UUID или совпадает с маршрутом сервиса
+func checkSlug(slug string) error {
+	if slug == "" || len(slug) > slugMaxLen {
+		return ErrSlugNotValid
+	}
+	for _, r := range slug {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return ErrSlugNotValid
+		}
+	}
+	if _, err := uuid.Parse(slug); err == nil {
+		return ErrSlugNotValid
+	}
+	if _, ok := reservedSlugs[strings.ToLower(slug)]; ok {
+		return ErrSlugNotValid
+	}
+	return nil
+}
+
+// ResolveID получает UUID ссылки по идентификатору из пути короткого URL
+// Принимает:
+// - ctx: контекст для контроля времени выполнения
+// - raw: UUID ссылки или короткое имя, перенесенное импортом из другого сервиса
+// Возвращает:
+// - UUID ссылки
+// - ErrSlugNotValid, если raw не UUID и не короткое имя, ErrNotFound, если короткого имени нет,
+// или ошибку хранилища
+func (s *Service) ResolveID(ctx context.Context, raw string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(raw); err == nil {
+		return id, nil
+	}
+
+	if err := checkSlug(raw); err != nil {
+		return uuid.Nil, fmt.Errorf("resolve id error: %w", err)
+	}
+
+	id, err := s.Repository.GetIDBySlug(ctx, raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("resolve id error: %w", err)
+	}
+	return id, nil
+}
+
+// importRetries количество повторных проверок части импорта, ссылку или короткое имя которой
+// сохранили между поиском и сохранением
+const importRetries = 3
+
+// importItem элемент импорта, прошедший проверку
+type importItem struct {
+	slug      string                     // Короткое имя ссылки в исходном сервисе
+	link      *models.Link               // Сохраняемая запись URL
+	state     int                        // Состояние записи с UUID link.ID
+	saveLink  bool                       // Запись URL сохраняется импортом
+	saveAlias bool                       // Короткое имя сохраняется импортом
+	result    *models.ResponseImportLink // Результат импорта элемента
+}
+
+// ImportLinks сохраняет ссылки из экспорта другого сервиса сокращения URL с их короткими именами
+// Каждая ссылка проверяется так же, как в SaveLink, и сохраняется с временем создания из экспорта.
+// Короткое имя сохраняется как дополнительный идентификатор ссылки. Если имя уже занято другой ссылкой,
+// элемент получает статус collision и не сохраняется. Элемент получает статус existing, если и ссылка,
+// и ее короткое имя уже были сохранены, и created, если сохранено хотя бы одно из них.
+// Ссылка, удаленная пользователем, не восстанавливается: элемент получает статус deleted.
+// Ссылки обрабатываются частями по config.ImportChunk: UUID ссылок и короткие имена части ищутся
+// пакетными запросами, а часть сохраняется в одной транзакции. Ошибка хранилища при поиске
+// записывается в элементы части со статусом failed
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - links: ссылки из экспорта
+// Возвращает:
+// - результаты в порядке элементов links
+// - ошибку, если links пуст или возникли проблемы при сохранении; части до ошибки остаются сохраненными
+func (s *Service) ImportLinks(ctx context.Context, links []*models.ImportLink) ([]*models.ResponseImportLink, error) {
+	if len(links) == 0 {
+		return nil, fmt.Errorf("import links error: %w", customError.ErrBatchIsEmpty)
+	}
+
+	userID := customContext.GetContextUserID(ctx)
+	results := make([]*models.ResponseImportLink, len(links))
+	chunk := max(config.ImportChunk, 1)
+	for start := 0; start < len(links); start += chunk {
+		end := min(start+chunk, len(links))
+		err := s.importChunk(ctx, userID, links[start:end], results[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("import links error: %w", err)
+		}
+	}
+	return results, nil
+}
+
+// importChunk сохраняет часть ссылок из экспорта и их короткие имена в одной транзакции
+// Если ссылку или короткое имя сохранили между поиском и сохранением, часть проверяется повторно
+// Записывает результаты в results и возвращает ошибку, не относящуюся к проверке элементов
+func (s *Service) importChunk(ctx context.Context, userID *uuid.UUID, links []*models.ImportLink, results []*models.ResponseImportLink) error {
+	items := make([]*importItem, 0, len(links))
+	for i, l := range links {
+		item, err := s.newImportItem(ctx, userID, l)
+		if err != nil {
+			return err
+		}
+		results[i] = item.result
+		if item.result.Status == "" {
+			items = append(items, item)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		s.resolveImport(ctx, items)
+		err := s.saveImport(ctx, userID, items)
+		if !errors.Is(err, customError.ErrConflict) || attempt == importRetries {
+			return err
+		}
+	}
+}
+
+// newImportItem проверяет ссылку из экспорта
+// Возвращает элемент импорта, результат которого получает статус invalid или blocked,
+// если ссылка не прошла проверку, или ошибку, не относящуюся к проверке элемента
+func (s *Service) newImportItem(ctx context.Context, userID *uuid.UUID, l *models.ImportLink) (*importItem, error) {
+	item := &importItem{
+		slug:   l.Slug,
+		result: &models.ResponseImportLink{Slug: l.Slug, OriginalURL: l.OriginalURL},
+	}
+	invalid := func(err error) (*importItem, error) {
+		item.result.Status, item.result.Error = models.BatchStatusInvalid, err.Error()
+		return item, nil
+	}
+
+	u, err := url.Parse(l.OriginalURL)
+	if err != nil || l.OriginalURL == "" {
+		return invalid(customError.ErrURLNotValid)
+	}
+	if l.Slug != "" {
+		if err := checkSlug(l.Slug); err != nil {
+			return invalid(err)
+		}
+	}
+
+	createdAt := l.CreatedAt.UTC()
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	item.link = &models.Link{
+		UserID:      userID,
+		OriginalURL: u.String(),
+		CreatedAt:   createdAt,
+		LinkMeta:    models.LinkMeta{Title: l.Title, Tags: l.Tags},
+	}
+	item.result.OriginalURL = item.link.OriginalURL
+
+	err = s.checkLink(ctx, item.link)
+	var blocked *BlockedError
+	if errors.As(err, &blocked) {
+		item.result.Status, item.result.Error = models.BatchStatusBlocked, blocked.Reason
+		return item, nil
+	}
+	if reason, ok := batchItemReason(err); ok {
+		item.result.Status, item.result.Error = models.BatchStatusInvalid, reason
+		return item, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// resolveImport выбирает UUID ссылок и проверяет короткие имена элементов импорта пакетными запросами
+// UUID выбираются по той же цепочке, что и в resolveLinkID: за каждый шаг цепочки выполняется один запрос
+// для всех элементов, еще не получивших UUID. Элементы, которые не сохраняются, получают статус
+// deleted, collision, existing или failed. Удаленная ссылка не восстанавливается импортом
+// и не переходит к импортирующему пользователю
+func (s *Service) resolveImport(ctx context.Context, items []*importItem) {
+	failed := func(items []*importItem, err error) {
+		for _, item := range items {
+			item.result.Status, item.result.Error = models.BatchStatusFailed, err.Error()
+		}
+	}
+
+	pending := make([]*importItem, 0, len(items))
+	for _, item := range items {
+		item.link.ID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(item.link.OriginalURL))
+		item.saveLink, item.saveAlias = false, false
+		*item.result = models.ResponseImportLink{Slug: item.result.Slug, OriginalURL: item.result.OriginalURL}
+		pending = append(pending, item)
+	}
+
+	for i := 0; i < maxLinkIDProbes && len(pending) > 0; i++ {
+		ids := make([]uuid.UUID, 0, len(pending))
+		for _, item := range pending {
+			ids = append(ids, item.link.ID)
+		}
+		found, err := s.Repository.GetLinksByIDs(ctx, ids)
+		if err != nil {
+			failed(pending, fmt.Errorf("resolve link id error: %w", err))
+			return
+		}
+
+		next := pending[:0]
+		for _, item := range pending {
+			link, ok := found[item.link.ID]
+			switch {
+			case !ok:
+				item.state = linkFree
+			case link.IsDeleted:
+				item.result.Status, item.result.Error = models.BatchStatusDeleted, ErrLinkDeleted.Error()
+			case link.OriginalURL == item.link.OriginalURL:
+				item.state = linkExists
+			default:
+				item.link.ID = uuid.NewSHA1(item.link.ID, []byte(item.link.OriginalURL))
+				next = append(next, item)
+			}
+		}
+		pending = next
+	}
+	failed(pending, fmt.Errorf("resolve link id error: %w", errLinkIDProbes))
+
+	slugs := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.result.Status == "" && item.slug != "" {
+			slugs = append(slugs, item.slug)
+		}
+	}
+	aliases := make(map[string]uuid.UUID, len(slugs))
+	if len(slugs) > 0 {
+		found, err := s.Repository.GetIDsBySlugs(ctx, slugs)
+		if err != nil {
+			for _, item := range items {
+				if item.result.Status == "" && item.slug != "" {
+					item.result.Status, item.result.Error = models.BatchStatusFailed, err.Error()
+				}
+			}
+		}
+		maps.Copy(aliases, found)
+	}
+
+	// Ссылки и короткие имена, сохраняемые предыдущими элементами части
+	saved := make(map[uuid.UUID]struct{}, len(items))
+	for _, item := range items {
+		if item.result.Status != "" {
+			continue
+		}
+
+		if item.slug != "" {
+			id, ok := aliases[item.slug]
+			switch {
+			case ok && id != item.link.ID:
+				item.result.Status, item.result.Error = models.BatchStatusCollision, ErrSlugCollision.Error()
+				continue
+			case !ok:
+				item.saveAlias = true
+				aliases[item.slug] = item.link.ID
+			}
+		}
+
+		if _, ok := saved[item.link.ID]; !ok && item.state == linkFree {
+			item.saveLink = true
+			saved[item.link.ID] = struct{}{}
+		}
+
+		item.result.ID, item.result.ShortURL = item.link.ID, config.URL+item.link.ID.String()
+		if item.slug != "" {
+			item.result.ShortURL = config.URL + item.slug
+		}
+		if !item.saveLink && !item.saveAlias {
+			item.result.Status = models.BatchStatusExisting
+		}
+	}
+}
+
+// saveImport сохраняет ссылки и короткие имена элементов импорта в одной транзакции
+// Элементы получают статус created после фиксации транзакции
+// Возвращает ErrConflict, если ссылку или короткое имя сохранили после resolveImport
+func (s *Service) saveImport(ctx context.Context, userID *uuid.UUID, items []*importItem) error {
+	save := make([]*importItem, 0, len(items))
+	for _, item := range items {
+		if item.result.Status == "" {
+			save = append(save, item)
+		}
+	}
+	if len(save) == 0 {
+		return nil
+	}
+
+	err := s.withTx(ctx, func(tx pgx.Tx) error {
+		events := make([]*models.OutboxEvent, 0, len(save))
+		for _, item := range save {
+			if item.saveLink {
+				if _, err := s.Repository.SaveLink(ctx, tx, item.link); err != nil {
+					return err
+				}
+				events = append(events, newOutboxEvent(models.OutboxEventSaved, userID, item.link.ID, item.link.OriginalURL))
+			}
+			if item.saveAlias {
+				alias := &models.Alias{Slug: item.slug, LinkID: item.link.ID, CreatedAt: item.link.CreatedAt}
+				if err := s.Repository.SaveAlias(ctx, tx, alias); err != nil {
+					return err
+				}
+			}
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return s.Repository.SaveOutbox(ctx, tx, events)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range save {
+		item.result.Status = models.BatchStatusCreated
+		if item.saveLink {
+			s.publishCreated(userID, item.link.ID, item.link.OriginalURL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CheckURL проверяет адрес назначения по списку блокировки и эвристикам защиты от фишинга
+// Принимает:
+// - raw: адрес назначения
+// Возвращает:
+// - *BlockedError с причиной блокировки или nil, если адрес разрешен либо не является URL
+func (s *Service) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+
+	if reason := s.Blocklist.Check(u); reason != "" {
+		return &BlockedError{Reason: reason}
+	}
+	return nil
+}
+
+// DisableByID отключает URL администратором, например при жалобе на злоупотребление
+// Принимает:
+// - ctx: контекст для контроля времени выполнения
+// - id: UUID сокращенного URL
+// - reason: причина отключения, пустая причина включает URL
+// Возвращает:
+// - ошибку, если URL не найден (ErrNotFound) или возникли проблемы при сохранении
+func (s *Service) DisableByID(ctx context.Context, id uuid.UUID, reason string) error {
+	err := s.Repository.DisableByID(ctx, id, reason)
+	if err != nil {
+		return fmt.Errorf("disable url error: %w", err)
+	}
+	return nil
+}
+
+// checkDestinations проверяет все адреса назначения ссылки: оригинальный URL, адреса правил,
+// вариантов и резервный адрес
+func (s *Service) checkDestinations(originalURL string, meta *models.LinkMeta) error {
+	urls := []string{originalURL, meta.FallbackURL}
+	for _, rule := range meta.Rules {
+		urls = append(urls, rule.URL)
+	}
+	for _, variant := range meta.Variants {
+		urls = append(urls, variant.URL)
+	}
+
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		if err := s.CheckURL(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
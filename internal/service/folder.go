@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+
+	"github.com/google/uuid"
+)
+
+// SaveFolder создает папку текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - name: название папки
+// Возвращает:
+// - созданную папку
+// - ошибку, если пользователь не авторизован или папка с таким названием уже существует (ErrConflict)
+func (s *Service) SaveFolder(ctx context.Context, name string) (*models.Folder, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("save folder error: %w", ErrUserUnauthorized)
+	}
+
+	folder := &models.Folder{
+		ID:        uuid.New(),
+		UserID:    *userID,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err := s.Repository.SaveFolder(ctx, folder)
+	if err != nil {
+		return nil, fmt.Errorf("user save folder error: %w", err)
+	}
+	return folder, nil
+}
+
+// GetFoldersByUserID получает все папки текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// Возвращает:
+// - массив папок в порядке создания
+// - ошибку, если пользователь не авторизован или возникли проблемы при получении данных
+func (s *Service) GetFoldersByUserID(ctx context.Context) ([]*models.Folder, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("get folders error: %w", ErrUserUnauthorized)
+	}
+
+	folders, err := s.Repository.GetFoldersByUserID(ctx, *userID)
+	if err != nil {
+		return nil, fmt.Errorf("user get folders error: %w", err)
+	}
+	return folders, nil
+}
+
+// UpdateFolderByUserID переименовывает папку текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID папки
+// - name: новое название папки
+// Возвращает:
+// - переименованную папку
+// - ошибку, если пользователь не авторизован, папка не найдена (ErrNotFound) или название занято (ErrConflict)
+func (s *Service) UpdateFolderByUserID(ctx context.Context, id uuid.UUID, name string) (*models.Folder, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("update folder error: %w", ErrUserUnauthorized)
+	}
+
+	folder := &models.Folder{
+		ID:     id,
+		UserID: *userID,
+		Name:   name,
+	}
+
+	err := s.Repository.UpdateFolderByUserID(ctx, folder)
+	if err != nil {
+		return nil, fmt.Errorf("user update folder error: %w", err)
+	}
+	return folder, nil
+}
+
+// DeleteFolderByUserID удаляет папку текущего пользователя, URL папки остаются без папки
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID папки
+// Возвращает:
+// - ошибку, если пользователь не авторизован или папка не найдена (ErrNotFound)
+func (s *Service) DeleteFolderByUserID(ctx context.Context, id uuid.UUID) error {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return fmt.Errorf("delete folder error: %w", ErrUserUnauthorized)
+	}
+
+	err := s.Repository.DeleteFolderByUserID(ctx, *userID, id)
+	if err != nil {
+		return fmt.Errorf("user delete folder error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// GetLinksToCheck получает URL для проверки доступности адресов назначения
+// Принимает:
+// - ctx: контекст для контроля времени выполнения
+// Возвращает:
+// - до config.LinkCheckBatch URL, не проверявшихся или проверенных раньше чем config.LinkCheckAge назад
+// - ошибку, если возникли проблемы при получении данных
+func (s *Service) GetLinksToCheck(ctx context.Context) ([]*models.Link, error) {
+	links, err := s.Repository.GetLinksToCheck(ctx, time.Now().UTC().Add(-config.LinkCheckAge), config.LinkCheckBatch)
+	if err != nil {
+		return nil, fmt.Errorf("get links to check error: %w", err)
+	}
+	return links, nil
+}
+
+// UpdateHealth сохраняет результат проверки доступности адреса назначения
+// Принимает:
+// - ctx: контекст для контроля времени выполнения
+// - id: UUID сокращенного URL
+// - health: результат проверки
+// Возвращает:
+// - ошибку, если URL не найден (ErrNotFound) или возникли проблемы при сохранении
+func (s *Service) UpdateHealth(ctx context.Context, id uuid.UUID, health *models.LinkHealth) error {
+	err := s.Repository.UpdateHealth(ctx, id, health)
+	if err != nil {
+		return fmt.Errorf("update health error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+)
+
+// maxIdempotencyKey максимальная длина Idempotency-Key
+const maxIdempotencyKey = 255
+
+// BeginIdempotent резервирует Idempotency-Key текущего пользователя на время config.IdempotencyTTL
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - key: значение заголовка Idempotency-Key
+// - requestHash: хэш метода, пути и тела запроса
+// Возвращает:
+// - сохраненный ответ, если запрос с этим ключом уже выполнен, или nil, если ключ зарезервирован для текущего запроса
+// - ошибку, если ключ невалиден, запрос с ключом еще выполняется или ключ использован для другого запроса
+func (s *Service) BeginIdempotent(ctx context.Context, key, requestHash string) (*models.IdempotencyRecord, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("begin idempotent error: %w", ErrUserUnauthorized)
+	}
+
+	if !validIdempotencyKey(key) {
+		return nil, fmt.Errorf("begin idempotent error: %w", ErrIdempotencyKeyNotValid)
+	}
+
+	existing, err := s.Repository.ReserveIdempotency(ctx, &models.IdempotencyRecord{
+		UserID:      *userID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().UTC().Add(config.IdempotencyTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("begin idempotent error: %w", err)
+	}
+
+	switch {
+	case existing == nil:
+		return nil, nil
+	case existing.Status == 0:
+		return nil, fmt.Errorf("begin idempotent error: %w", ErrIdempotencyInProgress)
+	case existing.RequestHash != requestHash:
+		return nil, fmt.Errorf("begin idempotent error: %w", ErrIdempotencyKeyReused)
+	}
+	return existing, nil
+}
+
+// CompleteIdempotent сохраняет ответ на запрос с Idempotency-Key текущего пользователя
+// Ответ с кодом 5xx не сохраняется: резерв снимается, и повтор запроса выполняется заново.
+// Резерв снимается и при ошибке сохранения ответа
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - key: значение заголовка Idempotency-Key
+// - status, contentType, body: код, тип содержимого и тело ответа
+// Возвращает:
+// - ошибку, если пользователь не авторизован или возникли проблемы при сохранении
+func (s *Service) CompleteIdempotent(ctx context.Context, key string, status int, contentType string, body []byte) error {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return fmt.Errorf("complete idempotent error: %w", ErrUserUnauthorized)
+	}
+
+	if status >= http.StatusInternalServerError {
+		err := s.Repository.DeleteIdempotency(ctx, *userID, key)
+		if err != nil {
+			return fmt.Errorf("complete idempotent error: %w", err)
+		}
+		return nil
+	}
+
+	err := s.Repository.CompleteIdempotency(ctx, &models.IdempotencyRecord{
+		UserID:      *userID,
+		Key:         key,
+		Status:      status,
+		ContentType: contentType,
+		Body:        body,
+	})
+	if err != nil {
+		// Без снятия резерва повторы с этим ключом получали бы ErrIdempotencyInProgress до истечения config.IdempotencyTTL
+		_ = s.Repository.DeleteIdempotency(ctx, *userID, key)
+		return fmt.Errorf("complete idempotent error: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotency удаляет ответы Idempotency-Key с истекшим временем хранения
+// Возвращает:
+// - количество удаленных записей
+// - ошибку, если возникли проблемы при удалении
+func (s *Service) DeleteExpiredIdempotency(ctx context.Context) (int64, error) {
+	n, err := s.Repository.DeleteExpiredIdempotency(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency error: %w", err)
+	}
+	return n, nil
+}
+
+// validIdempotencyKey проверяет, что ключ непустой, не длиннее maxIdempotencyKey и состоит из печатных символов ASCII
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKey {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
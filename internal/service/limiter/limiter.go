@@ -0,0 +1,52 @@
+package limiter
+
+import "time"
+
+// Allow проверяет, что ключ не заблокирован
+// Возвращает false и время до снятия блокировки, если ключ заблокирован
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+	a, ok := l.attempts[key]
+	if !ok || now.After(a.expiresAt) || a.count < l.max {
+		return true, 0
+	}
+	return false, a.expiresAt.Sub(now)
+}
+
+// Fail учитывает неудачную попытку ключа
+func (l *Limiter) Fail(key string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+	if len(l.attempts) >= sweepSize {
+		l.sweep(now)
+	}
+
+	a, ok := l.attempts[key]
+	if !ok || now.After(a.expiresAt) {
+		a = &attempt{expiresAt: now.Add(l.window)}
+		l.attempts[key] = a
+	}
+	a.count++
+}
+
+// Reset сбрасывает неудачные попытки ключа
+func (l *Limiter) Reset(key string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	delete(l.attempts, key)
+}
+
+// sweep удаляет истекшие записи
+func (l *Limiter) sweep(now time.Time) {
+	for key, a := range l.attempts {
+		if now.After(a.expiresAt) {
+			delete(l.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package limiter содержит ограничитель неудачных попыток (защита от перебора)
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepSize определяет количество ключей, после которого при записи попытки удаляются истекшие записи
+const sweepSize = 1024
+
+// Limiter ограничивает количество неудачных попыток по ключу в скользящем окне.
+// После max неудачных попыток ключ блокируется до истечения окна с момента первой попытки.
+type Limiter struct {
+	mux      sync.Mutex          // Мьютекс для потокобезопасного доступа
+	max      int                 // Максимальное количество неудачных попыток в окне
+	window   time.Duration       // Длительность окна (и блокировки)
+	attempts map[string]*attempt // Неудачные попытки по ключу
+	now      func() time.Time    // Источник текущего времени
+}
+
+// attempt содержит счетчик неудачных попыток ключа
+type attempt struct {
+	count     int       // Количество неудачных попыток
+	expiresAt time.Time // Время сброса счетчика
+}
+
+// NewLimiter создает новый ограничитель
+// Принимает:
+// - max: максимальное количество неудачных попыток в окне
+// - window: длительность окна и блокировки
+func NewLimiter(max int, window time.Duration) *Limiter {
+	return &Limiter{
+		max:      max,
+		window:   window,
+		attempts: make(map[string]*attempt),
+		now:      time.Now,
+	}
+}
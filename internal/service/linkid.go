@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// maxLinkIDProbes максимальное количество UUID цепочки, проверяемых для одного оригинального URL
+const maxLinkIDProbes = 16
+
+// errLinkIDProbes возвращается когда все maxLinkIDProbes UUID цепочки заняты другими адресами
+var errLinkIDProbes = errors.New("too many retargeted links")
+
+// Состояние записи с UUID, выбранным resolveLinkID
+const (
+	linkFree    = iota // Записи с UUID нет
+	linkExists         // Запись с тем же оригинальным URL уже есть
+	linkDeleted        // Запись с UUID удалена пользователем
+)
+
+// resolveLinkID выбирает UUID для сокращения оригинального URL
+// UUID ссылки - SHA-1 хэш ее оригинального URL, но после UpdateByUserID ссылка сохраняет UUID и указывает
+// на другой адрес. Поэтому запись с UUID проверяется по оригинальному URL, и если он отличается,
+// проверяется следующий UUID цепочки uuid.NewSHA1(UUID, URL). Цепочка детерминирована, и повторное
+// сокращение того же URL находит ту же запись
+// Принимает:
+// - ctx: контекст для запросов к хранилищу
+// - id: первый UUID цепочки, обычно хэш rawURL
+// - rawURL: оригинальный URL
+// Возвращает:
+// - UUID записи с тем же оригинальным URL, UUID удаленной записи или свободный UUID
+// - состояние записи с этим UUID: linkExists, linkDeleted или linkFree
+// - ошибку хранилища
+func (s *Service) resolveLinkID(ctx context.Context, id uuid.UUID, rawURL string) (uuid.UUID, int, error) {
+	for i := 0; i < maxLinkIDProbes; i++ {
+		link, err := s.Repository.GetLinkByID(ctx, id)
+		if errors.Is(err, customError.ErrNotFound) {
+			return id, linkFree, nil
+		}
+		if errors.Is(err, customError.ErrDeleteAccepted) {
+			return id, linkDeleted, nil
+		}
+		if err != nil {
+			return id, linkFree, fmt.Errorf("resolve link id error: %w", err)
+		}
+		if link.OriginalURL == rawURL {
+			return id, linkExists, nil
+		}
+		id = uuid.NewSHA1(id, []byte(rawURL))
+	}
+	return id, linkFree, fmt.Errorf("resolve link id error: %w", errLinkIDProbes)
+}
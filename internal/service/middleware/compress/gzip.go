@@ -23,6 +23,20 @@ func (c *compressWriter) WriteHeader(statusCode int) {
 	c.w.WriteHeader(statusCode)
 }
 
+// Flush отправляет клиенту уже сжатые данные, не дожидаясь завершения ответа.
+// Нужен потоковым ответам, например text/event-stream.
+func (c *compressWriter) Flush() {
+	if err := c.zw.Flush(); err != nil {
+		return
+	}
+	_ = http.NewResponseController(c.w).Flush()
+}
+
+// Unwrap возвращает исходный http.ResponseWriter для http.ResponseController.
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.w
+}
+
 // Close закрывает gzip.Writer.
 func (c *compressWriter) Close() error {
 	return c.zw.Close()
@@ -21,6 +21,12 @@ func (r *responseData) WriteHeader(statusCode int) {
 	r.status = statusCode
 }
 
+// Unwrap возвращает исходный http.ResponseWriter, чтобы через http.ResponseController
+// были доступны Flush и SetWriteDeadline.
+func (r *responseData) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // RequestLogger возвращает middleware для логирования HTTP-запросов.
 // Логируются URI, метод, длительность выполнения, статус и размер ответа.
 func RequestLogger(h http.Handler) http.Handler {
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// newOutboxEvent создает доменное событие для записи в outbox вместе с изменением данных
+// Принимает:
+// - eventType: тип события
+// - userID: UUID владельца ссылки или nil для анонимной ссылки
+// - id: UUID сокращенного URL
+// - originalURL: оригинальный URL или пустая строка
+// Возвращает доменное событие
+func newOutboxEvent(eventType string, userID *uuid.UUID, id uuid.UUID, originalURL string) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		ID:          uuid.New(),
+		Type:        eventType,
+		LinkID:      id,
+		UserID:      userID,
+		ShortURL:    config.URL + id.String(),
+		OriginalURL: originalURL,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
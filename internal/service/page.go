@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// UpdatePageMeta сохраняет метаданные страницы назначения
+// Принимает:
+// - ctx: контекст для контроля времени выполнения
+// - id: UUID сокращенного URL
+// - page: title, OpenGraph разметка и иконка страницы
+// Возвращает:
+// - ошибку, если URL не найден (ErrNotFound) или возникли проблемы при сохранении
+func (s *Service) UpdatePageMeta(ctx context.Context, id uuid.UUID, page *models.PageMeta) error {
+	err := s.Repository.UpdatePageMeta(ctx, id, page)
+	if err != nil {
+		return fmt.Errorf("update page meta error: %w", err)
+	}
+	return nil
+}
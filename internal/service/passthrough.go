@@ -0,0 +1,66 @@
+package service
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+)
+
+// PassthroughURL формирует адрес перенаправления с учетом политики передачи пути и параметров запроса ссылки
+// Принимает:
+// - link: запись URL с политикой передачи
+// - target: адрес назначения, выбранный правилами или вариантами ссылки
+// - extraPath: дополнительный путь после ID короткой ссылки
+// - query: параметры запроса к короткой ссылке
+// Возвращает:
+// - адрес назначения; при политике ignore или невалидном адресе назначения - target без изменений
+func PassthroughURL(link *models.Link, target, extraPath string, query url.Values) string {
+	policy := link.Passthrough
+	if policy == "" || policy == models.PassthroughIgnore || extraPath == "" && len(query) == 0 {
+		return target
+	}
+
+	dst, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+
+	if extraPath != "" {
+		// Путь очищается от ".." отдельно, чтобы он не мог подняться выше пути назначения
+		elem := path.Clean("/" + extraPath)
+		if strings.HasSuffix(extraPath, "/") && elem != "/" {
+			elem += "/"
+		}
+		dst = dst.JoinPath(elem)
+	}
+
+	if len(query) > 0 {
+		values := dst.Query()
+		for key, vs := range query {
+			if policy == models.PassthroughOverride {
+				values[key] = vs
+				continue
+			}
+			for _, v := range vs {
+				values.Add(key, v)
+			}
+		}
+		dst.RawQuery = values.Encode()
+	}
+	return dst.String()
+}
+
+// checkPassthrough проверяет политику передачи пути и параметров запроса ссылки
+// Принимает:
+// - policy: политика или пустая строка для политики по умолчанию
+// Возвращает:
+// - ErrPassthroughNotValid, если политика задана и не поддерживается
+func checkPassthrough(policy string) error {
+	switch policy {
+	case "", models.PassthroughIgnore, models.PassthroughAppend, models.PassthroughOverride:
+		return nil
+	}
+	return ErrPassthroughNotValid
+}
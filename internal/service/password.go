@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Ограничения пароля ссылки
+const (
+	maxPasswordLength = 72 // Максимальная длина пароля в байтах (ограничение bcrypt)
+)
+
+// HashPassword вычисляет bcrypt хэш пароля ссылки
+// Возвращает ErrPasswordNotValid, если пароль пуст или длиннее 72 байт
+func HashPassword(password string) (string, error) {
+	if password == "" || len(password) > maxPasswordLength {
+		return "", ErrPasswordNotValid
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password error: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword сравнивает пароль с bcrypt хэшем
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+)
+
+// UpdateScheduleStates фиксирует текущие состояния окон активности URL
+// Принимает:
+// - ctx: контекст для контроля времени выполнения
+// Возвращает:
+// - события смены состояния URL, окно активности которых началось или закончилось с прошлого вызова
+// - ошибку, если возникли проблемы при обновлении данных
+func (s *Service) UpdateScheduleStates(ctx context.Context) ([]*models.ScheduleEvent, error) {
+	events, err := s.Repository.UpdateScheduleStates(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("update schedule states error: %w", err)
+	}
+	return events, nil
+}
+
+// checkSchedule проверяет окно активности и резервный адрес и приводит границы окна к UTC
+// Принимает:
+// - meta: атрибуты ссылки
+// Возвращает:
+// - ErrScheduleNotValid, если окно пустое, резервный адрес невалиден или задан без окна активности
+func checkSchedule(meta *models.LinkMeta) error {
+	for _, t := range []**time.Time{&meta.ActiveFrom, &meta.ActiveUntil} {
+		if *t != nil {
+			utc := (*t).UTC()
+			*t = &utc
+		}
+	}
+
+	if meta.ActiveFrom != nil && meta.ActiveUntil != nil && !meta.ActiveFrom.Before(*meta.ActiveUntil) {
+		return ErrScheduleNotValid
+	}
+
+	if meta.FallbackURL == "" {
+		return nil
+	}
+	if meta.ActiveFrom == nil && meta.ActiveUntil == nil {
+		return ErrScheduleNotValid
+	}
+
+	u, err := url.Parse(meta.FallbackURL)
+	if err != nil || u.Scheme == "" {
+		return ErrScheduleNotValid
+	}
+	return nil
+}
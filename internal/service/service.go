@@ -2,91 +2,294 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
 	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
 	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // Save сохраняет URL в хранилище
+// Доменное событие записывается в outbox в той же транзакции, что и изменение данных
 // Принимает:
 // - ctx: контекст с информацией о пользователе
-// - id: UUID для сокращенного URL
+// - id: UUID для сокращенного URL, если он занят ссылкой с другим адресом, выбирается следующий UUID цепочки
 // - u: оригинальный URL
 // Возвращает:
 // - UUID сохраненного URL
-// - ошибку, если URL уже существует (ErrConflict) или возникли проблемы при сохранении
+// - ошибку, если URL уже существует (ErrConflict), запрещен (ErrURLBlocked) или возникли проблемы при сохранении
 func (s *Service) Save(ctx context.Context, id uuid.UUID, u *url.URL) (uuid.UUID, error) {
-	ok, _ := s.Repository.GetByID(ctx, id)
-	if ok != nil {
-		return id, fmt.Errorf("save error: %w", customError.ErrConflict)
+	if err := s.CheckURL(u.String()); err != nil {
+		return id, fmt.Errorf("save error: %w", err)
 	}
 
-	tx, err := s.BeginTx(ctx)
+	id, state, err := s.resolveLinkID(ctx, id, u.String())
 	if err != nil {
-		return id, fmt.Errorf("open transactional error: %w", err)
+		return id, fmt.Errorf("save error: %w", err)
 	}
-
-	if tx == nil {
-		userID := customContext.GetContextUserID(ctx)
-		if userID != nil {
-			return s.Repository.SaveUser(ctx, nil, *userID, id, u)
-		}
-		return s.Repository.Save(ctx, nil, id, u)
+	if state == linkExists {
+		return id, fmt.Errorf("save error: %w", customError.ErrConflict)
 	}
 
 	userID := customContext.GetContextUserID(ctx)
-	if userID != nil {
-		id, err = s.Repository.SaveUser(ctx, tx, *userID, id, u)
-		if err != nil {
-			_ = tx.Rollback(ctx)
-			return id, err
-		}
-		err = tx.Commit(ctx)
-		if err != nil {
-			return id, err
+	err = s.withTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		if userID != nil {
+			id, err = s.Repository.SaveUser(ctx, tx, *userID, id, u)
+		} else {
+			id, err = s.Repository.Save(ctx, tx, id, u)
 		}
-	} else {
-		id, err = s.Repository.Save(ctx, tx, id, u)
 		if err != nil {
-			_ = tx.Rollback(ctx)
-			return id, err
+			return err
 		}
-		err = tx.Commit(ctx)
+		return s.Repository.SaveOutbox(ctx, tx, []*models.OutboxEvent{
+			newOutboxEvent(models.OutboxEventSaved, userID, id, u.String()),
+		})
+	})
+	if err == nil {
+		s.publishCreated(userID, id, u.String())
+	}
+	return id, err
+}
+
+// SaveLink сохраняет URL с пользовательскими атрибутами в хранилище
+// Событие link.saved фиксируется в outbox вместе с записью URL.
+// Если link.ID занят ссылкой, перенаправленной на другой адрес, link.ID заменяется следующим UUID цепочки
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - link: запись URL с UUID, оригинальным URL и атрибутами
+// Возвращает:
+// - UUID сохраненного URL
+// - ошибку, если URL уже существует (ErrConflict), папка не принадлежит пользователю (ErrFolderNotValid)
+// или возникли проблемы при сохранении
+func (s *Service) SaveLink(ctx context.Context, link *models.Link) (uuid.UUID, error) {
+	id, state, err := s.resolveLinkID(ctx, link.ID, link.OriginalURL)
+	link.ID = id
+	if err != nil {
+		return link.ID, fmt.Errorf("save error: %w", err)
+	}
+	if state == linkExists {
+		return link.ID, fmt.Errorf("save error: %w", customError.ErrConflict)
+	}
+
+	link.UserID = customContext.GetContextUserID(ctx)
+	err = s.checkLink(ctx, link)
+	if err != nil {
+		return link.ID, fmt.Errorf("save error: %w", err)
+	}
+
+	err = s.withTx(ctx, func(tx pgx.Tx) error {
+		_, err := s.Repository.SaveLink(ctx, tx, link)
 		if err != nil {
-			return id, err
+			return err
 		}
+		return s.Repository.SaveOutbox(ctx, tx, []*models.OutboxEvent{
+			newOutboxEvent(models.OutboxEventSaved, link.UserID, link.ID, link.OriginalURL),
+		})
+	})
+	if err == nil {
+		s.publishCreated(link.UserID, link.ID, link.OriginalURL)
 	}
-	return id, err
+	return link.ID, err
+}
+
+// checkLink нормализует теги и проверяет атрибуты и адреса назначения записи URL перед сохранением
+// Принимает:
+// - ctx: контекст для проверки папки
+// - link: запись URL с заполненным владельцем
+// Возвращает:
+// - ошибку проверки атрибутов, *BlockedError для запрещенного адреса или ErrFolderNotValid
+func (s *Service) checkLink(ctx context.Context, link *models.Link) error {
+	link.Tags = models.NormalizeTags(link.Tags)
+	if err := checkRedirectCode(link.RedirectCode); err != nil {
+		return err
+	}
+	if err := checkPassthrough(link.Passthrough); err != nil {
+		return err
+	}
+	if err := checkRules(link.Rules); err != nil {
+		return err
+	}
+	if err := checkVariants(link.Variants); err != nil {
+		return err
+	}
+	if err := checkSchedule(&link.LinkMeta); err != nil {
+		return err
+	}
+	if err := s.checkDestinations(link.OriginalURL, &link.LinkMeta); err != nil {
+		return err
+	}
+	return s.checkFolder(ctx, link.UserID, link.FolderID)
 }
 
-// SaveBatch сохраняет несколько URL в хранилище
+// SaveBatch сохраняет несколько URL в хранилище и возвращает результат по каждому элементу
+// Элемент с невалидным URL, шаблоном, паролем или атрибутами получает статус invalid, а с запрещенным
+// адресом назначения - blocked; такие элементы не сохраняются и не прерывают сохранение остальных.
+// Уже существующие URL не изменяются и получают статус existing.
+// События link.saved для созданных URL записываются в outbox в одной транзакции с пакетом
 // Принимает:
 // - ctx: контекст с информацией о пользователе
 // - batch: массив URL для сохранения
 // Возвращает:
+// - результаты в порядке элементов batch
 // - ошибку, если batch пуст или возникли проблемы при сохранении
-func (s *Service) SaveBatch(ctx context.Context, batch []*models.RequestShortenAPIBatch) error {
+func (s *Service) SaveBatch(ctx context.Context, batch []*models.RequestShortenAPIBatch) ([]*models.ResponseShortenAPIBatch, error) {
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("save batch error: %w", customError.ErrBatchIsEmpty)
+	}
+
 	userID := customContext.GetContextUserID(ctx)
-	if userID != nil {
-		err := s.Repository.SaveBatchUser(ctx, *userID, batch)
+	results := make([]*models.ResponseShortenAPIBatch, 0, len(batch))
+	valid := make([]*models.RequestShortenAPIBatch, 0, len(batch))
+	for _, b := range batch {
+		result := &models.ResponseShortenAPIBatch{CorrelationID: b.CorrelationID}
+		results = append(results, result)
+
+		err := s.prepareBatchItem(ctx, userID, b)
+		if err == nil {
+			b.ID, _, err = s.resolveLinkID(ctx, uuid.NewSHA1(uuid.NameSpaceURL, []byte(b.OriginalURL)), b.OriginalURL)
+			if err != nil {
+				return nil, fmt.Errorf("save batch error: %w", err)
+			}
+			valid = append(valid, b)
+			continue
+		}
+
+		var blocked *BlockedError
+		if errors.As(err, &blocked) {
+			result.Status, result.Error = models.BatchStatusBlocked, blocked.Reason
+			continue
+		}
+
+		reason, ok := batchItemReason(err)
+		if !ok {
+			return nil, fmt.Errorf("save batch error: %w", err)
+		}
+		result.Status, result.Error = models.BatchStatusInvalid, reason
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	var created []bool
+	var events []*models.OutboxEvent
+	err := s.withTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		if userID != nil {
+			created, err = s.Repository.SaveBatchUser(ctx, tx, *userID, valid)
+		} else {
+			created, err = s.Repository.SaveBatch(ctx, tx, valid)
+		}
 		if err != nil {
-			return fmt.Errorf("user save batch error: %w", err)
+			return err
 		}
-		return nil
+
+		events = make([]*models.OutboxEvent, 0, len(valid))
+		for i, b := range valid {
+			if created[i] {
+				events = append(events, newOutboxEvent(models.OutboxEventSaved, userID, b.ID, b.OriginalURL))
+			}
+		}
+		return s.Repository.SaveOutbox(ctx, tx, events)
+	})
+	if err != nil {
+		if userID != nil {
+			return nil, fmt.Errorf("user save batch error: %w", err)
+		}
+		return nil, fmt.Errorf("save batch error: %w", err)
 	}
 
-	err := s.Repository.SaveBatch(ctx, batch)
+	i := 0
+	for _, result := range results {
+		if result.Status != "" {
+			continue
+		}
+
+		result.ShortURL = config.URL + valid[i].ID.String()
+		result.Status = models.BatchStatusExisting
+		if created[i] {
+			result.Status = models.BatchStatusCreated
+		}
+		i++
+	}
+
+	for _, event := range events {
+		s.publishCreated(userID, event.LinkID, event.OriginalURL)
+	}
+	return results, nil
+}
+
+// prepareBatchItem применяет шаблон UTM разметки, вычисляет хэш пароля и проверяет элемент пакета
+// Возвращает:
+// - одну из ошибок batchItemErrors, если элемент невалиден
+// - *BlockedError, если адрес назначения запрещен
+// - другую ошибку, если не удалось получить шаблоны из хранилища
+func (s *Service) prepareBatchItem(ctx context.Context, userID *uuid.UUID, b *models.RequestShortenAPIBatch) error {
+	u, err := url.Parse(b.OriginalURL)
 	if err != nil {
-		return fmt.Errorf("save batch error: %w", err)
+		return customError.ErrURLNotValid
+	}
+
+	if b.Template != "" {
+		u, err = s.ApplyTemplate(ctx, b.Template, u)
+		if err != nil {
+			return err
+		}
+		b.OriginalURL, b.Template = u.String(), ""
+	}
+
+	if b.Password != "" {
+		hash, err := HashPassword(b.Password)
+		if err != nil {
+			return ErrPasswordNotValid
+		}
+		b.PasswordHash, b.Password = hash, ""
+	}
+
+	b.Tags = models.NormalizeTags(b.Tags)
+	if err := checkRedirectCode(b.RedirectCode); err != nil {
+		return err
+	}
+	if err := checkPassthrough(b.Passthrough); err != nil {
+		return err
+	}
+	if err := checkRules(b.Rules); err != nil {
+		return err
+	}
+	if err := checkVariants(b.Variants); err != nil {
+		return err
+	}
+	if err := checkSchedule(&b.LinkMeta); err != nil {
+		return err
+	}
+	if err := s.checkDestinations(b.OriginalURL, &b.LinkMeta); err != nil {
+		return err
+	}
+	if err := s.checkFolder(ctx, userID, b.FolderID); err != nil {
+		return ErrFolderNotValid
 	}
 	return nil
 }
 
+// batchItemReason возвращает причину, по которой элемент пакета невалиден, или false,
+// если err не относится к проверке элемента
+func batchItemReason(err error) (string, bool) {
+	for _, target := range batchItemErrors {
+		if errors.Is(err, target) {
+			return target.Error(), true
+		}
+	}
+	return "", false
+}
+
 // GetByID получает оригинальный URL по его сокращенному идентификатору
 // Принимает:
 // - ctx: контекст с информацией о пользователе
@@ -102,40 +305,220 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error) {
 	return u, nil
 }
 
-// GetAllByUserID получает все URL, принадлежащие текущему пользователю
+// GetLinkByID получает запись URL с атрибутами и счетчиком переходов
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID сокращенного URL
+// Возвращает:
+// - запись URL
+// - ошибку, если URL не найден или был удален
+func (s *Service) GetLinkByID(ctx context.Context, id uuid.UUID) (*models.Link, error) {
+	link, err := s.Repository.GetLinkByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get link by id error: %w", err)
+	}
+	return link, nil
+}
+
+// AddClick учитывает переход по сокращенному URL
+// Отправляет событие click в потоки SSE владельца ссылки, а при достижении порога переходов -
+// событие webhook link.click_threshold
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID сокращенного URL
+// - variant: адрес выбранного варианта или пустая строка, если у ссылки нет вариантов
+// Возвращает:
+// - ошибку, если URL не найден
+func (s *Service) AddClick(ctx context.Context, id uuid.UUID, variant string) error {
+	clicks, err := s.Repository.AddClick(ctx, id, variant)
+	if err != nil {
+		return fmt.Errorf("add click error: %w", err)
+	}
+
+	if s.Stream == nil && (s.Webhooks == nil || !s.Webhooks.IsThreshold(clicks)) {
+		return nil
+	}
+
+	link, err := s.Repository.GetLinkByID(ctx, id)
+	if err != nil || link.UserID == nil {
+		return nil
+	}
+
+	s.publishStream(models.StreamEvent{
+		Type:    models.StreamEventClick,
+		UserID:  *link.UserID,
+		LinkID:  id,
+		Clicks:  clicks,
+		Variant: variant,
+	})
+	s.publishClickThreshold(link, clicks)
+	return nil
+}
+
+// GetAllByUserID получает страницу URL, принадлежащих текущему пользователю
 // Принимает:
 // - ctx: контекст с информацией о пользователе
+// - filter: параметры пагинации, сортировки и фильтрации
 // Возвращает:
 // - массив URL пользователя
+// - курсор следующей страницы или пустую строку, если страница последняя
 // - ошибку, если пользователь не авторизован или возникли проблемы при получении данных
-func (s *Service) GetAllByUserID(ctx context.Context) ([]*models.ResponseShortenAPIUser, error) {
+func (s *Service) GetAllByUserID(ctx context.Context, filter *models.FilterURLs) ([]*models.ResponseShortenAPIUser, string, error) {
 	userID := customContext.GetContextUserID(ctx)
-	if userID != nil {
-		urls, err := s.Repository.GetAllByUserID(ctx, *userID)
-		if err != nil {
-			return nil, fmt.Errorf("user get all urls error: %w", err)
-		}
-		return urls, nil
+	if userID == nil {
+		return nil, "", fmt.Errorf("get all url by user id error: %w", ErrUserUnauthorized)
+	}
+
+	page := *filter
+	page.Limit = filter.Limit + 1
+	urls, err := s.Repository.GetAllByUserID(ctx, *userID, &page)
+	if err != nil {
+		return nil, "", fmt.Errorf("user get all urls error: %w", err)
+	}
+
+	if len(urls) <= filter.Limit {
+		return urls, "", nil
+	}
+
+	urls = urls[:filter.Limit]
+	last := urls[len(urls)-1]
+	next := &models.Cursor{
+		CreatedAt: last.CreatedAt,
+		ID:        last.ID,
+	}
+	return urls, next.Encode(), nil
+}
+
+// ExportByUserID обходит все URL текущего пользователя, отобранные фильтром, без пагинации
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - filter: параметры сортировки и фильтрации, ограничение и курсор не учитываются
+// - fn: функция, вызываемая для каждого URL; ее ошибка прекращает обход
+// Возвращает:
+// - ошибку, если пользователь не авторизован, возникли проблемы при чтении данных или fn вернула ошибку
+func (s *Service) ExportByUserID(ctx context.Context, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return fmt.Errorf("export urls by user id error: %w", ErrUserUnauthorized)
+	}
+
+	all := *filter
+	all.Limit, all.Cursor = 0, nil
+	if err := s.Repository.ExportByUserID(ctx, *userID, &all, fn); err != nil {
+		return fmt.Errorf("user export urls error: %w", err)
 	}
-	return nil, fmt.Errorf("get all url by user id error: %w", ErrUserUnauthorized)
+	return nil
 }
 
 // DeleteBatchByUserID удаляет несколько URL текущего пользователя
+// Для каждого удаленного URL в outbox записывается событие link.deleted в одной транзакции с удалением,
+// а после удаления в потоки SSE пользователя отправляется событие delete для каждого удаленного URL
 // Принимает:
 // - ctx: контекст с информацией о пользователе
 // - batch: массив UUID URL для удаления
 // Возвращает:
+// - UUID удаленных URL; чужие, уже удаленные и несуществующие UUID пропускаются
 // - ошибку, если пользователь не авторизован или возникли проблемы при удалении
-func (s *Service) DeleteBatchByUserID(ctx context.Context, batch []uuid.UUID) error {
+func (s *Service) DeleteBatchByUserID(ctx context.Context, batch []uuid.UUID) ([]uuid.UUID, error) {
 	userID := customContext.GetContextUserID(ctx)
 	if userID != nil {
-		err := s.Repository.DeleteBatchByUserID(ctx, *userID, batch)
+		var deleted []uuid.UUID
+		err := s.withTx(ctx, func(tx pgx.Tx) error {
+			ids, err := s.Repository.DeleteBatchByUserID(ctx, tx, *userID, batch)
+			if err != nil {
+				return err
+			}
+			deleted = ids
+			if len(deleted) == 0 {
+				return nil
+			}
+
+			events := make([]*models.OutboxEvent, 0, len(deleted))
+			for _, id := range deleted {
+				events = append(events, newOutboxEvent(models.OutboxEventDeleted, userID, id, ""))
+			}
+			return s.Repository.SaveOutbox(ctx, tx, events)
+		})
 		if err != nil {
-			return fmt.Errorf("user delete batch error: %w", err)
+			return nil, fmt.Errorf("user delete batch error: %w", err)
 		}
-		return nil
+
+		for _, id := range deleted {
+			s.publishStream(models.StreamEvent{
+				Type:   models.StreamEventDelete,
+				UserID: *userID,
+				LinkID: id,
+			})
+		}
+		return deleted, nil
+	}
+	return nil, fmt.Errorf("delete batch by user id error: %w", ErrUserUnauthorized)
+}
+
+// UpdateByUserID изменяет оригинальный URL, принадлежащий текущему пользователю
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID сокращенного URL
+// - u: новый оригинальный URL
+// Возвращает:
+// - запись истории с предыдущим и новым значением
+// - ошибку, если пользователь не авторизован, URL ему не принадлежит или был удален
+func (s *Service) UpdateByUserID(ctx context.Context, id uuid.UUID, u *url.URL) (*models.URLHistory, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("update url by user id error: %w", ErrUserUnauthorized)
+	}
+
+	if err := s.CheckURL(u.String()); err != nil {
+		return nil, fmt.Errorf("update url by user id error: %w", err)
+	}
+
+	change := &models.URLHistory{
+		ID:        id,
+		UserID:    *userID,
+		NewURL:    u.String(),
+		ChangedAt: time.Now().UTC(),
+	}
+
+	err := s.withTx(ctx, func(tx pgx.Tx) error {
+		return s.Repository.UpdateByUserID(ctx, tx, change)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("user update url error: %w", err)
+	}
+	return change, nil
+}
+
+// GetHistoryByUserID получает историю изменений URL, принадлежащего текущему пользователю
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID сокращенного URL
+// Возвращает:
+// - массив изменений в порядке их применения
+// - ошибку, если пользователь не авторизован или URL ему не принадлежит
+func (s *Service) GetHistoryByUserID(ctx context.Context, id uuid.UUID) ([]*models.URLHistory, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("get history by user id error: %w", ErrUserUnauthorized)
+	}
+
+	history, err := s.Repository.GetHistoryByUserID(ctx, *userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("user get history error: %w", err)
 	}
-	return fmt.Errorf("delete batch by user id error: %w", ErrUserUnauthorized)
+	return history, nil
+}
+
+// CacheStats возвращает счетчики кэширующего хранилища во время работы сервиса
+// Возвращает:
+// - попадания, промахи и размер кэша
+// - ErrCacheDisabled, если хранилище работает без кэша
+func (s *Service) CacheStats() (models.CacheStats, error) {
+	stats, ok := s.Repository.(StatsRepository)
+	if !ok {
+		return models.CacheStats{}, fmt.Errorf("cache stats error: %w", ErrCacheDisabled)
+	}
+	return stats.Stats(), nil
 }
 
 // Ping проверяет доступность хранилища
@@ -150,3 +533,62 @@ func (s *Service) Ping(ctx context.Context) error {
 	}
 	return nil
 }
+
+// withTx выполняет fn в транзакции хранилища, если хранилище их поддерживает
+// Принимает:
+// - ctx: контекст
+// - fn: операция, получающая транзакцию или nil
+// Возвращает:
+// - ошибку операции или ошибку фиксации транзакции
+func (s *Service) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("open transactional error: %w", err)
+	}
+
+	if tx == nil {
+		return fn(nil)
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// checkFolder проверяет, что папка существует и принадлежит пользователю
+// Принимает:
+// - ctx: контекст
+// - userID: UUID пользователя или nil
+// - folderID: UUID папки или nil
+// Возвращает:
+// - ErrFolderNotValid, если папка задана, но пользователь не авторизован или папка ему не принадлежит
+func (s *Service) checkFolder(ctx context.Context, userID, folderID *uuid.UUID) error {
+	if folderID == nil {
+		return nil
+	}
+
+	if userID == nil {
+		return ErrFolderNotValid
+	}
+
+	_, err := s.Repository.GetFolderByUserID(ctx, *userID, *folderID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFolderNotValid, err)
+	}
+	return nil
+}
+
+// checkRedirectCode проверяет код перенаправления ссылки
+// Принимает:
+// - code: код перенаправления или 0 для кода по умолчанию
+// Возвращает:
+// - ErrRedirectCodeNotValid, если код задан и не поддерживается
+func checkRedirectCode(code int) error {
+	if code != 0 && !models.IsRedirectCode(code) {
+		return ErrRedirectCodeNotValid
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/IvanKondrashkov/go-shortener/internal/stream"
+)
+
+// SubscribeStream подписывает соединение SSE на события ссылок текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - lastID: номер последнего полученного клиентом события из Last-Event-ID или nil
+// Возвращает:
+// - подписку, которую нужно завершить через UnsubscribeStream
+// - пропущенные события после lastID
+// - true, если пропущенные события восстановить нельзя и клиенту нужно перечитать данные
+// - ошибку, если пользователь не авторизован или рассылка не настроена
+func (s *Service) SubscribeStream(ctx context.Context, lastID *uint64) (*stream.Subscriber, []models.StreamEvent, bool, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, nil, false, fmt.Errorf("subscribe stream error: %w", ErrUserUnauthorized)
+	}
+
+	if s.Stream == nil {
+		return nil, nil, false, fmt.Errorf("subscribe stream error: %w", ErrStreamDisabled)
+	}
+
+	sub, missed, reset := s.Stream.Subscribe(*userID, lastID)
+	return sub, missed, reset, nil
+}
+
+// UnsubscribeStream завершает подписку соединения SSE текущего пользователя
+func (s *Service) UnsubscribeStream(ctx context.Context, sub *stream.Subscriber) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil || s.Stream == nil {
+		return
+	}
+	s.Stream.Unsubscribe(*userID, sub)
+}
+
+// publishStream отправляет событие в открытые потоки SSE владельца ссылки
+func (s *Service) publishStream(event models.StreamEvent) {
+	if s.Stream == nil {
+		return
+	}
+
+	event.ShortURL = config.URL + event.LinkID.String()
+	s.Stream.Publish(event)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+)
+
+// Ограничения правил выбора адреса назначения
+const (
+	maxRules = 20 // Максимальное количество правил ссылки
+)
+
+// Client описывает клиента, перешедшего по короткой ссылке
+type Client struct {
+	UserAgent      string // Заголовок User-Agent
+	AcceptLanguage string // Заголовок Accept-Language
+	Country        string // Код страны по базе GeoIP или пустая строка
+}
+
+// TargetURL выбирает адрес назначения ссылки для клиента
+// Правила проверяются по порядку, срабатывает первое правило, все условия которого совпали
+// Принимает:
+// - link: запись URL
+// - client: параметры клиента
+// Возвращает:
+// - адрес назначения сработавшего правила или оригинальный URL
+// - true, если сработало одно из правил
+func TargetURL(link *models.Link, client *Client) (string, bool) {
+	if len(link.Rules) == 0 {
+		return link.OriginalURL, false
+	}
+
+	platform := Platform(client.UserAgent)
+	language := PreferredLanguage(client.AcceptLanguage)
+	for _, rule := range link.Rules {
+		if rule.Platform != "" && rule.Platform != platform {
+			continue
+		}
+		if rule.Language != "" && !matchLanguage(rule.Language, language) {
+			continue
+		}
+		if rule.Country != "" && !strings.EqualFold(rule.Country, client.Country) {
+			continue
+		}
+		return rule.URL, true
+	}
+	return link.OriginalURL, false
+}
+
+// Platform определяет платформу клиента по заголовку User-Agent
+func Platform(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "iPod"):
+		return models.PlatformIOS
+	case strings.Contains(userAgent, "Android"):
+		return models.PlatformAndroid
+	default:
+		return models.PlatformDesktop
+	}
+}
+
+// PreferredLanguage возвращает язык с наибольшим весом из заголовка Accept-Language
+// При равных весах выбирается язык, указанный первым; возвращает пустую строку, если язык не задан
+func PreferredLanguage(acceptLanguage string) string {
+	var res string
+	best := 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if q > best {
+			res, best = tag, q
+		}
+	}
+	return res
+}
+
+// matchLanguage проверяет совпадение языка правила с языком клиента
+// Язык без региона (pt) совпадает с любым регионом (pt-BR), язык с регионом - только с ним же
+func matchLanguage(rule, language string) bool {
+	if strings.EqualFold(rule, language) {
+		return true
+	}
+
+	primary, _, _ := strings.Cut(language, "-")
+	return !strings.Contains(rule, "-") && strings.EqualFold(rule, primary)
+}
+
+// checkRules проверяет и нормализует правила выбора адреса назначения
+// Принимает:
+// - rules: правила ссылки; платформа и язык приводятся к нижнему регистру, страна - к верхнему
+// Возвращает:
+// - ErrRulesNotValid, если правил слишком много, правило не имеет условий, платформа неизвестна
+// или адрес назначения невалиден
+func checkRules(rules []models.TargetRule) error {
+	if len(rules) > maxRules {
+		return ErrRulesNotValid
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		rule.Platform = strings.ToLower(strings.TrimSpace(rule.Platform))
+		rule.Language = strings.ToLower(strings.TrimSpace(rule.Language))
+		rule.Country = strings.ToUpper(strings.TrimSpace(rule.Country))
+
+		if rule.Platform == "" && rule.Language == "" && rule.Country == "" {
+			return ErrRulesNotValid
+		}
+
+		switch rule.Platform {
+		case "", models.PlatformIOS, models.PlatformAndroid, models.PlatformDesktop:
+		default:
+			return ErrRulesNotValid
+		}
+
+		if rule.Country != "" && len(rule.Country) != 2 {
+			return ErrRulesNotValid
+		}
+
+		u, err := url.Parse(rule.URL)
+		if err != nil || u.Scheme == "" {
+			return ErrRulesNotValid
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+
+	"github.com/google/uuid"
+)
+
+// SaveTemplate создает шаблон UTM разметки текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - reqDto: название шаблона и значения UTM параметров
+// Возвращает:
+// - созданный шаблон
+// - ошибку, если пользователь не авторизован или шаблон с таким названием уже существует (ErrConflict)
+func (s *Service) SaveTemplate(ctx context.Context, reqDto *models.RequestUTMTemplate) (*models.UTMTemplate, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("save template error: %w", ErrUserUnauthorized)
+	}
+
+	template := &models.UTMTemplate{
+		ID:        uuid.New(),
+		UserID:    *userID,
+		Name:      reqDto.Name,
+		CreatedAt: time.Now().UTC(),
+		UTMParams: reqDto.UTMParams,
+	}
+
+	err := s.Repository.SaveTemplate(ctx, template)
+	if err != nil {
+		return nil, fmt.Errorf("user save template error: %w", err)
+	}
+	return template, nil
+}
+
+// GetTemplatesByUserID получает все шаблоны UTM разметки текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// Возвращает:
+// - массив шаблонов в порядке создания
+// - ошибку, если пользователь не авторизован или возникли проблемы при получении данных
+func (s *Service) GetTemplatesByUserID(ctx context.Context) ([]*models.UTMTemplate, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("get templates error: %w", ErrUserUnauthorized)
+	}
+
+	templates, err := s.Repository.GetTemplatesByUserID(ctx, *userID)
+	if err != nil {
+		return nil, fmt.Errorf("user get templates error: %w", err)
+	}
+	return templates, nil
+}
+
+// UpdateTemplateByUserID изменяет шаблон UTM разметки текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID шаблона
+// - reqDto: новое название шаблона и значения UTM параметров
+// Возвращает:
+// - измененный шаблон
+// - ошибку, если пользователь не авторизован, шаблон не найден (ErrNotFound) или название занято (ErrConflict)
+func (s *Service) UpdateTemplateByUserID(ctx context.Context, id uuid.UUID, reqDto *models.RequestUTMTemplate) (*models.UTMTemplate, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("update template error: %w", ErrUserUnauthorized)
+	}
+
+	template := &models.UTMTemplate{
+		ID:        id,
+		UserID:    *userID,
+		Name:      reqDto.Name,
+		UTMParams: reqDto.UTMParams,
+	}
+
+	err := s.Repository.UpdateTemplateByUserID(ctx, template)
+	if err != nil {
+		return nil, fmt.Errorf("user update template error: %w", err)
+	}
+	return template, nil
+}
+
+// DeleteTemplateByUserID удаляет шаблон UTM разметки текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID шаблона
+// Возвращает:
+// - ошибку, если пользователь не авторизован или шаблон не найден (ErrNotFound)
+func (s *Service) DeleteTemplateByUserID(ctx context.Context, id uuid.UUID) error {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return fmt.Errorf("delete template error: %w", ErrUserUnauthorized)
+	}
+
+	err := s.Repository.DeleteTemplateByUserID(ctx, *userID, id)
+	if err != nil {
+		return fmt.Errorf("user delete template error: %w", err)
+	}
+	return nil
+}
+
+// ApplyTemplate добавляет к URL параметры шаблона UTM разметки текущего пользователя
+// Параметры, уже заданные в URL, не перезаписываются
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - name: название шаблона
+// - u: оригинальный URL
+// Возвращает:
+// - URL с параметрами шаблона
+// - ErrTemplateNotValid, если пользователь не авторизован или у него нет шаблона с таким названием
+func (s *Service) ApplyTemplate(ctx context.Context, name string, u *url.URL) (*url.URL, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("apply template error: %w", ErrTemplateNotValid)
+	}
+
+	templates, err := s.Repository.GetTemplatesByUserID(ctx, *userID)
+	if err != nil {
+		return nil, fmt.Errorf("apply template error: %w", err)
+	}
+
+	for _, template := range templates {
+		if template.Name != name {
+			continue
+		}
+
+		res := *u
+		query := res.Query()
+		for key, value := range map[string]string{
+			"utm_source":   template.Source,
+			"utm_medium":   template.Medium,
+			"utm_campaign": template.Campaign,
+			"utm_content":  template.Content,
+		} {
+			if value != "" && !query.Has(key) {
+				query.Set(key, value)
+			}
+		}
+		res.RawQuery = query.Encode()
+		return &res, nil
+	}
+	return nil, fmt.Errorf("apply template error: %w", ErrTemplateNotValid)
+}
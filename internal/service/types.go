@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"net/url"
+	"time"
 
+	"github.com/IvanKondrashkov/go-shortener/internal/blocklist"
 	"github.com/IvanKondrashkov/go-shortener/internal/logger"
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+	"github.com/IvanKondrashkov/go-shortener/internal/stream"
+	"github.com/IvanKondrashkov/go-shortener/internal/webhook"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -16,8 +21,75 @@ import (
 var (
 	// ErrUserUnauthorized возвращается когда операция требует авторизации пользователя
 	ErrUserUnauthorized = errors.New("user unauthorized")
+	// ErrFolderNotValid возвращается когда папка не существует или не принадлежит пользователю
+	ErrFolderNotValid = errors.New("folder is invalidate")
+	// ErrPasswordNotValid возвращается когда пароль ссылки пуст или слишком длинный
+	ErrPasswordNotValid = errors.New("password is invalidate")
+	// ErrRedirectCodeNotValid возвращается когда код перенаправления ссылки не поддерживается
+	ErrRedirectCodeNotValid = errors.New("redirect code is invalidate")
+	// ErrPassthroughNotValid возвращается когда политика передачи пути и параметров запроса не поддерживается
+	ErrPassthroughNotValid = errors.New("passthrough is invalidate")
+	// ErrTemplateNotValid возвращается когда шаблон UTM разметки не существует или не принадлежит пользователю
+	ErrTemplateNotValid = errors.New("template is invalidate")
+	// ErrRulesNotValid возвращается когда правила выбора адреса назначения невалидны
+	ErrRulesNotValid = errors.New("rules is invalidate")
+
+	// ErrVariantsNotValid возвращается когда варианты адреса назначения невалидны
+	ErrVariantsNotValid = errors.New("variants is invalidate")
+
+	// ErrScheduleNotValid возвращается когда окно активности или резервный адрес невалидны
+	ErrScheduleNotValid = errors.New("schedule is invalidate")
+
+	// ErrURLBlocked возвращается когда адрес назначения запрещен списком блокировки или эвристиками
+	ErrURLBlocked = errors.New("url is blocked")
+
+	// ErrWebhookNotValid возвращается когда адрес или события webhook невалидны
+	ErrWebhookNotValid = errors.New("webhook is invalidate")
+	// ErrWebhookLimit возвращается когда у пользователя уже максимальное количество webhook
+	ErrWebhookLimit = errors.New("webhook limit exceeded")
+
+	// ErrStreamDisabled возвращается когда рассылка событий потоков SSE не настроена
+	ErrStreamDisabled = errors.New("stream is disabled")
+
+	// ErrCacheDisabled возвращается когда хранилище работает без кэша
+	ErrCacheDisabled = errors.New("cache is disabled")
+
+	// ErrIdempotencyKeyNotValid возвращается когда Idempotency-Key пуст, слишком длинный или содержит непечатные символы
+	ErrIdempotencyKeyNotValid = errors.New("idempotency key is invalidate")
+	// ErrIdempotencyInProgress возвращается когда запрос с тем же Idempotency-Key еще выполняется
+	ErrIdempotencyInProgress = errors.New("idempotency key is in progress")
+	// ErrIdempotencyKeyReused возвращается когда Idempotency-Key уже использован для запроса с другим телом
+	ErrIdempotencyKeyReused = errors.New("idempotency key is reused")
+
+	// ErrSlugNotValid возвращается когда короткое имя содержит недопустимые символы, слишком длинное,
+	// совпадает с UUID или с маршрутом сервиса
+	ErrSlugNotValid = errors.New("slug is invalidate")
+	// ErrSlugCollision возвращается когда короткое имя уже занято другой ссылкой
+	ErrSlugCollision = errors.New("slug is already used")
+	// ErrLinkDeleted возвращается когда импортируемая ссылка была удалена пользователем
+	ErrLinkDeleted = errors.New("url is deleted")
 )
 
+// batchItemErrors ошибки проверки, с которыми элемент пакетного сокращения получает статус invalid
+var batchItemErrors = []error{
+	customError.ErrURLNotValid,
+	ErrTemplateNotValid,
+	ErrPasswordNotValid,
+	ErrRedirectCodeNotValid,
+	ErrPassthroughNotValid,
+	ErrRulesNotValid,
+	ErrVariantsNotValid,
+	ErrScheduleNotValid,
+	ErrFolderNotValid,
+	ErrSlugNotValid,
+}
+
+// StatsRepository реализуется кэширующим хранилищем, которое считает попадания и промахи
+type StatsRepository interface {
+	// Stats возвращает текущие счетчики кэша
+	Stats() models.CacheStats
+}
+
 // Runner интерфейс для работы с транзакциями
 type Runner interface {
 	// BeginTx начинает новую транзакцию
@@ -29,23 +101,131 @@ type UserRepository interface {
 	// SaveUser сохраняет URL для конкретного пользователя
 	SaveUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, id uuid.UUID, url *url.URL) (uuid.UUID, error)
 	// SaveBatchUser сохраняет несколько URL для конкретного пользователя
-	SaveBatchUser(ctx context.Context, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) error
-	// GetAllByUserID получает все URL пользователя
-	GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.ResponseShortenAPIUser, error)
-	// DeleteBatchByUserID удаляет несколько URL пользователя
-	DeleteBatchByUserID(ctx context.Context, userID uuid.UUID, batch []uuid.UUID) error
+	// Для каждого элемента возвращает true, если URL создан, и false, если URL уже существовал и не изменен
+	SaveBatchUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error)
+	// GetAllByUserID получает страницу URL пользователя согласно фильтру
+	GetAllByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs) ([]*models.ResponseShortenAPIUser, error)
+	// SaveAlias сохраняет короткое имя ссылки
+	// Возвращает ErrConflict, если короткое имя уже занято
+	SaveAlias(ctx context.Context, tx pgx.Tx, alias *models.Alias) error
+	// GetIDBySlug получает UUID ссылки по короткому имени
+	// Возвращает ErrNotFound, если короткого имени нет
+	GetIDBySlug(ctx context.Context, slug string) (uuid.UUID, error)
+	// GetIDsBySlugs получает UUID ссылок по нескольким коротким именам одним запросом
+	// Несуществующих коротких имен нет в результате
+	GetIDsBySlugs(ctx context.Context, slugs []string) (map[string]uuid.UUID, error)
+	// ExportByUserID вызывает fn для каждого URL пользователя согласно фильтру, не собирая их в срез
+	// Ошибка fn прекращает обход и возвращается вызывающему
+	ExportByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error
+	// DeleteBatchByUserID удаляет несколько URL пользователя и возвращает UUID удаленных URL
+	DeleteBatchByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []uuid.UUID) ([]uuid.UUID, error)
+	// UpdateByUserID изменяет оригинальный URL пользователя и записывает изменение в историю
+	UpdateByUserID(ctx context.Context, tx pgx.Tx, change *models.URLHistory) error
+	// GetHistoryByUserID получает историю изменений URL пользователя
+	GetHistoryByUserID(ctx context.Context, userID uuid.UUID, id uuid.UUID) ([]*models.URLHistory, error)
+}
+
+// FolderRepository интерфейс для работы с папками пользователя
+type FolderRepository interface {
+	// SaveFolder создает папку пользователя
+	SaveFolder(ctx context.Context, folder *models.Folder) error
+	// GetFoldersByUserID получает все папки пользователя
+	GetFoldersByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Folder, error)
+	// GetFolderByUserID получает папку пользователя по ее идентификатору
+	GetFolderByUserID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Folder, error)
+	// UpdateFolderByUserID переименовывает папку пользователя
+	UpdateFolderByUserID(ctx context.Context, folder *models.Folder) error
+	// DeleteFolderByUserID удаляет папку пользователя, URL папки остаются без папки
+	DeleteFolderByUserID(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+}
+
+// TemplateRepository интерфейс для работы с шаблонами UTM разметки пользователя
+type TemplateRepository interface {
+	// SaveTemplate создает шаблон пользователя
+	SaveTemplate(ctx context.Context, template *models.UTMTemplate) error
+	// GetTemplatesByUserID получает все шаблоны пользователя
+	GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UTMTemplate, error)
+	// UpdateTemplateByUserID изменяет шаблон пользователя
+	UpdateTemplateByUserID(ctx context.Context, template *models.UTMTemplate) error
+	// DeleteTemplateByUserID удаляет шаблон пользователя
+	DeleteTemplateByUserID(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+}
+
+// WebhookRepository интерфейс для работы с webhook пользователя и журналом их доставок
+type WebhookRepository interface {
+	// SaveWebhook создает webhook пользователя
+	SaveWebhook(ctx context.Context, webhook *models.Webhook) error
+	// GetWebhooksByUserID получает все webhook пользователя
+	GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error)
+	// UpdateWebhookByUserID сохраняет секреты webhook пользователя после ротации
+	UpdateWebhookByUserID(ctx context.Context, webhook *models.Webhook) error
+	// DeleteWebhookByUserID удаляет webhook пользователя вместе с журналом доставок
+	DeleteWebhookByUserID(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+	// SaveWebhookDelivery записывает попытку доставки события в журнал
+	SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// GetWebhookDeliveries получает до limit последних попыток доставки webhook пользователя
+	GetWebhookDeliveries(ctx context.Context, userID uuid.UUID, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error)
+}
+
+// OutboxRepository интерфейс для работы с доменными событиями, ожидающими публикации
+type OutboxRepository interface {
+	// SaveOutbox записывает доменные события в outbox в той же транзакции, что и изменение данных
+	SaveOutbox(ctx context.Context, tx pgx.Tx, events []*models.OutboxEvent) error
+	// GetOutbox получает до limit неопубликованных событий в порядке записи
+	GetOutbox(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	// DeleteOutbox удаляет опубликованные события из outbox
+	DeleteOutbox(ctx context.Context, ids []uuid.UUID) error
+}
+
+// IdempotencyRepository интерфейс для хранения ответов на запросы с Idempotency-Key
+type IdempotencyRepository interface {
+	// ReserveIdempotency резервирует ключ пользователя, если для него нет действующей записи, и возвращает nil,
+	// иначе возвращает действующую запись. Проверка и резерв выполняются атомарно
+	ReserveIdempotency(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	// CompleteIdempotency сохраняет ответ на запрос с зарезервированным ключом
+	CompleteIdempotency(ctx context.Context, record *models.IdempotencyRecord) error
+	// DeleteIdempotency снимает резерв ключа пользователя
+	DeleteIdempotency(ctx context.Context, userID uuid.UUID, key string) error
+	// DeleteExpiredIdempotency удаляет записи с истекшим временем хранения и возвращает их количество
+	DeleteExpiredIdempotency(ctx context.Context) (int64, error)
 }
 
 // Repository объединяет интерфейсы для работы с хранилищем URL
 type Repository interface {
 	Runner
 	UserRepository
+	FolderRepository
+	TemplateRepository
+	WebhookRepository
+	OutboxRepository
+	IdempotencyRepository
 	// Save сохраняет URL
 	Save(ctx context.Context, tx pgx.Tx, id uuid.UUID, url *url.URL) (uuid.UUID, error)
+	// SaveLink сохраняет запись URL с ее атрибутами
+	SaveLink(ctx context.Context, tx pgx.Tx, link *models.Link) (uuid.UUID, error)
 	// SaveBatch сохраняет несколько URL
-	SaveBatch(ctx context.Context, batch []*models.RequestShortenAPIBatch) error
+	// Для каждого элемента возвращает true, если URL создан, и false, если URL уже существовал и не изменен
+	SaveBatch(ctx context.Context, tx pgx.Tx, batch []*models.RequestShortenAPIBatch) ([]bool, error)
 	// GetByID получает URL по его идентификатору
 	GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error)
+	// GetLinkByID получает запись URL с атрибутами и счетчиком переходов по его идентификатору
+	GetLinkByID(ctx context.Context, id uuid.UUID) (*models.Link, error)
+	// GetLinksByIDs получает записи URL по нескольким идентификаторам одним запросом
+	// Удаленные записи возвращаются с IsDeleted, несуществующих идентификаторов нет в результате
+	GetLinksByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Link, error)
+	// AddClick увеличивает счетчик переходов по URL и, если variant не пуст, по адресу варианта,
+	// и возвращает новое количество переходов по URL
+	AddClick(ctx context.Context, id uuid.UUID, variant string) (int64, error)
+	// UpdateScheduleStates фиксирует состояния окон активности URL на момент now и возвращает изменения
+	UpdateScheduleStates(ctx context.Context, now time.Time) ([]*models.ScheduleEvent, error)
+	// DisableByID отключает URL с указанной причиной, пустая причина включает URL
+	DisableByID(ctx context.Context, id uuid.UUID, reason string) error
+	// GetLinksToCheck получает до limit URL, не проверявшихся или проверенных до before, для проверки доступности
+	GetLinksToCheck(ctx context.Context, before time.Time, limit int) ([]*models.Link, error)
+	// UpdateHealth сохраняет результат проверки доступности URL
+	UpdateHealth(ctx context.Context, id uuid.UUID, health *models.LinkHealth) error
+	// UpdatePageMeta сохраняет метаданные страницы назначения URL
+	UpdatePageMeta(ctx context.Context, id uuid.UUID, page *models.PageMeta) error
 	// Load загружает данные в хранилище
 	Load(ctx context.Context) error
 	// Ping проверяет доступность хранилища
@@ -54,11 +234,29 @@ type Repository interface {
 	Close()
 }
 
+// BlockedError ошибка запрещенного адреса назначения с причиной блокировки
+type BlockedError struct {
+	Reason string // Причина блокировки (blocklisted, ip_literal, excessive_subdomains, punycode_lookalike)
+}
+
+// Error возвращает текст ошибки с причиной блокировки
+func (e *BlockedError) Error() string {
+	return ErrURLBlocked.Error() + ": " + e.Reason
+}
+
+// Unwrap позволяет сравнивать ошибку с ErrURLBlocked
+func (e *BlockedError) Unwrap() error {
+	return ErrURLBlocked
+}
+
 // Service реализует бизнес-логику сервиса сокращения URL
 type Service struct {
-	Runner                       // Для работы с транзакциями
-	Logger     *logger.ZapLogger // Логгер для записи событий
-	Repository Repository        // Репозиторий для работы с данными
+	Runner                         // Для работы с транзакциями
+	Logger     *logger.ZapLogger   // Логгер для записи событий
+	Repository Repository          // Репозиторий для работы с данными
+	Blocklist  *blocklist.List     // Список запрещенных адресов назначения (может быть nil)
+	Webhooks   *webhook.Dispatcher // Доставка событий жизненного цикла ссылок (может быть nil)
+	Stream     *stream.Hub         // Рассылка событий о переходах и удалениях в потоки SSE (может быть nil)
 }
 
 // NewService создает новый экземпляр сервиса
@@ -66,11 +264,17 @@ type Service struct {
 // - zl: логгер
 // - ru: реализация интерфейса Runner
 // - r: реализация интерфейса Repository
+// - b: список запрещенных адресов назначения, nil - проверяются только эвристики
+// - wh: доставка событий webhook, nil - события не отправляются
+// - st: рассылка событий потоков SSE, nil - события не рассылаются
 // Возвращает инициализированный Service
-func NewService(zl *logger.ZapLogger, ru Runner, r Repository) *Service {
+func NewService(zl *logger.ZapLogger, ru Runner, r Repository, b *blocklist.List, wh *webhook.Dispatcher, st *stream.Hub) *Service {
 	return &Service{
 		Logger:     zl,
 		Runner:     ru,
 		Repository: r,
+		Blocklist:  b,
+		Webhooks:   wh,
+		Stream:     st,
 	}
 }
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"math/rand/v2"
+	"net/url"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+)
+
+// Ограничения вариантов адреса назначения
+const (
+	maxVariants      = 10   // Максимальное количество вариантов ссылки
+	maxVariantWeight = 1000 // Максимальный вес варианта
+)
+
+// PickVariant выбирает вариант адреса назначения для посетителя
+// Закрепленный за посетителем вариант сохраняется, пока он существует и имеет ненулевой вес,
+// иначе вариант выбирается случайно пропорционально весу
+// Принимает:
+// - variants: варианты ссылки
+// - sticky: индекс закрепленного варианта или -1
+// Возвращает:
+// - индекс выбранного варианта или -1, если у ссылки нет вариантов с ненулевым весом
+func PickVariant(variants []models.Variant, sticky int) int {
+	if sticky >= 0 && sticky < len(variants) && variants[sticky].Weight > 0 {
+		return sticky
+	}
+
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	if total == 0 {
+		return -1
+	}
+
+	n := rand.IntN(total)
+	for i, v := range variants {
+		if n < v.Weight {
+			return i
+		}
+		n -= v.Weight
+	}
+	return -1
+}
+
+// checkVariants проверяет варианты адреса назначения
+// Принимает:
+// - variants: варианты ссылки
+// Возвращает:
+// - ErrVariantsNotValid, если вариантов слишком много, адрес варианта невалиден или повторяется,
+// вес вне допустимого диапазона или суммарный вес равен нулю
+func checkVariants(variants []models.Variant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	if len(variants) > maxVariants {
+		return ErrVariantsNotValid
+	}
+
+	total := 0
+	seen := make(map[string]struct{}, len(variants))
+	for _, v := range variants {
+		u, err := url.Parse(v.URL)
+		if err != nil || u.Scheme == "" {
+			return ErrVariantsNotValid
+		}
+
+		if _, ok := seen[v.URL]; ok {
+			return ErrVariantsNotValid
+		}
+		seen[v.URL] = struct{}{}
+
+		if v.Weight < 0 || v.Weight > maxVariantWeight {
+			return ErrVariantsNotValid
+		}
+		total += v.Weight
+	}
+
+	if total == 0 {
+		return ErrVariantsNotValid
+	}
+	return nil
+}
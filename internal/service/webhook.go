@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Ограничения webhook пользователя
+const (
+	maxWebhooks      = 10   // Максимальное количество webhook у пользователя
+	maxWebhookURL    = 2048 // Максимальная длина адреса webhook
+	webhookSecretLen = 32   // Длина секрета webhook (в байтах)
+)
+
+// webhookEvents типы событий, на которые можно подписать webhook
+var webhookEvents = []string{
+	models.WebhookEventCreated,
+	models.WebhookEventDeleted,
+	models.WebhookEventExpired,
+	models.WebhookEventClickThreshold,
+}
+
+// SaveWebhook создает webhook текущего пользователя со случайным секретом подписи
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - reqDto: адрес получателя и события
+// Возвращает:
+// - созданный webhook с секретом
+// - ошибку, если пользователь не авторизован, webhook невалиден (ErrWebhookNotValid)
+// или у пользователя уже максимальное количество webhook (ErrWebhookLimit)
+func (s *Service) SaveWebhook(ctx context.Context, reqDto *models.RequestWebhook) (*models.Webhook, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("save webhook error: %w", ErrUserUnauthorized)
+	}
+
+	events, err := checkWebhook(reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("save webhook error: %w", err)
+	}
+
+	webhooks, err := s.Repository.GetWebhooksByUserID(ctx, *userID)
+	if err != nil {
+		return nil, fmt.Errorf("user save webhook error: %w", err)
+	}
+	if len(webhooks) >= maxWebhooks {
+		return nil, fmt.Errorf("save webhook error: %w", ErrWebhookLimit)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("save webhook error: %w", err)
+	}
+
+	webhook := &models.Webhook{
+		ID:        uuid.New(),
+		UserID:    *userID,
+		URL:       reqDto.URL,
+		Events:    events,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err = s.Repository.SaveWebhook(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("user save webhook error: %w", err)
+	}
+	return webhook, nil
+}
+
+// GetWebhooksByUserID получает все webhook текущего пользователя без секретов
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// Возвращает:
+// - массив webhook в порядке создания
+// - ошибку, если пользователь не авторизован или возникли проблемы при получении данных
+func (s *Service) GetWebhooksByUserID(ctx context.Context) ([]*models.Webhook, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("get webhooks error: %w", ErrUserUnauthorized)
+	}
+
+	webhooks, err := s.Repository.GetWebhooksByUserID(ctx, *userID)
+	if err != nil {
+		return nil, fmt.Errorf("user get webhooks error: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		webhook.Secret, webhook.PreviousSecret = "", ""
+	}
+	return webhooks, nil
+}
+
+// RotateWebhookSecret заменяет секрет подписи webhook текущего пользователя
+// В течение config.WebhookSecretGrace события подписываются и новым, и предыдущим секретом
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID webhook
+// Возвращает:
+// - webhook с новым секретом
+// - ошибку, если пользователь не авторизован или webhook не найден (ErrNotFound)
+func (s *Service) RotateWebhookSecret(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("rotate webhook secret error: %w", ErrUserUnauthorized)
+	}
+
+	webhooks, err := s.Repository.GetWebhooksByUserID(ctx, *userID)
+	if err != nil {
+		return nil, fmt.Errorf("user rotate webhook secret error: %w", err)
+	}
+
+	i := slices.IndexFunc(webhooks, func(w *models.Webhook) bool {
+		return w.ID == id
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("rotate webhook secret error: %w", customError.ErrNotFound)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("rotate webhook secret error: %w", err)
+	}
+
+	rotatedAt := time.Now().UTC()
+	webhook := webhooks[i]
+	webhook.Secret, webhook.PreviousSecret, webhook.RotatedAt = secret, webhook.Secret, &rotatedAt
+
+	err = s.Repository.UpdateWebhookByUserID(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("user rotate webhook secret error: %w", err)
+	}
+
+	webhook.PreviousSecret = ""
+	return webhook, nil
+}
+
+// DeleteWebhookByUserID удаляет webhook текущего пользователя вместе с журналом доставок
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID webhook
+// Возвращает:
+// - ошибку, если пользователь не авторизован или webhook не найден (ErrNotFound)
+func (s *Service) DeleteWebhookByUserID(ctx context.Context, id uuid.UUID) error {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return fmt.Errorf("delete webhook error: %w", ErrUserUnauthorized)
+	}
+
+	err := s.Repository.DeleteWebhookByUserID(ctx, *userID, id)
+	if err != nil {
+		return fmt.Errorf("user delete webhook error: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries получает журнал доставок webhook текущего пользователя
+// Принимает:
+// - ctx: контекст с информацией о пользователе
+// - id: UUID webhook
+// - limit: максимальное количество попыток
+// Возвращает:
+// - последние попытки доставки, начиная с новых
+// - ошибку, если пользователь не авторизован или webhook не найден (ErrNotFound)
+func (s *Service) GetWebhookDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	userID := customContext.GetContextUserID(ctx)
+	if userID == nil {
+		return nil, fmt.Errorf("get webhook deliveries error: %w", ErrUserUnauthorized)
+	}
+
+	deliveries, err := s.Repository.GetWebhookDeliveries(ctx, *userID, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("user get webhook deliveries error: %w", err)
+	}
+	return deliveries, nil
+}
+
+// PublishWebhookEvent ставит событие жизненного цикла ссылки в очередь доставки на webhook ее владельца
+// Заполняет UUID события, короткую ссылку и время события. События анонимных ссылок не отправляются
+// Принимает:
+// - event: тип события, владелец и UUID ссылки
+func (s *Service) PublishWebhookEvent(event models.WebhookEvent) {
+	if s.Webhooks == nil || event.UserID == uuid.Nil {
+		return
+	}
+
+	event.ID = uuid.New()
+	event.ShortURL = config.URL + event.LinkID.String()
+	event.CreatedAt = time.Now().UTC()
+	if !s.Webhooks.Publish(event) && s.Logger != nil {
+		s.Logger.Log.Warn("webhook queue is full, event is dropped",
+			zap.String("type", event.Type),
+			zap.String("link_id", event.LinkID.String()),
+		)
+	}
+}
+
+// publishCreated отправляет событие создания ссылки пользователя
+func (s *Service) publishCreated(userID *uuid.UUID, id uuid.UUID, originalURL string) {
+	if userID == nil {
+		return
+	}
+
+	s.PublishWebhookEvent(models.WebhookEvent{
+		Type:        models.WebhookEventCreated,
+		UserID:      *userID,
+		LinkID:      id,
+		OriginalURL: originalURL,
+	})
+}
+
+// publishClickThreshold отправляет событие достижения порога переходов, если clicks равно одному из порогов
+func (s *Service) publishClickThreshold(link *models.Link, clicks int64) {
+	if s.Webhooks == nil || !s.Webhooks.IsThreshold(clicks) || link.UserID == nil {
+		return
+	}
+
+	s.PublishWebhookEvent(models.WebhookEvent{
+		Type:        models.WebhookEventClickThreshold,
+		UserID:      *link.UserID,
+		LinkID:      link.ID,
+		OriginalURL: link.OriginalURL,
+		Clicks:      clicks,
+	})
+}
+
+// checkWebhook проверяет адрес и события webhook
+// Возвращает события без повторов или ErrWebhookNotValid
+func checkWebhook(reqDto *models.RequestWebhook) ([]string, error) {
+	u, err := url.Parse(reqDto.URL)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" || len(reqDto.URL) > maxWebhookURL {
+		return nil, ErrWebhookNotValid
+	}
+
+	events := make([]string, 0, len(reqDto.Events))
+	for _, event := range reqDto.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return nil, ErrWebhookNotValid
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, ErrWebhookNotValid
+	}
+	return events, nil
+}
+
+// newWebhookSecret создает случайный секрет подписи webhook
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate secret error: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
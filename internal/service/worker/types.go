@@ -4,37 +4,63 @@ import (
 	"context"
 	"sync"
 
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/linkcheck"
 	"github.com/IvanKondrashkov/go-shortener/internal/logger"
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/opengraph"
+	"github.com/IvanKondrashkov/go-shortener/internal/outbox"
 	"github.com/IvanKondrashkov/go-shortener/internal/service"
 )
 
 const (
 	bufCh = 100
+
+	linkCheckConcurrency = 8 // Количество одновременных проверок доступности адресов назначения
 )
 
-// Worker - структура для фоновой обработки задач удаления URL
+// Worker - структура для фоновой обработки задач удаления URL, окон активности, проверки адресов назначения,
+// загрузки метаданных их страниц, доставки событий webhook и публикации доменных событий outbox
 type Worker struct {
 	wg       sync.WaitGroup          // Группа ожидания завершения воркеров
+	zl       *logger.ZapLogger       // Логгер для записи событий
 	service  *service.Service        // Сервис для операций с URL
 	resultCh chan models.DeleteEvent // Канал для задач удаления
 	errorCh  chan error              // Канал для ошибок
 	doneCh   chan struct{}           // Канал для сигнализации завершения ErrorListener
+	stopCh   chan struct{}           // Канал для остановки периодических задач
+	checker  *linkcheck.Checker      // Проверка доступности адресов назначения, nil если проверка выключена
+	fetcher  *opengraph.Fetcher      // Загрузчик метаданных страниц назначения, nil если загрузка выключена
+	pageCh   chan models.FetchEvent  // Канал для задач загрузки метаданных страниц
+	relay    *outbox.Relay           // Публикация доменных событий outbox, nil если получатели не заданы
 }
 
 // NewWorker создает новый пул воркеров для обработки удаления URL
+// и запускает проверку окон активности URL с периодом config.ScheduleInterval,
+// а при заданном config.LinkCheckInterval - проверку доступности адресов назначения.
+// При заданном загрузчике запускает config.PageFetchWorkers воркеров загрузки метаданных страниц,
+// а при заданной в сервисе доставке webhook - config.WebhookWorkers воркеров доставки событий.
+// При заданном relay публикует доменные события outbox с периодом config.OutboxInterval.
+// Устаревшие ответы Idempotency-Key удаляются с периодом config.IdempotencyInterval
 // Принимает:
 // - ctx: контекст для контроля времени выполнения
 // - workerCount: количество воркеров
 // - zl: логгер
 // - s: сервис для операций с URL
+// - f: загрузчик метаданных страниц назначения (может быть nil)
+// - r: relay доменных событий outbox (может быть nil)
 // Возвращает инициализированный Worker
-func NewWorker(ctx context.Context, workerCount int, zl *logger.ZapLogger, s *service.Service) *Worker {
+func NewWorker(ctx context.Context, workerCount int, zl *logger.ZapLogger, s *service.Service, f *opengraph.Fetcher, r *outbox.Relay) *Worker {
 	w := &Worker{
+		zl:       zl,
 		service:  s,
 		resultCh: make(chan models.DeleteEvent, bufCh),
 		errorCh:  make(chan error, bufCh),
 		doneCh:   make(chan struct{}),
+		stopCh:   make(chan struct{}),
+		fetcher:  f,
+		pageCh:   make(chan models.FetchEvent, bufCh),
+		relay:    r,
 	}
 
 	go w.ErrorListener(ctx, zl)
@@ -43,5 +69,46 @@ func NewWorker(ctx context.Context, workerCount int, zl *logger.ZapLogger, s *se
 		w.wg.Add(1)
 		go w.RunJobDeleteBatch(ctx)
 	}
+
+	if f != nil {
+		for i := 0; i < config.PageFetchWorkers; i++ {
+			w.wg.Add(1)
+			go w.RunJobFetchPage(ctx)
+		}
+	}
+
+	if s.Webhooks != nil {
+		for i := 0; i < config.WebhookWorkers; i++ {
+			w.wg.Add(1)
+			go w.RunJobWebhook(ctx)
+		}
+	}
+
+	if config.ScheduleInterval > 0 {
+		w.wg.Add(1)
+		go w.RunJobSchedule(ctx, config.ScheduleInterval)
+	}
+
+	if config.IdempotencyInterval > 0 {
+		w.wg.Add(1)
+		go w.RunJobIdempotency(ctx, config.IdempotencyInterval)
+	}
+
+	if r != nil && config.OutboxInterval > 0 {
+		w.wg.Add(1)
+		go w.RunJobOutbox(ctx, config.OutboxInterval)
+	}
+
+	if config.LinkCheckInterval > 0 {
+		allow, err := linkcheck.ParseAllowlist(config.LinkCheckAllowlist)
+		if err != nil {
+			zl.Log.Warn("link check is disabled: " + err.Error())
+			return w
+		}
+
+		w.checker = linkcheck.NewChecker(config.LinkCheckTimeout, allow)
+		w.wg.Add(1)
+		go w.RunJobLinkCheck(ctx, config.LinkCheckInterval)
+	}
 	return w
 }
@@ -2,9 +2,13 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/IvanKondrashkov/go-shortener/internal/logger"
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/opengraph"
 	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
 	"go.uber.org/zap"
 )
@@ -22,6 +26,7 @@ func (w *Worker) SendDeleteBatchRequest(ctx context.Context, event models.Delete
 }
 
 // RunJobDeleteBatch запускает воркер для обработки задач удаления
+// После удаления отправляет событие link.deleted для каждого удаленного URL пакета
 // Принимает:
 // ctx - контекст для контроля времени выполнения
 func (w *Worker) RunJobDeleteBatch(ctx context.Context) {
@@ -31,11 +36,310 @@ func (w *Worker) RunJobDeleteBatch(ctx context.Context) {
 			continue
 		}
 		ctx = customContext.SetContextUserID(ctx, *event.UserID)
-		err := w.service.DeleteBatchByUserID(ctx, event.Batch)
-		if err != nil && ctx.Err() == nil {
-			w.errorCh <- err
+		deleted, err := w.service.DeleteBatchByUserID(ctx, event.Batch)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.errorCh <- err
+			}
+			continue
+		}
+
+		for _, id := range deleted {
+			w.service.PublishWebhookEvent(models.WebhookEvent{
+				Type:   models.WebhookEventDeleted,
+				UserID: *event.UserID,
+				LinkID: id,
+			})
+		}
+	}
+}
+
+// detach возвращает контекст фоновой задачи: он сохраняет значения ctx, не отменяется вместе с ним
+// и отменяется после закрытия stopCh
+func detach(ctx context.Context, stopCh <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// SendFetchPageRequest ставит задачу загрузки метаданных страницы назначения в очередь
+// Не блокирует вызывающего: при выключенной загрузке или заполненной очереди задача отбрасывается
+// Принимает:
+// event - событие загрузки (UUID сокращенного URL и адрес назначения)
+func (w *Worker) SendFetchPageRequest(event models.FetchEvent) {
+	if w.fetcher == nil {
+		return
+	}
+
+	select {
+	case w.pageCh <- event:
+	default:
+		w.zl.Log.Debug("fetch page queue is full", zap.String("id", event.ID.String()))
+	}
+}
+
+// RunJobFetchPage запускает воркер загрузки метаданных страниц назначения до вызова Close
+// Принимает:
+// ctx - контекст со значениями запроса; его отмена не останавливает загрузку
+func (w *Worker) RunJobFetchPage(ctx context.Context) {
+	defer w.wg.Done()
+
+	ctx, cancel := detach(ctx, w.stopCh)
+	defer cancel()
+
+	for event := range w.pageCh {
+		if ctx.Err() != nil {
+			continue
 		}
+		w.fetchPage(ctx, event)
+	}
+}
+
+// fetchPage загружает страницу назначения и сохраняет ее метаданные
+// Страницы, запретившие использование метаданных, пропускаются
+func (w *Worker) fetchPage(ctx context.Context, event models.FetchEvent) {
+	page, err := w.fetcher.Fetch(ctx, event.URL)
+	if ctx.Err() != nil {
+		return
+	}
+	if errors.Is(err, opengraph.ErrOptOut) {
+		w.zl.Log.Debug("page opted out of preview", zap.String("id", event.ID.String()))
+		return
+	}
+	if err != nil {
+		w.zl.Log.Debug("fetch page error", zap.String("id", event.ID.String()), zap.Error(err))
+		return
+	}
+
+	err = w.service.UpdatePageMeta(ctx, event.ID, page)
+	if err != nil {
+		w.zl.Log.Debug("update page meta error", zap.Error(err))
+	}
+}
+
+// RunJobWebhook запускает воркер доставки событий webhook до вызова Close
+// Принимает:
+// ctx - контекст со значениями запроса; его отмена не останавливает доставку
+func (w *Worker) RunJobWebhook(ctx context.Context) {
+	defer w.wg.Done()
+
+	ctx, cancel := detach(ctx, w.stopCh)
+	defer cancel()
+
+	events := w.service.Webhooks.Events()
+	for {
+		select {
+		case event := <-events:
+			err := w.service.Webhooks.Deliver(ctx, event)
+			if err != nil && ctx.Err() == nil {
+				w.zl.Log.Debug("deliver webhook error", zap.String("type", event.Type), zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunJobSchedule запускает периодическую проверку окон активности URL до вызова Close
+// На каждой границе окна фиксирует новое состояние URL и записывает событие в лог
+// Принимает:
+// ctx - контекст со значениями запроса; его отмена не останавливает проверку
+// interval - период проверки
+func (w *Worker) RunJobSchedule(ctx context.Context, interval time.Duration) {
+	defer w.wg.Done()
+
+	ctx, cancel := detach(ctx, w.stopCh)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.updateSchedule(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// updateSchedule фиксирует состояния окон активности URL, записывает события смены состояния
+// и отправляет событие link.expired владельцам URL с закончившимся окном активности
+func (w *Worker) updateSchedule(ctx context.Context) {
+	events, err := w.service.UpdateScheduleStates(ctx)
+	if err != nil && ctx.Err() == nil {
+		w.zl.Log.Debug("update schedule states error", zap.Error(err))
+	}
+
+	for _, event := range events {
+		fields := []zap.Field{
+			zap.String("id", event.ID.String()),
+			zap.String("state", event.State),
+			zap.Time("changed_at", event.ChangedAt),
+		}
+		if event.UserID != nil {
+			fields = append(fields, zap.String("user_id", event.UserID.String()))
+		}
+		w.zl.Log.Info("link schedule state changed", fields...)
+
+		if event.State == models.ScheduleStateExpired && event.UserID != nil {
+			w.service.PublishWebhookEvent(models.WebhookEvent{
+				Type:   models.WebhookEventExpired,
+				UserID: *event.UserID,
+				LinkID: event.ID,
+			})
+		}
+	}
+}
+
+// RunJobOutbox запускает периодическую публикацию доменных событий outbox получателям до вызова Close
+// Принимает:
+// ctx - контекст со значениями запроса; его отмена не останавливает публикацию
+// interval - период публикации
+func (w *Worker) RunJobOutbox(ctx context.Context, interval time.Duration) {
+	defer w.wg.Done()
+
+	ctx, cancel := detach(ctx, w.stopCh)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.flushOutbox(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// flushOutbox публикует накопленные события outbox пакетами, пока outbox не опустеет
+// или получатель не вернет ошибку; неопубликованные события отправляются на следующем периоде
+func (w *Worker) flushOutbox(ctx context.Context) {
+	for {
+		n, err := w.relay.Flush(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.zl.Log.Warn("relay outbox error", zap.Int("published", n), zap.Error(err))
+			}
+			return
+		}
+
+		if n < w.relay.Batch() {
+			return
+		}
+	}
+}
+
+// RunJobIdempotency запускает периодическое удаление устаревших ответов Idempotency-Key до вызова Close
+// Принимает:
+// ctx - контекст со значениями запроса; его отмена не останавливает удаление
+// interval - период удаления
+func (w *Worker) RunJobIdempotency(ctx context.Context, interval time.Duration) {
+	defer w.wg.Done()
+
+	ctx, cancel := detach(ctx, w.stopCh)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n, err := w.service.DeleteExpiredIdempotency(ctx)
+			if err != nil && ctx.Err() == nil {
+				w.zl.Log.Debug("delete expired idempotency error", zap.Error(err))
+			}
+			if n > 0 {
+				w.zl.Log.Debug("expired idempotency keys deleted", zap.Int64("count", n))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunJobLinkCheck запускает периодическую проверку доступности адресов назначения до вызова Close
+// Принимает:
+// ctx - контекст со значениями запроса; его отмена не останавливает проверку
+// interval - период проверки
+func (w *Worker) RunJobLinkCheck(ctx context.Context, interval time.Duration) {
+	defer w.wg.Done()
+
+	ctx, cancel := detach(ctx, w.stopCh)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.checkLinks(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkLinks проверяет доступность адресов назначения URL, давно не проверявшихся,
+// сохраняет результаты и записывает в лог недоступные адреса
+func (w *Worker) checkLinks(ctx context.Context) {
+	links, err := w.service.GetLinksToCheck(ctx)
+	if err != nil {
+		w.zl.Log.Debug("get links to check error", zap.Error(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, linkCheckConcurrency)
+	for _, link := range links {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(link *models.Link) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			health := w.checker.Check(ctx, link.OriginalURL)
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err := w.service.UpdateHealth(ctx, link.ID, health); err != nil {
+				w.zl.Log.Debug("update link health error", zap.Error(err))
+				return
+			}
+
+			if health.Status != models.HealthStatusOK {
+				w.zl.Log.Info("link destination is unhealthy",
+					zap.String("id", link.ID.String()),
+					zap.String("status", health.Status),
+					zap.Int("status_code", health.StatusCode),
+					zap.String("final_url", health.FinalURL),
+					zap.String("error", health.Error),
+				)
+			}
+		}(link)
 	}
+	wg.Wait()
 }
 
 // ErrorListener обрабатывает ошибки от воркеров
@@ -59,7 +363,9 @@ func (w *Worker) ErrorListener(ctx context.Context, zl *logger.ZapLogger) <-chan
 
 // Close останавливает воркеры и освобождает ресурсы
 func (w *Worker) Close() {
+	close(w.stopCh)
 	close(w.resultCh)
+	close(w.pageCh)
 	w.wg.Wait()
 	close(w.errorCh)
 
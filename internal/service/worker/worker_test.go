@@ -0,0 +1,335 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/linkcheck"
+	"github.com/IvanKondrashkov/go-shortener/internal/logger"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/opengraph"
+	"github.com/IvanKondrashkov/go-shortener/internal/outbox"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+	customContext "github.com/IvanKondrashkov/go-shortener/internal/service/middleware/auth"
+	"github.com/IvanKondrashkov/go-shortener/internal/storage/mem"
+	"github.com/IvanKondrashkov/go-shortener/internal/webhook"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLinks(t *testing.T) {
+	zl, _ := logger.NewZapLogger(config.LogLevel)
+	repository := mem.NewRepository(zl)
+	s := service.NewService(zl, repository, repository, nil, nil, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(res http.ResponseWriter, _ *http.Request) {
+		res.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/moved", func(res http.ResponseWriter, req *http.Request) {
+		http.Redirect(res, req, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(res http.ResponseWriter, req *http.Request) {
+		http.Redirect(res, req, "/loop", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	userID := uuid.New()
+	want := map[string]*models.LinkHealth{
+		srv.URL + "/moved": {Status: models.HealthStatusOK, StatusCode: http.StatusOK, FinalURL: srv.URL + "/ok"},
+		srv.URL + "/loop":  {Status: models.HealthStatusRedirectLoop, StatusCode: http.StatusFound, FinalURL: srv.URL + "/loop"},
+		srv.URL + "/gone":  {Status: models.HealthStatusDead, StatusCode: http.StatusNotFound, FinalURL: srv.URL + "/gone"},
+		dead.URL + "/":     {Status: models.HealthStatusDead, FinalURL: dead.URL + "/"},
+	}
+	for rawURL := range want {
+		_, err := repository.SaveLink(context.Background(), nil, &models.Link{
+			ID:          uuid.NewSHA1(uuid.NameSpaceURL, []byte(rawURL)),
+			UserID:      &userID,
+			OriginalURL: rawURL,
+			CreatedAt:   time.Now().UTC(),
+		})
+		require.NoError(t, err)
+	}
+
+	allow, err := linkcheck.ParseAllowlist("127.0.0.0/8,::1")
+	require.NoError(t, err)
+	w := &Worker{
+		zl:      zl,
+		service: s,
+		checker: linkcheck.NewChecker(time.Second, allow),
+	}
+	w.checkLinks(context.Background())
+
+	urls, err := repository.GetAllByUserID(context.Background(), userID, &models.FilterURLs{})
+	require.NoError(t, err)
+	require.Len(t, urls, len(want))
+	for _, u := range urls {
+		require.NotNil(t, u.Health, u.OriginalURL)
+		expected := want[u.OriginalURL]
+		assert.Equal(t, expected.Status, u.Health.Status, u.OriginalURL)
+		assert.Equal(t, expected.StatusCode, u.Health.StatusCode, u.OriginalURL)
+		assert.Equal(t, expected.FinalURL, u.Health.FinalURL, u.OriginalURL)
+		assert.False(t, u.Health.CheckedAt.IsZero())
+	}
+
+	links, err := s.GetLinksToCheck(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, links)
+}
+
+func TestCheckLinksRefused(t *testing.T) {
+	zl, _ := logger.NewZapLogger(config.LogLevel)
+	repository := mem.NewRepository(zl)
+	s := service.NewService(zl, repository, repository, nil, nil, nil)
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(srv.URL))
+	_, err := repository.SaveLink(context.Background(), nil, &models.Link{
+		ID:          id,
+		OriginalURL: srv.URL,
+		CreatedAt:   time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	w := &Worker{
+		zl:      zl,
+		service: s,
+		checker: linkcheck.NewChecker(time.Second, nil),
+	}
+	w.checkLinks(context.Background())
+
+	link, err := s.GetLinkByID(context.Background(), id)
+	require.NoError(t, err)
+	require.NotNil(t, link.Health)
+	assert.Equal(t, models.HealthStatusRefused, link.Health.Status)
+	assert.Zero(t, link.Health.StatusCode)
+}
+
+func TestFetchPage(t *testing.T) {
+	zl, _ := logger.NewZapLogger(config.LogLevel)
+	repository := mem.NewRepository(zl)
+	s := service.NewService(zl, repository, repository, nil, nil, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = res.Write([]byte(`<head><title>Docs</title><meta property="og:image" content="/cover.png"></head>`))
+	})
+	mux.HandleFunc("/private", func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/html")
+		res.Header().Set("X-Robots-Tag", "nosnippet")
+		_, _ = res.Write([]byte("<title>Private</title>"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ids := make(map[string]uuid.UUID)
+	for _, path := range []string{"/page", "/private"} {
+		ids[path] = uuid.NewSHA1(uuid.NameSpaceURL, []byte(srv.URL+path))
+		_, err := repository.SaveLink(context.Background(), nil, &models.Link{
+			ID:          ids[path],
+			OriginalURL: srv.URL + path,
+			CreatedAt:   time.Now().UTC(),
+		})
+		require.NoError(t, err)
+	}
+
+	allow, err := linkcheck.ParseAllowlist("127.0.0.0/8,::1")
+	require.NoError(t, err)
+	w := &Worker{
+		zl:      zl,
+		service: s,
+		fetcher: opengraph.NewFetcher(time.Second, 64*1024, allow),
+		pageCh:  make(chan models.FetchEvent, bufCh),
+		stopCh:  make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.RunJobFetchPage(context.Background())
+
+	for path, id := range ids {
+		w.SendFetchPageRequest(models.FetchEvent{ID: id, URL: srv.URL + path})
+	}
+	close(w.pageCh)
+	w.wg.Wait()
+	close(w.stopCh)
+
+	link, err := s.GetLinkByID(context.Background(), ids["/page"])
+	require.NoError(t, err)
+	require.NotNil(t, link.Page)
+	assert.Equal(t, "Docs", link.Page.Title)
+	assert.Equal(t, srv.URL+"/cover.png", link.Page.Image)
+	assert.Equal(t, srv.URL+"/favicon.ico", link.Page.Favicon)
+
+	link, err = s.GetLinkByID(context.Background(), ids["/private"])
+	require.NoError(t, err)
+	assert.Nil(t, link.Page)
+
+	w.fetcher = nil
+	w.SendFetchPageRequest(models.FetchEvent{ID: ids["/page"], URL: srv.URL + "/page"})
+}
+
+func TestWebhookEvents(t *testing.T) {
+	zl, _ := logger.NewZapLogger(config.LogLevel)
+	repository := mem.NewRepository(zl)
+	allow, err := linkcheck.ParseAllowlist("127.0.0.0/8,::1")
+	require.NoError(t, err)
+	s := service.NewService(zl, repository, repository, nil, webhook.NewDispatcher(repository, allow, []int64{2}), nil)
+
+	userID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), userID)
+	var secret string
+	received := make(chan models.WebhookEvent, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		ts, _ := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if !webhook.Verify(secret, req.Header.Get(webhook.HeaderSignature), ts, body) {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var event models.WebhookEvent
+		_ = json.Unmarshal(body, &event)
+		received <- event
+	}))
+	defer srv.Close()
+
+	hook, err := s.SaveWebhook(ctx, &models.RequestWebhook{
+		URL:    srv.URL,
+		Events: []string{models.WebhookEventCreated, models.WebhookEventDeleted, models.WebhookEventClickThreshold},
+	})
+	require.NoError(t, err)
+	secret = hook.Secret
+
+	w := &Worker{
+		zl:       zl,
+		service:  s,
+		resultCh: make(chan models.DeleteEvent, bufCh),
+		errorCh:  make(chan error, bufCh),
+		stopCh:   make(chan struct{}),
+	}
+	w.wg.Add(2)
+	go w.RunJobWebhook(context.Background())
+	go w.RunJobDeleteBatch(context.Background())
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://example.com/docs"))
+	_, err = s.SaveLink(ctx, &models.Link{
+		ID:          id,
+		OriginalURL: "https://example.com/docs",
+		CreatedAt:   time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	for range 3 {
+		require.NoError(t, s.AddClick(context.Background(), id, ""))
+	}
+	// Для несуществующего UUID пакета событие link.deleted не отправляется
+	w.resultCh <- models.DeleteEvent{UserID: &userID, Batch: []uuid.UUID{uuid.New(), id}}
+
+	types := make(map[string]models.WebhookEvent)
+	for range 3 {
+		select {
+		case event := <-received:
+			types[event.Type] = event
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook event not delivered")
+		}
+	}
+	var deliveries []*models.WebhookDelivery
+	assert.Eventually(t, func() bool {
+		deliveries, err = s.GetWebhookDeliveries(ctx, hook.ID, 10)
+		return err == nil && len(deliveries) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	close(w.resultCh)
+	close(w.stopCh)
+	w.wg.Wait()
+
+	assert.Equal(t, "https://example.com/docs", types[models.WebhookEventCreated].OriginalURL)
+	assert.Equal(t, int64(2), types[models.WebhookEventClickThreshold].Clicks)
+	assert.Equal(t, id, types[models.WebhookEventDeleted].LinkID)
+	assert.Equal(t, config.URL+id.String(), types[models.WebhookEventDeleted].ShortURL)
+
+	for _, delivery := range deliveries {
+		assert.Equal(t, models.DeliveryStatusDelivered, delivery.Status)
+	}
+}
+
+func TestOutboxRelay(t *testing.T) {
+	zl, _ := logger.NewZapLogger(config.LogLevel)
+	repository := mem.NewRepository(zl)
+	s := service.NewService(zl, repository, repository, nil, nil, nil)
+
+	userID := uuid.New()
+	ctx := customContext.SetContextUserID(context.Background(), userID)
+	u, _ := url.Parse("https://example.com/docs")
+	id, err := s.Save(ctx, uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String())), u)
+	require.NoError(t, err)
+	_, err = s.SaveBatch(ctx, []*models.RequestShortenAPIBatch{
+		{CorrelationID: uuid.New(), OriginalURL: "https://example.com/a"},
+		{CorrelationID: uuid.New(), OriginalURL: "https://example.com/b"},
+	})
+	require.NoError(t, err)
+	deleted, err := s.DeleteBatchByUserID(ctx, []uuid.UUID{id, uuid.New()})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{id}, deleted)
+	deleted, err = s.DeleteBatchByUserID(ctx, []uuid.UUID{id})
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	var buf bytes.Buffer
+	w := &Worker{
+		zl:      zl,
+		service: s,
+		relay:   outbox.NewRelay(repository, []outbox.Sink{outbox.NewWriterSink(&buf)}, 2),
+	}
+	w.flushOutbox(context.Background())
+
+	decoder := json.NewDecoder(&buf)
+	types := make([]string, 0)
+	for decoder.More() {
+		var event models.OutboxEvent
+		require.NoError(t, decoder.Decode(&event))
+		assert.Equal(t, userID, *event.UserID)
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{models.OutboxEventSaved, models.OutboxEventSaved, models.OutboxEventSaved, models.OutboxEventDeleted}, types)
+
+	events, err := repository.GetOutbox(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestDetach(t *testing.T) {
+	userID := uuid.New()
+	parent, cancelParent := context.WithCancel(customContext.SetContextUserID(context.Background(), userID))
+	stopCh := make(chan struct{})
+
+	ctx, cancel := detach(parent, stopCh)
+	defer cancel()
+
+	cancelParent()
+	assert.NoError(t, ctx.Err())
+	assert.Equal(t, &userID, customContext.GetContextUserID(ctx))
+
+	close(stopCh)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context is not canceled after stop")
+	}
+}
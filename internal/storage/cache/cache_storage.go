@@ -0,0 +1,435 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// BeginTx начинает новую транзакцию во вложенном хранилище.
+func (c *Repository) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return c.repository.BeginTx(ctx)
+}
+
+// Save сохраняет URL во вложенном хранилище и удаляет устаревшую запись из кэша.
+func (c *Repository) Save(ctx context.Context, tx pgx.Tx, id uuid.UUID, u *url.URL) (uuid.UUID, error) {
+	defer c.Invalidate(id)
+	return c.repository.Save(ctx, tx, id, u)
+}
+
+// SaveUser сохраняет URL пользователя во вложенном хранилище и удаляет устаревшую запись из кэша.
+func (c *Repository) SaveUser(ctx context.Context, tx pgx.Tx, userID, id uuid.UUID, u *url.URL) (uuid.UUID, error) {
+	defer c.Invalidate(id)
+	return c.repository.SaveUser(ctx, tx, userID, id, u)
+}
+
+// SaveLink сохраняет запись URL во вложенном хранилище и удаляет устаревшую запись из кэша.
+func (c *Repository) SaveLink(ctx context.Context, tx pgx.Tx, link *models.Link) (uuid.UUID, error) {
+	defer c.Invalidate(link.ID)
+	return c.repository.SaveLink(ctx, tx, link)
+}
+
+// SaveBatch сохраняет несколько URL во вложенном хранилище и удаляет их записи из кэша.
+func (c *Repository) SaveBatch(ctx context.Context, tx pgx.Tx, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	defer c.Invalidate(batchIDs(batch)...)
+	return c.repository.SaveBatch(ctx, tx, batch)
+}
+
+// SaveBatchUser сохраняет несколько URL пользователя во вложенном хранилище и удаляет их записи из кэша.
+func (c *Repository) SaveBatchUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	defer c.Invalidate(batchIDs(batch)...)
+	return c.repository.SaveBatchUser(ctx, tx, userID, batch)
+}
+
+// GetByID получает URL по его UUID ключу из кэшированной записи URL.
+func (c *Repository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error) {
+	link, err := c.GetLinkByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(link.OriginalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get in cache error: %w", customError.ErrURLNotValid)
+	}
+	return u, nil
+}
+
+// GetLinkByID получает запись URL по его UUID ключу из кэша, при промахе обращается к вложенному хранилищу.
+// Ошибки ErrNotFound и ErrDeleteAccepted кэшируются так же, как и найденные записи, остальные ошибки
+// вложенного хранилища не кэшируются. Результат не кэшируется, если запись была удалена из кэша
+// во время обращения к вложенному хранилищу.
+// Возвращает копию записи, которую вызывающий код может изменять.
+func (c *Repository) GetLinkByID(ctx context.Context, id uuid.UUID) (*models.Link, error) {
+	if e, ok := c.get(id); ok {
+		c.hits.Add(1)
+		return e.link, e.err
+	}
+	c.misses.Add(1)
+
+	gen := c.beginFill(id)
+	link, err := c.repository.GetLinkByID(ctx, id)
+	c.endFill(id, gen, link, err, cacheable(ctx, err))
+	return link, err
+}
+
+// GetLinksByIDs получает записи URL по нескольким UUID ключам из вложенного хранилища, минуя кэш.
+func (c *Repository) GetLinksByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Link, error) {
+	return c.repository.GetLinksByIDs(ctx, ids)
+}
+
+// AddClick увеличивает счетчик переходов во вложенном хранилище и в кэшированной записи.
+// Запись не удаляется из кэша, чтобы переходы не снижали долю попаданий.
+func (c *Repository) AddClick(ctx context.Context, id uuid.UUID, variant string) (int64, error) {
+	clicks, err := c.repository.AddClick(ctx, id, variant)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if el, ok := c.items[id]; ok {
+		if e := el.Value.(*entry); e.link != nil {
+			e.link.Clicks++
+			if variant != "" {
+				if e.link.VariantClicks == nil {
+					e.link.VariantClicks = make(map[string]int64)
+				}
+				e.link.VariantClicks[variant]++
+			}
+		}
+	}
+	return clicks, nil
+}
+
+// DisableByID задает причину отключения URL во вложенном хранилище и удаляет запись из кэша.
+func (c *Repository) DisableByID(ctx context.Context, id uuid.UUID, reason string) error {
+	defer c.Invalidate(id)
+	return c.repository.DisableByID(ctx, id, reason)
+}
+
+// GetLinksToCheck получает записи URL для проверки доступности из вложенного хранилища.
+func (c *Repository) GetLinksToCheck(ctx context.Context, before time.Time, limit int) ([]*models.Link, error) {
+	return c.repository.GetLinksToCheck(ctx, before, limit)
+}
+
+// UpdateHealth сохраняет результат проверки доступности URL во вложенном хранилище и удаляет запись из кэша.
+func (c *Repository) UpdateHealth(ctx context.Context, id uuid.UUID, health *models.LinkHealth) error {
+	defer c.Invalidate(id)
+	return c.repository.UpdateHealth(ctx, id, health)
+}
+
+// UpdatePageMeta сохраняет метаданные страницы назначения URL во вложенном хранилище и удаляет запись из кэша.
+func (c *Repository) UpdatePageMeta(ctx context.Context, id uuid.UUID, page *models.PageMeta) error {
+	defer c.Invalidate(id)
+	return c.repository.UpdatePageMeta(ctx, id, page)
+}
+
+// UpdateScheduleStates фиксирует состояния окон активности во вложенном хранилище
+// и удаляет из кэша записи URL, состояние которых изменилось.
+func (c *Repository) UpdateScheduleStates(ctx context.Context, now time.Time) ([]*models.ScheduleEvent, error) {
+	events, err := c.repository.UpdateScheduleStates(ctx, now)
+
+	ids := make([]uuid.UUID, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	c.Invalidate(ids...)
+	return events, err
+}
+
+// GetAllByUserID получает страницу URL пользователя из вложенного хранилища.
+func (c *Repository) GetAllByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs) ([]*models.ResponseShortenAPIUser, error) {
+	return c.repository.GetAllByUserID(ctx, userID, filter)
+}
+
+// ExportByUserID вызывает fn для каждого URL пользователя из вложенного хранилища.
+func (c *Repository) ExportByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error {
+	return c.repository.ExportByUserID(ctx, userID, filter, fn)
+}
+
+// SaveAlias сохраняет короткое имя ссылки во вложенном хранилище.
+func (c *Repository) SaveAlias(ctx context.Context, tx pgx.Tx, alias *models.Alias) error {
+	return c.repository.SaveAlias(ctx, tx, alias)
+}
+
+// GetIDBySlug получает UUID ссылки по короткому имени из кэша, при промахе обращается к вложенному хранилищу.
+// Кэшируются только найденные имена: имя не меняет ссылку после сохранения, а отсутствующее имя
+// может быть сохранено другим экземпляром сервиса.
+func (c *Repository) GetIDBySlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	if id, ok := c.getSlug(slug); ok {
+		c.hits.Add(1)
+		return id, nil
+	}
+	c.misses.Add(1)
+
+	id, err := c.repository.GetIDBySlug(ctx, slug)
+	if err == nil {
+		c.putSlug(slug, id)
+	}
+	return id, err
+}
+
+// GetIDsBySlugs получает UUID ссылок по нескольким коротким именам из вложенного хранилища, минуя кэш.
+func (c *Repository) GetIDsBySlugs(ctx context.Context, slugs []string) (map[string]uuid.UUID, error) {
+	return c.repository.GetIDsBySlugs(ctx, slugs)
+}
+
+// DeleteBatchByUserID помечает несколько URL как удаленные во вложенном хранилище и удаляет их записи из кэша.
+func (c *Repository) DeleteBatchByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []uuid.UUID) ([]uuid.UUID, error) {
+	defer c.Invalidate(batch...)
+	return c.repository.DeleteBatchByUserID(ctx, tx, userID, batch)
+}
+
+// UpdateByUserID изменяет оригинальный URL во вложенном хранилище и удаляет устаревшую запись из кэша.
+func (c *Repository) UpdateByUserID(ctx context.Context, tx pgx.Tx, change *models.URLHistory) error {
+	defer c.Invalidate(change.ID)
+	return c.repository.UpdateByUserID(ctx, tx, change)
+}
+
+// GetHistoryByUserID получает историю изменений URL пользователя из вложенного хранилища.
+func (c *Repository) GetHistoryByUserID(ctx context.Context, userID, id uuid.UUID) ([]*models.URLHistory, error) {
+	return c.repository.GetHistoryByUserID(ctx, userID, id)
+}
+
+// Load инициализирует вложенное хранилище.
+func (c *Repository) Load(ctx context.Context) error {
+	return c.repository.Load(ctx)
+}
+
+// Ping проверяет доступность вложенного хранилища.
+func (c *Repository) Ping(ctx context.Context) error {
+	return c.repository.Ping(ctx)
+}
+
+// Close освобождает ресурсы вложенного хранилища.
+func (c *Repository) Close() {
+	c.repository.Close()
+}
+
+// Invalidate удаляет записи с указанными UUID из кэша.
+func (c *Repository) Invalidate(ids ...uuid.UUID) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for _, id := range ids {
+		if el, ok := c.items[id]; ok {
+			c.remove(el)
+		}
+		if f, ok := c.fills[id]; ok {
+			f.gen++
+		}
+	}
+}
+
+// Flush удаляет все записи из кэша.
+func (c *Repository) Flush() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.items = make(map[uuid.UUID]*list.Element, c.size)
+	c.slugs = make(map[string]*list.Element)
+	c.order.Init()
+	for _, f := range c.fills {
+		f.gen++
+	}
+}
+
+// Stats возвращает счетчики попаданий и промахов кэша.
+func (c *Repository) Stats() models.CacheStats {
+	c.mux.Lock()
+	size := c.order.Len()
+	c.mux.Unlock()
+
+	return models.CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Size:   size,
+	}
+}
+
+// LogStats записывает счетчики кэша в лог.
+func (c *Repository) LogStats() {
+	if c.Logger == nil {
+		return
+	}
+
+	s := c.Stats()
+	c.Logger.Log.Info("Cache stats",
+		zap.Uint64("hits", s.Hits),
+		zap.Uint64("misses", s.Misses),
+		zap.Int("size", s.Size),
+	)
+}
+
+// get возвращает копию не истекшей записи кэша и перемещает ее в начало LRU списка.
+func (c *Repository) get(id uuid.UUID) (entry, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	el, ok := c.items[id]
+	if !ok {
+		return entry{}, false
+	}
+
+	e := el.Value.(*entry)
+	if c.now().After(e.expiresAt) {
+		c.remove(el)
+		return entry{}, false
+	}
+
+	c.order.MoveToFront(el)
+	res := *e
+	if e.link != nil {
+		link := *e.link
+		link.VariantClicks = maps.Clone(e.link.VariantClicks)
+		res.link = &link
+	}
+	return res, true
+}
+
+// beginFill регистрирует обращение к вложенному хранилищу за записью URL.
+// Возвращает поколение записи, с которым endFill сравнивает поколение после обращения.
+func (c *Repository) beginFill(id uuid.UUID) uint64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	f, ok := c.fills[id]
+	if !ok {
+		f = &fill{}
+		c.fills[id] = f
+	}
+	f.pending++
+	return f.gen
+}
+
+// endFill завершает обращение к вложенному хранилищу и добавляет результат в кэш,
+// если он кэшируемый и запись не удалялась из кэша после beginFill.
+func (c *Repository) endFill(id uuid.UUID, gen uint64, link *models.Link, err error, ok bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	f := c.fills[id]
+	f.pending--
+	if f.pending == 0 {
+		delete(c.fills, id)
+	}
+	if ok && f.gen == gen {
+		c.put(id, link, err)
+	}
+}
+
+// put добавляет копию записи в кэш, вытесняя наименее используемую при превышении размера.
+// Вызывается под блокировкой кэша.
+func (c *Repository) put(id uuid.UUID, link *models.Link, err error) {
+	e := &entry{
+		id:        id,
+		err:       err,
+		expiresAt: c.now().Add(c.ttl),
+	}
+	if link != nil {
+		cp := *link
+		cp.VariantClicks = maps.Clone(link.VariantClicks)
+		e.link = &cp
+	}
+
+	if el, ok := c.items[id]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[id] = c.order.PushFront(e)
+	c.evict()
+}
+
+// getSlug возвращает UUID ссылки не истекшей записи короткого имени и перемещает ее в начало LRU списка.
+func (c *Repository) getSlug(slug string) (uuid.UUID, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	el, ok := c.slugs[slug]
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	e := el.Value.(*entry)
+	if c.now().After(e.expiresAt) {
+		c.remove(el)
+		return uuid.Nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return e.id, true
+}
+
+// putSlug добавляет запись короткого имени в кэш, вытесняя наименее используемую при превышении размера.
+func (c *Repository) putSlug(slug string, id uuid.UUID) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	e := &entry{
+		id:        id,
+		slug:      slug,
+		expiresAt: c.now().Add(c.ttl),
+	}
+
+	if el, ok := c.slugs[slug]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.slugs[slug] = c.order.PushFront(e)
+	c.evict()
+}
+
+// evict вытесняет наименее используемые записи при превышении размера кэша.
+// Вызывается под блокировкой кэша.
+func (c *Repository) evict() {
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove удаляет запись из LRU списка и ее индекса.
+// Вызывается под блокировкой кэша.
+func (c *Repository) remove(el *list.Element) {
+	c.order.Remove(el)
+	if e := el.Value.(*entry); e.slug != "" {
+		delete(c.slugs, e.slug)
+	} else {
+		delete(c.items, e.id)
+	}
+}
+
+// cacheable проверяет, можно ли кэшировать результат GetLinkByID вложенного хранилища:
+// кэшируются найденные записи, ErrNotFound и ErrDeleteAccepted, но не результаты отмененного запроса
+// и временные ошибки хранилища.
+func cacheable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return err == nil || errors.Is(err, customError.ErrNotFound) || errors.Is(err, customError.ErrDeleteAccepted)
+}
+
+// batchIDs вычисляет UUID сокращенных URL пакета.
+func batchIDs(batch []*models.RequestShortenAPIBatch) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(batch))
+	for _, b := range batch {
+		ids = append(ids, b.LinkID())
+	}
+	return ids
+}
@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/handlers/mock"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	missingID := uuid.New()
+	u, _ := url.Parse("https://ya.ru/")
+	link := &models.Link{ID: id, OriginalURL: u.String()}
+
+	repoMock := mock.NewMockRepository(ctrl)
+	repoMock.EXPECT().GetLinkByID(gomock.Any(), id).Return(link, nil).Times(1)
+	repoMock.EXPECT().GetLinkByID(gomock.Any(), missingID).
+		Return(nil, fmt.Errorf("get in mock storage error: %w", customError.ErrNotFound)).
+		Times(1)
+
+	c := NewRepository(nil, repoMock, 10, time.Minute)
+	for i := 0; i < 3; i++ {
+		got, err := c.GetByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, u, got)
+
+		_, err = c.GetByID(context.Background(), missingID)
+		assert.ErrorIs(t, err, customError.ErrNotFound)
+	}
+
+	assert.Equal(t, models.CacheStats{Hits: 4, Misses: 2, Size: 2}, c.Stats())
+}
+
+func TestGetIDBySlug(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+
+	repoMock := mock.NewMockRepository(ctrl)
+	repoMock.EXPECT().GetIDBySlug(gomock.Any(), "ya").Return(id, nil).Times(1)
+	repoMock.EXPECT().GetIDBySlug(gomock.Any(), "missing").
+		Return(uuid.Nil, fmt.Errorf("get slug in mock storage error: %w", customError.ErrNotFound)).
+		Times(2)
+
+	c := NewRepository(nil, repoMock, 10, time.Minute)
+	for i := 0; i < 2; i++ {
+		got, err := c.GetIDBySlug(context.Background(), "ya")
+		assert.NoError(t, err)
+		assert.Equal(t, id, got)
+
+		_, err = c.GetIDBySlug(context.Background(), "missing")
+		assert.ErrorIs(t, err, customError.ErrNotFound)
+	}
+
+	// Запись короткого имени не удаляется вместе с записью URL, к которой ведет имя
+	c.Invalidate(id)
+	got, err := c.GetIDBySlug(context.Background(), "ya")
+	assert.NoError(t, err)
+	assert.Equal(t, id, got)
+	assert.Equal(t, models.CacheStats{Hits: 2, Misses: 3, Size: 1}, c.Stats())
+}
+
+func TestInvalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userID := uuid.New()
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	u, _ := url.Parse("https://ya.ru/")
+	link := &models.Link{ID: id, OriginalURL: u.String()}
+
+	repoMock := mock.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repoMock.EXPECT().GetLinkByID(gomock.Any(), id).Return(link, nil),
+		repoMock.EXPECT().DeleteBatchByUserID(gomock.Any(), nil, userID, []uuid.UUID{id}).Return([]uuid.UUID{id}, nil),
+		repoMock.EXPECT().GetLinkByID(gomock.Any(), id).
+			Return(nil, fmt.Errorf("get in mock storage error: %w", customError.ErrDeleteAccepted)),
+		repoMock.EXPECT().Save(gomock.Any(), nil, id, u).Return(id, nil),
+		repoMock.EXPECT().GetLinkByID(gomock.Any(), id).Return(link, nil),
+	)
+
+	c := NewRepository(nil, repoMock, 10, time.Minute)
+	_, _ = c.GetByID(context.Background(), id)
+	_, _ = c.DeleteBatchByUserID(context.Background(), nil, userID, []uuid.UUID{id})
+
+	_, err := c.GetByID(context.Background(), id)
+	assert.ErrorIs(t, err, customError.ErrDeleteAccepted)
+
+	_, _ = c.Save(context.Background(), nil, id, u)
+	got, err := c.GetByID(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, u, got)
+}
+
+func TestEviction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	link := &models.Link{OriginalURL: "https://ya.ru/"}
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	repoMock := mock.NewMockRepository(ctrl)
+	repoMock.EXPECT().GetLinkByID(gomock.Any(), first).Return(link, nil).Times(2)
+	repoMock.EXPECT().GetLinkByID(gomock.Any(), second).Return(link, nil).Times(2)
+	repoMock.EXPECT().GetLinkByID(gomock.Any(), third).Return(link, nil).Times(1)
+
+	c := NewRepository(nil, repoMock, 2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	_, _ = c.GetByID(context.Background(), first)
+	_, _ = c.GetByID(context.Background(), second)
+	_, _ = c.GetByID(context.Background(), first)
+	_, _ = c.GetByID(context.Background(), third)
+	_, _ = c.GetByID(context.Background(), first)
+	_, _ = c.GetByID(context.Background(), second)
+
+	now = now.Add(2 * time.Minute)
+	_, _ = c.GetByID(context.Background(), first)
+
+	assert.Equal(t, models.CacheStats{Hits: 2, Misses: 5, Size: 2}, c.Stats())
+}
+
+func TestAddClick(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	link := &models.Link{ID: id, OriginalURL: "https://ya.ru/", Clicks: 1}
+
+	repoMock := mock.NewMockRepository(ctrl)
+	repoMock.EXPECT().GetLinkByID(gomock.Any(), id).Return(link, nil).Times(1)
+	repoMock.EXPECT().AddClick(gomock.Any(), id, gomock.Any()).Return(int64(2), nil).Times(2)
+
+	c := NewRepository(nil, repoMock, 10, time.Minute)
+	_, _ = c.GetLinkByID(context.Background(), id)
+	_, _ = c.AddClick(context.Background(), id, "")
+	_, _ = c.AddClick(context.Background(), id, "https://ya.ru/b")
+
+	got, err := c.GetLinkByID(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), got.Clicks)
+	assert.Equal(t, map[string]int64{"https://ya.ru/b": 1}, got.VariantClicks)
+	assert.Equal(t, int64(1), link.Clicks)
+	assert.Nil(t, link.VariantClicks)
+}
+
+func TestGetLinkByIDTransientError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	link := &models.Link{ID: id, OriginalURL: "https://ya.ru/"}
+
+	repoMock := mock.NewMockRepository(ctrl)
+	gomock.InOrder(
+		repoMock.EXPECT().GetLinkByID(gomock.Any(), id).
+			Return(nil, fmt.Errorf("get link in mock storage error: %w", context.DeadlineExceeded)),
+		repoMock.EXPECT().GetLinkByID(gomock.Any(), id).Return(link, nil),
+	)
+
+	c := NewRepository(nil, repoMock, 10, time.Minute)
+	_, err := c.GetLinkByID(context.Background(), id)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	got, err := c.GetLinkByID(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, link.OriginalURL, got.OriginalURL)
+	assert.Equal(t, models.CacheStats{Hits: 0, Misses: 2, Size: 1}, c.Stats())
+}
+
+func TestGetLinkByIDInvalidateDuringFill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://ya.ru/"))
+	stale := &models.Link{ID: id, OriginalURL: "https://ya.ru/"}
+	fresh := &models.Link{ID: id, OriginalURL: "https://ya.ru/new"}
+
+	repoMock := mock.NewMockRepository(ctrl)
+	c := NewRepository(nil, repoMock, 10, time.Minute)
+	gomock.InOrder(
+		// Запись изменили и удалили из кэша, пока чтение устаревшей записи было в пути
+		repoMock.EXPECT().GetLinkByID(gomock.Any(), id).
+			DoAndReturn(func(ctx context.Context, id uuid.UUID) (*models.Link, error) {
+				c.Invalidate(id)
+				return stale, nil
+			}),
+		repoMock.EXPECT().GetLinkByID(gomock.Any(), id).Return(fresh, nil),
+	)
+
+	got, err := c.GetLinkByID(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, stale.OriginalURL, got.OriginalURL)
+
+	for i := 0; i < 2; i++ {
+		got, err = c.GetLinkByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, fresh.OriginalURL, got.OriginalURL)
+	}
+	assert.Equal(t, models.CacheStats{Hits: 1, Misses: 2, Size: 1}, c.Stats())
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SaveFolder сохраняет папку пользователя во вложенном хранилище.
+func (c *Repository) SaveFolder(ctx context.Context, folder *models.Folder) error {
+	return c.repository.SaveFolder(ctx, folder)
+}
+
+// GetFoldersByUserID получает все папки пользователя из вложенного хранилища.
+func (c *Repository) GetFoldersByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Folder, error) {
+	return c.repository.GetFoldersByUserID(ctx, userID)
+}
+
+// GetFolderByUserID получает папку пользователя из вложенного хранилища.
+func (c *Repository) GetFolderByUserID(ctx context.Context, userID, id uuid.UUID) (*models.Folder, error) {
+	return c.repository.GetFolderByUserID(ctx, userID, id)
+}
+
+// UpdateFolderByUserID переименовывает папку пользователя во вложенном хранилище.
+func (c *Repository) UpdateFolderByUserID(ctx context.Context, folder *models.Folder) error {
+	return c.repository.UpdateFolderByUserID(ctx, folder)
+}
+
+// DeleteFolderByUserID удаляет папку пользователя во вложенном хранилище.
+func (c *Repository) DeleteFolderByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	return c.repository.DeleteFolderByUserID(ctx, userID, id)
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ReserveIdempotency резервирует ключ пользователя во вложенном хранилище.
+func (c *Repository) ReserveIdempotency(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	return c.repository.ReserveIdempotency(ctx, record)
+}
+
+// CompleteIdempotency сохраняет ответ на запрос с зарезервированным ключом во вложенном хранилище.
+func (c *Repository) CompleteIdempotency(ctx context.Context, record *models.IdempotencyRecord) error {
+	return c.repository.CompleteIdempotency(ctx, record)
+}
+
+// DeleteIdempotency снимает резерв ключа пользователя во вложенном хранилище.
+func (c *Repository) DeleteIdempotency(ctx context.Context, userID uuid.UUID, key string) error {
+	return c.repository.DeleteIdempotency(ctx, userID, key)
+}
+
+// DeleteExpiredIdempotency удаляет записи с истекшим временем хранения во вложенном хранилище.
+func (c *Repository) DeleteExpiredIdempotency(ctx context.Context) (int64, error) {
+	return c.repository.DeleteExpiredIdempotency(ctx)
+}
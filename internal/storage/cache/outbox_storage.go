@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveOutbox записывает доменные события в outbox вложенного хранилища.
+func (c *Repository) SaveOutbox(ctx context.Context, tx pgx.Tx, events []*models.OutboxEvent) error {
+	return c.repository.SaveOutbox(ctx, tx, events)
+}
+
+// GetOutbox получает неопубликованные события из вложенного хранилища.
+func (c *Repository) GetOutbox(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	return c.repository.GetOutbox(ctx, limit)
+}
+
+// DeleteOutbox удаляет опубликованные события во вложенном хранилище.
+func (c *Repository) DeleteOutbox(ctx context.Context, ids []uuid.UUID) error {
+	return c.repository.DeleteOutbox(ctx, ids)
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SaveTemplate сохраняет шаблон UTM разметки пользователя во вложенном хранилище.
+func (c *Repository) SaveTemplate(ctx context.Context, template *models.UTMTemplate) error {
+	return c.repository.SaveTemplate(ctx, template)
+}
+
+// GetTemplatesByUserID получает все шаблоны UTM разметки пользователя из вложенного хранилища.
+func (c *Repository) GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UTMTemplate, error) {
+	return c.repository.GetTemplatesByUserID(ctx, userID)
+}
+
+// UpdateTemplateByUserID изменяет шаблон UTM разметки пользователя во вложенном хранилище.
+func (c *Repository) UpdateTemplateByUserID(ctx context.Context, template *models.UTMTemplate) error {
+	return c.repository.UpdateTemplateByUserID(ctx, template)
+}
+
+// DeleteTemplateByUserID удаляет шаблон UTM разметки пользователя во вложенном хранилище.
+func (c *Repository) DeleteTemplateByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	return c.repository.DeleteTemplateByUserID(ctx, userID, id)
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/logger"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	"github.com/IvanKondrashkov/go-shortener/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// Декоратор реализует каждый метод хранилища явно, без встраивания интерфейса
+var _ service.Repository = (*Repository)(nil)
+
+// Repository реализует кэширующий декоратор хранилища для сервиса сокращения URL.
+// Хранит записи URL (результаты GetLinkByID) в ограниченном LRU кэше с TTL, включая отрицательные результаты,
+// и найденные UUID коротких имен (результаты GetIDBySlug) в том же кэше.
+// Остальные операции делегирует вложенному хранилищу.
+type Repository struct {
+	Logger     *logger.ZapLogger           // Логгер для записи событий
+	mux        sync.Mutex                  // Мьютекс для потокобезопасного доступа
+	size       int                         // Максимальное количество записей в кэше
+	ttl        time.Duration               // Время жизни записи в кэше
+	items      map[uuid.UUID]*list.Element // Индекс записей кэша по UUID
+	slugs      map[string]*list.Element    // Индекс записей кэша по короткому имени
+	order      *list.List                  // Порядок использования записей (LRU)
+	fills      map[uuid.UUID]*fill         // Незавершенные обращения к вложенному хранилищу по UUID
+	hits       atomic.Uint64               // Количество попаданий в кэш
+	misses     atomic.Uint64               // Количество промахов кэша
+	repository service.Repository          // Вложенное хранилище
+	now        func() time.Time            // Источник текущего времени
+}
+
+// entry запись кэша с результатом GetLinkByID или GetIDBySlug.
+type entry struct {
+	id        uuid.UUID    // UUID сокращенного URL
+	slug      string       // Короткое имя ссылки, пустое для записи URL
+	link      *models.Link // Запись URL
+	err       error        // Ошибка вложенного хранилища (отрицательное кэширование)
+	expiresAt time.Time    // Время истечения записи
+}
+
+// fill незавершенные обращения к вложенному хранилищу за записью URL.
+// Invalidate и Flush увеличивают поколение, чтобы результат, прочитанный до удаления записи,
+// не попал в кэш после него.
+type fill struct {
+	gen     uint64 // Поколение записи
+	pending int    // Количество незавершенных обращений
+}
+
+// NewRepository создает новый экземпляр кэширующего хранилища.
+// Принимает логгер, вложенное хранилище, максимальный размер кэша и время жизни записей.
+func NewRepository(zl *logger.ZapLogger, r service.Repository, size int, ttl time.Duration) *Repository {
+	return &Repository{
+		Logger:     zl,
+		size:       size,
+		ttl:        ttl,
+		items:      make(map[uuid.UUID]*list.Element, size),
+		slugs:      make(map[string]*list.Element),
+		order:      list.New(),
+		fills:      make(map[uuid.UUID]*fill),
+		repository: r,
+		now:        time.Now,
+	}
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SaveWebhook сохраняет webhook пользователя во вложенном хранилище.
+func (c *Repository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	return c.repository.SaveWebhook(ctx, webhook)
+}
+
+// GetWebhooksByUserID получает все webhook пользователя из вложенного хранилища.
+func (c *Repository) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	return c.repository.GetWebhooksByUserID(ctx, userID)
+}
+
+// UpdateWebhookByUserID сохраняет секреты webhook пользователя после ротации во вложенном хранилище.
+func (c *Repository) UpdateWebhookByUserID(ctx context.Context, webhook *models.Webhook) error {
+	return c.repository.UpdateWebhookByUserID(ctx, webhook)
+}
+
+// DeleteWebhookByUserID удаляет webhook пользователя во вложенном хранилище.
+func (c *Repository) DeleteWebhookByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	return c.repository.DeleteWebhookByUserID(ctx, userID, id)
+}
+
+// SaveWebhookDelivery записывает попытку доставки во вложенное хранилище.
+func (c *Repository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return c.repository.SaveWebhookDelivery(ctx, delivery)
+}
+
+// GetWebhookDeliveries получает последние попытки доставки webhook пользователя из вложенного хранилища.
+func (c *Repository) GetWebhookDeliveries(ctx context.Context, userID, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	return c.repository.GetWebhookDeliveries(ctx, userID, id, limit)
+}
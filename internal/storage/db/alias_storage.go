@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SaveAlias сохраняет короткое имя ссылки в PostgreSQL базе данных.
+// При заданной транзакции имя сохраняется в ней, иначе на соединении из пула.
+// Возвращает ErrNotFound если ссылки нет или ErrConflict если короткое имя уже занято.
+func (pg *Repository) SaveAlias(ctx context.Context, tx pgx.Tx, alias *models.Alias) error {
+	query := `
+	INSERT INTO aliases(slug, short_url, created_at)
+	VALUES ($1, $2, $3);
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, alias.Slug, alias.LinkID, alias.CreatedAt)
+	} else {
+		_, err = pg.pool.Exec(ctx, query, alias.Slug, alias.LinkID, alias.CreatedAt)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return fmt.Errorf("save alias in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("save alias in pg storage error: %w", uniqueError(err))
+	}
+	return nil
+}
+
+// GetIDBySlug получает UUID ссылки по короткому имени из PostgreSQL базы данных.
+// Возвращает ErrNotFound если короткого имени нет.
+func (pg *Repository) GetIDBySlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	query := `
+	SELECT short_url
+	FROM aliases
+	WHERE slug = $1;
+	`
+
+	var id uuid.UUID
+	err := pg.pool.QueryRow(ctx, query, slug).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, fmt.Errorf("get alias in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("get alias in pg storage error: %w", err)
+	}
+	return id, nil
+}
+
+// GetIDsBySlugs получает UUID ссылок по нескольким коротким именам из PostgreSQL базы данных одним запросом.
+// Отсутствующих коротких имен нет в результате.
+func (pg *Repository) GetIDsBySlugs(ctx context.Context, slugs []string) (map[string]uuid.UUID, error) {
+	query := `
+	SELECT slug, short_url
+	FROM aliases
+	WHERE slug = ANY($1);
+	`
+
+	rows, err := pg.pool.Query(ctx, query, slugs)
+	if err != nil {
+		return nil, fmt.Errorf("get aliases in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	res := make(map[string]uuid.UUID, len(slugs))
+	for rows.Next() {
+		var slug string
+		var id uuid.UUID
+		if err := rows.Scan(&slug, &id); err != nil {
+			return nil, fmt.Errorf("get aliases in pg storage error: %w", err)
+		}
+		res[slug] = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get aliases in pg storage error: %w", err)
+	}
+	return res, nil
+}
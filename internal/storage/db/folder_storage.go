@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SaveFolder сохраняет папку пользователя в PostgreSQL базе данных.
+// Возвращает ErrConflict если у пользователя уже есть папка с таким названием.
+func (pg *Repository) SaveFolder(ctx context.Context, folder *models.Folder) error {
+	query := `
+	INSERT INTO folders(id, user_id, name, created_at)
+	VALUES ($1, $2, $3, $4);
+	`
+
+	_, err := pg.pool.Exec(ctx, query, folder.ID, folder.UserID, folder.Name, folder.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save folder in pg storage error: %w", uniqueError(err))
+	}
+	return nil
+}
+
+// GetFoldersByUserID получает все папки пользователя из PostgreSQL базы данных в порядке создания.
+func (pg *Repository) GetFoldersByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Folder, error) {
+	query := `
+	SELECT id, user_id, name, created_at
+	FROM folders
+	WHERE user_id = $1
+	ORDER BY created_at, id;
+	`
+
+	rows, err := pg.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get folders in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	folders := make([]*models.Folder, 0)
+	for rows.Next() {
+		var folder models.Folder
+		if err = rows.Scan(&folder.ID, &folder.UserID, &folder.Name, &folder.CreatedAt); err != nil {
+			return nil, fmt.Errorf("get folders in pg storage error: %w", err)
+		}
+		folder.CreatedAt = folder.CreatedAt.UTC()
+		folders = append(folders, &folder)
+	}
+	return folders, rows.Err()
+}
+
+// GetFolderByUserID получает папку пользователя из PostgreSQL базы данных.
+// Возвращает ErrNotFound если папка не существует или не принадлежит пользователю.
+func (pg *Repository) GetFolderByUserID(ctx context.Context, userID, id uuid.UUID) (*models.Folder, error) {
+	query := `
+	SELECT id, user_id, name, created_at
+	FROM folders
+	WHERE id = $1 AND user_id = $2;
+	`
+
+	var folder models.Folder
+	err := pg.pool.QueryRow(ctx, query, id, userID).Scan(&folder.ID, &folder.UserID, &folder.Name, &folder.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get folder in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get folder in pg storage error: %w", err)
+	}
+	folder.CreatedAt = folder.CreatedAt.UTC()
+	return &folder, nil
+}
+
+// UpdateFolderByUserID переименовывает папку пользователя в PostgreSQL базе данных.
+// Возвращает ErrNotFound если папка не существует или ErrConflict если название занято.
+func (pg *Repository) UpdateFolderByUserID(ctx context.Context, folder *models.Folder) error {
+	query := `
+	UPDATE folders SET name = $3
+	WHERE id = $1 AND user_id = $2
+	RETURNING created_at;
+	`
+
+	err := pg.pool.QueryRow(ctx, query, folder.ID, folder.UserID, folder.Name).Scan(&folder.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("update folder in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("update folder in pg storage error: %w", uniqueError(err))
+	}
+	folder.CreatedAt = folder.CreatedAt.UTC()
+	return nil
+}
+
+// DeleteFolderByUserID удаляет папку пользователя из PostgreSQL базы данных.
+// URL папки остаются без папки за счет ограничения ON DELETE SET NULL.
+// Возвращает ErrNotFound если папка не существует или не принадлежит пользователю.
+func (pg *Repository) DeleteFolderByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+	DELETE FROM folders WHERE id = $1 AND user_id = $2;
+	`
+
+	tag, err := pg.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete folder in pg storage error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("delete folder in pg storage error: %w", customError.ErrNotFound)
+	}
+	return nil
+}
+
+// uniqueError преобразует нарушение уникальности названия папки или шаблона в ErrConflict.
+func uniqueError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return customError.ErrConflict
+	}
+	return err
+}
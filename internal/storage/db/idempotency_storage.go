@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReserveIdempotency резервирует ключ пользователя в PostgreSQL базе данных.
+// Резерв вставляется или заменяет запись с истекшим временем хранения одним запросом,
+// поэтому из одновременных запросов с одним ключом резерв получает только один.
+func (pg *Repository) ReserveIdempotency(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	query := `
+	INSERT INTO idempotency(user_id, key, request_hash, status, content_type, body, expires_at)
+	VALUES ($1, $2, $3, 0, '', NULL, $4)
+	ON CONFLICT (user_id, key) DO UPDATE
+	SET request_hash = EXCLUDED.request_hash, status = 0, content_type = '', body = NULL, expires_at = EXCLUDED.expires_at
+	WHERE idempotency.expires_at <= NOW()
+	RETURNING status;
+	`
+
+	var status int
+	err := pg.pool.QueryRow(ctx, query, record.UserID, record.Key, record.RequestHash, record.ExpiresAt).Scan(&status)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("reserve idempotency in pg storage error: %w", err)
+	}
+
+	query = `
+	SELECT request_hash, status, content_type, body, expires_at
+	FROM idempotency
+	WHERE user_id = $1 AND key = $2;
+	`
+
+	existing := models.IdempotencyRecord{
+		UserID: record.UserID,
+		Key:    record.Key,
+	}
+	err = pg.pool.QueryRow(ctx, query, record.UserID, record.Key).Scan(&existing.RequestHash, &existing.Status,
+		&existing.ContentType, &existing.Body, &existing.ExpiresAt)
+	// Запись удалили между запросами: первый запрос с этим ключом еще не завершился или только что завершился ошибкой
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &existing, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reserve idempotency in pg storage error: %w", err)
+	}
+	existing.ExpiresAt = existing.ExpiresAt.UTC()
+	return &existing, nil
+}
+
+// CompleteIdempotency сохраняет ответ на запрос с зарезервированным ключом в PostgreSQL базе данных.
+func (pg *Repository) CompleteIdempotency(ctx context.Context, record *models.IdempotencyRecord) error {
+	query := `
+	UPDATE idempotency SET status = $3, content_type = $4, body = $5
+	WHERE user_id = $1 AND key = $2;
+	`
+
+	_, err := pg.pool.Exec(ctx, query, record.UserID, record.Key, record.Status, record.ContentType, record.Body)
+	if err != nil {
+		return fmt.Errorf("complete idempotency in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// DeleteIdempotency снимает резерв ключа пользователя в PostgreSQL базе данных.
+func (pg *Repository) DeleteIdempotency(ctx context.Context, userID uuid.UUID, key string) error {
+	query := `
+	DELETE FROM idempotency WHERE user_id = $1 AND key = $2;
+	`
+
+	_, err := pg.pool.Exec(ctx, query, userID, key)
+	if err != nil {
+		return fmt.Errorf("delete idempotency in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotency удаляет записи с истекшим временем хранения из PostgreSQL базы данных.
+func (pg *Repository) DeleteExpiredIdempotency(ctx context.Context) (int64, error) {
+	query := `
+	DELETE FROM idempotency WHERE expires_at <= NOW();
+	`
+
+	tag, err := pg.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency in pg storage error: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Задержки переподключения слушателя уведомлений
+const (
+	minReconnectDelay = time.Second      // Начальная задержка переподключения
+	maxReconnectDelay = time.Second * 30 // Максимальная задержка переподключения
+)
+
+// Invalidator интерфейс локального кэша, записи которого сбрасываются по уведомлениям.
+type Invalidator interface {
+	// Invalidate удаляет записи с указанными UUID
+	Invalidate(ids ...uuid.UUID)
+	// Flush удаляет все записи
+	Flush()
+}
+
+// Listener слушает PostgreSQL уведомления об изменении URL и сбрасывает записи локального кэша.
+// Использует выделенное соединение, так как LISTEN привязан к сессии.
+type Listener struct {
+	Logger      *logger.ZapLogger // Логгер для записи событий
+	dns         string            // Строка подключения к БД
+	invalidator Invalidator       // Локальный кэш
+	doneCh      chan struct{}     // Канал для сигнализации завершения Listen
+}
+
+// NewListener создает новый слушатель уведомлений.
+// Принимает логгер, строку подключения к БД и локальный кэш.
+func NewListener(zl *logger.ZapLogger, dns string, inv Invalidator) *Listener {
+	return &Listener{
+		Logger:      zl,
+		dns:         dns,
+		invalidator: inv,
+		doneCh:      make(chan struct{}),
+	}
+}
+
+// Listen подписывается на канал InvalidateChannel и обрабатывает уведомления до отмены контекста.
+// При потере соединения переподключается с экспоненциальной задержкой.
+// После каждой подписки кэш сбрасывается полностью, так как уведомления за время разрыва потеряны.
+func (l *Listener) Listen(ctx context.Context) {
+	defer close(l.doneCh)
+
+	delay := minReconnectDelay
+	for {
+		err := l.listen(ctx, func() { delay = minReconnectDelay })
+		if ctx.Err() != nil {
+			return
+		}
+		l.Logger.Log.Warn("Invalidate listener connection lost", zap.Error(err), zap.Duration("retry", delay))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// Done возвращает канал, закрываемый после завершения Listen.
+func (l *Listener) Done() <-chan struct{} {
+	return l.doneCh
+}
+
+// listen устанавливает соединение, подписывается на канал и обрабатывает уведомления.
+// Вызывает onSubscribe после успешной подписки.
+func (l *Listener) listen(ctx context.Context, onSubscribe func()) error {
+	conn, err := pgx.Connect(ctx, l.dns)
+	if err != nil {
+		return fmt.Errorf("listener connection error: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{InvalidateChannel}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("listener subscribe error: %w", err)
+	}
+
+	l.invalidator.Flush()
+	onSubscribe()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("listener wait notification error: %w", err)
+		}
+
+		id, err := uuid.Parse(n.Payload)
+		if err != nil {
+			l.Logger.Log.Debug("Invalidate notification payload is invalid", zap.String("payload", n.Payload))
+			continue
+		}
+		l.invalidator.Invalidate(id)
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveOutbox записывает доменные события в outbox PostgreSQL базы данных.
+// При заданной транзакции события записываются в ней, чтобы они зафиксировались вместе с изменением данных.
+func (pg *Repository) SaveOutbox(ctx context.Context, tx pgx.Tx, events []*models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `
+	INSERT INTO outbox(id, type, link_id, user_id, short_url, original_url, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+
+	b := &pgx.Batch{}
+	for _, event := range events {
+		b.Queue(query, event.ID, event.Type, event.LinkID, event.UserID, event.ShortURL, event.OriginalURL,
+			event.CreatedAt)
+	}
+
+	var results pgx.BatchResults
+	if tx != nil {
+		results = tx.SendBatch(ctx, b)
+	} else {
+		results = pg.pool.SendBatch(ctx, b)
+	}
+
+	err := results.Close()
+	if err != nil {
+		return fmt.Errorf("save outbox in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// GetOutbox получает до limit неопубликованных событий из PostgreSQL базы данных в порядке записи.
+func (pg *Repository) GetOutbox(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	query := `
+	SELECT id, type, link_id, user_id, short_url, original_url, created_at
+	FROM outbox
+	ORDER BY seq
+	LIMIT $1;
+	`
+
+	rows, err := pg.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get outbox in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*models.OutboxEvent, 0)
+	for rows.Next() {
+		var event models.OutboxEvent
+		err = rows.Scan(&event.ID, &event.Type, &event.LinkID, &event.UserID, &event.ShortURL, &event.OriginalURL,
+			&event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("get outbox in pg storage error: %w", err)
+		}
+		event.CreatedAt = event.CreatedAt.UTC()
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+// DeleteOutbox удаляет опубликованные события из PostgreSQL базы данных.
+func (pg *Repository) DeleteOutbox(ctx context.Context, ids []uuid.UUID) error {
+	query := `
+	DELETE FROM outbox WHERE id = ANY($1);
+	`
+
+	_, err := pg.pool.Exec(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("delete outbox in pg storage error: %w", err)
+	}
+	return nil
+}
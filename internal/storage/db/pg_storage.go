@@ -2,8 +2,11 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/IvanKondrashkov/go-shortener/internal/config"
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
@@ -22,15 +25,19 @@ func (pg *Repository) BeginTx(ctx context.Context) (pgx.Tx, error) {
 // Возвращает UUID сохраненного URL или ошибку если операция не удалась.
 func (pg *Repository) Save(ctx context.Context, tx pgx.Tx, id uuid.UUID, u *url.URL) (uuid.UUID, error) {
 	query := `
-	INSERT INTO urls(short_url, original_url)
-	VALUES ($1, $2)
-	ON CONFLICT (short_url) DO UPDATE
-	SET
-	short_url = EXCLUDED.short_url,
-	original_url = EXCLUDED.original_url;
+	WITH saved AS (
+		INSERT INTO urls(short_url, original_url)
+		VALUES ($1, $2)
+		ON CONFLICT (short_url) DO UPDATE
+		SET
+		short_url = EXCLUDED.short_url,
+		original_url = EXCLUDED.original_url
+		RETURNING short_url
+	)
+	SELECT pg_notify($3, short_url::TEXT) FROM saved;
 	`
 
-	_, err := tx.Exec(ctx, query, id, u.String())
+	_, err := tx.Exec(ctx, query, id, u.String(), InvalidateChannel)
 	if err != nil {
 		return id, fmt.Errorf("save in pg storage error: %w", err)
 	}
@@ -41,80 +48,142 @@ func (pg *Repository) Save(ctx context.Context, tx pgx.Tx, id uuid.UUID, u *url.
 // Возвращает UUID сохраненного URL или ошибку если операция не удалась.
 func (pg *Repository) SaveUser(ctx context.Context, tx pgx.Tx, userID, id uuid.UUID, u *url.URL) (uuid.UUID, error) {
 	query := `
-	INSERT INTO urls(short_url, user_id, original_url)
-	VALUES ($1, $2, $3)
-	ON CONFLICT (short_url) DO UPDATE
-	SET
-	short_url = EXCLUDED.short_url,
-	user_id = EXCLUDED.user_id,
-	original_url = EXCLUDED.original_url;
+	WITH saved AS (
+		INSERT INTO urls(short_url, user_id, original_url)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (short_url) DO UPDATE
+		SET
+		short_url = EXCLUDED.short_url,
+		user_id = EXCLUDED.user_id,
+		original_url = EXCLUDED.original_url
+		RETURNING short_url
+	)
+	SELECT pg_notify($4, short_url::TEXT) FROM saved;
 	`
 
-	_, err := tx.Exec(ctx, query, id, userID, u.String())
+	_, err := tx.Exec(ctx, query, id, userID, u.String(), InvalidateChannel)
 	if err != nil {
 		return id, fmt.Errorf("save in pg storage error: %w", err)
 	}
 	return id, nil
 }
 
-// SaveBatch сохраняет несколько URL в PostgreSQL базе данных одной операцией.
-// Возвращает ErrBatchIsEmpty если batch пуст.
-func (pg *Repository) SaveBatch(ctx context.Context, batch []*models.RequestShortenAPIBatch) error {
-	if len(batch) == 0 {
-		return fmt.Errorf("save batch in pg storage error: %w", customError.ErrBatchIsEmpty)
-	}
-
-	valuesShortURL := make([]uuid.UUID, 0, len(batch))
-	valuesOriginalURL := make([]string, 0, len(batch))
-	for _, b := range batch {
-		valuesShortURL = append(valuesShortURL, uuid.NewSHA1(uuid.NameSpaceURL, []byte(b.OriginalURL)))
-		valuesOriginalURL = append(valuesOriginalURL, b.OriginalURL)
-	}
-
+// SaveLink сохраняет запись URL в PostgreSQL базе данных с сохранением времени создания.
+// Существующая запись перезаписывается, только если она была удалена: ссылка восстанавливается
+// и переходит к новому владельцу, время ее создания не изменяется.
+// Возвращает ErrConflict если запись с UUID уже существует и не удалена, или ошибку если операция не удалась.
+func (pg *Repository) SaveLink(ctx context.Context, tx pgx.Tx, link *models.Link) (uuid.UUID, error) {
 	query := `
-	INSERT INTO urls(short_url, original_url)
-	VALUES (UNNEST($1::UUID[]), UNNEST($2::VARCHAR[]))
-	ON CONFLICT (short_url) DO NOTHING;
+	WITH saved AS (
+		INSERT INTO urls(short_url, user_id, original_url, created_at, title, tags, note, folder_id, interstitial,
+		redirect_code, passthrough, rules, variants, active_from, active_until, fallback_url, password_hash)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6, '{}'), $7, $8, $9, $10, $11, COALESCE($12, '[]'), COALESCE($13, '[]'),
+		$14, $15, $16, $17)
+		ON CONFLICT (short_url) DO UPDATE
+		SET
+		user_id = COALESCE(EXCLUDED.user_id, urls.user_id),
+		original_url = EXCLUDED.original_url,
+		is_deleted = NULL,
+		title = EXCLUDED.title,
+		tags = EXCLUDED.tags,
+		note = EXCLUDED.note,
+		folder_id = EXCLUDED.folder_id,
+		interstitial = EXCLUDED.interstitial,
+		redirect_code = EXCLUDED.redirect_code,
+		passthrough = EXCLUDED.passthrough,
+		rules = EXCLUDED.rules,
+		variants = EXCLUDED.variants,
+		active_from = EXCLUDED.active_from,
+		active_until = EXCLUDED.active_until,
+		fallback_url = EXCLUDED.fallback_url,
+		password_hash = CASE WHEN EXCLUDED.password_hash = '' THEN urls.password_hash ELSE EXCLUDED.password_hash END
+		WHERE urls.is_deleted
+		RETURNING short_url
+	)
+	SELECT pg_notify($18, short_url::TEXT) FROM saved;
 	`
 
-	b := &pgx.Batch{}
-	b.Queue(query, valuesShortURL, valuesOriginalURL)
-
-	err := pg.pool.SendBatch(ctx, b).Close()
+	err := tx.QueryRow(ctx, query, link.ID, link.UserID, link.OriginalURL, link.CreatedAt,
+		link.Title, link.Tags, link.Note, link.FolderID, link.Interstitial,
+		link.RedirectCode, link.Passthrough, link.Rules, link.Variants, link.ActiveFrom, link.ActiveUntil, link.FallbackURL,
+		link.PasswordHash, InvalidateChannel).Scan(nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Запись с UUID сохранили между проверкой в сервисе и вставкой
+		return link.ID, fmt.Errorf("save in pg storage error: %w", customError.ErrConflict)
+	}
 	if err != nil {
-		return fmt.Errorf("save batch in pg storage error: %w", err)
+		return link.ID, fmt.Errorf("save in pg storage error: %w", err)
 	}
-	return nil
+	return link.ID, nil
+}
+
+// SaveBatch сохраняет несколько URL в PostgreSQL базе данных одной операцией.
+// Возвращает ErrBatchIsEmpty если batch пуст.
+func (pg *Repository) SaveBatch(ctx context.Context, tx pgx.Tx, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	return pg.saveBatch(ctx, tx, nil, batch)
 }
 
 // SaveBatchUser сохраняет несколько URL в PostgreSQL базе данных, ассоциированных с пользователем.
 // Возвращает ErrBatchIsEmpty если batch пуст.
-func (pg *Repository) SaveBatchUser(ctx context.Context, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) error {
-	if len(batch) == 0 {
-		return fmt.Errorf("save batch in pg storage error: %w", customError.ErrBatchIsEmpty)
-	}
+func (pg *Repository) SaveBatchUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	return pg.saveBatch(ctx, tx, &userID, batch)
+}
 
-	valuesShortURL := make([]uuid.UUID, 0, len(batch))
-	valuesOriginalURL := make([]string, 0, len(batch))
-	for _, b := range batch {
-		valuesShortURL = append(valuesShortURL, uuid.NewSHA1(uuid.NameSpaceURL, []byte(b.OriginalURL)))
-		valuesOriginalURL = append(valuesOriginalURL, b.OriginalURL)
+// saveBatch сохраняет несколько URL одним пакетом запросов, уже существующие URL не изменяются.
+// Вставленный URL возвращает строку из RETURNING, поэтому созданные URL отличаются от существующих по результату запроса.
+// При заданной транзакции пакет выполняется в ней, иначе на соединении из пула.
+func (pg *Repository) saveBatch(ctx context.Context, tx pgx.Tx, userID *uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("save batch in pg storage error: %w", customError.ErrBatchIsEmpty)
 	}
 
 	query := `
-	INSERT INTO urls(short_url, user_id, original_url)
-	VALUES (UNNEST($1::UUID[]), $2, UNNEST($3::VARCHAR[]))
-	ON CONFLICT (short_url) DO NOTHING;
+	WITH saved AS (
+		INSERT INTO urls(short_url, user_id, original_url, title, tags, note, folder_id, interstitial, redirect_code,
+		passthrough, rules, variants, active_from, active_until, fallback_url, password_hash)
+		VALUES ($1, $2, $3, $4, COALESCE($5, '{}'), $6, $7, $8, $9, $10, COALESCE($11, '[]'), COALESCE($12, '[]'),
+		$13, $14, $15, $16)
+		ON CONFLICT (short_url) DO NOTHING
+		RETURNING short_url
+	)
+	SELECT pg_notify($17, short_url::TEXT) FROM saved;
 	`
 
 	b := &pgx.Batch{}
-	b.Queue(query, valuesShortURL, userID, valuesOriginalURL)
+	for _, item := range batch {
+		b.Queue(query, item.LinkID(), userID, item.OriginalURL,
+			item.Title, item.Tags, item.Note, item.FolderID, item.Interstitial, item.RedirectCode, item.Passthrough, item.Rules,
+			item.Variants, item.ActiveFrom, item.ActiveUntil, item.FallbackURL, item.PasswordHash, InvalidateChannel)
+	}
+
+	var results pgx.BatchResults
+	if tx != nil {
+		results = tx.SendBatch(ctx, b)
+	} else {
+		results = pg.pool.SendBatch(ctx, b)
+	}
 
-	err := pg.pool.SendBatch(ctx, b).Close()
+	defer results.Close()
+
+	created := make([]bool, len(batch))
+	for i := range batch {
+		rows, err := results.Query()
+		if err != nil {
+			return nil, fmt.Errorf("save batch in pg storage error: %w", err)
+		}
+		created[i] = rows.Next()
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("save batch in pg storage error: %w", err)
+		}
+	}
+
+	err := results.Close()
 	if err != nil {
-		return fmt.Errorf("save batch in pg storage error: %w", err)
+		return nil, fmt.Errorf("save batch in pg storage error: %w", err)
 	}
-	return nil
+	return created, nil
 }
 
 // GetByID получает URL из PostgreSQL базы данных по его UUID ключу.
@@ -129,9 +198,12 @@ func (pg *Repository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, erro
 	var isDeleted *bool
 	var originalURL string
 	err := pg.pool.QueryRow(ctx, query, id).Scan(&originalURL, &isDeleted)
-	if err != nil {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("get in pg storage error: %w", customError.ErrNotFound)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("get in pg storage error: %w", err)
+	}
 
 	u, err := url.Parse(originalURL)
 	if err != nil {
@@ -144,60 +216,458 @@ func (pg *Repository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, erro
 	return u, nil
 }
 
-// GetAllByUserID получает все URL, ассоциированные с пользователем, из PostgreSQL базы данных.
-// Возвращает срез URL или ошибку если запрос не удался.
-func (pg *Repository) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.ResponseShortenAPIUser, error) {
+// GetLinkByID получает запись URL с атрибутами и счетчиком переходов из PostgreSQL базы данных.
+// Возвращает ErrNotFound если ключ не существует или ErrDeleteAccepted если URL был удален.
+func (pg *Repository) GetLinkByID(ctx context.Context, id uuid.UUID) (*models.Link, error) {
+	query := `
+	SELECT short_url, user_id, original_url, created_at, COALESCE(is_deleted, false), clicks,
+	title, tags, note, folder_id, interstitial, redirect_code, passthrough, rules, variants, variant_clicks,
+	active_from, active_until, fallback_url, schedule_state, disabled_reason, page, password_hash
+	FROM urls
+	WHERE short_url = $1;
+	`
+
+	var link models.Link
+	err := pg.pool.QueryRow(ctx, query, id).Scan(&link.ID, &link.UserID, &link.OriginalURL, &link.CreatedAt,
+		&link.IsDeleted, &link.Clicks, &link.Title, &link.Tags, &link.Note, &link.FolderID, &link.Interstitial,
+		&link.RedirectCode, &link.Passthrough, &link.Rules, &link.Variants, &link.VariantClicks,
+		&link.ActiveFrom, &link.ActiveUntil, &link.FallbackURL, &link.ScheduleState, &link.Disabled, &link.Page,
+		&link.PasswordHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get link in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get link in pg storage error: %w", err)
+	}
+
+	if link.IsDeleted {
+		return nil, fmt.Errorf("get link in pg storage error: %w", customError.ErrDeleteAccepted)
+	}
+	linkToUTC(&link)
+	return &link, nil
+}
+
+// GetLinksByIDs получает записи URL по нескольким UUID ключам из PostgreSQL базы данных одним запросом.
+// Удаленные записи возвращаются с IsDeleted, отсутствующих ключей нет в результате.
+func (pg *Repository) GetLinksByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Link, error) {
 	query := `
-	SELECT short_url, original_url
+	SELECT short_url, user_id, original_url, created_at, COALESCE(is_deleted, false), clicks,
+	title, tags, note, folder_id, interstitial, redirect_code, passthrough, rules, variants, variant_clicks,
+	active_from, active_until, fallback_url, schedule_state, disabled_reason, page, password_hash
 	FROM urls
-	WHERE user_id = $1;
+	WHERE short_url = ANY($1);
 	`
 
-	rows, err := pg.pool.Query(ctx, query, userID)
+	rows, err := pg.pool.Query(ctx, query, ids)
 	if err != nil {
-		return nil, fmt.Errorf("get all in pg storage error: %w", err)
+		return nil, fmt.Errorf("get links in pg storage error: %w", err)
 	}
 	defer rows.Close()
 
-	var urls []*models.ResponseShortenAPIUser
+	res := make(map[uuid.UUID]*models.Link, len(ids))
+	for rows.Next() {
+		var link models.Link
+		err := rows.Scan(&link.ID, &link.UserID, &link.OriginalURL, &link.CreatedAt,
+			&link.IsDeleted, &link.Clicks, &link.Title, &link.Tags, &link.Note, &link.FolderID, &link.Interstitial,
+			&link.RedirectCode, &link.Passthrough, &link.Rules, &link.Variants, &link.VariantClicks,
+			&link.ActiveFrom, &link.ActiveUntil, &link.FallbackURL, &link.ScheduleState, &link.Disabled, &link.Page,
+			&link.PasswordHash)
+		if err != nil {
+			return nil, fmt.Errorf("get links in pg storage error: %w", err)
+		}
+
+		linkToUTC(&link)
+		res[link.ID] = &link
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get links in pg storage error: %w", err)
+	}
+	return res, nil
+}
+
+// AddClick увеличивает счетчик переходов по URL и по адресу варианта в PostgreSQL базе данных.
+// Возвращает новое количество переходов или ErrNotFound если ключ не существует.
+func (pg *Repository) AddClick(ctx context.Context, id uuid.UUID, variant string) (int64, error) {
+	query := `
+	UPDATE urls
+	SET
+	clicks = clicks + 1,
+	variant_clicks = CASE WHEN $2 = '' THEN variant_clicks
+		ELSE jsonb_set(variant_clicks, ARRAY[$2::TEXT], to_jsonb(COALESCE((variant_clicks->>$2)::BIGINT, 0) + 1)) END
+	WHERE short_url = $1
+	RETURNING clicks;
+	`
+
+	var clicks int64
+	err := pg.pool.QueryRow(ctx, query, id, variant).Scan(&clicks)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("add click in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("add click in pg storage error: %w", err)
+	}
+	return clicks, nil
+}
+
+// DisableByID задает причину отключения URL в PostgreSQL базе данных, пустая причина включает URL.
+// Уведомляет об изменении через канал InvalidateChannel. Возвращает ErrNotFound если ключ не существует.
+func (pg *Repository) DisableByID(ctx context.Context, id uuid.UUID, reason string) error {
+	query := `
+	WITH changed AS (
+		UPDATE urls
+		SET disabled_reason = $2
+		WHERE short_url = $1
+		RETURNING short_url
+	)
+	SELECT pg_notify($3, short_url::TEXT) FROM changed;
+	`
+
+	err := pg.pool.QueryRow(ctx, query, id, reason, InvalidateChannel).Scan(nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("disable in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("disable in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// GetLinksToCheck получает записи URL для проверки доступности из PostgreSQL базы данных.
+// Возвращает не более limit URL, не проверявшихся или проверенных до before, сначала непроверенные,
+// затем по времени проверки. Удаленные и отключенные URL не возвращаются.
+func (pg *Repository) GetLinksToCheck(ctx context.Context, before time.Time, limit int) ([]*models.Link, error) {
+	query := `
+	SELECT short_url, user_id, original_url, created_at, health
+	FROM urls
+	WHERE is_deleted IS NOT TRUE AND disabled_reason = '' AND (checked_at IS NULL OR checked_at < $1)
+	ORDER BY checked_at NULLS FIRST, short_url
+	LIMIT $2;
+	`
+
+	rows, err := pg.pool.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get links to check in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.Link
+	for rows.Next() {
+		link := &models.Link{}
+		err = rows.Scan(&link.ID, &link.UserID, &link.OriginalURL, &link.CreatedAt, &link.Health)
+		if err != nil {
+			return res, fmt.Errorf("get links to check in pg storage error: %w", err)
+		}
+		linkToUTC(link)
+		res = append(res, link)
+	}
+	return res, rows.Err()
+}
+
+// UpdateHealth сохраняет результат проверки доступности URL в PostgreSQL базе данных.
+// Уведомляет об изменении через канал InvalidateChannel.
+// Возвращает ErrNotFound если ключ не существует.
+func (pg *Repository) UpdateHealth(ctx context.Context, id uuid.UUID, health *models.LinkHealth) error {
+	query := `
+	WITH changed AS (
+		UPDATE urls
+		SET health = $2, checked_at = $3
+		WHERE short_url = $1
+		RETURNING short_url
+	)
+	SELECT pg_notify($4, short_url::TEXT) FROM changed;
+	`
+
+	err := pg.pool.QueryRow(ctx, query, id, health, health.CheckedAt, InvalidateChannel).Scan(nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("update health in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("update health in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// UpdatePageMeta сохраняет метаданные страницы назначения URL в PostgreSQL базе данных.
+// Уведомляет об изменении через канал InvalidateChannel. Возвращает ErrNotFound если ключ не существует.
+func (pg *Repository) UpdatePageMeta(ctx context.Context, id uuid.UUID, page *models.PageMeta) error {
+	query := `
+	WITH changed AS (
+		UPDATE urls
+		SET page = $2
+		WHERE short_url = $1
+		RETURNING short_url
+	)
+	SELECT pg_notify($3, short_url::TEXT) FROM changed;
+	`
+
+	err := pg.pool.QueryRow(ctx, query, id, page, InvalidateChannel).Scan(nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("update page meta in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("update page meta in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// UpdateScheduleStates фиксирует состояния окон активности URL в PostgreSQL базе данных на момент now.
+// Уведомляет об изменившихся URL через канал InvalidateChannel и возвращает события, упорядоченные по UUID.
+func (pg *Repository) UpdateScheduleStates(ctx context.Context, now time.Time) ([]*models.ScheduleEvent, error) {
+	query := `
+	WITH next AS (
+		SELECT short_url,
+		CASE
+			WHEN active_from IS NOT NULL AND $1 < active_from THEN 'pending'
+			WHEN active_until IS NOT NULL AND $1 >= active_until THEN 'expired'
+			WHEN active_from IS NOT NULL OR active_until IS NOT NULL THEN 'active'
+			ELSE ''
+		END AS state
+		FROM urls
+		WHERE COALESCE(is_deleted, false) = false
+		AND (active_from IS NOT NULL OR active_until IS NOT NULL OR schedule_state <> '')
+	), changed AS (
+		UPDATE urls
+		SET schedule_state = next.state
+		FROM next
+		WHERE urls.short_url = next.short_url AND urls.schedule_state <> next.state
+		RETURNING urls.short_url, urls.user_id, urls.schedule_state
+	)
+	SELECT short_url, user_id, schedule_state, pg_notify($2, short_url::TEXT)
+	FROM changed
+	WHERE schedule_state <> ''
+	ORDER BY short_url;
+	`
+
+	rows, err := pg.pool.Query(ctx, query, now, InvalidateChannel)
+	if err != nil {
+		return nil, fmt.Errorf("update schedule states in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	var res []*models.ScheduleEvent
 	for rows.Next() {
-		var u models.ResponseShortenAPIUser
-		if err = rows.Scan(&u.ShortURL, &u.OriginalURL); err != nil {
-			return urls, fmt.Errorf("get all in pg storage error: %w", err)
+		event := &models.ScheduleEvent{ChangedAt: now.UTC()}
+		err = rows.Scan(&event.ID, &event.UserID, &event.State, nil)
+		if err != nil {
+			return res, fmt.Errorf("update schedule states in pg storage error: %w", err)
 		}
-		u.ShortURL = config.URL + u.ShortURL
-		urls = append(urls, &u)
+		res = append(res, event)
+	}
+	return res, rows.Err()
+}
+
+// GetAllByUserID получает страницу URL, ассоциированных с пользователем, из PostgreSQL базы данных.
+// URL упорядочены по времени создания и UUID, отфильтрованы согласно filter.
+// Возвращает срез URL или ошибку если запрос не удался.
+func (pg *Repository) GetAllByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs) ([]*models.ResponseShortenAPIUser, error) {
+	var urls []*models.ResponseShortenAPIUser
+	err := pg.eachByUserID(ctx, userID, filter, func(u *models.ResponseShortenAPIUser) error {
+		urls = append(urls, u)
+		return nil
+	})
+	if err != nil {
+		return urls, fmt.Errorf("get all in pg storage error: %w", err)
 	}
 	return urls, nil
 }
 
+// ExportByUserID вызывает fn для каждого URL пользователя из PostgreSQL базы данных по мере чтения строк результата,
+// поэтому URL не накапливаются в памяти. Соединение из пула занято до окончания обхода.
+// Возвращает ошибку запроса или первую ошибку fn, после которой обход прекращается.
+func (pg *Repository) ExportByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error {
+	if err := pg.eachByUserID(ctx, userID, filter, fn); err != nil {
+		return fmt.Errorf("export in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// eachByUserID выполняет выборку URL пользователя согласно filter и вызывает fn для каждой строки
+func (pg *Repository) eachByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error {
+	where, args := filterURLs(userID, filter)
+
+	order := "ASC"
+	if filter.Desc {
+		order = "DESC"
+	}
+
+	query := `
+	SELECT short_url, original_url, created_at, COALESCE(is_deleted, false), clicks, title, tags, note, folder_id, interstitial,
+	redirect_code, passthrough, rules, variants, variant_clicks, active_from, active_until, fallback_url, schedule_state,
+	disabled_reason, health, page, password_hash
+	FROM urls
+	WHERE ` + where + `
+	ORDER BY created_at ` + order + `, short_url ` + order
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := pg.pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link models.Link
+		err = rows.Scan(&link.ID, &link.OriginalURL, &link.CreatedAt, &link.IsDeleted, &link.Clicks,
+			&link.Title, &link.Tags, &link.Note, &link.FolderID, &link.Interstitial, &link.RedirectCode,
+			&link.Passthrough, &link.Rules, &link.Variants, &link.VariantClicks, &link.ActiveFrom, &link.ActiveUntil,
+			&link.FallbackURL, &link.ScheduleState, &link.Disabled, &link.Health, &link.Page,
+			&link.PasswordHash)
+		if err != nil {
+			return err
+		}
+		linkToUTC(&link)
+		if err = fn(models.LinkToResponseUser(&link)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // DeleteBatchByUserID помечает несколько URL как удаленные для пользователя в PostgreSQL базе данных.
+// При заданной транзакции URL помечаются в ней, иначе на соединении из пула.
+// Возвращает UUID помеченных URL: чужие, уже удаленные и несуществующие UUID пропускаются.
 // Возвращает ErrBatchIsEmpty если batch пуст или ошибку если операция не удалась.
-func (pg *Repository) DeleteBatchByUserID(ctx context.Context, userID uuid.UUID, batch []uuid.UUID) error {
+func (pg *Repository) DeleteBatchByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []uuid.UUID) ([]uuid.UUID, error) {
 	if len(batch) == 0 {
-		return fmt.Errorf("delete batch in pg storage error: %w", customError.ErrBatchIsEmpty)
+		return nil, fmt.Errorf("delete batch in pg storage error: %w", customError.ErrBatchIsEmpty)
 	}
 
 	valuesShortURL := make([]uuid.UUID, 0, len(batch))
 	valuesShortURL = append(valuesShortURL, batch...)
 
 	query := `
-	UPDATE urls SET is_deleted = true WHERE short_url = ANY($1) AND user_id = $2;
+	WITH deleted AS (
+		UPDATE urls SET is_deleted = true
+		WHERE short_url = ANY($1) AND user_id = $2 AND COALESCE(is_deleted, false) = false
+		RETURNING short_url
+	)
+	SELECT short_url, pg_notify($3, short_url::TEXT) FROM deleted;
+	`
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx, query, valuesShortURL, userID, InvalidateChannel)
+	} else {
+		rows, err = pg.pool.Query(ctx, query, valuesShortURL, userID, InvalidateChannel)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("delete batch in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	deleted := make([]uuid.UUID, 0, len(batch))
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id, nil)
+		if err != nil {
+			return deleted, fmt.Errorf("delete batch in pg storage error: %w", err)
+		}
+		deleted = append(deleted, id)
+	}
+	if err = rows.Err(); err != nil {
+		return deleted, fmt.Errorf("delete batch in pg storage error: %w", err)
+	}
+	return deleted, nil
+}
+
+// UpdateByUserID изменяет оригинальный URL пользователя в PostgreSQL базе данных
+// и записывает изменение в таблицу истории в рамках переданной транзакции.
+// Заполняет change.OldURL предыдущим значением и сбрасывает результат проверки доступности и метаданные страницы.
+// Возвращает ErrNotFound если URL не принадлежит пользователю или ErrDeleteAccepted если URL был удален.
+func (pg *Repository) UpdateByUserID(ctx context.Context, tx pgx.Tx, change *models.URLHistory) error {
+	query := `
+	SELECT original_url, is_deleted
+	FROM urls
+	WHERE short_url = $1 AND user_id = $2
+	FOR UPDATE;
 	`
 
-	conn, err := pg.pool.Acquire(ctx)
+	var isDeleted *bool
+	err := tx.QueryRow(ctx, query, change.ID, change.UserID).Scan(&change.OldURL, &isDeleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("update in pg storage error: %w", customError.ErrNotFound)
+	}
 	if err != nil {
-		return fmt.Errorf("delete batch in pg storage error: %w", err)
+		return fmt.Errorf("update in pg storage error: %w", err)
 	}
-	defer conn.Release()
 
-	_, err = conn.Exec(ctx, query, valuesShortURL, userID)
+	if isDeleted != nil && *isDeleted {
+		return fmt.Errorf("update in pg storage error: %w", customError.ErrDeleteAccepted)
+	}
+
+	query = `
+	WITH updated AS (
+		UPDATE urls SET original_url = $3, health = NULL, checked_at = NULL, page = NULL
+		WHERE short_url = $1 AND user_id = $2
+		RETURNING short_url
+	), history AS (
+		INSERT INTO url_history(short_url, user_id, old_url, new_url, changed_at)
+		VALUES ($1, $2, $4, $3, $5)
+	)
+	SELECT pg_notify($6, short_url::TEXT) FROM updated;
+	`
+
+	_, err = tx.Exec(ctx, query, change.ID, change.UserID, change.NewURL, change.OldURL, change.ChangedAt, InvalidateChannel)
 	if err != nil {
-		return fmt.Errorf("delete batch in pg storage error: %w", err)
+		return fmt.Errorf("update in pg storage error: %w", err)
 	}
 	return nil
 }
 
+// GetHistoryByUserID получает историю изменений URL пользователя из PostgreSQL базы данных.
+// Возвращает ErrNotFound если URL не принадлежит пользователю.
+func (pg *Repository) GetHistoryByUserID(ctx context.Context, userID, id uuid.UUID) ([]*models.URLHistory, error) {
+	query := `
+	SELECT h.user_id, h.old_url, h.new_url, h.changed_at
+	FROM urls u
+	LEFT JOIN url_history h ON h.short_url = u.short_url
+	WHERE u.short_url = $1 AND u.user_id = $2
+	ORDER BY h.changed_at, h.id;
+	`
+
+	rows, err := pg.pool.Query(ctx, query, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get history in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	found := false
+	history := make([]*models.URLHistory, 0)
+	for rows.Next() {
+		found = true
+		var changeUserID *uuid.UUID
+		var oldURL, newURL *string
+		var changedAt *time.Time
+		if err = rows.Scan(&changeUserID, &oldURL, &newURL, &changedAt); err != nil {
+			return nil, fmt.Errorf("get history in pg storage error: %w", err)
+		}
+		if changeUserID == nil {
+			continue
+		}
+		history = append(history, &models.URLHistory{
+			ID:        id,
+			UserID:    *changeUserID,
+			OldURL:    *oldURL,
+			NewURL:    *newURL,
+			ChangedAt: changedAt.UTC(),
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("get history in pg storage error: %w", err)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("get history in pg storage error: %w", customError.ErrNotFound)
+	}
+	return history, nil
+}
+
 // Ping проверяет соединение с базой данных.
 // Возвращает ошибку если соединение не может быть установлено.
 func (pg *Repository) Ping(ctx context.Context) error {
@@ -211,3 +681,66 @@ func (pg *Repository) Ping(ctx context.Context) error {
 func (pg *Repository) Close() {
 	pg.pool.Close()
 }
+
+// filterURLs строит условие WHERE и его аргументы для выборки URL пользователя.
+func filterURLs(userID uuid.UUID, filter *models.FilterURLs) (string, []any) {
+	args := []any{userID}
+	conditions := []string{"user_id = $1"}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !filter.WithDeleted {
+		conditions = append(conditions, "is_deleted IS NOT TRUE")
+	}
+
+	if c := filter.Cursor; c != nil {
+		op := ">"
+		if filter.Desc {
+			op = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("(created_at, short_url) %s (%s, %s)", op, arg(c.CreatedAt), arg(c.ID)))
+	}
+
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedFrom))
+	}
+
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.CreatedTo))
+	}
+
+	if filter.Search != "" {
+		conditions = append(conditions, "original_url ILIKE '%' || "+arg(escapeLike(filter.Search))+" || '%'")
+	}
+
+	if filter.Tag != "" {
+		conditions = append(conditions, arg(filter.Tag)+" = ANY(tags)")
+	}
+
+	if filter.FolderID != nil {
+		conditions = append(conditions, "folder_id = "+arg(*filter.FolderID))
+	}
+
+	if filter.Domain != "" {
+		conditions = append(conditions, "LOWER(SUBSTRING(original_url FROM '^[^:/]+://(?:[^@/]*@)?([^/:?#]+)')) = LOWER("+arg(filter.Domain)+")")
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// escapeLike экранирует специальные символы шаблона LIKE.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// linkToUTC приводит время создания и границы окна активности записи URL к UTC.
+func linkToUTC(link *models.Link) {
+	link.CreatedAt = link.CreatedAt.UTC()
+	for _, t := range []**time.Time{&link.ActiveFrom, &link.ActiveUntil} {
+		if *t != nil {
+			utc := (*t).UTC()
+			*t = &utc
+		}
+	}
+}
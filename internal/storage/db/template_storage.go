@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveTemplate сохраняет шаблон UTM разметки пользователя в PostgreSQL базе данных.
+// Возвращает ErrConflict если у пользователя уже есть шаблон с таким названием.
+func (pg *Repository) SaveTemplate(ctx context.Context, template *models.UTMTemplate) error {
+	query := `
+	INSERT INTO utm_templates(id, user_id, name, source, medium, campaign, content, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+
+	_, err := pg.pool.Exec(ctx, query, template.ID, template.UserID, template.Name, template.Source,
+		template.Medium, template.Campaign, template.Content, template.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save template in pg storage error: %w", uniqueError(err))
+	}
+	return nil
+}
+
+// GetTemplatesByUserID получает все шаблоны UTM разметки пользователя из PostgreSQL базы данных в порядке создания.
+func (pg *Repository) GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UTMTemplate, error) {
+	query := `
+	SELECT id, user_id, name, source, medium, campaign, content, created_at
+	FROM utm_templates
+	WHERE user_id = $1
+	ORDER BY created_at, id;
+	`
+
+	rows, err := pg.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get templates in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]*models.UTMTemplate, 0)
+	for rows.Next() {
+		var template models.UTMTemplate
+		err = rows.Scan(&template.ID, &template.UserID, &template.Name, &template.Source, &template.Medium,
+			&template.Campaign, &template.Content, &template.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("get templates in pg storage error: %w", err)
+		}
+		template.CreatedAt = template.CreatedAt.UTC()
+		templates = append(templates, &template)
+	}
+	return templates, rows.Err()
+}
+
+// UpdateTemplateByUserID изменяет шаблон UTM разметки пользователя в PostgreSQL базе данных.
+// Возвращает ErrNotFound если шаблон не существует или ErrConflict если название занято.
+func (pg *Repository) UpdateTemplateByUserID(ctx context.Context, template *models.UTMTemplate) error {
+	query := `
+	UPDATE utm_templates SET name = $3, source = $4, medium = $5, campaign = $6, content = $7
+	WHERE id = $1 AND user_id = $2
+	RETURNING created_at;
+	`
+
+	err := pg.pool.QueryRow(ctx, query, template.ID, template.UserID, template.Name, template.Source,
+		template.Medium, template.Campaign, template.Content).Scan(&template.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("update template in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("update template in pg storage error: %w", uniqueError(err))
+	}
+	template.CreatedAt = template.CreatedAt.UTC()
+	return nil
+}
+
+// DeleteTemplateByUserID удаляет шаблон UTM разметки пользователя из PostgreSQL базы данных.
+// Возвращает ErrNotFound если шаблон не существует или не принадлежит пользователю.
+func (pg *Repository) DeleteTemplateByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+	DELETE FROM utm_templates WHERE id = $1 AND user_id = $2;
+	`
+
+	tag, err := pg.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete template in pg storage error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("delete template in pg storage error: %w", customError.ErrNotFound)
+	}
+	return nil
+}
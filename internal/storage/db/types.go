@@ -16,6 +16,18 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
+// InvalidateChannel канал PostgreSQL уведомлений об изменении сокращенных URL.
+// Полезная нагрузка уведомления - UUID измененного URL.
+const (
+	InvalidateChannel = "urls_invalidate"
+)
+
+// Коды ошибок PostgreSQL при нарушении ограничений уникальности и внешнего ключа
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
 // Repository реализует PostgreSQL хранилище для сервиса сокращения URL.
 type Repository struct {
 	service.Runner
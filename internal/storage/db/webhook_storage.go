@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveWebhook сохраняет webhook пользователя в PostgreSQL базе данных.
+func (pg *Repository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+	INSERT INTO webhooks(id, user_id, url, events, secret, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6);
+	`
+
+	_, err := pg.pool.Exec(ctx, query, webhook.ID, webhook.UserID, webhook.URL, webhook.Events,
+		webhook.Secret, webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save webhook in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// GetWebhooksByUserID получает все webhook пользователя из PostgreSQL базы данных в порядке создания.
+func (pg *Repository) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	query := `
+	SELECT id, user_id, url, events, secret, previous_secret, rotated_at, created_at
+	FROM webhooks
+	WHERE user_id = $1
+	ORDER BY created_at, id;
+	`
+
+	rows, err := pg.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get webhooks in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := make([]*models.Webhook, 0)
+	for rows.Next() {
+		var webhook models.Webhook
+		err = rows.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Events, &webhook.Secret,
+			&webhook.PreviousSecret, &webhook.RotatedAt, &webhook.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("get webhooks in pg storage error: %w", err)
+		}
+		webhook.CreatedAt = webhook.CreatedAt.UTC()
+		if webhook.RotatedAt != nil {
+			rotatedAt := webhook.RotatedAt.UTC()
+			webhook.RotatedAt = &rotatedAt
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// UpdateWebhookByUserID сохраняет секреты webhook пользователя после ротации в PostgreSQL базе данных.
+// Возвращает ErrNotFound если webhook не существует или не принадлежит пользователю.
+func (pg *Repository) UpdateWebhookByUserID(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+	UPDATE webhooks SET secret = $3, previous_secret = $4, rotated_at = $5
+	WHERE id = $1 AND user_id = $2
+	RETURNING url, events, created_at;
+	`
+
+	err := pg.pool.QueryRow(ctx, query, webhook.ID, webhook.UserID, webhook.Secret, webhook.PreviousSecret,
+		webhook.RotatedAt).Scan(&webhook.URL, &webhook.Events, &webhook.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("update webhook in pg storage error: %w", customError.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("update webhook in pg storage error: %w", err)
+	}
+	webhook.CreatedAt = webhook.CreatedAt.UTC()
+	return nil
+}
+
+// DeleteWebhookByUserID удаляет webhook пользователя и его журнал доставок из PostgreSQL базы данных.
+// Возвращает ErrNotFound если webhook не существует или не принадлежит пользователю.
+func (pg *Repository) DeleteWebhookByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+	DELETE FROM webhooks WHERE id = $1 AND user_id = $2;
+	`
+
+	tag, err := pg.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete webhook in pg storage error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("delete webhook in pg storage error: %w", customError.ErrNotFound)
+	}
+	return nil
+}
+
+// SaveWebhookDelivery записывает попытку доставки в журнал webhook в PostgreSQL базе данных.
+// Попытки доставки удаленного webhook не записываются.
+func (pg *Repository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+	INSERT INTO webhook_deliveries(id, webhook_id, event_id, event_type, attempt, status, status_code, error,
+		duration_ms, created_at)
+	SELECT $1, id, $3, $4, $5, $6, $7, $8, $9, $10 FROM webhooks WHERE id = $2;
+	`
+
+	_, err := pg.pool.Exec(ctx, query, delivery.ID, delivery.WebhookID, delivery.EventID, delivery.EventType,
+		delivery.Attempt, delivery.Status, delivery.StatusCode, delivery.Error, delivery.DurationMs, delivery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save webhook delivery in pg storage error: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries получает до limit последних попыток доставки webhook пользователя из PostgreSQL базы данных,
+// начиная с новых. Возвращает ErrNotFound если webhook не существует или не принадлежит пользователю.
+func (pg *Repository) GetWebhookDeliveries(ctx context.Context, userID, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	var exists bool
+	err := pg.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM webhooks WHERE id = $1 AND user_id = $2);`,
+		id, userID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook deliveries in pg storage error: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("get webhook deliveries in pg storage error: %w", customError.ErrNotFound)
+	}
+
+	query := `
+	SELECT id, webhook_id, event_id, event_type, attempt, status, status_code, error, duration_ms, created_at
+	FROM webhook_deliveries
+	WHERE webhook_id = $1
+	ORDER BY created_at DESC, id
+	LIMIT $2;
+	`
+
+	rows, err := pg.pool.Query(ctx, query, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook deliveries in pg storage error: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err = rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Attempt, &d.Status, &d.StatusCode,
+			&d.Error, &d.DurationMs, &d.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("get webhook deliveries in pg storage error: %w", err)
+		}
+		d.CreatedAt = d.CreatedAt.UTC()
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
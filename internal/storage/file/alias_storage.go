@@ -0,0 +1,61 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveAlias сохраняет короткое имя ссылки в in-memory хранилище и записывает событие в файловое хранилище.
+func (f *Repository) SaveAlias(ctx context.Context, tx pgx.Tx, alias *models.Alias) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.SaveAlias(ctx, tx, alias)
+	if err != nil {
+		return fmt.Errorf("save alias in mem storage error: %w", err)
+	}
+
+	var encoder = f.encoder(tx)
+	event := &models.Event{
+		Type:      models.EventTypeAlias,
+		ShortURL:  alias.LinkID.String(),
+		Name:      alias.Slug,
+		CreatedAt: alias.CreatedAt,
+	}
+
+	err = encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+	return nil
+}
+
+// GetIDBySlug получает UUID ссылки по короткому имени из in-memory хранилища.
+func (f *Repository) GetIDBySlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	return f.repository.GetIDBySlug(ctx, slug)
+}
+
+// GetIDsBySlugs получает UUID ссылок по нескольким коротким именам из in-memory хранилища.
+func (f *Repository) GetIDsBySlugs(ctx context.Context, slugs []string) (map[string]uuid.UUID, error) {
+	return f.repository.GetIDsBySlugs(ctx, slugs)
+}
+
+// replayAlias применяет событие короткого имени к in-memory хранилищу.
+func (f *Repository) replayAlias(ctx context.Context, event *models.Event) error {
+	id, err := uuid.Parse(event.ShortURL)
+	if err != nil {
+		return fmt.Errorf("deserialize error: %w", err)
+	}
+
+	err = f.repository.SaveAlias(ctx, nil, &models.Alias{Slug: event.Name, LinkID: id, CreatedAt: event.CreatedAt})
+	if err != nil {
+		return fmt.Errorf("replay alias in mem storage error: %w", err)
+	}
+	return nil
+}
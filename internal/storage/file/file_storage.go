@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"time"
 
 	"github.com/IvanKondrashkov/go-shortener/internal/config"
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
@@ -15,10 +16,10 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-// BeginTx начинает новую транзакцию (заглушка для файлового хранилища).
-// Возвращает nil транзакцию и nil ошибку, так как файловое хранилище не поддерживает транзакции.
+// BeginTx начинает новую транзакцию файлового хранилища.
+// События, записанные в транзакции, дописываются в файл одной операцией записи при Commit.
 func (f *Repository) BeginTx(ctx context.Context) (pgx.Tx, error) {
-	return nil, nil
+	return newTx(f.producer.file), nil
 }
 
 // Save сохраняет URL в файловое хранилище и in-memory хранилище.
@@ -27,28 +28,11 @@ func (f *Repository) Save(ctx context.Context, tx pgx.Tx, id uuid.UUID, u *url.U
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	var encoder = f.producer.encoder
-	event := &models.Event{
+	return f.SaveLink(ctx, tx, &models.Link{
 		ID:          id,
-		ShortURL:    id.String(),
 		OriginalURL: u.String(),
-	}
-
-	err := encoder.Encode(&event)
-	if err != nil {
-		return id, fmt.Errorf("serialize error: %w", err)
-	}
-
-	u, err = url.Parse(event.OriginalURL)
-	if err != nil {
-		return id, fmt.Errorf("save in mem storage error: %w", customError.ErrURLNotValid)
-	}
-
-	_, err = f.repository.Save(ctx, tx, event.ID, u)
-	if err != nil {
-		return id, fmt.Errorf("save in mem storage error: %w", err)
-	}
-	return id, nil
+		CreatedAt:   time.Now().UTC(),
+	})
 }
 
 // SaveUser сохраняет URL в файловое хранилище, ассоциированный с пользователем.
@@ -57,105 +41,316 @@ func (f *Repository) SaveUser(ctx context.Context, tx pgx.Tx, userID, id uuid.UU
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	var encoder = f.producer.encoder
-	event := &models.Event{
-		ID:          userID,
-		ShortURL:    id.String(),
+	return f.SaveLink(ctx, tx, &models.Link{
+		ID:          id,
+		UserID:      &userID,
 		OriginalURL: u.String(),
+		CreatedAt:   time.Now().UTC(),
+	})
+}
+
+// SaveLink сохраняет запись URL в файловое хранилище и in-memory хранилище.
+// Для анонимных URL в событие записывается UUID сокращенного URL вместо UUID пользователя.
+// Возвращает UUID сохраненного URL или ошибку если сериализация не удалась.
+func (f *Repository) SaveLink(ctx context.Context, tx pgx.Tx, link *models.Link) (uuid.UUID, error) {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	var encoder = f.encoder(tx)
+	event := &models.Event{
+		ID:           link.ID,
+		ShortURL:     link.ID.String(),
+		OriginalURL:  link.OriginalURL,
+		CreatedAt:    link.CreatedAt,
+		LinkMeta:     link.LinkMeta,
+		PasswordHash: link.PasswordHash,
+	}
+	if link.UserID != nil {
+		event.ID = *link.UserID
 	}
 
 	err := encoder.Encode(&event)
 	if err != nil {
-		return id, fmt.Errorf("serialize error: %w", err)
+		return link.ID, fmt.Errorf("serialize error: %w", err)
 	}
 
-	u, err = url.Parse(event.OriginalURL)
+	_, err = f.repository.SaveLink(ctx, tx, link)
 	if err != nil {
-		return id, fmt.Errorf("save in mem storage error: %w", customError.ErrURLNotValid)
+		return link.ID, fmt.Errorf("save in mem storage error: %w", err)
 	}
+	return link.ID, nil
+}
 
-	_, err = f.repository.SaveUser(ctx, tx, event.ID, uuid.MustParse(event.ShortURL), u)
+// SaveBatch сохраняет несколько URL в in-memory хранилище одной операцией
+// и записывает в файловое хранилище события только для созданных URL.
+// Возвращает ErrBatchIsEmpty если batch пуст или ErrURLNotValid если какой-то URL невалиден.
+func (f *Repository) SaveBatch(ctx context.Context, tx pgx.Tx, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	created, err := f.repository.SaveBatch(ctx, tx, batch)
 	if err != nil {
-		return id, fmt.Errorf("save in mem storage error: %w", err)
+		return nil, fmt.Errorf("save batch in mem storage error: %w", err)
 	}
-	return id, nil
+
+	events, _ := models.RequestBatchToEvents(batch)
+	return created, f.encodeCreated(ctx, tx, events, created)
 }
 
-// SaveBatch сохраняет несколько URL в файловое хранилище одной операцией.
+// SaveBatchUser сохраняет несколько URL пользователя в in-memory хранилище одной операцией
+// и записывает в файловое хранилище события только для созданных URL.
 // Возвращает ErrBatchIsEmpty если batch пуст или ErrURLNotValid если какой-то URL невалиден.
-func (f *Repository) SaveBatch(ctx context.Context, batch []*models.RequestShortenAPIBatch) error {
+func (f *Repository) SaveBatchUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	created, err := f.repository.SaveBatchUser(ctx, tx, userID, batch)
+	if err != nil {
+		return nil, fmt.Errorf("save batch in mem storage error: %w", err)
+	}
+
+	events, _ := models.RequestBatchUserToEvents(userID, batch)
+	return created, f.encodeCreated(ctx, tx, events, created)
+}
+
+// encodeCreated записывает в файловое хранилище события пакета, для которых created равно true.
+// События существующих URL не записываются, чтобы при загрузке файла они не изменили эти URL.
+func (f *Repository) encodeCreated(ctx context.Context, tx pgx.Tx, events []*models.Event, created []bool) error {
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	if len(batch) == 0 {
-		return fmt.Errorf("save batch in file storage error: %w", customError.ErrBatchIsEmpty)
-	}
+	var encoder = f.encoder(tx)
+	for i, event := range events {
+		if !created[i] {
+			continue
+		}
 
-	var encoder = f.producer.encoder
-	events, _ := models.RequestBatchToEvents(batch)
-	for _, event := range events {
 		err := encoder.Encode(&event)
 		if err != nil {
 			return fmt.Errorf("serialize error: %w", err)
 		}
+	}
+	return nil
+}
 
-		u, err := url.Parse(event.OriginalURL)
-		if err != nil {
-			return fmt.Errorf("save in mem storage error: %w", customError.ErrURLNotValid)
+// GetByID получает URL по его UUID ключу, из in-memory хранилища.
+func (f *Repository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error) {
+	return f.repository.GetByID(ctx, id)
+}
+
+// GetAllByUserID получает страницу URL, ассоциированных с пользователем, из in-memory хранилища.
+func (f *Repository) GetAllByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs) ([]*models.ResponseShortenAPIUser, error) {
+	return f.repository.GetAllByUserID(ctx, userID, filter)
+}
+
+// ExportByUserID вызывает fn для каждого URL пользователя из in-memory хранилища.
+func (f *Repository) ExportByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error {
+	return f.repository.ExportByUserID(ctx, userID, filter, fn)
+}
+
+// DeleteBatchByUserID помечает несколько URL как удаленные для пользователя в in-memory хранилище
+// и записывает в файловое хранилище событие удаления для каждого удаленного URL.
+// Возвращает UUID удаленных URL, ошибку in-memory хранилища или ошибку если сериализация не удалась.
+func (f *Repository) DeleteBatchByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []uuid.UUID) ([]uuid.UUID, error) {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	deleted, err := f.repository.DeleteBatchByUserID(ctx, tx, userID, batch)
+	if err != nil {
+		return nil, fmt.Errorf("delete batch in mem storage error: %w", err)
+	}
+
+	var encoder = f.encoder(tx)
+	now := time.Now().UTC()
+	for _, id := range deleted {
+		event := &models.Event{
+			Type:      models.EventTypeDelete,
+			ID:        userID,
+			ShortURL:  id.String(),
+			CreatedAt: now,
 		}
 
-		_, err = f.repository.Save(ctx, nil, event.ID, u)
-		if err != nil && !errors.Is(err, customError.ErrConflict) {
-			return fmt.Errorf("save in mem storage error: %w", err)
+		err = encoder.Encode(&event)
+		if err != nil {
+			return deleted, fmt.Errorf("serialize error: %w", err)
 		}
 	}
+	return deleted, nil
+}
+
+// UpdateByUserID изменяет оригинальный URL пользователя в in-memory хранилище
+// и записывает событие изменения в файловое хранилище.
+// Возвращает ошибку in-memory хранилища или ошибку если сериализация не удалась.
+func (f *Repository) UpdateByUserID(ctx context.Context, tx pgx.Tx, change *models.URLHistory) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.UpdateByUserID(ctx, tx, change)
+	if err != nil {
+		return fmt.Errorf("update in mem storage error: %w", err)
+	}
+
+	var encoder = f.encoder(tx)
+	event := &models.Event{
+		Type:        models.EventTypeUpdate,
+		ID:          change.UserID,
+		ShortURL:    change.ID.String(),
+		OriginalURL: change.NewURL,
+		OldURL:      change.OldURL,
+		CreatedAt:   change.ChangedAt,
+	}
+
+	err = encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
 	return nil
 }
 
-// SaveBatchUser сохраняет несколько URL в файловое хранилище, ассоциированных с пользователем.
-// Возвращает ErrBatchIsEmpty если batch пуст или ErrURLNotValid если какой-то URL невалиден.
-func (f *Repository) SaveBatchUser(ctx context.Context, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) error {
+// GetLinkByID получает запись URL с атрибутами из in-memory хранилища.
+func (f *Repository) GetLinkByID(ctx context.Context, id uuid.UUID) (*models.Link, error) {
+	return f.repository.GetLinkByID(ctx, id)
+}
+
+// GetLinksByIDs получает записи URL по нескольким UUID ключам из in-memory хранилища.
+func (f *Repository) GetLinksByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Link, error) {
+	return f.repository.GetLinksByIDs(ctx, ids)
+}
+
+// AddClick увеличивает счетчик переходов в in-memory хранилище и записывает событие перехода в файл.
+func (f *Repository) AddClick(ctx context.Context, id uuid.UUID, variant string) (int64, error) {
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	if len(batch) == 0 {
-		return fmt.Errorf("save batch in file storage error: %w", customError.ErrBatchIsEmpty)
+	clicks, err := f.repository.AddClick(ctx, id, variant)
+	if err != nil {
+		return 0, fmt.Errorf("add click in mem storage error: %w", err)
 	}
 
 	var encoder = f.producer.encoder
-	events, _ := models.RequestBatchUserToEvents(userID, batch)
-	for _, event := range events {
-		err := encoder.Encode(&event)
-		if err != nil {
-			return fmt.Errorf("serialize error: %w", err)
-		}
+	event := &models.Event{
+		Type:      models.EventTypeClick,
+		ID:        id,
+		ShortURL:  id.String(),
+		Variant:   variant,
+		CreatedAt: time.Now().UTC(),
+	}
 
-		u, err := url.Parse(event.OriginalURL)
-		if err != nil {
-			return fmt.Errorf("save in mem storage error: %w", customError.ErrURLNotValid)
-		}
+	err = encoder.Encode(&event)
+	if err != nil {
+		return 0, fmt.Errorf("serialize error: %w", err)
+	}
+	return clicks, nil
+}
 
-		_, err = f.repository.SaveUser(ctx, nil, event.ID, uuid.MustParse(event.ShortURL), u)
-		if err != nil && !errors.Is(err, customError.ErrConflict) {
-			return fmt.Errorf("save in mem storage error: %w", err)
-		}
+// DisableByID задает причину отключения URL в in-memory хранилище и записывает событие в файл.
+func (f *Repository) DisableByID(ctx context.Context, id uuid.UUID, reason string) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.DisableByID(ctx, id, reason)
+	if err != nil {
+		return fmt.Errorf("disable in mem storage error: %w", err)
+	}
+
+	var encoder = f.producer.encoder
+	event := &models.Event{
+		Type:      models.EventTypeDisable,
+		ShortURL:  id.String(),
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err = encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
 	}
 	return nil
 }
 
-// GetByID получает URL по его UUID ключу, из in-memory хранилища.
-func (f *Repository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error) {
-	return f.repository.GetByID(ctx, id)
+// GetLinksToCheck получает записи URL для проверки доступности из in-memory хранилища.
+func (f *Repository) GetLinksToCheck(ctx context.Context, before time.Time, limit int) ([]*models.Link, error) {
+	return f.repository.GetLinksToCheck(ctx, before, limit)
 }
 
-// GetAllByUserID получает все URL, ассоциированные с пользователем, из in-memory хранилища.
-func (f *Repository) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.ResponseShortenAPIUser, error) {
-	return f.repository.GetAllByUserID(ctx, userID)
+// UpdateHealth сохраняет результат проверки доступности URL в in-memory хранилище и записывает событие в файл.
+func (f *Repository) UpdateHealth(ctx context.Context, id uuid.UUID, health *models.LinkHealth) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.UpdateHealth(ctx, id, health)
+	if err != nil {
+		return fmt.Errorf("update health in mem storage error: %w", err)
+	}
+
+	var encoder = f.producer.encoder
+	event := &models.Event{
+		Type:      models.EventTypeHealth,
+		ShortURL:  id.String(),
+		Health:    health,
+		CreatedAt: health.CheckedAt,
+	}
+
+	err = encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+	return nil
 }
 
-// DeleteBatchByUserID помечает несколько URL как удаленные для пользователя в in-memory хранилище.
-func (f *Repository) DeleteBatchByUserID(ctx context.Context, userID uuid.UUID, batch []uuid.UUID) error {
-	return f.repository.DeleteBatchByUserID(ctx, userID, batch)
+// UpdatePageMeta сохраняет метаданные страницы назначения URL в in-memory хранилище и записывает событие в файл.
+func (f *Repository) UpdatePageMeta(ctx context.Context, id uuid.UUID, page *models.PageMeta) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.UpdatePageMeta(ctx, id, page)
+	if err != nil {
+		return fmt.Errorf("update page meta in mem storage error: %w", err)
+	}
+
+	var encoder = f.producer.encoder
+	event := &models.Event{
+		Type:      models.EventTypePage,
+		ShortURL:  id.String(),
+		Page:      page,
+		CreatedAt: page.FetchedAt,
+	}
+
+	err = encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+	return nil
+}
+
+// UpdateScheduleStates фиксирует состояния окон активности URL в in-memory хранилище
+// и записывает события смены состояния в файл.
+func (f *Repository) UpdateScheduleStates(ctx context.Context, now time.Time) ([]*models.ScheduleEvent, error) {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	events, err := f.repository.UpdateScheduleStates(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("update schedule states in mem storage error: %w", err)
+	}
+
+	var encoder = f.producer.encoder
+	for _, e := range events {
+		event := &models.Event{
+			Type:      models.EventTypeSchedule,
+			ShortURL:  e.ID.String(),
+			State:     e.State,
+			CreatedAt: e.ChangedAt,
+		}
+		if e.UserID != nil {
+			event.ID = *e.UserID
+		}
+
+		err = encoder.Encode(&event)
+		if err != nil {
+			return events, fmt.Errorf("serialize error: %w", err)
+		}
+	}
+	return events, nil
+}
+
+// GetHistoryByUserID получает историю изменений URL пользователя из in-memory хранилища.
+func (f *Repository) GetHistoryByUserID(ctx context.Context, userID, id uuid.UUID) ([]*models.URLHistory, error) {
+	return f.repository.GetHistoryByUserID(ctx, userID, id)
 }
 
 // ReadFile читает URL из файлового хранилища и загружает их в память.
@@ -168,17 +363,100 @@ func (f *Repository) ReadFile(ctx context.Context) error {
 			return fmt.Errorf("deserialize error: %w", err)
 		}
 
-		u, err := url.Parse(event.OriginalURL)
+		err := f.replay(ctx, event)
 		if err != nil {
-			return fmt.Errorf("save in mem storage error: %w", customError.ErrURLNotValid)
+			return err
 		}
+	}
+	return nil
+}
 
-		_, err = f.repository.Save(ctx, nil, event.ID, u)
-		if err != nil && !errors.Is(err, customError.ErrConflict) {
-			return fmt.Errorf("save in mem storage error: %w", err)
+// replay применяет событие файлового хранилища к in-memory хранилищу.
+func (f *Repository) replay(ctx context.Context, event *models.Event) error {
+	switch event.Type {
+	case models.EventTypeUpdate:
+		change, err := models.EventToHistory(event)
+		if err != nil {
+			return fmt.Errorf("deserialize error: %w", err)
+		}
+
+		err = f.repository.UpdateByUserID(ctx, nil, change)
+		if err != nil {
+			return fmt.Errorf("update in mem storage error: %w", err)
+		}
+	case models.EventTypeDelete:
+		id, err := uuid.Parse(event.ShortURL)
+		if err != nil {
+			return fmt.Errorf("deserialize error: %w", err)
+		}
+
+		_, err = f.repository.DeleteBatchByUserID(ctx, nil, event.ID, []uuid.UUID{id})
+		if err != nil && !errors.Is(err, customError.ErrNotFound) {
+			return fmt.Errorf("delete batch in mem storage error: %w", err)
+		}
+	case models.EventTypeClick:
+		id, err := uuid.Parse(event.ShortURL)
+		if err != nil {
+			return fmt.Errorf("deserialize error: %w", err)
+		}
+
+		_, err = f.repository.AddClick(ctx, id, event.Variant)
+		if err != nil && !errors.Is(err, customError.ErrNotFound) {
+			return fmt.Errorf("add click in mem storage error: %w", err)
+		}
+	case models.EventTypeDisable:
+		id, err := uuid.Parse(event.ShortURL)
+		if err != nil {
+			return fmt.Errorf("deserialize error: %w", err)
+		}
+
+		err = f.repository.DisableByID(ctx, id, event.Reason)
+		if err != nil && !errors.Is(err, customError.ErrNotFound) {
+			return fmt.Errorf("disable in mem storage error: %w", err)
+		}
+	case models.EventTypeHealth:
+		id, err := uuid.Parse(event.ShortURL)
+		if err != nil {
+			return fmt.Errorf("deserialize error: %w", err)
+		}
+
+		err = f.repository.UpdateHealth(ctx, id, event.Health)
+		if err != nil && !errors.Is(err, customError.ErrNotFound) {
+			return fmt.Errorf("update health in mem storage error: %w", err)
+		}
+	case models.EventTypePage:
+		id, err := uuid.Parse(event.ShortURL)
+		if err != nil {
+			return fmt.Errorf("deserialize error: %w", err)
+		}
+
+		err = f.repository.UpdatePageMeta(ctx, id, event.Page)
+		if err != nil && !errors.Is(err, customError.ErrNotFound) {
+			return fmt.Errorf("update page meta in mem storage error: %w", err)
+		}
+	case models.EventTypeSchedule:
+		// Состояния всех URL пересчитываются на момент события, как при исходном вызове воркера
+		_, err := f.repository.UpdateScheduleStates(ctx, event.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("update schedule states in mem storage error: %w", err)
+		}
+	case models.EventTypeFolderSave, models.EventTypeFolderUpdate, models.EventTypeFolderDelete:
+		return f.replayFolder(ctx, event)
+	case models.EventTypeTemplateSave, models.EventTypeTemplateUpdate, models.EventTypeTemplateDelete:
+		return f.replayTemplate(ctx, event)
+	case models.EventTypeWebhookSave, models.EventTypeWebhookUpdate, models.EventTypeWebhookDelete:
+		return f.replayWebhook(ctx, event)
+	case models.EventTypeOutbox, models.EventTypeOutboxDelete:
+		return f.replayOutbox(ctx, event)
+	case models.EventTypeAlias:
+		return f.replayAlias(ctx, event)
+	default:
+		link, err := models.EventToLink(event)
+		if err != nil {
+			return fmt.Errorf("deserialize error: %w", err)
 		}
 
-		_, err = f.repository.SaveUser(ctx, nil, event.ID, uuid.MustParse(event.ShortURL), u)
+		_, err = f.repository.SaveLink(ctx, nil, link)
 		if err != nil && !errors.Is(err, customError.ErrConflict) {
 			return fmt.Errorf("save in mem storage error: %w", err)
 		}
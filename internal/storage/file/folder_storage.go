@@ -0,0 +1,101 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SaveFolder сохраняет папку пользователя в in-memory хранилище и записывает событие в файловое хранилище.
+func (f *Repository) SaveFolder(ctx context.Context, folder *models.Folder) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.SaveFolder(ctx, folder)
+	if err != nil {
+		return fmt.Errorf("save folder in mem storage error: %w", err)
+	}
+	return f.writeFolderEvent(models.EventTypeFolderSave, folder)
+}
+
+// GetFoldersByUserID получает все папки пользователя из in-memory хранилища.
+func (f *Repository) GetFoldersByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Folder, error) {
+	return f.repository.GetFoldersByUserID(ctx, userID)
+}
+
+// GetFolderByUserID получает папку пользователя из in-memory хранилища.
+func (f *Repository) GetFolderByUserID(ctx context.Context, userID, id uuid.UUID) (*models.Folder, error) {
+	return f.repository.GetFolderByUserID(ctx, userID, id)
+}
+
+// UpdateFolderByUserID переименовывает папку пользователя в in-memory хранилище
+// и записывает событие в файловое хранилище.
+func (f *Repository) UpdateFolderByUserID(ctx context.Context, folder *models.Folder) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.UpdateFolderByUserID(ctx, folder)
+	if err != nil {
+		return fmt.Errorf("update folder in mem storage error: %w", err)
+	}
+	return f.writeFolderEvent(models.EventTypeFolderUpdate, folder)
+}
+
+// DeleteFolderByUserID удаляет папку пользователя из in-memory хранилища
+// и записывает событие в файловое хранилище.
+func (f *Repository) DeleteFolderByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.DeleteFolderByUserID(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("delete folder in mem storage error: %w", err)
+	}
+	return f.writeFolderEvent(models.EventTypeFolderDelete, &models.Folder{ID: id, UserID: userID})
+}
+
+// writeFolderEvent записывает событие папки в файловое хранилище.
+func (f *Repository) writeFolderEvent(eventType string, folder *models.Folder) error {
+	var encoder = f.producer.encoder
+	event := &models.Event{
+		Type:      eventType,
+		ID:        folder.UserID,
+		Name:      folder.Name,
+		CreatedAt: folder.CreatedAt,
+		LinkMeta: models.LinkMeta{
+			FolderID: &folder.ID,
+		},
+	}
+
+	err := encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+	return nil
+}
+
+// replayFolder применяет событие папки к in-memory хранилищу.
+func (f *Repository) replayFolder(ctx context.Context, event *models.Event) error {
+	folder, err := models.EventToFolder(event)
+	if err != nil {
+		return fmt.Errorf("deserialize error: %w", err)
+	}
+
+	switch event.Type {
+	case models.EventTypeFolderSave:
+		err = f.repository.SaveFolder(ctx, folder)
+	case models.EventTypeFolderUpdate:
+		err = f.repository.UpdateFolderByUserID(ctx, folder)
+	case models.EventTypeFolderDelete:
+		err = f.repository.DeleteFolderByUserID(ctx, folder.UserID, folder.ID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("replay folder in mem storage error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package file
+
+import (
+	"context"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ReserveIdempotency резервирует ключ пользователя в in-memory хранилище.
+// Ответы Idempotency-Key не записываются в файл событий и не переживают перезапуск.
+func (f *Repository) ReserveIdempotency(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	return f.repository.ReserveIdempotency(ctx, record)
+}
+
+// CompleteIdempotency сохраняет ответ на запрос с зарезервированным ключом в in-memory хранилище.
+func (f *Repository) CompleteIdempotency(ctx context.Context, record *models.IdempotencyRecord) error {
+	return f.repository.CompleteIdempotency(ctx, record)
+}
+
+// DeleteIdempotency снимает резерв ключа пользователя в in-memory хранилище.
+func (f *Repository) DeleteIdempotency(ctx context.Context, userID uuid.UUID, key string) error {
+	return f.repository.DeleteIdempotency(ctx, userID, key)
+}
+
+// DeleteExpiredIdempotency удаляет записи с истекшим временем хранения из in-memory хранилища.
+func (f *Repository) DeleteExpiredIdempotency(ctx context.Context) (int64, error) {
+	return f.repository.DeleteExpiredIdempotency(ctx)
+}
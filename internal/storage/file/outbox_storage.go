@@ -0,0 +1,91 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveOutbox записывает доменные события в тот же файл событий, что и изменения данных,
+// и сохраняет их в in-memory хранилище. При загрузке файла неопубликованные события восстанавливаются.
+func (f *Repository) SaveOutbox(ctx context.Context, tx pgx.Tx, events []*models.OutboxEvent) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	var encoder = f.encoder(tx)
+	for _, outbox := range events {
+		event := &models.Event{
+			Type:      models.EventTypeOutbox,
+			ID:        outbox.ID,
+			ShortURL:  outbox.LinkID.String(),
+			Outbox:    outbox,
+			CreatedAt: outbox.CreatedAt,
+		}
+
+		err := encoder.Encode(&event)
+		if err != nil {
+			return fmt.Errorf("serialize error: %w", err)
+		}
+	}
+
+	err := f.repository.SaveOutbox(ctx, tx, events)
+	if err != nil {
+		return fmt.Errorf("save outbox in mem storage error: %w", err)
+	}
+	return nil
+}
+
+// GetOutbox получает неопубликованные события из in-memory хранилища.
+func (f *Repository) GetOutbox(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	return f.repository.GetOutbox(ctx, limit)
+}
+
+// DeleteOutbox удаляет опубликованные события из in-memory хранилища
+// и записывает отметки о публикации в файловое хранилище.
+func (f *Repository) DeleteOutbox(ctx context.Context, ids []uuid.UUID) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	var encoder = f.producer.encoder
+	for _, id := range ids {
+		event := &models.Event{
+			Type: models.EventTypeOutboxDelete,
+			ID:   id,
+		}
+
+		err := encoder.Encode(&event)
+		if err != nil {
+			return fmt.Errorf("serialize error: %w", err)
+		}
+	}
+
+	err := f.repository.DeleteOutbox(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("delete outbox in mem storage error: %w", err)
+	}
+	return nil
+}
+
+// replayOutbox применяет событие outbox к in-memory хранилищу.
+func (f *Repository) replayOutbox(ctx context.Context, event *models.Event) error {
+	var err error
+	switch event.Type {
+	case models.EventTypeOutbox:
+		if event.Outbox == nil {
+			return fmt.Errorf("deserialize error: outbox event %s is empty", event.ID)
+		}
+		err = f.repository.SaveOutbox(ctx, nil, []*models.OutboxEvent{event.Outbox})
+	case models.EventTypeOutboxDelete:
+		err = f.repository.DeleteOutbox(ctx, []uuid.UUID{event.ID})
+	}
+
+	if err != nil {
+		return fmt.Errorf("replay outbox in mem storage error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SaveTemplate сохраняет шаблон UTM разметки пользователя в in-memory хранилище
+// и записывает событие в файловое хранилище.
+func (f *Repository) SaveTemplate(ctx context.Context, template *models.UTMTemplate) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.SaveTemplate(ctx, template)
+	if err != nil {
+		return fmt.Errorf("save template in mem storage error: %w", err)
+	}
+	return f.writeTemplateEvent(models.EventTypeTemplateSave, template)
+}
+
+// GetTemplatesByUserID получает все шаблоны UTM разметки пользователя из in-memory хранилища.
+func (f *Repository) GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UTMTemplate, error) {
+	return f.repository.GetTemplatesByUserID(ctx, userID)
+}
+
+// UpdateTemplateByUserID изменяет шаблон UTM разметки пользователя в in-memory хранилище
+// и записывает событие в файловое хранилище.
+func (f *Repository) UpdateTemplateByUserID(ctx context.Context, template *models.UTMTemplate) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.UpdateTemplateByUserID(ctx, template)
+	if err != nil {
+		return fmt.Errorf("update template in mem storage error: %w", err)
+	}
+	return f.writeTemplateEvent(models.EventTypeTemplateUpdate, template)
+}
+
+// DeleteTemplateByUserID удаляет шаблон UTM разметки пользователя из in-memory хранилища
+// и записывает событие в файловое хранилище.
+func (f *Repository) DeleteTemplateByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.DeleteTemplateByUserID(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("delete template in mem storage error: %w", err)
+	}
+	return f.writeTemplateEvent(models.EventTypeTemplateDelete, &models.UTMTemplate{ID: id, UserID: userID})
+}
+
+// writeTemplateEvent записывает событие шаблона UTM разметки в файловое хранилище.
+func (f *Repository) writeTemplateEvent(eventType string, template *models.UTMTemplate) error {
+	var encoder = f.producer.encoder
+	event := &models.Event{
+		Type:       eventType,
+		ID:         template.UserID,
+		Name:       template.Name,
+		TemplateID: &template.ID,
+		UTM:        &template.UTMParams,
+		CreatedAt:  template.CreatedAt,
+	}
+
+	err := encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+	return nil
+}
+
+// replayTemplate применяет событие шаблона UTM разметки к in-memory хранилищу.
+func (f *Repository) replayTemplate(ctx context.Context, event *models.Event) error {
+	template, err := models.EventToTemplate(event)
+	if err != nil {
+		return fmt.Errorf("deserialize error: %w", err)
+	}
+
+	switch event.Type {
+	case models.EventTypeTemplateSave:
+		err = f.repository.SaveTemplate(ctx, template)
+	case models.EventTypeTemplateUpdate:
+		err = f.repository.UpdateTemplateByUserID(ctx, template)
+	case models.EventTypeTemplateDelete:
+		err = f.repository.DeleteTemplateByUserID(ctx, template.UserID, template.ID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("replay template in mem storage error: %w", err)
+	}
+	return nil
+}
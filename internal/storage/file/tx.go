@@ -0,0 +1,152 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrSQLNotSupported возвращается SQL методами транзакции файлового хранилища
+var ErrSQLNotSupported = errors.New("sql is not supported by file storage transaction")
+
+var _ pgx.Tx = (*Tx)(nil)
+
+// Tx реализует транзакцию файлового хранилища.
+// События, записанные в рамках транзакции, накапливаются в буфере и дописываются в файл
+// одной операцией записи при Commit, поэтому изменение данных и его события outbox
+// не могут попасть в файл по отдельности. Rollback отбрасывает буфер.
+// In-memory хранилище изменяется сразу и не откатывается.
+type Tx struct {
+	file    io.Writer     // Файловый дескриптор для записи
+	buf     bytes.Buffer  // Буфер событий транзакции
+	encoder *json.Encoder // JSON энкодер в буфер транзакции
+	closed  bool          // Транзакция завершена
+}
+
+// newTx создает транзакцию, дописывающую события в file.
+func newTx(file io.Writer) *Tx {
+	tx := &Tx{file: file}
+	tx.encoder = json.NewEncoder(&tx.buf)
+	return tx
+}
+
+// Begin не поддерживается: вложенные транзакции файловому хранилищу не нужны.
+func (tx *Tx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// Commit дописывает накопленные события в файл одной операцией записи.
+// Возвращает pgx.ErrTxClosed если транзакция уже завершена.
+func (tx *Tx) Commit(ctx context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+
+	if tx.buf.Len() == 0 {
+		return nil
+	}
+
+	_, err := tx.file.Write(tx.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("write file error: %w", err)
+	}
+	return nil
+}
+
+// Rollback отбрасывает накопленные события.
+// Возвращает pgx.ErrTxClosed если транзакция уже завершена.
+func (tx *Tx) Rollback(ctx context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+	tx.buf.Reset()
+	return nil
+}
+
+// CopyFrom не поддерживается файловым хранилищем.
+func (tx *Tx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, ErrSQLNotSupported
+}
+
+// SendBatch не поддерживается файловым хранилищем.
+func (tx *Tx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return errBatchResults{}
+}
+
+// LargeObjects не поддерживается файловым хранилищем.
+func (tx *Tx) LargeObjects() pgx.LargeObjects {
+	return pgx.LargeObjects{}
+}
+
+// Prepare не поддерживается файловым хранилищем.
+func (tx *Tx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// Exec не поддерживается файловым хранилищем.
+func (tx *Tx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, ErrSQLNotSupported
+}
+
+// Query не поддерживается файловым хранилищем.
+func (tx *Tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// QueryRow не поддерживается файловым хранилищем.
+func (tx *Tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return errRow{}
+}
+
+// Conn возвращает nil: у файлового хранилища нет соединения с базой данных.
+func (tx *Tx) Conn() *pgx.Conn {
+	return nil
+}
+
+// errRow возвращает ErrSQLNotSupported при чтении строки
+type errRow struct{}
+
+// Scan возвращает ErrSQLNotSupported.
+func (errRow) Scan(dest ...any) error {
+	return ErrSQLNotSupported
+}
+
+// errBatchResults возвращает ErrSQLNotSupported для каждого запроса пакета
+type errBatchResults struct{}
+
+// Exec возвращает ErrSQLNotSupported.
+func (errBatchResults) Exec() (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, ErrSQLNotSupported
+}
+
+// Query возвращает ErrSQLNotSupported.
+func (errBatchResults) Query() (pgx.Rows, error) {
+	return nil, ErrSQLNotSupported
+}
+
+// QueryRow возвращает строку, чтение которой возвращает ErrSQLNotSupported.
+func (errBatchResults) QueryRow() pgx.Row {
+	return errRow{}
+}
+
+// Close ничего не делает.
+func (errBatchResults) Close() error {
+	return nil
+}
+
+// encoder возвращает JSON энкодер для записи события: в буфер транзакции файлового хранилища,
+// если она передана, иначе сразу в файл.
+func (f *Repository) encoder(tx pgx.Tx) *json.Encoder {
+	if t, ok := tx.(*Tx); ok && t != nil {
+		return t.encoder
+	}
+	return f.producer.encoder
+}
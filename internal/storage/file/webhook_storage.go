@@ -0,0 +1,105 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SaveWebhook сохраняет webhook пользователя в in-memory хранилище
+// и записывает событие в файловое хранилище.
+func (f *Repository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.SaveWebhook(ctx, webhook)
+	if err != nil {
+		return fmt.Errorf("save webhook in mem storage error: %w", err)
+	}
+	return f.writeWebhookEvent(models.EventTypeWebhookSave, webhook)
+}
+
+// GetWebhooksByUserID получает все webhook пользователя из in-memory хранилища.
+func (f *Repository) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	return f.repository.GetWebhooksByUserID(ctx, userID)
+}
+
+// UpdateWebhookByUserID сохраняет секреты webhook пользователя после ротации в in-memory хранилище
+// и записывает событие в файловое хранилище.
+func (f *Repository) UpdateWebhookByUserID(ctx context.Context, webhook *models.Webhook) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.UpdateWebhookByUserID(ctx, webhook)
+	if err != nil {
+		return fmt.Errorf("update webhook in mem storage error: %w", err)
+	}
+	return f.writeWebhookEvent(models.EventTypeWebhookUpdate, webhook)
+}
+
+// DeleteWebhookByUserID удаляет webhook пользователя из in-memory хранилища
+// и записывает событие в файловое хранилище.
+func (f *Repository) DeleteWebhookByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	err := f.repository.DeleteWebhookByUserID(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook in mem storage error: %w", err)
+	}
+	return f.writeWebhookEvent(models.EventTypeWebhookDelete, &models.Webhook{ID: id, UserID: userID})
+}
+
+// SaveWebhookDelivery записывает попытку доставки в журнал in-memory хранилища.
+// Журнал доставок не записывается в файл и не восстанавливается после перезапуска.
+func (f *Repository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return f.repository.SaveWebhookDelivery(ctx, delivery)
+}
+
+// GetWebhookDeliveries получает последние попытки доставки webhook пользователя из in-memory хранилища.
+func (f *Repository) GetWebhookDeliveries(ctx context.Context, userID, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	return f.repository.GetWebhookDeliveries(ctx, userID, id, limit)
+}
+
+// writeWebhookEvent записывает событие webhook в файловое хранилище.
+func (f *Repository) writeWebhookEvent(eventType string, webhook *models.Webhook) error {
+	var encoder = f.producer.encoder
+	event := &models.Event{
+		Type:      eventType,
+		ID:        webhook.UserID,
+		Webhook:   webhook,
+		CreatedAt: webhook.CreatedAt,
+	}
+
+	err := encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("serialize error: %w", err)
+	}
+	return nil
+}
+
+// replayWebhook применяет событие webhook к in-memory хранилищу.
+func (f *Repository) replayWebhook(ctx context.Context, event *models.Event) error {
+	webhook, err := models.EventToWebhook(event)
+	if err != nil {
+		return fmt.Errorf("deserialize error: %w", err)
+	}
+
+	switch event.Type {
+	case models.EventTypeWebhookSave:
+		err = f.repository.SaveWebhook(ctx, webhook)
+	case models.EventTypeWebhookUpdate:
+		err = f.repository.UpdateWebhookByUserID(ctx, webhook)
+	case models.EventTypeWebhookDelete:
+		err = f.repository.DeleteWebhookByUserID(ctx, webhook.UserID, webhook.ID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("replay webhook in mem storage error: %w", err)
+	}
+	return nil
+}
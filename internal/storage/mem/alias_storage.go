@@ -0,0 +1,67 @@
+package mem
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveAlias сохраняет короткое имя ссылки в in-memory хранилище.
+// Возвращает ErrNotFound если ссылки нет или ErrConflict если короткое имя уже занято.
+func (m *Repository) SaveAlias(ctx context.Context, tx pgx.Tx, alias *models.Alias) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	if _, ok := m.memRepository[alias.LinkID]; !ok {
+		return fmt.Errorf("save alias in mem storage error: %w", customError.ErrNotFound)
+	}
+	if _, ok := m.aliasRepository[alias.Slug]; ok {
+		return fmt.Errorf("save alias in mem storage error: %w", customError.ErrConflict)
+	}
+
+	m.aliasRepository[alias.Slug] = alias.LinkID
+	return nil
+}
+
+// GetIDBySlug получает UUID ссылки по короткому имени из in-memory хранилища.
+// Возвращает ErrNotFound если короткого имени нет.
+func (m *Repository) GetIDBySlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	id, ok := m.aliasRepository[slug]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("get alias in mem storage error: %w", customError.ErrNotFound)
+	}
+	return id, nil
+}
+
+// GetIDsBySlugs получает UUID ссылок по нескольким коротким именам из in-memory хранилища.
+// Отсутствующих коротких имен нет в результате.
+func (m *Repository) GetIDsBySlugs(ctx context.Context, slugs []string) (map[string]uuid.UUID, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	res := make(map[string]uuid.UUID, len(slugs))
+	for _, slug := range slugs {
+		if id, ok := m.aliasRepository[slug]; ok {
+			res[slug] = id
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,125 @@
+package mem
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// SaveFolder сохраняет папку пользователя в in-memory хранилище.
+// Возвращает ErrConflict если у пользователя уже есть папка с таким названием.
+func (m *Repository) SaveFolder(ctx context.Context, folder *models.Folder) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	folders, ok := m.folderRepository[folder.UserID]
+	if !ok {
+		folders = make(map[uuid.UUID]*models.Folder)
+		m.folderRepository[folder.UserID] = folders
+	}
+
+	for _, f := range folders {
+		if f.Name == folder.Name {
+			return fmt.Errorf("save folder in mem storage error: %w", customError.ErrConflict)
+		}
+	}
+
+	f := *folder
+	folders[folder.ID] = &f
+	return nil
+}
+
+// GetFoldersByUserID получает все папки пользователя из in-memory хранилища в порядке создания.
+func (m *Repository) GetFoldersByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Folder, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	res := make([]*models.Folder, 0, len(m.folderRepository[userID]))
+	for _, f := range m.folderRepository[userID] {
+		folder := *f
+		res = append(res, &folder)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+// GetFolderByUserID получает папку пользователя из in-memory хранилища.
+// Возвращает ErrNotFound если папка не существует или не принадлежит пользователю.
+func (m *Repository) GetFolderByUserID(ctx context.Context, userID, id uuid.UUID) (*models.Folder, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	f, ok := m.folderRepository[userID][id]
+	if !ok {
+		return nil, fmt.Errorf("get folder in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	folder := *f
+	return &folder, nil
+}
+
+// UpdateFolderByUserID переименовывает папку пользователя в in-memory хранилище.
+// Возвращает ErrNotFound если папка не существует или ErrConflict если название занято.
+func (m *Repository) UpdateFolderByUserID(ctx context.Context, folder *models.Folder) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	f, ok := m.folderRepository[folder.UserID][folder.ID]
+	if !ok {
+		return fmt.Errorf("update folder in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	for _, other := range m.folderRepository[folder.UserID] {
+		if other.ID != folder.ID && other.Name == folder.Name {
+			return fmt.Errorf("update folder in mem storage error: %w", customError.ErrConflict)
+		}
+	}
+
+	f.Name = folder.Name
+	folder.CreatedAt = f.CreatedAt
+	return nil
+}
+
+// DeleteFolderByUserID удаляет папку пользователя из in-memory хранилища и убирает из нее URL.
+// Возвращает ErrNotFound если папка не существует или не принадлежит пользователю.
+func (m *Repository) DeleteFolderByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	_, ok := m.folderRepository[userID][id]
+	if !ok {
+		return fmt.Errorf("delete folder in mem storage error: %w", customError.ErrNotFound)
+	}
+	delete(m.folderRepository[userID], id)
+
+	for _, link := range m.userRepository[userID] {
+		if link.FolderID != nil && *link.FolderID == id {
+			link.FolderID = nil
+		}
+	}
+	return nil
+}
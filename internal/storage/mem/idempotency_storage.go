@@ -0,0 +1,85 @@
+package mem
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ReserveIdempotency резервирует ключ пользователя в in-memory хранилище.
+// Запись с истекшим временем хранения заменяется новым резервом.
+func (m *Repository) ReserveIdempotency(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	k := idempotencyKey{userID: record.UserID, key: record.Key}
+	if existing, ok := m.idempotencyRepository[k]; ok && existing.ExpiresAt.After(time.Now()) {
+		r := *existing
+		r.Body = slices.Clone(existing.Body)
+		return &r, nil
+	}
+
+	r := *record
+	r.Status = 0
+	r.Body = nil
+	m.idempotencyRepository[k] = &r
+	return nil, nil
+}
+
+// CompleteIdempotency сохраняет ответ на запрос с зарезервированным ключом в in-memory хранилище.
+func (m *Repository) CompleteIdempotency(ctx context.Context, record *models.IdempotencyRecord) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	existing, ok := m.idempotencyRepository[idempotencyKey{userID: record.UserID, key: record.Key}]
+	if !ok {
+		return nil
+	}
+
+	existing.Status = record.Status
+	existing.ContentType = record.ContentType
+	existing.Body = slices.Clone(record.Body)
+	return nil
+}
+
+// DeleteIdempotency снимает резерв ключа пользователя в in-memory хранилище.
+func (m *Repository) DeleteIdempotency(ctx context.Context, userID uuid.UUID, key string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	delete(m.idempotencyRepository, idempotencyKey{userID: userID, key: key})
+	return nil
+}
+
+// DeleteExpiredIdempotency удаляет записи с истекшим временем хранения из in-memory хранилища.
+func (m *Repository) DeleteExpiredIdempotency(ctx context.Context) (int64, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	var n int64
+	now := time.Now()
+	for k, record := range m.idempotencyRepository {
+		if !record.ExpiresAt.After(now) {
+			delete(m.idempotencyRepository, k)
+			n++
+		}
+	}
+	return n, nil
+}
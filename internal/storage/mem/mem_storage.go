@@ -3,7 +3,12 @@ package mem
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/IvanKondrashkov/go-shortener/internal/config"
 	"github.com/IvanKondrashkov/go-shortener/internal/models"
@@ -29,12 +34,14 @@ func (m *Repository) Save(ctx context.Context, tx pgx.Tx, id uuid.UUID, u *url.U
 	defer cancel()
 
 	_, ok := m.memRepository[id]
+	m.save(&models.Link{
+		ID:          id,
+		OriginalURL: u.String(),
+		CreatedAt:   time.Now().UTC(),
+	})
 	if ok {
-		m.memRepository[id] = u
 		return id, fmt.Errorf("save in mem storage error: %w", customError.ErrConflict)
 	}
-
-	m.memRepository[id] = u
 	return id, nil
 }
 
@@ -47,123 +54,394 @@ func (m *Repository) SaveUser(ctx context.Context, tx pgx.Tx, userID, id uuid.UU
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	_, ok := m.userRepository[userID]
-	if !ok {
-		m.userRepository[userID] = make(map[uuid.UUID]*url.URL)
-	}
-
-	_, ok = m.userRepository[userID][id]
+	_, ok := m.userRepository[userID][id]
+	m.save(&models.Link{
+		ID:          id,
+		UserID:      &userID,
+		OriginalURL: u.String(),
+		CreatedAt:   time.Now().UTC(),
+	})
 	if ok {
-		m.memRepository[id] = u
-		m.userRepository[userID][id] = u
 		return id, fmt.Errorf("save in mem storage error: %w", customError.ErrConflict)
 	}
-
-	m.memRepository[id] = u
-	m.userRepository[userID][id] = u
 	return id, nil
 }
 
+// SaveLink сохраняет запись URL в in-memory хранилище с сохранением времени создания.
+// Если запись уже существует, время ее создания не изменяется.
+func (m *Repository) SaveLink(ctx context.Context, tx pgx.Tx, link *models.Link) (uuid.UUID, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	if _, err := url.Parse(link.OriginalURL); err != nil {
+		return link.ID, fmt.Errorf("save in mem storage error: %w", customError.ErrURLNotValid)
+	}
+
+	m.save(link)
+	return link.ID, nil
+}
+
 // SaveBatch сохраняет несколько URL в in-memory хранилище одной операцией.
-// Возвращает ErrBatchIsEmpty если batch пуст или ErrURLNotValid если какой-то URL невалиден.
-func (m *Repository) SaveBatch(ctx context.Context, batch []*models.RequestShortenAPIBatch) error {
+// Уже существующие URL не изменяются, для них возвращается false.
+// Возвращает ErrBatchIsEmpty если batch пуст или ErrURLNotValid если какой-то URL невалиден, тогда пакет не сохраняется.
+func (m *Repository) SaveBatch(ctx context.Context, tx pgx.Tx, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	if len(batch) == 0 {
-		return fmt.Errorf("save batch in mem storage error: %w", customError.ErrBatchIsEmpty)
+	return m.saveBatch(nil, batch)
+}
+
+// SaveBatchUser сохраняет несколько URL в in-memory хранилище, ассоциированных с пользователем.
+// Уже существующие URL, в том числе другого пользователя, не изменяются, для них возвращается false.
+// Возвращает ErrBatchIsEmpty если batch пуст или ErrURLNotValid если какой-то URL невалиден, тогда пакет не сохраняется.
+func (m *Repository) SaveBatchUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	return m.saveBatch(&userID, batch)
+}
+
+// GetByID получает URL из in-memory хранилища по его UUID ключу.
+// Возвращает ErrNotFound если ключ не существует или ErrDeleteAccepted если URL был удален.
+func (m *Repository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	link, ok := m.memRepository[id]
+	if !ok || link == nil {
+		return nil, fmt.Errorf("get in mem storage error: %w", customError.ErrNotFound)
 	}
 
-	for _, b := range batch {
-		u, err := url.Parse(b.OriginalURL)
-		if err != nil {
-			return fmt.Errorf("save batch in mem storage error: %w", customError.ErrURLNotValid)
-		}
-		m.memRepository[uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String()))] = u
+	if link.IsDeleted {
+		return nil, fmt.Errorf("get in mem storage error: %w", customError.ErrDeleteAccepted)
 	}
-	return nil
+
+	u, err := url.Parse(link.OriginalURL)
+	if err != nil {
+		return nil, fmt.Errorf("get in mem storage error: %w", customError.ErrURLNotValid)
+	}
+	return u, nil
 }
 
-// SaveBatchUser сохраняет несколько URL в in-memory хранилище, ассоциированных с пользователем.
-// Возвращает ErrBatchIsEmpty если batch пуст или ErrURLNotValid если какой-то URL невалиден.
-func (m *Repository) SaveBatchUser(ctx context.Context, userID uuid.UUID, batch []*models.RequestShortenAPIBatch) error {
+// GetLinkByID получает копию записи URL из in-memory хранилища по его UUID ключу.
+// Возвращает те же ошибки, что и GetByID.
+func (m *Repository) GetLinkByID(ctx context.Context, id uuid.UUID) (*models.Link, error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	if len(batch) == 0 {
-		return fmt.Errorf("save batch in mem storage error: %w", customError.ErrBatchIsEmpty)
+	link, ok := m.memRepository[id]
+	if !ok || link == nil {
+		return nil, fmt.Errorf("get link in mem storage error: %w", customError.ErrNotFound)
 	}
 
-	_, ok := m.userRepository[userID]
+	if link.IsDeleted {
+		return nil, fmt.Errorf("get link in mem storage error: %w", customError.ErrDeleteAccepted)
+	}
+
+	res := *link
+	res.VariantClicks = maps.Clone(link.VariantClicks)
+	return &res, nil
+}
+
+// GetLinksByIDs получает записи URL по нескольким UUID ключам из in-memory хранилища.
+// Удаленные записи возвращаются с IsDeleted, отсутствующих ключей нет в результате.
+func (m *Repository) GetLinksByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Link, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	res := make(map[uuid.UUID]*models.Link, len(ids))
+	for _, id := range ids {
+		link, ok := m.memRepository[id]
+		if !ok || link == nil {
+			continue
+		}
+
+		cp := *link
+		cp.VariantClicks = maps.Clone(link.VariantClicks)
+		res[id] = &cp
+	}
+	return res, nil
+}
+
+// AddClick увеличивает счетчик переходов по URL и по адресу варианта в in-memory хранилище.
+// Возвращает новое количество переходов или ErrNotFound если ключ не существует.
+func (m *Repository) AddClick(ctx context.Context, id uuid.UUID, variant string) (int64, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	link, ok := m.memRepository[id]
 	if !ok {
-		m.userRepository[userID] = make(map[uuid.UUID]*url.URL)
+		return 0, fmt.Errorf("add click in mem storage error: %w", customError.ErrNotFound)
 	}
 
-	for _, b := range batch {
-		u, err := url.Parse(b.OriginalURL)
-		if err != nil {
-			return fmt.Errorf("save batch in mem storage error: %w", customError.ErrURLNotValid)
+	link.Clicks++
+	if variant != "" {
+		if link.VariantClicks == nil {
+			link.VariantClicks = make(map[string]int64)
 		}
-		m.memRepository[uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String()))] = u
-		m.userRepository[userID][uuid.NewSHA1(uuid.NameSpaceURL, []byte(u.String()))] = u
+		link.VariantClicks[variant]++
 	}
+	return link.Clicks, nil
+}
+
+// DisableByID задает причину отключения URL в in-memory хранилище, пустая причина включает URL.
+// Возвращает ErrNotFound если ключ не существует.
+func (m *Repository) DisableByID(ctx context.Context, id uuid.UUID, reason string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	link, ok := m.memRepository[id]
+	if !ok || link == nil {
+		return fmt.Errorf("disable in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	link.Disabled = reason
 	return nil
 }
 
-// GetByID получает URL из in-memory хранилища по его UUID ключу.
-// Возвращает ErrNotFound если ключ не существует или ErrDeleteAccepted если URL был удален.
-func (m *Repository) GetByID(ctx context.Context, id uuid.UUID) (*url.URL, error) {
+// GetLinksToCheck получает копии записей URL для проверки доступности из in-memory хранилища.
+// Возвращает не более limit URL, не проверявшихся или проверенных до before, сначала непроверенные,
+// затем по времени проверки. Удаленные и отключенные URL не возвращаются.
+func (m *Repository) GetLinksToCheck(ctx context.Context, before time.Time, limit int) ([]*models.Link, error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	u, ok := m.memRepository[id]
-	if !ok && u != nil {
-		return nil, fmt.Errorf("get in mem storage error: %w", customError.ErrNotFound)
+	var res []*models.Link
+	for _, link := range m.memRepository {
+		if link == nil || link.IsDeleted || link.Disabled != "" {
+			continue
+		}
+		if link.Health != nil && !link.Health.CheckedAt.Before(before) {
+			continue
+		}
+
+		copied := *link
+		copied.VariantClicks = maps.Clone(link.VariantClicks)
+		res = append(res, &copied)
 	}
 
-	if u == nil {
-		return nil, fmt.Errorf("get in mem storage error: %w", customError.ErrDeleteAccepted)
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i].Health, res[j].Health
+		switch {
+		case a == nil && b == nil:
+			return res[i].ID.String() < res[j].ID.String()
+		case a == nil || b == nil:
+			return a == nil
+		case !a.CheckedAt.Equal(b.CheckedAt):
+			return a.CheckedAt.Before(b.CheckedAt)
+		}
+		return res[i].ID.String() < res[j].ID.String()
+	})
+
+	if len(res) > limit {
+		res = res[:limit]
 	}
-	return u, nil
+	return res, nil
+}
+
+// UpdateHealth сохраняет результат проверки доступности URL в in-memory хранилище.
+// Возвращает ErrNotFound если ключ не существует.
+func (m *Repository) UpdateHealth(ctx context.Context, id uuid.UUID, health *models.LinkHealth) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	link, ok := m.memRepository[id]
+	if !ok || link == nil {
+		return fmt.Errorf("update health in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	link.Health = health
+	return nil
+}
+
+// UpdatePageMeta сохраняет метаданные страницы назначения URL в in-memory хранилище.
+// Возвращает ErrNotFound если ключ не существует.
+func (m *Repository) UpdatePageMeta(ctx context.Context, id uuid.UUID, page *models.PageMeta) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	link, ok := m.memRepository[id]
+	if !ok || link == nil {
+		return fmt.Errorf("update page meta in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	link.Page = page
+	return nil
 }
 
-// GetAllByUserID получает все URL, ассоциированные с конкретным пользователем.
+// UpdateScheduleStates фиксирует состояния окон активности URL в in-memory хранилище на момент now.
+// Возвращает события по URL, состояние которых изменилось, упорядоченные по UUID.
+func (m *Repository) UpdateScheduleStates(ctx context.Context, now time.Time) ([]*models.ScheduleEvent, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	var res []*models.ScheduleEvent
+	for id, link := range m.memRepository {
+		if link == nil || link.IsDeleted {
+			continue
+		}
+
+		state := link.ScheduleStateAt(now)
+		if state == link.ScheduleState {
+			continue
+		}
+
+		link.ScheduleState = state
+		if state == "" {
+			continue
+		}
+		res = append(res, &models.ScheduleEvent{
+			ID:        id,
+			UserID:    link.UserID,
+			State:     state,
+			ChangedAt: now.UTC(),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID.String() < res[j].ID.String()
+	})
+	return res, nil
+}
+
+// GetAllByUserID получает страницу URL, ассоциированных с конкретным пользователем.
+// URL упорядочены по времени создания и UUID, отфильтрованы согласно filter.
 // Возвращает ErrNotFound если у пользователя нет сохраненных URL.
-func (m *Repository) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]*models.ResponseShortenAPIUser, error) {
+func (m *Repository) GetAllByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs) ([]*models.ResponseShortenAPIUser, error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
 	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
 	defer cancel()
 
-	urls, ok := m.userRepository[userID]
-	if !ok {
+	if _, ok := m.userRepository[userID]; !ok {
 		return nil, fmt.Errorf("get all in mem storage error: %w", customError.ErrNotFound)
 	}
+	return m.filterByUserID(userID, filter), nil
+}
+
+// ExportByUserID вызывает fn для каждого URL пользователя, отобранного filter, в порядке GetAllByUserID.
+// Под блокировкой отбираются только UUID URL, а ответ по каждому URL формируется отдельно непосредственно перед вызовом fn,
+// поэтому в памяти не собирается весь экспорт, а медленный получатель не задерживает хранилище.
+// URL, удаленные или измененные после отбора так, что больше не подходят под filter, пропускаются.
+// Возвращает первую ошибку fn, после которой обход прекращается.
+func (m *Repository) ExportByUserID(ctx context.Context, userID uuid.UUID, filter *models.FilterURLs, fn func(*models.ResponseShortenAPIUser) error) error {
+	m.mux.Lock()
+	ids := m.idsByUserID(userID, filter)
+	m.mux.Unlock()
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("export in mem storage error: %w", err)
+		}
 
-	res := make([]*models.ResponseShortenAPIUser, 0, len(urls))
-	for k, v := range urls {
-		u := models.ResponseShortenAPIUser{
-			ShortURL:    config.URL + k.String(),
-			OriginalURL: v.String(),
+		u := m.exportLink(userID, id, filter)
+		if u == nil {
+			continue
+		}
+		if err := fn(u); err != nil {
+			return fmt.Errorf("export in mem storage error: %w", err)
 		}
-		res = append(res, &u)
 	}
-	return res, nil
+	return nil
+}
+
+// exportLink возвращает ответ по URL пользователя или nil, если URL больше не подходит под filter.
+func (m *Repository) exportLink(userID, id uuid.UUID, filter *models.FilterURLs) *models.ResponseShortenAPIUser {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	link, ok := m.userRepository[userID][id]
+	if !ok || !matchFilter(link, filter) {
+		return nil
+	}
+	return models.LinkToResponseUser(link)
+}
+
+// filterByUserID возвращает URL пользователя, отобранные и упорядоченные согласно filter.
+// Вызывается под блокировкой хранилища.
+func (m *Repository) filterByUserID(userID uuid.UUID, filter *models.FilterURLs) []*models.ResponseShortenAPIUser {
+	urls := m.userRepository[userID]
+	ids := m.idsByUserID(userID, filter)
+
+	res := make([]*models.ResponseShortenAPIUser, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, models.LinkToResponseUser(urls[id]))
+	}
+	return res
+}
+
+// idsByUserID возвращает UUID URL пользователя, отобранных и упорядоченных согласно filter.
+// Вызывается под блокировкой хранилища.
+func (m *Repository) idsByUserID(userID uuid.UUID, filter *models.FilterURLs) []uuid.UUID {
+	urls := m.userRepository[userID]
+	links := make([]*models.Link, 0, len(urls))
+	for _, link := range urls {
+		if matchFilter(link, filter) {
+			links = append(links, link)
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		a, b := links[i], links[j]
+		if filter.Desc {
+			a, b = b, a
+		}
+		c := models.Cursor{CreatedAt: b.CreatedAt, ID: b.ID}
+		return c.Less(a.CreatedAt, a.ID)
+	})
+
+	if filter.Limit > 0 && len(links) > filter.Limit {
+		links = links[:filter.Limit]
+	}
+
+	ids := make([]uuid.UUID, 0, len(links))
+	for _, link := range links {
+		ids = append(ids, link.ID)
+	}
+	return ids
 }
 
 // DeleteBatchByUserID помечает несколько URL как удаленные для конкретного пользователя.
+// Возвращает UUID помеченных URL: чужие, уже удаленные и несуществующие UUID пропускаются.
 // Возвращает ErrNotFound если у пользователя нет сохраненных URL.
-func (m *Repository) DeleteBatchByUserID(ctx context.Context, userID uuid.UUID, batch []uuid.UUID) error {
+func (m *Repository) DeleteBatchByUserID(ctx context.Context, tx pgx.Tx, userID uuid.UUID, batch []uuid.UUID) ([]uuid.UUID, error) {
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
@@ -172,12 +450,176 @@ func (m *Repository) DeleteBatchByUserID(ctx context.Context, userID uuid.UUID,
 
 	urls, ok := m.userRepository[userID]
 	if !ok {
-		return fmt.Errorf("delete batch in mem storage error: %w", customError.ErrNotFound)
+		return nil, fmt.Errorf("delete batch in mem storage error: %w", customError.ErrNotFound)
 	}
 
+	deleted := make([]uuid.UUID, 0, len(batch))
 	for _, b := range batch {
-		urls[b] = nil
-		m.memRepository[b] = nil
+		if link, ok := urls[b]; ok && !link.IsDeleted {
+			link.IsDeleted = true
+			deleted = append(deleted, b)
+		}
+	}
+	return deleted, nil
+}
+
+// UpdateByUserID изменяет оригинальный URL пользователя и добавляет запись в историю изменений.
+// Заполняет change.OldURL предыдущим значением и сбрасывает результат проверки доступности и метаданные страницы.
+// Возвращает ErrNotFound если URL не принадлежит пользователю или ErrDeleteAccepted если URL был удален.
+func (m *Repository) UpdateByUserID(ctx context.Context, tx pgx.Tx, change *models.URLHistory) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	link, ok := m.userRepository[change.UserID][change.ID]
+	if !ok {
+		return fmt.Errorf("update in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	if link.IsDeleted {
+		return fmt.Errorf("update in mem storage error: %w", customError.ErrDeleteAccepted)
 	}
+
+	change.OldURL = link.OriginalURL
+	link.OriginalURL = change.NewURL
+	link.Health, link.Page = nil, nil
+	m.historyRepository[change.ID] = append(m.historyRepository[change.ID], change)
 	return nil
 }
+
+// GetHistoryByUserID получает историю изменений URL пользователя в порядке их применения.
+// Возвращает ErrNotFound если URL не принадлежит пользователю.
+func (m *Repository) GetHistoryByUserID(ctx context.Context, userID, id uuid.UUID) ([]*models.URLHistory, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	_, ok := m.userRepository[userID][id]
+	if !ok {
+		return nil, fmt.Errorf("get history in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	res := make([]*models.URLHistory, len(m.historyRepository[id]))
+	copy(res, m.historyRepository[id])
+	return res, nil
+}
+
+// save сохраняет запись в основное и пользовательское хранилища.
+// Существующая запись обновляется на месте с сохранением времени создания,
+// пользовательские атрибуты заменяются только если заданы. Если запись сохраняет
+// другой пользователь, она переходит к нему, как и в PostgreSQL хранилище.
+// Вызывающий должен удерживать мьютекс.
+func (m *Repository) save(link *models.Link) {
+	owner := link.UserID
+	if existing, ok := m.memRepository[link.ID]; ok {
+		existing.OriginalURL = link.OriginalURL
+		existing.IsDeleted = false
+		if !link.LinkMeta.IsEmpty() {
+			existing.LinkMeta = link.LinkMeta
+		}
+		if link.PasswordHash != "" {
+			existing.PasswordHash = link.PasswordHash
+		}
+		if owner != nil && (existing.UserID == nil || *existing.UserID != *owner) {
+			if existing.UserID != nil {
+				delete(m.userRepository[*existing.UserID], existing.ID)
+			}
+			existing.UserID = owner
+		}
+		link = existing
+	} else {
+		m.memRepository[link.ID] = link
+	}
+
+	if owner == nil {
+		return
+	}
+
+	_, ok := m.userRepository[*owner]
+	if !ok {
+		m.userRepository[*owner] = make(map[uuid.UUID]*models.Link)
+	}
+	m.userRepository[*owner][link.ID] = link
+}
+
+// saveBatch проверяет все URL пакета и сохраняет те из них, которых еще нет в хранилище.
+func (m *Repository) saveBatch(userID *uuid.UUID, batch []*models.RequestShortenAPIBatch) ([]bool, error) {
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("save batch in mem storage error: %w", customError.ErrBatchIsEmpty)
+	}
+
+	urls := make([]*url.URL, 0, len(batch))
+	for _, b := range batch {
+		u, err := url.Parse(b.OriginalURL)
+		if err != nil {
+			return nil, fmt.Errorf("save batch in mem storage error: %w", customError.ErrURLNotValid)
+		}
+		urls = append(urls, u)
+	}
+
+	createdAt := time.Now().UTC()
+	created := make([]bool, len(batch))
+	for i, b := range batch {
+		id := b.LinkID()
+		if _, ok := m.memRepository[id]; ok {
+			continue
+		}
+
+		m.save(&models.Link{
+			ID:           id,
+			UserID:       userID,
+			OriginalURL:  urls[i].String(),
+			CreatedAt:    createdAt,
+			PasswordHash: b.PasswordHash,
+			LinkMeta:     b.LinkMeta,
+		})
+		created[i] = true
+	}
+	return created, nil
+}
+
+// matchFilter проверяет соответствие записи фильтрам и позиции курсора.
+func matchFilter(link *models.Link, filter *models.FilterURLs) bool {
+	if link.IsDeleted && !filter.WithDeleted {
+		return false
+	}
+
+	if c := filter.Cursor; c != nil {
+		pos := models.Cursor{CreatedAt: link.CreatedAt, ID: link.ID}
+		if filter.Desc && !c.Less(pos.CreatedAt, pos.ID) || !filter.Desc && !pos.Less(c.CreatedAt, c.ID) {
+			return false
+		}
+	}
+
+	if filter.CreatedFrom != nil && link.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+
+	if filter.CreatedTo != nil && !link.CreatedAt.Before(*filter.CreatedTo) {
+		return false
+	}
+
+	if filter.Search != "" && !strings.Contains(strings.ToLower(link.OriginalURL), strings.ToLower(filter.Search)) {
+		return false
+	}
+
+	if filter.Tag != "" && !slices.Contains(link.Tags, filter.Tag) {
+		return false
+	}
+
+	if filter.FolderID != nil && (link.FolderID == nil || *link.FolderID != *filter.FolderID) {
+		return false
+	}
+
+	if filter.Domain != "" {
+		u, err := url.Parse(link.OriginalURL)
+		if err != nil || !strings.EqualFold(u.Hostname(), filter.Domain) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package mem
+
+import (
+	"context"
+	"slices"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveOutbox записывает доменные события в outbox in-memory хранилища.
+func (m *Repository) SaveOutbox(ctx context.Context, tx pgx.Tx, events []*models.OutboxEvent) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	for _, event := range events {
+		e := *event
+		m.outboxRepository = append(m.outboxRepository, &e)
+	}
+	return nil
+}
+
+// GetOutbox получает до limit неопубликованных событий из in-memory хранилища в порядке записи.
+func (m *Repository) GetOutbox(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	res := make([]*models.OutboxEvent, 0, min(limit, len(m.outboxRepository)))
+	for _, event := range m.outboxRepository[:min(limit, len(m.outboxRepository))] {
+		e := *event
+		res = append(res, &e)
+	}
+	return res, nil
+}
+
+// DeleteOutbox удаляет опубликованные события из in-memory хранилища.
+func (m *Repository) DeleteOutbox(ctx context.Context, ids []uuid.UUID) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	m.outboxRepository = slices.DeleteFunc(m.outboxRepository, func(event *models.OutboxEvent) bool {
+		return slices.Contains(ids, event.ID)
+	})
+	return nil
+}
@@ -0,0 +1,102 @@
+package mem
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// SaveTemplate сохраняет шаблон UTM разметки пользователя в in-memory хранилище.
+// Возвращает ErrConflict если у пользователя уже есть шаблон с таким названием.
+func (m *Repository) SaveTemplate(ctx context.Context, template *models.UTMTemplate) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	templates, ok := m.templateRepository[template.UserID]
+	if !ok {
+		templates = make(map[uuid.UUID]*models.UTMTemplate)
+		m.templateRepository[template.UserID] = templates
+	}
+
+	for _, t := range templates {
+		if t.Name == template.Name {
+			return fmt.Errorf("save template in mem storage error: %w", customError.ErrConflict)
+		}
+	}
+
+	t := *template
+	templates[template.ID] = &t
+	return nil
+}
+
+// GetTemplatesByUserID получает все шаблоны UTM разметки пользователя из in-memory хранилища в порядке создания.
+func (m *Repository) GetTemplatesByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UTMTemplate, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	res := make([]*models.UTMTemplate, 0, len(m.templateRepository[userID]))
+	for _, t := range m.templateRepository[userID] {
+		template := *t
+		res = append(res, &template)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+// UpdateTemplateByUserID изменяет шаблон UTM разметки пользователя в in-memory хранилище.
+// Возвращает ErrNotFound если шаблон не существует или ErrConflict если название занято.
+func (m *Repository) UpdateTemplateByUserID(ctx context.Context, template *models.UTMTemplate) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	t, ok := m.templateRepository[template.UserID][template.ID]
+	if !ok {
+		return fmt.Errorf("update template in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	for _, other := range m.templateRepository[template.UserID] {
+		if other.ID != template.ID && other.Name == template.Name {
+			return fmt.Errorf("update template in mem storage error: %w", customError.ErrConflict)
+		}
+	}
+
+	t.Name = template.Name
+	t.UTMParams = template.UTMParams
+	template.CreatedAt = t.CreatedAt
+	return nil
+}
+
+// DeleteTemplateByUserID удаляет шаблон UTM разметки пользователя из in-memory хранилища.
+// Возвращает ErrNotFound если шаблон не существует или не принадлежит пользователю.
+func (m *Repository) DeleteTemplateByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	_, ok := m.templateRepository[userID][id]
+	if !ok {
+		return fmt.Errorf("delete template in mem storage error: %w", customError.ErrNotFound)
+	}
+	delete(m.templateRepository[userID], id)
+	return nil
+}
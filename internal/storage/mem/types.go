@@ -1,10 +1,10 @@
 package mem
 
 import (
-	"net/url"
 	"sync"
 
 	"github.com/IvanKondrashkov/go-shortener/internal/logger"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
 	"github.com/IvanKondrashkov/go-shortener/internal/service"
 
 	"github.com/google/uuid"
@@ -15,19 +15,40 @@ import (
 type Repository struct {
 	service.Runner
 	service.Repository
-	Logger         *logger.ZapLogger                    // Логгер для записи событий
-	mux            sync.Mutex                           // Мьютекс для потокобезопасного доступа
-	memRepository  map[uuid.UUID]*url.URL               // Основное хранилище URL
-	userRepository map[uuid.UUID]map[uuid.UUID]*url.URL // Хранилище URL по пользователям
+	Logger                *logger.ZapLogger                               // Логгер для записи событий
+	mux                   sync.Mutex                                      // Мьютекс для потокобезопасного доступа
+	memRepository         map[uuid.UUID]*models.Link                      // Основное хранилище URL
+	userRepository        map[uuid.UUID]map[uuid.UUID]*models.Link        // Хранилище URL по пользователям
+	historyRepository     map[uuid.UUID][]*models.URLHistory              // История изменений URL
+	folderRepository      map[uuid.UUID]map[uuid.UUID]*models.Folder      // Папки по пользователям
+	templateRepository    map[uuid.UUID]map[uuid.UUID]*models.UTMTemplate // Шаблоны UTM разметки по пользователям
+	webhookRepository     map[uuid.UUID]map[uuid.UUID]*models.Webhook     // Webhook по пользователям
+	deliveryRepository    map[uuid.UUID][]*models.WebhookDelivery         // Журнал доставок по webhook
+	outboxRepository      []*models.OutboxEvent                           // Неопубликованные доменные события в порядке записи
+	idempotencyRepository map[idempotencyKey]*models.IdempotencyRecord    // Ответы на запросы с Idempotency-Key
+	aliasRepository       map[string]uuid.UUID                            // UUID ссылок по коротким именам
+}
+
+// idempotencyKey ключ записи Idempotency-Key: ключи разных пользователей не пересекаются
+type idempotencyKey struct {
+	userID uuid.UUID
+	key    string
 }
 
 // NewRepository создает новый экземпляр in-memory хранилища.
 // Принимает логгер и возвращает инициализированный Repository.
 func NewRepository(zl *logger.ZapLogger) *Repository {
 	return &Repository{
-		Logger:         zl,
-		mux:            sync.Mutex{},
-		memRepository:  make(map[uuid.UUID]*url.URL),
-		userRepository: make(map[uuid.UUID]map[uuid.UUID]*url.URL),
+		Logger:                zl,
+		mux:                   sync.Mutex{},
+		memRepository:         make(map[uuid.UUID]*models.Link),
+		userRepository:        make(map[uuid.UUID]map[uuid.UUID]*models.Link),
+		historyRepository:     make(map[uuid.UUID][]*models.URLHistory),
+		folderRepository:      make(map[uuid.UUID]map[uuid.UUID]*models.Folder),
+		templateRepository:    make(map[uuid.UUID]map[uuid.UUID]*models.UTMTemplate),
+		webhookRepository:     make(map[uuid.UUID]map[uuid.UUID]*models.Webhook),
+		deliveryRepository:    make(map[uuid.UUID][]*models.WebhookDelivery),
+		idempotencyRepository: make(map[idempotencyKey]*models.IdempotencyRecord),
+		aliasRepository:       make(map[string]uuid.UUID),
 	}
 }
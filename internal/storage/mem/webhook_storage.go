@@ -0,0 +1,136 @@
+package mem
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+	customError "github.com/IvanKondrashkov/go-shortener/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// maxDeliveries количество последних попыток доставки, хранимых для каждого webhook
+const maxDeliveries = 100
+
+// SaveWebhook сохраняет webhook пользователя в in-memory хранилище.
+func (m *Repository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	webhooks, ok := m.webhookRepository[webhook.UserID]
+	if !ok {
+		webhooks = make(map[uuid.UUID]*models.Webhook)
+		m.webhookRepository[webhook.UserID] = webhooks
+	}
+
+	w := *webhook
+	w.Events = slices.Clone(webhook.Events)
+	webhooks[webhook.ID] = &w
+	return nil
+}
+
+// GetWebhooksByUserID получает все webhook пользователя из in-memory хранилища в порядке создания.
+func (m *Repository) GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	res := make([]*models.Webhook, 0, len(m.webhookRepository[userID]))
+	for _, w := range m.webhookRepository[userID] {
+		webhook := *w
+		webhook.Events = slices.Clone(w.Events)
+		res = append(res, &webhook)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+// UpdateWebhookByUserID сохраняет секреты webhook пользователя после ротации в in-memory хранилище.
+// Возвращает ErrNotFound если webhook не существует или не принадлежит пользователю.
+func (m *Repository) UpdateWebhookByUserID(ctx context.Context, webhook *models.Webhook) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	w, ok := m.webhookRepository[webhook.UserID][webhook.ID]
+	if !ok {
+		return fmt.Errorf("update webhook in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	w.Secret, w.PreviousSecret, w.RotatedAt = webhook.Secret, webhook.PreviousSecret, webhook.RotatedAt
+	webhook.URL, webhook.Events, webhook.CreatedAt = w.URL, slices.Clone(w.Events), w.CreatedAt
+	return nil
+}
+
+// DeleteWebhookByUserID удаляет webhook пользователя и его журнал доставок из in-memory хранилища.
+// Возвращает ErrNotFound если webhook не существует или не принадлежит пользователю.
+func (m *Repository) DeleteWebhookByUserID(ctx context.Context, userID, id uuid.UUID) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	_, ok := m.webhookRepository[userID][id]
+	if !ok {
+		return fmt.Errorf("delete webhook in mem storage error: %w", customError.ErrNotFound)
+	}
+	delete(m.webhookRepository[userID], id)
+	delete(m.deliveryRepository, id)
+	return nil
+}
+
+// SaveWebhookDelivery записывает попытку доставки в журнал webhook в in-memory хранилище.
+// Хранит только последние maxDeliveries попыток каждого webhook.
+func (m *Repository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	d := *delivery
+	deliveries := append(m.deliveryRepository[delivery.WebhookID], &d)
+	if len(deliveries) > maxDeliveries {
+		deliveries = slices.Clone(deliveries[len(deliveries)-maxDeliveries:])
+	}
+	m.deliveryRepository[delivery.WebhookID] = deliveries
+	return nil
+}
+
+// GetWebhookDeliveries получает до limit последних попыток доставки webhook пользователя из in-memory хранилища,
+// начиная с новых. Возвращает ErrNotFound если webhook не существует или не принадлежит пользователю.
+func (m *Repository) GetWebhookDeliveries(ctx context.Context, userID, id uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	_, cancel := context.WithTimeout(ctx, config.TerminationTimeout)
+	defer cancel()
+
+	_, ok := m.webhookRepository[userID][id]
+	if !ok {
+		return nil, fmt.Errorf("get webhook deliveries in mem storage error: %w", customError.ErrNotFound)
+	}
+
+	deliveries := m.deliveryRepository[id]
+	res := make([]*models.WebhookDelivery, 0, min(limit, len(deliveries)))
+	for i := len(deliveries) - 1; i >= 0 && len(res) < limit; i-- {
+		d := *deliveries[i]
+		res = append(res, &d)
+	}
+	return res, nil
+}
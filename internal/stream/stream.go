@@ -0,0 +1,158 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// NewHub создает рассылку событий потоков SSE
+// Принимает:
+// - size: количество последних событий в буфере для продолжения по Last-Event-ID
+// - queue: размер очереди событий одного соединения
+func NewHub(size, queue int) *Hub {
+	return &Hub{
+		seq:         uint64(time.Now().UnixMicro()),
+		buffer:      make([]models.StreamEvent, max(size, 1)),
+		queue:       max(queue, 1),
+		subscribers: make(map[uuid.UUID]map[*Subscriber]struct{}),
+	}
+}
+
+// Publish присваивает событию номер, записывает его в буфер и отправляет подписчикам владельца ссылки
+// Публикация не блокируется: подписчик с переполненной очередью завершается
+// Принимает:
+// - event: событие с заполненным UserID
+func (h *Hub) Publish(event models.StreamEvent) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.seq++
+	event.ID = h.seq
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	h.push(event)
+
+	for s := range h.subscribers[event.UserID] {
+		select {
+		case s.events <- event:
+		default:
+			h.remove(event.UserID, s)
+		}
+	}
+}
+
+// Subscribe подписывает соединение на события пользователя
+// Подписка и выборка пропущенных событий выполняются атомарно, поэтому события не теряются и не повторяются
+// Принимает:
+// - userID: UUID пользователя
+// - lastID: номер последнего полученного события или nil для нового потока
+// Возвращает:
+// - подписку, которую нужно завершить через Unsubscribe
+// - события пользователя из буфера с номером больше lastID
+// - true, если часть событий после lastID уже вытеснена из буфера или номер не из этого запуска
+func (h *Hub) Subscribe(userID uuid.UUID, lastID *uint64) (*Subscriber, []models.StreamEvent, bool) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	s := &Subscriber{
+		events: make(chan models.StreamEvent, h.queue),
+		done:   make(chan struct{}),
+	}
+	if h.closed {
+		s.stop()
+		return s, nil, false
+	}
+
+	subscribers, ok := h.subscribers[userID]
+	if !ok {
+		subscribers = make(map[*Subscriber]struct{})
+		h.subscribers[userID] = subscribers
+	}
+	subscribers[s] = struct{}{}
+
+	if lastID == nil {
+		return s, nil, false
+	}
+
+	oldest := h.seq + 1
+	if h.size > 0 {
+		oldest = h.buffer[h.head].ID
+	}
+	reset := *lastID+1 < oldest || *lastID > h.seq
+
+	missed := make([]models.StreamEvent, 0)
+	for i := 0; i < h.size; i++ {
+		event := h.buffer[(h.head+i)%len(h.buffer)]
+		if event.ID > *lastID && event.UserID == userID {
+			missed = append(missed, event)
+		}
+	}
+	return s, missed, reset
+}
+
+// Unsubscribe завершает подписку соединения
+func (h *Hub) Unsubscribe(userID uuid.UUID, s *Subscriber) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.remove(userID, s)
+}
+
+// Close завершает все подписки, чтобы открытые потоки закрылись при остановке сервера
+func (h *Hub) Close() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.closed = true
+	for userID, subscribers := range h.subscribers {
+		for s := range subscribers {
+			s.stop()
+		}
+		delete(h.subscribers, userID)
+	}
+}
+
+// Events возвращает очередь событий подписки
+func (s *Subscriber) Events() <-chan models.StreamEvent {
+	return s.events
+}
+
+// Done возвращает канал, закрываемый при завершении подписки
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// push записывает событие в кольцевой буфер, вытесняя самое старое
+func (h *Hub) push(event models.StreamEvent) {
+	h.buffer[(h.head+h.size)%len(h.buffer)] = event
+	if h.size < len(h.buffer) {
+		h.size++
+		return
+	}
+	h.head = (h.head + 1) % len(h.buffer)
+}
+
+// remove удаляет подписчика пользователя и завершает его подписку
+func (h *Hub) remove(userID uuid.UUID, s *Subscriber) {
+	subscribers := h.subscribers[userID]
+	delete(subscribers, s)
+	if len(subscribers) == 0 {
+		delete(h.subscribers, userID)
+	}
+	s.stop()
+}
+
+// stop закрывает канал завершения подписки один раз
+func (s *Subscriber) stop() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublish(t *testing.T) {
+	h := NewHub(10, 10)
+	userID, otherID := uuid.New(), uuid.New()
+	s, missed, reset := h.Subscribe(userID, nil)
+	defer h.Unsubscribe(userID, s)
+	assert.Empty(t, missed)
+	assert.False(t, reset)
+
+	h.Publish(models.StreamEvent{Type: models.StreamEventClick, UserID: otherID})
+	h.Publish(models.StreamEvent{Type: models.StreamEventDelete, UserID: userID})
+
+	require.Len(t, s.Events(), 1)
+	event := <-s.Events()
+	assert.Equal(t, models.StreamEventDelete, event.Type)
+	assert.NotZero(t, event.ID)
+	assert.False(t, event.CreatedAt.IsZero())
+}
+
+func TestResume(t *testing.T) {
+	h := NewHub(3, 10)
+	userID, otherID := uuid.New(), uuid.New()
+	s, _, _ := h.Subscribe(userID, nil)
+	h.Publish(models.StreamEvent{Type: models.StreamEventClick, UserID: userID})
+	h.Publish(models.StreamEvent{Type: models.StreamEventClick, UserID: otherID})
+	h.Publish(models.StreamEvent{Type: models.StreamEventDelete, UserID: userID})
+	first := <-s.Events()
+	h.Unsubscribe(userID, s)
+
+	tests := []struct {
+		name   string
+		lastID uint64
+		missed int
+		reset  bool
+	}{
+		{
+			name:   "resume after first event",
+			lastID: first.ID,
+			missed: 1,
+		},
+		{
+			name:   "id from previous run",
+			lastID: 1,
+			missed: 2,
+			reset:  true,
+		},
+		{
+			name:   "id from future",
+			lastID: first.ID + 100,
+			reset:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, missed, reset := h.Subscribe(userID, &tt.lastID)
+			defer h.Unsubscribe(userID, s)
+
+			assert.Len(t, missed, tt.missed)
+			assert.Equal(t, tt.reset, reset)
+		})
+	}
+
+	// Четвертое событие вытесняет первое из буфера на три события
+	h.Publish(models.StreamEvent{Type: models.StreamEventClick, UserID: otherID})
+	s, missed, reset := h.Subscribe(userID, &first.ID)
+	defer h.Unsubscribe(userID, s)
+	assert.Len(t, missed, 1)
+	assert.False(t, reset)
+
+	lastID := first.ID - 1
+	s, _, reset = h.Subscribe(userID, &lastID)
+	defer h.Unsubscribe(userID, s)
+	assert.True(t, reset)
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	h := NewHub(10, 1)
+	userID := uuid.New()
+	s, _, _ := h.Subscribe(userID, nil)
+
+	h.Publish(models.StreamEvent{Type: models.StreamEventClick, UserID: userID})
+	select {
+	case <-s.Done():
+		t.Fatal("subscriber is done before overflow")
+	default:
+	}
+
+	h.Publish(models.StreamEvent{Type: models.StreamEventClick, UserID: userID})
+	<-s.Done()
+	assert.Empty(t, h.subscribers)
+}
+
+func TestClose(t *testing.T) {
+	h := NewHub(10, 10)
+	userID := uuid.New()
+	s, _, _ := h.Subscribe(userID, nil)
+
+	h.Close()
+	<-s.Done()
+
+	s, _, _ = h.Subscribe(userID, nil)
+	<-s.Done()
+	h.Publish(models.StreamEvent{Type: models.StreamEventClick, UserID: userID})
+	assert.Empty(t, s.Events())
+}
@@ -0,0 +1,34 @@
+// Package stream содержит рассылку событий о переходах и удалениях ссылок открытым потокам SSE пользователей
+// с буфером последних событий для продолжения по Last-Event-ID
+package stream
+
+import (
+	"sync"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Hub рассылает события подписчикам владельца ссылки и хранит последние события всех пользователей
+// в кольцевом буфере. Номера событий возрастают и начинаются с времени запуска в микросекундах,
+// поэтому Last-Event-ID из предыдущего запуска приводит к событию reset, а не к пропуску событий
+type Hub struct {
+	mux         sync.Mutex                             // Мьютекс для буфера и подписчиков
+	seq         uint64                                 // Номер последнего события
+	buffer      []models.StreamEvent                   // Кольцевой буфер последних событий
+	head        int                                    // Индекс самого старого события в буфере
+	size        int                                    // Количество событий в буфере
+	queue       int                                    // Размер очереди событий подписчика
+	subscribers map[uuid.UUID]map[*Subscriber]struct{} // Подписчики по пользователям
+	closed      bool                                   // Рассылка остановлена, новые подписчики сразу завершаются
+}
+
+// Subscriber подписка одного соединения SSE
+// Если соединение не успевает забирать события и очередь переполняется, подписка завершается,
+// а клиент переподключается с Last-Event-ID и получает пропущенные события из буфера
+type Subscriber struct {
+	events chan models.StreamEvent // Очередь событий соединения
+	done   chan struct{}           // Закрывается при завершении подписки
+	once   sync.Once               // Для однократного закрытия done
+}
@@ -0,0 +1,56 @@
+// Package webhook содержит доставку событий жизненного цикла ссылок на адреса пользователей
+// с подписью HMAC-SHA256, повторами и журналом доставок
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Заголовки запроса доставки события
+const (
+	HeaderID        = "X-Webhook-ID"        // UUID события, одинаковый для всех попыток доставки
+	HeaderEvent     = "X-Webhook-Event"     // Тип события
+	HeaderTimestamp = "X-Webhook-Timestamp" // Время попытки в секундах Unix, входит в подпись
+	HeaderSignature = "X-Webhook-Signature" // Подписи тела через запятую в формате sha256=<hex>
+)
+
+const (
+	bufQueue        = 100                        // Размер очереди событий
+	signaturePrefix = "sha256="                  // Префикс подписи в заголовке X-Webhook-Signature
+	userAgent       = "go-shortener-webhook/1.0" // Заголовок User-Agent запросов доставки
+	maxErrorLength  = 255                        // Максимальная длина текста ошибки в журнале доставок
+)
+
+var (
+	// ErrStatusNotOK возвращается когда получатель отвечает кодом, отличным от 2xx
+	ErrStatusNotOK = errors.New("webhook status is not ok")
+	// ErrThresholdNotValid возвращается когда порог переходов не является положительным числом
+	ErrThresholdNotValid = errors.New("threshold is invalidate")
+)
+
+// Store хранилище webhook пользователей и журнала их доставок
+type Store interface {
+	// GetWebhooksByUserID получает все webhook пользователя
+	GetWebhooksByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error)
+	// SaveWebhookDelivery записывает попытку доставки события в журнал
+	SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// Dispatcher принимает события жизненного цикла ссылок и доставляет их на подписанные адреса пользователей
+// Соединения с внутренними адресами отклоняются так же, как при проверке доступности (linkcheck)
+type Dispatcher struct {
+	store       Store                    // Хранилище webhook и журнала доставок
+	client      *http.Client             // Клиент с защитой от SSRF, не следующий перенаправлениям
+	queue       chan models.WebhookEvent // Очередь событий на доставку
+	thresholds  map[int64]struct{}       // Пороги переходов для события link.click_threshold
+	maxAttempts int                      // Максимальное количество попыток доставки
+	backoff     time.Duration            // Задержка перед первым повтором
+	grace       time.Duration            // Время после ротации, в течение которого добавляется подпись старым секретом
+}
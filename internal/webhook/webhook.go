@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/config"
+	"github.com/IvanKondrashkov/go-shortener/internal/linkcheck"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// NewDispatcher создает доставку событий webhook с таймаутом, количеством попыток, задержкой повторов
+// и временем действия старого секрета из конфигурации
+// Принимает:
+// - s: хранилище webhook и журнала доставок
+// - allow: внутренние сети, запросы к которым разрешены (может быть nil)
+// - thresholds: пороги переходов для события link.click_threshold
+func NewDispatcher(s Store, allow []netip.Prefix, thresholds []int64) *Dispatcher {
+	d := &Dispatcher{
+		store: s,
+		client: &http.Client{
+			Timeout:   config.WebhookTimeout,
+			Transport: linkcheck.NewTransport(config.WebhookTimeout, allow),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		queue:       make(chan models.WebhookEvent, bufQueue),
+		thresholds:  make(map[int64]struct{}, len(thresholds)),
+		maxAttempts: max(config.WebhookMaxAttempts, 1),
+		backoff:     config.WebhookBackoff,
+		grace:       config.WebhookSecretGrace,
+	}
+	for _, t := range thresholds {
+		d.thresholds[t] = struct{}{}
+	}
+	return d
+}
+
+// ParseThresholds разбирает пороги переходов
+// Принимает:
+// - raw: положительные числа через запятую
+// Возвращает:
+// - пороги или ErrThresholdNotValid
+func ParseThresholds(raw string) ([]int64, error) {
+	var res []int64
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		t, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || t <= 0 {
+			return nil, fmt.Errorf("%s: %w", field, ErrThresholdNotValid)
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// Publish ставит событие в очередь доставки без блокировки
+// Возвращает false, если очередь заполнена и событие отброшено
+func (d *Dispatcher) Publish(event models.WebhookEvent) bool {
+	select {
+	case d.queue <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// Events возвращает очередь событий для воркеров доставки
+func (d *Dispatcher) Events() <-chan models.WebhookEvent {
+	return d.queue
+}
+
+// IsThreshold проверяет, что количество переходов равно одному из порогов события link.click_threshold
+func (d *Dispatcher) IsThreshold(clicks int64) bool {
+	_, ok := d.thresholds[clicks]
+	return ok
+}
+
+// Deliver доставляет событие на все webhook владельца ссылки, подписанные на его тип
+// Каждая попытка записывается в журнал доставок, неудачные попытки повторяются
+// с удвоением задержки до config.WebhookMaxAttempts попыток
+// Принимает:
+// - ctx: контекст, отмена которого прекращает повторы
+// - event: событие жизненного цикла ссылки
+// Возвращает:
+// - ошибку получения webhook или записи журнала доставок
+func (d *Dispatcher) Deliver(ctx context.Context, event models.WebhookEvent) error {
+	webhooks, err := d.store.GetWebhooksByUserID(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("get webhooks error: %w", err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("serialize event error: %w", err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mux  sync.Mutex
+		errs []error
+	)
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.Events, event.Type) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(webhook *models.Webhook) {
+			defer wg.Done()
+			if err := d.send(ctx, webhook, event, body); err != nil {
+				mux.Lock()
+				errs = append(errs, err)
+				mux.Unlock()
+			}
+		}(webhook)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// send доставляет событие на один webhook с повторами и записывает попытки в журнал
+func (d *Dispatcher) send(ctx context.Context, webhook *models.Webhook, event models.WebhookEvent, body []byte) error {
+	delay := d.backoff
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		code, err := d.post(ctx, webhook, event, body)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		delivery := &models.WebhookDelivery{
+			ID:         uuid.New(),
+			WebhookID:  webhook.ID,
+			EventID:    event.ID,
+			EventType:  event.Type,
+			Attempt:    attempt,
+			Status:     models.DeliveryStatusDelivered,
+			StatusCode: code,
+			DurationMs: time.Since(start).Milliseconds(),
+			CreatedAt:  start.UTC(),
+		}
+
+		retry := false
+		if err != nil {
+			delivery.Error = truncate(err.Error(), maxErrorLength)
+			retry = retryable(code, err)
+			delivery.Status = models.DeliveryStatusFailed
+			if retry && attempt < d.maxAttempts {
+				delivery.Status = models.DeliveryStatusRetry
+			}
+		}
+
+		if err := d.store.SaveWebhookDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("save webhook delivery error: %w", err)
+		}
+		if delivery.Status != models.DeliveryStatusRetry {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+		delay *= 2
+	}
+}
+
+// post выполняет одну попытку доставки и возвращает код ответа получателя
+func (d *Dispatcher) post(ctx context.Context, webhook *models.Webhook, event models.WebhookEvent, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("new request error: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	signatures := []string{Sign(webhook.Secret, timestamp, body)}
+	if webhook.PreviousSecret != "" && webhook.RotatedAt != nil && time.Since(*webhook.RotatedAt) < d.grace {
+		signatures = append(signatures, Sign(webhook.PreviousSecret, timestamp, body))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, event.ID.String())
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, strings.Join(signatures, ", "))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("post request error: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxErrorLength))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("status %d: %w", res.StatusCode, ErrStatusNotOK)
+	}
+	return res.StatusCode, nil
+}
+
+// Sign вычисляет подпись тела события
+// Подписывается строка "<timestamp>.<body>" секретом webhook алгоритмом HMAC-SHA256
+// Принимает:
+// - secret: секрет webhook
+// - timestamp: время попытки в секундах Unix из заголовка X-Webhook-Timestamp
+// - body: тело запроса
+// Возвращает:
+// - подпись в формате sha256=<hex>
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет, что одна из подписей заголовка X-Webhook-Signature сделана секретом получателя
+// Принимает:
+// - secret: секрет webhook
+// - header: значение заголовка X-Webhook-Signature
+// - timestamp: время попытки в секундах Unix из заголовка X-Webhook-Timestamp
+// - body: тело запроса
+func Verify(secret, header string, timestamp int64, body []byte) bool {
+	want := []byte(Sign(secret, timestamp, body))
+	for _, signature := range strings.Split(header, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(signature)), want) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryable проверяет, что неудачную попытку нужно повторить:
+// при сетевых ошибках, кроме запрещенных адресов, и при ответах 408, 429 и 5xx
+func retryable(code int, err error) bool {
+	if errors.Is(err, linkcheck.ErrAddressNotAllowed) {
+		return false
+	}
+	if code == 0 {
+		return true
+	}
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// truncate обрезает строку до limit байт
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return s[:limit]
+}
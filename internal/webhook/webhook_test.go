@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IvanKondrashkov/go-shortener/internal/linkcheck"
+	"github.com/IvanKondrashkov/go-shortener/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStore struct {
+	mux        sync.Mutex
+	webhooks   []*models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func (s *testStore) GetWebhooksByUserID(_ context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	var res []*models.Webhook
+	for _, w := range s.webhooks {
+		if w.UserID == userID {
+			res = append(res, w)
+		}
+	}
+	return res, nil
+}
+
+func (s *testStore) SaveWebhookDelivery(_ context.Context, delivery *models.WebhookDelivery) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func newTestDispatcher(t *testing.T, s Store) *Dispatcher {
+	t.Helper()
+
+	allow, err := linkcheck.ParseAllowlist("127.0.0.0/8,::1")
+	require.NoError(t, err)
+	d := NewDispatcher(s, allow, []int64{10, 100})
+	d.maxAttempts, d.backoff = 3, time.Millisecond
+	return d
+}
+
+func TestDeliver(t *testing.T) {
+	var (
+		calls     atomic.Int32
+		signature string
+		body      []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if calls.Add(1) < 3 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		body, _ = io.ReadAll(req.Body)
+		signature = req.Header.Get(HeaderSignature)
+		assert.True(t, Verify("secret", signature, timestamp, body))
+		assert.False(t, Verify("other", signature, timestamp, body))
+		assert.Equal(t, models.WebhookEventCreated, req.Header.Get(HeaderEvent))
+		res.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	userID := uuid.New()
+	hook := &models.Webhook{
+		ID:     uuid.New(),
+		UserID: userID,
+		URL:    srv.URL,
+		Events: []string{models.WebhookEventCreated},
+		Secret: "secret",
+	}
+	s := &testStore{webhooks: []*models.Webhook{
+		hook,
+		{ID: uuid.New(), UserID: userID, URL: srv.URL, Events: []string{models.WebhookEventDeleted}, Secret: "secret"},
+		{ID: uuid.New(), UserID: uuid.New(), URL: srv.URL, Events: []string{models.WebhookEventCreated}, Secret: "secret"},
+	}}
+	d := newTestDispatcher(t, s)
+
+	event := models.WebhookEvent{ID: uuid.New(), Type: models.WebhookEventCreated, UserID: userID, LinkID: uuid.New()}
+	require.NoError(t, d.Deliver(context.Background(), event))
+
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Contains(t, string(body), event.LinkID.String())
+	require.Len(t, s.deliveries, 3)
+	for i, status := range []string{models.DeliveryStatusRetry, models.DeliveryStatusRetry, models.DeliveryStatusDelivered} {
+		assert.Equal(t, hook.ID, s.deliveries[i].WebhookID)
+		assert.Equal(t, event.ID, s.deliveries[i].EventID)
+		assert.Equal(t, i+1, s.deliveries[i].Attempt)
+		assert.Equal(t, status, s.deliveries[i].Status)
+	}
+	assert.Equal(t, http.StatusNoContent, s.deliveries[2].StatusCode)
+}
+
+func TestDeliverFailed(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+		if req.URL.Path == "/gone" {
+			res.WriteHeader(http.StatusGone)
+			return
+		}
+		res.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	userID := uuid.New()
+	s := &testStore{webhooks: []*models.Webhook{
+		{ID: uuid.New(), UserID: userID, URL: srv.URL + "/gone", Events: []string{models.WebhookEventExpired}},
+		{ID: uuid.New(), UserID: userID, URL: srv.URL + "/error", Events: []string{models.WebhookEventExpired}},
+	}}
+	d := newTestDispatcher(t, s)
+
+	event := models.WebhookEvent{ID: uuid.New(), Type: models.WebhookEventExpired, UserID: userID}
+	require.NoError(t, d.Deliver(context.Background(), event))
+
+	assert.Equal(t, int32(1+3), calls.Load())
+	statuses := make(map[uuid.UUID][]string)
+	for _, delivery := range s.deliveries {
+		statuses[delivery.WebhookID] = append(statuses[delivery.WebhookID], delivery.Status)
+		assert.NotEmpty(t, delivery.Error)
+	}
+	assert.Equal(t, []string{models.DeliveryStatusFailed}, statuses[s.webhooks[0].ID])
+	assert.Equal(t, []string{models.DeliveryStatusRetry, models.DeliveryStatusRetry, models.DeliveryStatusFailed},
+		statuses[s.webhooks[1].ID])
+}
+
+func TestDeliverRefused(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	userID := uuid.New()
+	s := &testStore{webhooks: []*models.Webhook{
+		{ID: uuid.New(), UserID: userID, URL: srv.URL, Events: []string{models.WebhookEventDeleted}},
+	}}
+	d := NewDispatcher(s, nil, nil)
+
+	require.NoError(t, d.Deliver(context.Background(), models.WebhookEvent{Type: models.WebhookEventDeleted, UserID: userID}))
+	require.Len(t, s.deliveries, 1)
+	assert.Equal(t, models.DeliveryStatusFailed, s.deliveries[0].Status)
+	assert.Contains(t, s.deliveries[0].Error, linkcheck.ErrAddressNotAllowed.Error())
+}
+
+func TestSignRotated(t *testing.T) {
+	var signature, timestamp string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		signature, timestamp = req.Header.Get(HeaderSignature), req.Header.Get(HeaderTimestamp)
+		body, _ = io.ReadAll(req.Body)
+	}))
+	defer srv.Close()
+
+	rotatedAt := time.Now().UTC()
+	userID := uuid.New()
+	s := &testStore{webhooks: []*models.Webhook{{
+		ID:             uuid.New(),
+		UserID:         userID,
+		URL:            srv.URL,
+		Events:         []string{models.WebhookEventClickThreshold},
+		Secret:         "new",
+		PreviousSecret: "old",
+		RotatedAt:      &rotatedAt,
+	}}}
+	d := newTestDispatcher(t, s)
+
+	event := models.WebhookEvent{Type: models.WebhookEventClickThreshold, UserID: userID}
+	require.NoError(t, d.Deliver(context.Background(), event))
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify("new", signature, ts, body))
+	assert.True(t, Verify("old", signature, ts, body))
+
+	d.grace = 0
+	require.NoError(t, d.Deliver(context.Background(), event))
+	ts, err = strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify("new", signature, ts, body))
+	assert.False(t, Verify("old", signature, ts, body))
+}
+
+func TestThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds(" 10, 100 ,")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{10, 100}, thresholds)
+
+	_, err = ParseThresholds("10,-1")
+	assert.ErrorIs(t, err, ErrThresholdNotValid)
+
+	d := NewDispatcher(&testStore{}, nil, thresholds)
+	assert.True(t, d.IsThreshold(100))
+	assert.False(t, d.IsThreshold(11))
+}
+
+func TestPublish(t *testing.T) {
+	d := NewDispatcher(&testStore{}, nil, nil)
+	for i := 0; i < bufQueue; i++ {
+		assert.True(t, d.Publish(models.WebhookEvent{}))
+	}
+	assert.False(t, d.Publish(models.WebhookEvent{}))
+	assert.Len(t, d.Events(), bufQueue)
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS url_history (
+    id BIGSERIAL PRIMARY KEY,
+    short_url UUID NOT NULL REFERENCES urls (short_url) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    old_url VARCHAR(1000) NOT NULL,
+    new_url VARCHAR(1000) NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS url_history_short_url_idx ON url_history (short_url, changed_at);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS urls_folder_id_idx;
+DROP INDEX IF EXISTS urls_tags_idx;
+
+ALTER TABLE urls
+    DROP COLUMN IF EXISTS folder_id,
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS title;
+
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS folders (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE urls
+    ADD COLUMN IF NOT EXISTS title VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS folder_id UUID NULL REFERENCES folders (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS urls_tags_idx ON urls USING GIN (tags);
+CREATE INDEX IF NOT EXISTS urls_folder_id_idx ON urls (folder_id);
@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    DROP COLUMN IF EXISTS interstitial,
+    DROP COLUMN IF EXISTS clicks;
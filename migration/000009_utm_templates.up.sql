@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS utm_templates (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    medium VARCHAR(255) NOT NULL DEFAULT '',
+    campaign VARCHAR(255) NOT NULL DEFAULT '',
+    content VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
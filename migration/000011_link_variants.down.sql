@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    DROP COLUMN IF EXISTS variant_clicks,
+    DROP COLUMN IF EXISTS variants;
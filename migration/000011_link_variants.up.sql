@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS variant_clicks JSONB NOT NULL DEFAULT '{}';
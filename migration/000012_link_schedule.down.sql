@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS urls_schedule_idx;
+
+ALTER TABLE urls
+    DROP COLUMN IF EXISTS schedule_state,
+    DROP COLUMN IF EXISTS fallback_url,
+    DROP COLUMN IF EXISTS active_until,
+    DROP COLUMN IF EXISTS active_from;
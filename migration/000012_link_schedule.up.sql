@@ -0,0 +1,8 @@
+ALTER TABLE urls
+    ADD COLUMN IF NOT EXISTS active_from TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS active_until TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS fallback_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS schedule_state VARCHAR(16) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS urls_schedule_idx ON urls (short_url)
+    WHERE active_from IS NOT NULL OR active_until IS NOT NULL OR schedule_state <> '';
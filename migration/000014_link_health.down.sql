@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS urls_checked_at_idx;
+
+ALTER TABLE urls
+    DROP COLUMN IF EXISTS health,
+    DROP COLUMN IF EXISTS checked_at;
@@ -0,0 +1,6 @@
+ALTER TABLE urls
+    ADD COLUMN IF NOT EXISTS health JSONB NULL,
+    ADD COLUMN IF NOT EXISTS checked_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS urls_checked_at_idx ON urls (checked_at NULLS FIRST, short_url)
+    WHERE is_deleted IS NOT TRUE AND disabled_reason = '';
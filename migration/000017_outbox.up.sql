@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    link_id UUID NOT NULL,
+    user_id UUID NULL,
+    short_url VARCHAR(255) NOT NULL,
+    original_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
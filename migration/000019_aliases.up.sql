@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS aliases (
+    slug VARCHAR(64) PRIMARY KEY,
+    short_url UUID NOT NULL REFERENCES urls (short_url) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS aliases_short_url_idx ON aliases (short_url);